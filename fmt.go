@@ -12,9 +12,11 @@ func main() {
 
 	var f []byte
 	var err error
+	filename := "<stdin>"
 	if len(args) == 0 {
 		f, err = ioutil.ReadAll(os.Stdin)
 	} else {
+		filename = args[0]
 		f, err = ioutil.ReadFile(args[0])
 		if err != nil {
 			fmt.Println("Formatter: cannot read file", err.Error())
@@ -22,6 +24,10 @@ func main() {
 		}
 	}
 
-	formatter := formatter.New(string(f))
-	formatter.Format()
+	res, err := formatter.Source(filename, f)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	os.Stdout.Write(res)
 }
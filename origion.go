@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"originscript/dap"
 	"originscript/eval"
+	"originscript/formatter"
+	"originscript/linter"
+	"originscript/lsp"
 	"originscript/lexer"
 	"originscript/message"
 	"originscript/parser"
 
-	// "originscript/repl"
+	"originscript/repl"
+	"originscript/tester"
 	"math/rand"
 	"io"
 	"os"
@@ -35,29 +40,37 @@ import (
 	"net/url"
 )
 
-func runProgram(debug bool, filename string) {
+// runProgram runs a program, with 'postMortem', the debugger's prompt is shown where it fails.
+func runProgram(debug bool, postMortem bool, filename string) {
 	wd, err := os.Getwd()
 	if err != nil {
-		fmt.Println(err.Error())
+		printFileError(filename, err)
 		os.Exit(1)
 	}
 	f, err := ioutil.ReadFile(wd + "/" + filename)
 	if err != nil {
-		fmt.Println("OriginScript: ", err.Error())
+		printFileError(filename, err)
 		os.Exit(1)
 	}
 
 	input := string(f)
 	l := lexer.New(filename, input)
 
+	//the debugger needs the information collected while parsing
+	if debug || postMortem {
+		parser.NoCache = true
+	}
 	p := parser.New(l, wd)
-	program := p.ParseProgram()
+	program := p.ParseProgramCached(wd+"/"+filename, f)
 	if len(p.Errors()) != 0 {
-		for _, err := range p.Errors() {
-			fmt.Println(err)
-		}
+		printParseErrors(p)
 		os.Exit(1)
 	}
+	if dumpOptimized {
+		eval.OptimizeProgram(program)
+		os.Stdout.Write(formatter.Print(f, program))
+		return
+	}
 	scope := eval.NewScope(nil, os.Stdout)
 	RegisterGoGlobals()
 
@@ -78,11 +91,25 @@ func runProgram(debug bool, filename string) {
 		eval.MsgHandler = message.NewMessageHandler()
 		eval.MsgHandler.AddListener(eval.Dbg)
 
+	} else if postMortem {
+		eval.Dbg = eval.NewDebugger()
+		eval.Dbg.SetFunctions(p.Functions)
+		eval.Dbg.SetDbgInfos(parser.SplitSlice(parser.DebugInfos))
+		eval.Dbg.Stepping = false
+		eval.Dbg.PostMortem = true
+
+		eval.MsgHandler = message.NewMessageHandler()
+		eval.MsgHandler.AddListener(eval.Dbg)
 	}
 
 	result := eval.Eval(program, scope)
-	if result.Type() == eval.ERROR_OBJ {
-		fmt.Println(result.Inspect())
+	if e, ok := result.(*eval.Error); ok {
+		printRuntimeError(e)
+		if postMortem {
+			eval.REPLColor = true
+			eval.Dbg.EnterPostMortem()
+		}
+		os.Exit(1)
 	}
 
 	//	e := eval.Eval(program, scope)
@@ -90,27 +117,85 @@ func runProgram(debug bool, filename string) {
 	//		fmt.Println(e.Inspect())
 	//	}
 }
+
+//defineOptions applies the '-D NAME' and '-D NAME=VALUE' options preceding the
+//command, the macros are defined in all the parsed files(see '#ifdef').
+func defineOptions(args []string) []string {
+	for len(args) > 0 {
+		var define string
+		switch {
+		case args[0] == "-D" && len(args) >= 2:
+			define, args = args[1], args[2:]
+		case strings.HasPrefix(args[0], "-D") && len(args[0]) > 2:
+			define, args = args[0][2:], args[1:]
+		default:
+			return args
+		}
+		name, value := define, ""
+		if idx := strings.IndexByte(define, '='); idx >= 0 {
+			name, value = define[:idx], define[idx+1:]
+		}
+		parser.Defines[name] = value
+	}
+	return args
+}
+
+//dumpOptimized prints the optimized program instead of running it.
+var dumpOptimized bool
+
+//runOptions applies the options of 'run' and 'lun'(e.g. '--vm') preceding
+//the file name, and returns the remaining arguments.
+func runOptions(args []string) []string {
+	for len(args) > 0 {
+		switch args[0] {
+		case "--vm":
+			eval.UseVM = true
+		case "--no-cache":
+			parser.NoCache = true
+		case "-O":
+			eval.UseOptimizer = true
+		case "--dump-optimized":
+			dumpOptimized = true
+		case "--error-format=json":
+			errorFormat = "json"
+		case "--error-format=text":
+			errorFormat = "text"
+		case "--typecheck":
+			eval.TypeCheck = true
+		case "--exceptions":
+			eval.RaiseExceptions = true
+		default:
+			if strings.HasPrefix(args[0], "--error-format") { //e.g. '--error-format=xml'
+				fmt.Fprintf(os.Stderr, "OriginScript: unknown error format '%s', expected '--error-format=text' or '--error-format=json'.\n", args[0])
+				os.Exit(1)
+			}
+			return args
+		}
+		args = args[1:]
+	}
+	return args
+}
+
 func runProgramDebugList(debug bool, filename string) {
 	wd, err := os.Getwd()
 	if err != nil {
-		fmt.Println(err.Error())
+		printFileError(filename, err)
 		os.Exit(1)
 	}
 	f, err := ioutil.ReadFile(wd + "/" + filename)
 	if err != nil {
-		fmt.Println("OriginScript: ", err.Error())
+		printFileError(filename, err)
 		os.Exit(1)
 	}
 
 	input := string(f)
 	l := lexer.New(filename, input)
 
+	parser.NoCache = true
 	p := parser.New(l, wd)
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		for _, err := range p.Errors() {
-			fmt.Println(err)
-		}
+		printParseErrors(p)
 		os.Exit(1)
 	}
 	scope := eval.NewScope(nil, os.Stdout)
@@ -136,8 +221,9 @@ func runProgramDebugList(debug bool, filename string) {
 	}
 
 	result := eval.Eval(program, scope)
-	if result.Type() == eval.ERROR_OBJ {
-		fmt.Println(result.Inspect())
+	if e, ok := result.(*eval.Error); ok {
+		printRuntimeError(e)
+		os.Exit(1)
 	}
 
 	//	e := eval.Eval(program, scope)
@@ -146,6 +232,97 @@ func runProgramDebugList(debug bool, filename string) {
 	//	}
 }
 
+//errorFormat is the format of the errors of 'run', 'lun' and 'debug', "text" or
+//"json"(see '--error-format').
+var errorFormat = "text"
+
+//errorDiagnostic is an error of the program in the '--error-format=json'
+//output, it's printed on stderr as a JSON array of errors:
+//
+//    [{"code":"eUDE-0019","message":"divide by zero","file":"main.aero","line":2,"column":11,
+//      "stack":[{"function":"<module>","file":"main.aero","line":5,"column":9}, ...]}]
+type errorDiagnostic struct {
+	Code    string       `json:"code"` //e3209(import), e3301(syntax), eUDE-NNNN(runtime), "" if the file cannot be read
+	Message string       `json:"message"`
+	File    string       `json:"file"`
+	Line    int          `json:"line"`
+	Column  int          `json:"column"`
+	Stack   []stackFrame `json:"stack,omitempty"` //the outermost call first
+}
+
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+//printFileError prints the error reading the program's file.
+func printFileError(filename string, err error) {
+	if errorFormat != "json" {
+		fmt.Println("OriginScript: ", err.Error())
+		return
+	}
+	printDiagnostics([]errorDiagnostic{{Message: err.Error(), File: filename}})
+}
+
+//printParseErrors prints the errors of the parser.
+func printParseErrors(p *parser.Parser) {
+	if errorFormat != "json" {
+		for _, err := range p.Errors() {
+			fmt.Println(err)
+		}
+		return
+	}
+
+	diags := []errorDiagnostic{}
+	positions := p.ErrorPositions()
+	for i, err := range p.Errors() {
+		pos := positions[i]
+		d := errorDiagnostic{File: pos.Filename, Line: pos.Line, Column: pos.Col}
+
+		//the message is 'OriginScript: CODE: (POSITION)- TEXT', only the text is kept
+		msg := strings.TrimPrefix(strings.TrimSpace(err), "OriginScript:")
+		if i := strings.Index(msg, ":"); i >= 0 {
+			d.Code, msg = strings.TrimSpace(msg[:i]), strings.TrimSpace(msg[i+1:])
+		}
+		if strings.HasPrefix(msg, "(") {
+			if i := strings.Index(msg, ")"); i >= 0 {
+				msg = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(msg[i+1:]), "-"))
+			}
+		}
+		d.Message = msg
+		diags = append(diags, d)
+	}
+	printDiagnostics(diags)
+}
+
+//printRuntimeError prints the error stopping the program.
+func printRuntimeError(e *eval.Error) {
+	if errorFormat != "json" {
+		fmt.Println(e.Traceback())
+		return
+	}
+
+	d := errorDiagnostic{Code: eval.ErrorCode(e.Kind), Message: e.Text}
+	if d.Message == "" { //e.g. an unhandled 'throw'
+		d.Message = strings.TrimSpace(e.Message)
+	}
+	for _, frame := range e.Stack {
+		d.Stack = append(d.Stack, stackFrame{Function: frame.Function, File: frame.Pos.Filename, Line: frame.Pos.Line, Column: frame.Pos.Col})
+	}
+	if len(d.Stack) > 0 { //where the error occurred
+		last := d.Stack[len(d.Stack)-1]
+		d.File, d.Line, d.Column = last.File, last.Line, last.Column
+	}
+	printDiagnostics([]errorDiagnostic{d})
+}
+
+func printDiagnostics(diags []errorDiagnostic) {
+	out, _ := json.Marshal(diags)
+	fmt.Fprintln(os.Stderr, string(out))
+}
+
 // Register go package methods/types
 // Note here, we use 'gfmt', 'glog', 'gos' 'gtime', because in magpie
 // we already have built in module 'fmt', 'log' 'os', 'time'.
@@ -547,6 +724,14 @@ func showHelpRecursive(item string) {
 		fmt.Println("\tUsage:")
 		fmt.Println("\t   run $FILE_NAME       : Run the aeroscript codefile.                : Usage == $EXE run $FILE_NAME")
 		fmt.Println("\t   run debug $FILE_NAME : Run the aeroscript codefile in debug mode.  : Usage == $EXE run debug $FILE_NAME")
+		fmt.Println("\t   run --pm $FILE_NAME  : Debug the codefile where it fails(post-mortem). : Usage == $EXE run --pm $FILE_NAME")
+		fmt.Println("\t   run --vm $FILE_NAME  : Run the codefile with the bytecode VM.       : Usage == $EXE run --vm $FILE_NAME")
+		fmt.Println("\t   run --no-cache $FILE_NAME : Parse the codefile, ignore its cache.  : Usage == $EXE run --no-cache $FILE_NAME")
+		fmt.Println("\t   run -O $FILE_NAME     : Optimize the codefile before running it.  : Usage == $EXE run -O $FILE_NAME")
+		fmt.Println("\t   run --dump-optimized $FILE_NAME : Print the optimized codefile.  : Usage == $EXE run --dump-optimized $FILE_NAME")
+		fmt.Println("\t   run --error-format=json $FILE_NAME : Print the errors as JSON on stderr. : Usage == $EXE run --error-format=json $FILE_NAME")
+		fmt.Println("\t   run --typecheck $FILE_NAME : Check the type annotations at runtime. : Usage == $EXE run --typecheck $FILE_NAME")
+		fmt.Println("\t   run --exceptions $FILE_NAME : Raise IOError, NetError... from the stdlib failures. : Usage == $EXE run --exceptions $FILE_NAME")
 
 		fmt.Println("   Lun:")
 		fmt.Println("\tDescription:")
@@ -554,19 +739,113 @@ func showHelpRecursive(item string) {
 		fmt.Println("\tUsage:")
 		fmt.Println("\t   lun $FILE_NAME       : Run the aeroscript codefile.                : Usage == $EXE lun $FILE_NAME")
 		fmt.Println("\t   lun debug $FILE_NAME : Run the aeroscript codefile in debug mode.  : Usage == $EXE lun debug $FILE_NAME")
+		fmt.Println("\t   lun --pm $FILE_NAME  : Debug the codefile where it fails(post-mortem). : Usage == $EXE lun --pm $FILE_NAME")
+		fmt.Println("\t   lun --vm $FILE_NAME  : Run the codefile with the bytecode VM.       : Usage == $EXE lun --vm $FILE_NAME")
+		fmt.Println("\t   lun --no-cache $FILE_NAME : Parse the codefile, ignore its cache.  : Usage == $EXE lun --no-cache $FILE_NAME")
+		fmt.Println("\t   lun -O $FILE_NAME     : Optimize the codefile before running it.  : Usage == $EXE lun -O $FILE_NAME")
+		fmt.Println("\t   lun --dump-optimized $FILE_NAME : Print the optimized codefile.  : Usage == $EXE lun --dump-optimized $FILE_NAME")
+		fmt.Println("\t   lun --error-format=json $FILE_NAME : Print the errors as JSON on stderr. : Usage == $EXE lun --error-format=json $FILE_NAME")
+		fmt.Println("\t   lun --typecheck $FILE_NAME : Check the type annotations at runtime. : Usage == $EXE lun --typecheck $FILE_NAME")
+		fmt.Println("\t   lun --exceptions $FILE_NAME : Raise IOError, NetError... from the stdlib failures. : Usage == $EXE lun --exceptions $FILE_NAME")
+
+		fmt.Println("   Defines:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   DEFINE MACROS FOR `#ifdef` IN ALL THE PARSED FILES, BEFORE THE COMMAND.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   -D $NAME $COMMAND    : Define the macro $NAME.                    : Usage == $EXE -D DEBUG lun $FILE_NAME")
+		fmt.Println("\t   -D $NAME=$VALUE $COMMAND : Define the macro $NAME with a value.  : Usage == $EXE -D PLATFORM=linux lun $FILE_NAME")
+
+		fmt.Println("   Cache:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   THE PARSED FILES ARE CACHED IN `$FILE_NAME.cache` NEXT TO THEM.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   cache clean [$DIR]   : Remove the cache files of a directory.     : Usage == $EXE cache clean $DIR")
+
+		fmt.Println("   Repl:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START AN INTERACTIVE ORIGINSCRIPT SESSION, STATE IS KEPT BETWEEN INPUTS.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   repl                 : Start the interactive prompt.               : Usage == $EXE repl")
+
+		fmt.Println("   Test:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   RUN EVERY `fn test*()` OF THE `*_test.aero` FILES IN A DIRECTORY.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   test [$DIR]          : Run the tests, exit code is 1 on failure.  : Usage == $EXE test $DIR")
+
+		fmt.Println("   Fmt:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   FORMAT ORIGINSCRIPT FILES, DIRECTORIES ARE SEARCHED FOR `*.aero` FILES.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   fmt $FILES           : Print the formatted files.                  : Usage == $EXE fmt $FILE_NAME")
+		fmt.Println("\t   fmt -w $FILES        : Write the formatted files back.             : Usage == $EXE fmt -w $FILE_NAME")
+		fmt.Println("\t   fmt --check $FILES   : List unformatted files, exit code is 1.     : Usage == $EXE fmt --check $DIR")
+		fmt.Println("\t   fmt --diff $FILES    : Show the changes as a diff.                 : Usage == $EXE fmt --diff $FILE_NAME")
+
+		fmt.Println("   Lint:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   REPORT THE LIKELY BUGS OF ORIGINSCRIPT FILES WITHOUT RUNNING THEM.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   lint $FILES          : Print the diagnostics as file:line:col.     : Usage == $EXE lint $DIR")
+		fmt.Println("\t   lint --json $FILES   : Print the diagnostics as a JSON array.      : Usage == $EXE lint --json $DIR")
+
+		fmt.Println("   Check:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   INFER THE TYPES OF ORIGINSCRIPT FILES, AND REPORT THE MISTAKES THEY PROVE WITHOUT RUNNING THEM.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   check $FILES         : Print the diagnostics as file:line:col.     : Usage == $EXE check $DIR")
+		fmt.Println("\t   check --json $FILES  : Print the diagnostics as a JSON array.      : Usage == $EXE check --json $DIR")
+
+		fmt.Println("   Lsp:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START A LANGUAGE SERVER(LSP) OVER STDIO, FOR THE EDITORS.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   lsp                  : Serve the editor on stdin/stdout.           : Usage == $EXE lsp")
+
+		fmt.Println("   Dap:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START A DEBUG ADAPTER(DAP) OVER STDIO OR TCP, FOR THE EDITORS.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   dap                  : Serve the debugger client on stdin/stdout.  : Usage == $EXE dap")
+		fmt.Println("\t   dap --port $PORT     : Serve the first client connecting to $PORT. : Usage == $EXE dap --port 4711")
+
+		fmt.Println("   Explain:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   EXPLAIN AN ERROR CODE: A DESCRIPTION, A FAILING EXAMPLE AND A FIX.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   explain $CODE        : Explain the error code.                     : Usage == $EXE explain eUDE-0019")
+		fmt.Println("\t   explain              : List the error codes.                       : Usage == $EXE explain")
+
+		fmt.Println("   Which:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   PRINT THE FILE A `require` OF THE MODULE LOADS.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   which $MODULE        : Print the file of the module.               : Usage == $EXE which net.util")
+		fmt.Println("\t   which --path         : Print the module search path.               : Usage == $EXE which --path")
 
 		fmt.Println("   Others:")
 		fmt.Println("\t-h error|errors : List of errors with descriptions.  : Usage == $EXE -h errors")
 
 		fmt.Println("\t   Errors:")
 		fmt.Println("\t      e3209 : Import Error  : Indicates that, the lib you imported, could not be imported!")
+		fmt.Println("\t      e3210 : Import Cycle  : Indicates that, the libs you imported, require each other!")
+		fmt.Println("\t      e3211 : Import Name   : Indicates that, two libs you imported, have the same name!")
 		fmt.Println("\t      e3301 : Syntax Error  : Indicates that, your code has a syntax mistake!")
 		fmt.Println("\t      eUDE  : Eval Error    : This is an Eval error, occurred at runtime!")
+		fmt.Println("\t              Every eval error has its own code, e.g. eUDE-0019(divide by zero), see '$EXE explain'.")
 	} else if item == "except" || item == "excepts" {
 		fmt.Printf("showing list of errors\n")
 		fmt.Println("\te3209 : Import Error  : Indicates that, the lib you imported, could not be imported!")
+		fmt.Println("\te3210 : Import Cycle  : Indicates that, the libs you imported, require each other!")
+		fmt.Println("\te3211 : Import Name   : Indicates that, two libs you imported, have the same name!")
 		fmt.Println("\te3301 : Syntax Error  : Indicates that, your code has a syntax mistake!")
 		fmt.Println("\teUDE  : Eval Error    : This is an Eval error, occurred at runtime!")
+		for _, info := range eval.ErrorInfos() {
+			if strings.HasPrefix(info.Code, "eUDE-") {
+				fmt.Printf("\t    %s : %s\n", info.Code, info.Title)
+			}
+		}
+		fmt.Println("\tRun '$EXE explain $CODE' for the details of an error.")
 	} else if item == "pack" {
 		fmt.Println("   Usage of pack:")
 		fmt.Println(fmt.Sprintf("\t%-10s : %-62s : Usage == $EXE pack init", "pack init", "Init the pack file."))
@@ -581,6 +860,99 @@ func showHelpRecursive(item string) {
 		fmt.Println("\tUsage:")
 		fmt.Println("\t   lun $FILE_NAME       : Run the aeroscript codefile.                : Usage == $EXE lun $FILE_NAME")
 		fmt.Println("\t   lun debug $FILE_NAME : Run the aeroscript codefile in debug mode.  : Usage == $EXE lun debug $FILE_NAME")
+		fmt.Println("\t   lun --pm $FILE_NAME  : Debug the codefile where it fails(post-mortem). : Usage == $EXE lun --pm $FILE_NAME")
+		fmt.Println("\t   lun --vm $FILE_NAME  : Run the codefile with the bytecode VM.       : Usage == $EXE lun --vm $FILE_NAME")
+		fmt.Println("\t   lun --no-cache $FILE_NAME : Parse the codefile, ignore its cache.  : Usage == $EXE lun --no-cache $FILE_NAME")
+		fmt.Println("\t   lun -O $FILE_NAME     : Optimize the codefile before running it.  : Usage == $EXE lun -O $FILE_NAME")
+		fmt.Println("\t   lun --dump-optimized $FILE_NAME : Print the optimized codefile.  : Usage == $EXE lun --dump-optimized $FILE_NAME")
+		fmt.Println("\t   lun --error-format=json $FILE_NAME : Print the errors as JSON on stderr. : Usage == $EXE lun --error-format=json $FILE_NAME")
+		fmt.Println("\t   lun --typecheck $FILE_NAME : Check the type annotations at runtime. : Usage == $EXE lun --typecheck $FILE_NAME")
+		fmt.Println("\t   lun --exceptions $FILE_NAME : Raise IOError, NetError... from the stdlib failures. : Usage == $EXE lun --exceptions $FILE_NAME")
+	} else if item == "define" {
+		fmt.Println("   Defines:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   DEFINE MACROS FOR `#ifdef` IN ALL THE PARSED FILES, BEFORE THE COMMAND.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   -D $NAME $COMMAND    : Define the macro $NAME.                    : Usage == $EXE -D DEBUG lun $FILE_NAME")
+		fmt.Println("\t   -D $NAME=$VALUE $COMMAND : Define the macro $NAME with a value.  : Usage == $EXE -D PLATFORM=linux lun $FILE_NAME")
+	} else if item == "cache" {
+		fmt.Println("   Cache:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   THE PARSED FILES ARE CACHED IN `$FILE_NAME.cache` NEXT TO THEM.")
+		fmt.Println("\t   A CACHE IS USED IF THE FILE, ITS IMPORTS AND THE INTERPRETER VERSION ARE UNCHANGED.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   cache clean [$DIR]   : Remove the cache files of a directory.     : Usage == $EXE cache clean $DIR")
+	} else if item == "repl" {
+		fmt.Println("   Repl:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START AN INTERACTIVE ORIGINSCRIPT SESSION, STATE IS KEPT BETWEEN INPUTS.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   repl                 : Start the interactive prompt.               : Usage == $EXE repl")
+	} else if item == "test" {
+		fmt.Println("   Test:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   RUN EVERY `fn test*()` OF THE `*_test.aero` FILES IN A DIRECTORY.")
+		fmt.Println("\t   ASSERTIONS: assert(cond), assertEqual(expected, actual), assertDeepEqual(expected, actual), assertThrows(fn).")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   test [$DIR]          : Run the tests, exit code is 1 on failure.  : Usage == $EXE test $DIR")
+	} else if item == "fmt" {
+		fmt.Println("   Fmt:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   FORMAT ORIGINSCRIPT FILES, DIRECTORIES ARE SEARCHED FOR `*.aero` FILES.")
+		fmt.Println("\t   BLOCKS ARE RE-INDENTED, CLASS AND SERVICE BODIES ARE NORMALIZED, COMMENTS ARE KEPT.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   fmt $FILES           : Print the formatted files.                  : Usage == $EXE fmt $FILE_NAME")
+		fmt.Println("\t   fmt -w $FILES        : Write the formatted files back.             : Usage == $EXE fmt -w $FILE_NAME")
+		fmt.Println("\t   fmt --check $FILES   : List unformatted files, exit code is 1.     : Usage == $EXE fmt --check $DIR")
+		fmt.Println("\t   fmt --diff $FILES    : Show the changes as a diff.                 : Usage == $EXE fmt --diff $FILE_NAME")
+	} else if item == "lint" {
+		fmt.Println("   Lint:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   REPORT THE LIKELY BUGS OF ORIGINSCRIPT FILES WITHOUT RUNNING THEM.")
+		fmt.Println("\t   CHECKS: unused, unreachable, undefined, shadow, constassign, defer, exhaustive.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   lint $FILES          : Print the diagnostics as file:line:col.     : Usage == $EXE lint $DIR")
+		fmt.Println("\t   lint --json $FILES   : Print the diagnostics as a JSON array.      : Usage == $EXE lint --json $DIR")
+	} else if item == "check" {
+		fmt.Println("   Check:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   INFER THE TYPES OF ORIGINSCRIPT FILES, AND REPORT THE MISTAKES THEY PROVE WITHOUT RUNNING THEM.")
+		fmt.Println("\t   TYPES OF THE LITERALS, THE BUILTINS, THE STANDARD LIBRARY, THE FUNCTIONS AND THE REQUIRED MODULES.")
+		fmt.Println("\t   CHECKS: nomethod, operands, argcount.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   check $FILES         : Print the diagnostics as file:line:col.     : Usage == $EXE check $DIR")
+		fmt.Println("\t   check --json $FILES  : Print the diagnostics as a JSON array.      : Usage == $EXE check --json $DIR")
+	} else if item == "lsp" {
+		fmt.Println("   Lsp:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START A LANGUAGE SERVER(LSP) OVER STDIO, FOR THE EDITORS.")
+		fmt.Println("\t   DIAGNOSTICS, HOVER, GO-TO-DEFINITION, DOCUMENT SYMBOLS AND COMPLETION.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   lsp                  : Serve the editor on stdin/stdout.           : Usage == $EXE lsp")
+	} else if item == "dap" {
+		fmt.Println("   Dap:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   START A DEBUG ADAPTER(DAP) OVER STDIO OR TCP, FOR THE EDITORS.")
+		fmt.Println("\t   BREAKPOINTS, CONTINUE/NEXT/STEP IN/STEP OUT, STACK TRACE, VARIABLES AND EVALUATE.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   dap                  : Serve the debugger client on stdin/stdout.  : Usage == $EXE dap")
+		fmt.Println("\t   dap --port $PORT     : Serve the first client connecting to $PORT. : Usage == $EXE dap --port 4711")
+	} else if item == "explain" {
+		fmt.Println("   Explain:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   EXPLAIN AN ERROR CODE: A DESCRIPTION, A FAILING EXAMPLE AND A FIX.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   explain $CODE        : Explain the error code.                     : Usage == $EXE explain eUDE-0019")
+		fmt.Println("\t   explain              : List the error codes.                       : Usage == $EXE explain")
+	} else if item == "which" {
+		fmt.Println("   Which:")
+		fmt.Println("\tDescription:")
+		fmt.Println("\t   PRINT THE FILE A `require` OF THE MODULE LOADS. THE MODULES ARE SEARCHED IN THE WORKING DIRECTORY,")
+		fmt.Println("\t   THE DIRECTORY OF THE REQUIRING FILE, THE `path=` LINES OF THE PROJECT'S origion.pack, ITS `opkg/`")
+		fmt.Println("\t   DIRECTORY, THE DIRECTORIES OF $ORIGION_PATH(SEPARATED BY ':') AND `aerolibs/`.")
+		fmt.Println("\tUsage:")
+		fmt.Println("\t   which $MODULE        : Print the file of the module.               : Usage == $EXE which net.util")
+		fmt.Println("\t   which --path         : Print the module search path.               : Usage == $EXE which --path")
 	} else if item == "run" {
 		fmt.Println("   Run:")
 		fmt.Println("\tDescription:")
@@ -588,6 +960,14 @@ func showHelpRecursive(item string) {
 		fmt.Println("\tUsage:")
 		fmt.Println("\t   run $FILE_NAME       : Run the aeroscript codefile.                : Usage == $EXE run $FILE_NAME")
 		fmt.Println("\t   run debug $FILE_NAME : Run the aeroscript codefile in debug mode.  : Usage == $EXE run debug $FILE_NAME")
+		fmt.Println("\t   run --pm $FILE_NAME  : Debug the codefile where it fails(post-mortem). : Usage == $EXE run --pm $FILE_NAME")
+		fmt.Println("\t   run --vm $FILE_NAME  : Run the codefile with the bytecode VM.       : Usage == $EXE run --vm $FILE_NAME")
+		fmt.Println("\t   run --no-cache $FILE_NAME : Parse the codefile, ignore its cache.  : Usage == $EXE run --no-cache $FILE_NAME")
+		fmt.Println("\t   run -O $FILE_NAME     : Optimize the codefile before running it.  : Usage == $EXE run -O $FILE_NAME")
+		fmt.Println("\t   run --dump-optimized $FILE_NAME : Print the optimized codefile.  : Usage == $EXE run --dump-optimized $FILE_NAME")
+		fmt.Println("\t   run --error-format=json $FILE_NAME : Print the errors as JSON on stderr. : Usage == $EXE run --error-format=json $FILE_NAME")
+		fmt.Println("\t   run --typecheck $FILE_NAME : Check the type annotations at runtime. : Usage == $EXE run --typecheck $FILE_NAME")
+		fmt.Println("\t   run --exceptions $FILE_NAME : Raise IOError, NetError... from the stdlib failures. : Usage == $EXE run --exceptions $FILE_NAME")
 	} else {
 		showHelp("***")
 		//fmt.Println("OriginScript: Usage: $AERO_SCRIPT_EXE_PATH -h $THING\n hint: type `$AERO_SCRIPT_EXE_PATH -h /list/` for list of items.")
@@ -632,13 +1012,24 @@ func listScripts() {
 	defer file.Close()
 
 	fmt.Println("   Scripts:")
+	var paths []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), parser.PackPathPrefix) { // a module search path setting
+			paths = append(paths, scanner.Text())
+			continue
+		}
 		fmt.Println("\t"+scanner.Text())
 	}
 	if err := scanner.Err(); err != nil {
 		fmt.Println("OriginScript: pack: Error reading origion.pack file. \n\t└─Error Message:", err)
 	}
+	if len(paths) > 0 {
+		fmt.Println("   Settings:")
+		for _, path := range paths {
+			fmt.Println("\t"+path)
+		}
+	}
 }
 // isScriptInModFile checks if a script is already listed in the origion.pack file.
 func isScriptInModFile(modFilename, scriptName string) bool {
@@ -792,7 +1183,9 @@ func checkScripts() {
 
 	modScanner := bufio.NewScanner(modFile)
 	for modScanner.Scan() {
-		modScripts[modScanner.Text()] = true
+		if !strings.HasPrefix(modScanner.Text(), parser.PackPathPrefix) {
+			modScripts[modScanner.Text()] = true
+		}
 	}
 	if err := modScanner.Err(); err != nil {
 		fmt.Println("OriginScript: pack: Error reading origion.pack file.\n\t└─Error Message:", err)
@@ -833,24 +1226,207 @@ func checkScripts() {
 	}
 }
 
+// serveDap implements `dap [--port $PORT]`, a Debug Adapter Protocol server over stdio,
+// or over the first TCP connection to the port.
+func serveDap(args []string) error {
+	port := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--port" && i+1 < len(args) {
+			port = args[i+1]
+			i++
+		} else if strings.HasPrefix(args[i], "--port=") {
+			port = strings.TrimPrefix(args[i], "--port=")
+		} else {
+			return fmt.Errorf("unknown argument '%s'", args[i])
+		}
+	}
+	RegisterGoGlobals()
+
+	if port == "" {
+		// the program's output to stdout must not break the protocol's messages
+		stdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		os.Stdout = w
+		server := dap.NewServer(os.Stdin, stdout)
+		go server.ForwardOutput(r)
+		return server.Run()
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:"+port)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	fmt.Println("OriginScript: dap: listening on", listener.Addr().String())
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return dap.NewServer(conn, conn).Run()
+}
+
+// explainError implements `explain [$CODE]`, without a code it lists the codes.
+// It returns false if the code is unknown.
+func explainError(args []string) bool {
+	if len(args) == 0 {
+		for _, info := range eval.ErrorInfos() {
+			fmt.Printf("%-10s %s\n", info.Code, info.Title)
+		}
+		return true
+	}
+
+	info, ok := eval.ExplainError(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "OriginScript: explain: unknown error code '%s', run 'explain' for the list of codes\n", args[0])
+		return false
+	}
+	fmt.Printf("%s: %s\n\n", info.Code, info.Title)
+	fmt.Println(info.Description)
+	if info.Example != "" {
+		fmt.Println("\nExample:")
+		for _, line := range strings.Split(info.Example, "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+	fmt.Println("\nFix:")
+	fmt.Println(info.Fix)
+	return true
+}
+
+// whichModule implements `which [--path] $MODULES`, it prints the file a `require $MODULE` in the
+// working directory loads, with '--path', the searched directories too. It returns false if a module is not found.
+func whichModule(args []string) bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "OriginScript: which:", err.Error())
+		return false
+	}
+	dirs := parser.SearchPath(wd, wd)
+
+	var modules []string
+	for _, arg := range args {
+		if arg == "-p" || arg == "--path" {
+			for _, dir := range dirs {
+				fmt.Println(dir)
+			}
+		} else {
+			modules = append(modules, arg)
+		}
+	}
+	if len(modules) == 0 && len(args) == 0 {
+		showHelp("which")
+		return false
+	}
+
+	ok := true
+	for _, module := range modules {
+		path := strings.Replace(strings.TrimSuffix(module, ".aero"), ".", "/", -1)
+		if file, found := parser.FindModule(path, dirs); found {
+			fmt.Println(file)
+		} else {
+			fmt.Fprintf(os.Stderr, "OriginScript: which: module '%s' not found, searched: %s\n", module, strings.Join(dirs, ", "))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// formatFiles implements `fmt [-w|--check|--diff] $FILES`, directories are searched for '*.aero' files.
+// It returns false if a file could not be formatted, or with '--check', if a file is not formatted.
+func formatFiles(args []string) bool {
+	var write, check, diff bool
+	var files []string
+	for _, arg := range args {
+		switch arg {
+		case "-w", "--write":
+			write = true
+		case "-c", "--check":
+			check = true
+		case "-d", "--diff":
+			diff = true
+		default:
+			files = append(files, arg)
+		}
+	}
+	if len(files) == 0 {
+		files = append(files, ".")
+	}
+
+	var paths []string
+	for _, file := range files {
+		filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				fmt.Println("OriginScript: fmt:", err.Error())
+				return nil
+			}
+			if !info.IsDir() && (path == file || strings.HasSuffix(path, ".aero")) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+	}
+
+	ok := true
+	for _, path := range paths {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Println("OriginScript: fmt:", err.Error())
+			ok = false
+			continue
+		}
+		res, err := formatter.Source(path, src)
+		if err != nil {
+			fmt.Println(err.Error())
+			ok = false
+			continue
+		}
+
+		changed := !bytes.Equal(src, res)
+		switch {
+		case check:
+			if changed {
+				fmt.Println(path)
+				ok = false
+			}
+		case diff:
+			fmt.Print(formatter.Diff(path, src, res))
+		case write:
+			if changed {
+				if err := ioutil.WriteFile(path, res, 0644); err != nil {
+					fmt.Println("OriginScript: fmt:", err.Error())
+					ok = false
+				}
+			}
+		default:
+			os.Stdout.Write(res)
+		}
+	}
+	return ok
+}
+
 ///
 /// MAIN STUFF
 ///
 
 func main() {
 	version := "0.1i"
-	args := os.Args[1:]
+	parser.Version = version
+	args := defineOptions(os.Args[1:])
 	//We must reset `os.Args`, or the `flag` module will not functioning correctly
-	os.Args = os.Args[1:]
+	os.Args = args
 	if len(args) == 0 {
 
-		fmt.Println("OriginScript: version[`",version,"`] , Usage[`pack`,`--debug`,`--help`,`--lun`,`--run`,`--pack`]")
+		fmt.Println("OriginScript: version[`",version,"`] , Usage[`pack`,`repl`,`test`,`fmt`,`lint`,`check`,`which`,`lsp`,`dap`,`--debug`,`--help`,`--lun`,`--run`,`--pack`]")
 
 		showHelp("***")
-		//repl.Start(os.Stdout, true)
 	} else {
 		if len(args) >= 1 {
 			if args[0] == "-d" || args[0] == "--debug" { // debug
+				args = append(args[:1], runOptions(args[1:])...)
 				if len(args) >= 2 {
 					if args[1] != "" {
 						runProgramDebugList(true, args[1])
@@ -878,10 +1454,13 @@ func main() {
 				}
 			} else if args[0] == "-l" || args[0] == "--lun" { 
 				if len(args) >= 1 {
+					args = append(args[:1], runOptions(args[1:])...)
 					if args[1] == "debug" {
-						runProgram(true, args[2])
+						runProgram(true, false, args[2])
+					} else if args[1] == "--pm" {
+						runProgram(false, true, args[2])
 					} else {
-						runProgram(false, args[1])
+						runProgram(false, false, args[1])
 						//fmt.Printf("OriginScript: Usage: $AERO_SCRIPT_EXE_PATH %s $FILE_NAME.aero\n",args[0])
 						//os.Exit(1)
 					}
@@ -892,12 +1471,16 @@ func main() {
 				}
 			} else if args[0] == "-r" || args[0] == "--run" { 
 				if len(args) >= 1 {
+					args = append(args[:1], runOptions(args[1:])...)
 					if args[1] == "debug" {
 						formatted := fmt.Sprintf("/opkg/%s", args[2])
-						runProgram(true, formatted)
+						runProgram(true, false, formatted)
+					} else if args[1] == "--pm" {
+						formatted := fmt.Sprintf("/opkg/%s", args[2])
+						runProgram(false, true, formatted)
 					} else {
 						formatted := fmt.Sprintf("/opkg/%s", args[1])
-						runProgram(false, formatted)
+						runProgram(false, false, formatted)
 						//fmt.Printf("OriginScript: Usage: $AERO_SCRIPT_EXE_PATH %s $FILE_NAME.aero\n",args[0])
 						//os.Exit(1)
 					}
@@ -906,6 +1489,85 @@ func main() {
 					fmt.Printf("OriginScript: Usage: $AERO_SCRIPT_EXE_PATH %s $FILE_NAME.aero\\n",args[0])
 					//os.Exit(1)
 				}
+			} else if args[0] == "cache" || args[0] == "--cache" {
+				if len(args) < 2 || args[1] != "clean" {
+					showHelp("cache")
+					return
+				}
+				dir := "."
+				if len(args) >= 3 && args[2] != "" {
+					dir = args[2]
+				}
+				n, err := parser.CleanCache(dir)
+				if err != nil {
+					fmt.Println("OriginScript: cache:", err.Error())
+					os.Exit(1)
+				}
+				fmt.Printf("OriginScript: %d cache file(s) removed.\n", n)
+			} else if args[0] == "repl" || args[0] == "-i" || args[0] == "--repl" {
+				fmt.Println("OriginScript: version[`",version,"`] , type `exit` or `quit` to leave.")
+				RegisterGoGlobals()
+				repl.Start(os.Stdout, true)
+			} else if args[0] == "test" || args[0] == "-t" || args[0] == "--test" {
+				dir := "."
+				if len(args) >= 2 && args[1] != "" {
+					dir = args[1]
+				}
+				RegisterGoGlobals()
+				if summary := tester.Run(dir, os.Stdout); !summary.Ok() {
+					os.Exit(1)
+				}
+			} else if args[0] == "fmt" || args[0] == "--fmt" {
+				if !formatFiles(args[1:]) {
+					os.Exit(1)
+				}
+			} else if args[0] == "lint" || args[0] == "--lint" {
+				var jsonOutput bool
+				var paths []string
+				for _, arg := range args[1:] {
+					if arg == "--json" || arg == "-j" {
+						jsonOutput = true
+					} else {
+						paths = append(paths, arg)
+					}
+				}
+				RegisterGoGlobals()
+				if linter.Run(paths, jsonOutput, os.Stdout) != 0 {
+					os.Exit(1)
+				}
+			} else if args[0] == "check" || args[0] == "--check" {
+				var jsonOutput bool
+				var paths []string
+				for _, arg := range args[1:] {
+					if arg == "--json" || arg == "-j" {
+						jsonOutput = true
+					} else {
+						paths = append(paths, arg)
+					}
+				}
+				RegisterGoGlobals()
+				if linter.RunCheck(paths, jsonOutput, os.Stdout) != 0 {
+					os.Exit(1)
+				}
+			} else if args[0] == "lsp" || args[0] == "--lsp" {
+				RegisterGoGlobals()
+				if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+					fmt.Fprintln(os.Stderr, "OriginScript: lsp:", err.Error())
+					os.Exit(1)
+				}
+			} else if args[0] == "explain" || args[0] == "--explain" {
+				if !explainError(args[1:]) {
+					os.Exit(1)
+				}
+			} else if args[0] == "which" || args[0] == "--which" {
+				if !whichModule(args[1:]) {
+					os.Exit(1)
+				}
+			} else if args[0] == "dap" || args[0] == "--dap" {
+				if err := serveDap(args[1:]); err != nil {
+					fmt.Fprintln(os.Stderr, "OriginScript: dap:", err.Error())
+					os.Exit(1)
+				}
 			} else if args[0] == "-p" || args[0] == "--pack" {
 				fmt.Println("OriginScript: version<",version,">")
 				if len(args) < 2 {
@@ -948,7 +1610,7 @@ func main() {
 				//fmt.Println("OriginScript: Usage: $AERO_SCRIPT_EXE_PATH -$OPTION $EXTRA_ARGS_IF_REQUIRED")
 			}
 		} else {
-			runProgram(false, args[0])
+			runProgram(false, false, args[0])
 		}
 	}
 }
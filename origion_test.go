@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//TestMainProcess runs 'main' with the arguments of 'ORIGION_ARGS', it's the process started by 'origion'.
+func TestMainProcess(t *testing.T) {
+	args := os.Getenv("ORIGION_ARGS")
+	if args == "" {
+		return
+	}
+	os.Args = append([]string{"origion"}, strings.Split(args, "\n")...)
+	main()
+	os.Exit(0)
+}
+
+//origion runs the command line 'args' in 'dir', it returns the output on stderr and the exit code.
+func origion(t *testing.T, dir string, args ...string) (string, int) {
+	_, stderr, code := origionWithInput(t, dir, "", args...)
+	return stderr, code
+}
+
+//origionWithInput runs the command line 'args' in 'dir' with 'input' on stdin, it returns
+//the output on stdout and on stderr, and the exit code.
+func origionWithInput(t *testing.T, dir string, input string, args ...string) (string, string, int) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "ORIGION_ARGS="+strings.Join(args, "\n"))
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestErrorFormatJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "origion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		input    string
+		expected errorDiagnostic
+		stack    int //the number of frames
+	}{
+		{"fn div(a, b) {\n\treturn a / b\n}\nprintln(div(1, 0))\n",
+			errorDiagnostic{Code: "eUDE-0019", Message: "divide by zero", File: "main.aero", Line: 2, Column: 11}, 2},
+		{"throw 42\n",
+			errorDiagnostic{Code: "eUDE-0021", Message: "throw object '42' not handled", File: "main.aero", Line: 1, Column: 1}, 1},
+		{"lit x = (1 + 2\n",
+			errorDiagnostic{Code: "e3301", Message: "expected next token to be ), got EOF instead", File: "main.aero", Line: 1, Column: 15}, 0},
+		{"require nosuchmodule\n",
+			errorDiagnostic{Code: "e3209", File: "main.aero", Line: 1, Column: 9}, 0},
+	}
+
+	for _, tt := range tests {
+		ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte(tt.input), 0644)
+		output, code := origion(t, dir, "--lun", "--no-cache", "--error-format=json", "main.aero")
+		if code != 1 {
+			t.Errorf("%q: wrong exit code. expected=1, got=%d", tt.input, code)
+		}
+
+		var diags []errorDiagnostic
+		if err := json.Unmarshal([]byte(output), &diags); err != nil || len(diags) != 1 {
+			t.Errorf("%q: expected one diagnostic, got=%q", tt.input, output)
+			continue
+		}
+		d := diags[0]
+		if tt.expected.Message == "" { //the searched directories depend on the environment
+			if !strings.HasPrefix(d.Message, "no file or directory: nosuchmodule.aero") {
+				t.Errorf("%q: wrong message, got=%q", tt.input, d.Message)
+			}
+			d.Message = ""
+		}
+		if len(d.Stack) != tt.stack {
+			t.Errorf("%q: wrong stack. expected %d frames, got=%v", tt.input, tt.stack, d.Stack)
+		}
+		d.Stack = nil
+		if !reflect.DeepEqual(d, tt.expected) {
+			t.Errorf("%q: wrong diagnostic.\nexpected=%+v\ngot=%+v", tt.input, tt.expected, d)
+		}
+	}
+
+	//the file cannot be read
+	output, code := origion(t, dir, "--lun", "--error-format=json", "nosuchfile.aero")
+	var diags []errorDiagnostic
+	if err := json.Unmarshal([]byte(output), &diags); err != nil || len(diags) != 1 || diags[0].File != "nosuchfile.aero" || code != 1 {
+		t.Errorf("wrong error of a missing file. exit code=%d, output=%q", code, output)
+	}
+}
+
+func TestErrorFormatOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "origion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte("lit x = 1\n"), 0644)
+
+	tests := []struct {
+		format   string
+		expected int
+	}{
+		{"--error-format=json", 0},
+		{"--error-format=text", 0},
+		{"--error-format=xml", 1},
+		{"--error-format", 1},
+	}
+
+	for _, tt := range tests {
+		output, code := origion(t, dir, "--lun", "--no-cache", tt.format, "main.aero")
+		if code != tt.expected {
+			t.Errorf("%s: wrong exit code. expected=%d, got=%d", tt.format, tt.expected, code)
+		}
+		if tt.expected == 0 && output != "" {
+			t.Errorf("%s: expected no error, got=%q", tt.format, output)
+		}
+		if tt.expected != 0 && !strings.Contains(output, "unknown error format") {
+			t.Errorf("%s: expected the format to be rejected, got=%q", tt.format, output)
+		}
+	}
+}
+
+func TestTestExitCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"fn testA() { assertEqual(2, 1 + 1) }\n", 0},
+		{"fn testA() { assertEqual(3, 1 + 1) }\n", 1},
+		{"fn testA() { lit a = [1]; a.nosuchmethod() }\n", 1},
+	}
+
+	for _, tt := range tests {
+		dir, err := ioutil.TempDir("", "origion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(dir, "a_test.aero"), []byte(tt.input), 0644)
+		if _, code := origion(t, dir, "test", dir); code != tt.expected {
+			t.Errorf("%q: wrong exit code. expected=%d, got=%d", tt.input, tt.expected, code)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPostMortemOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "origion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := "fn div(a, b) {\n\treturn a / b\n}\nlit x = 0\nprintln(div(1, x))\n"
+	ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte(input), 0644)
+
+	output, _, code := origionWithInput(t, dir, "$bt\n$lo\n$c\n", "--lun", "--pm", "main.aero")
+	if code != 1 {
+		t.Errorf("wrong exit code. expected=1, got=%d", code)
+	}
+	for _, expected := range []string{
+		"eUDE-0019: divide by zero",
+		"(!) Post-mortem: failed at 'main.aero:2'",
+		"#0  div at main.aero:2",
+		"#1  <module> at main.aero:5",
+		"a = 1\nb = 0\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in the output, got=%q", expected, output)
+		}
+	}
+
+	//no prompt if the program does not fail
+	ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte("println(1)\n"), 0644)
+	output, _, code = origionWithInput(t, dir, "", "--lun", "--pm", "main.aero")
+	if code != 0 || strings.Contains(output, "Post-mortem") {
+		t.Errorf("unexpected post-mortem. exit code=%d, output=%q", code, output)
+	}
+}
+
+func TestDumpOptimized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "origion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := "const GREETING = \"hello, \" + \"world\"\nlit n = 2 * 21\nif 1 > 2 {\n\tprintln(\"never\")\n}\nprintln(GREETING, \" \", n, \" \", 1.5 * 2)\n"
+	ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte(input), 0644)
+
+	dump, stderr, code := origionWithInput(t, dir, "", "--lun", "--no-cache", "--dump-optimized", "main.aero")
+	if code != 0 || stderr != "" {
+		t.Fatalf("--dump-optimized failed. exit code=%d, stderr=%q", code, stderr)
+	}
+	expected := "const GREETING = \"hello, world\"\nlit n = 42\n\nprintln(\"hello, world\", \" \", n, \" \", 3.0)\n"
+	if dump != expected {
+		t.Errorf("wrong dump.\nexpected=%q\ngot=%q", expected, dump)
+	}
+
+	//the dump runs like the program
+	ioutil.WriteFile(filepath.Join(dir, "dump.aero"), []byte(dump), 0644)
+	output, _, _ := origionWithInput(t, dir, "", "--lun", "--no-cache", "main.aero")
+	dumpOutput, _, _ := origionWithInput(t, dir, "", "--lun", "--no-cache", "dump.aero")
+	if output != "hello, world 42 3\n" || dumpOutput != output {
+		t.Errorf("the dump runs differently. expected=%q, got=%q", output, dumpOutput)
+	}
+}
+
+func TestExceptionsOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "origion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	//the builtin 'json' is not replaced by the Go functions registered as 'json'
+	input := "println(json.marshal([1]))\nlit f = open(\"no/such/file\")\nprintln(f == nil)\n"
+	ioutil.WriteFile(filepath.Join(dir, "main.aero"), []byte(input), 0644)
+
+	output, stderr, code := origionWithInput(t, dir, "", "--lun", "--no-cache", "main.aero")
+	if output != "[1]\ntrue\n" || code != 0 {
+		t.Errorf("wrong output. exit code=%d, output=%q, stderr=%q", code, output, stderr)
+	}
+	output, stderr, code = origionWithInput(t, dir, "", "--lun", "--no-cache", "--exceptions", "main.aero")
+	if !strings.HasPrefix(output, "[1]\n") || code != 1 || !strings.Contains(output, "eUDE-0058: IOError: open no/such/file") {
+		t.Errorf("expected an IOError. exit code=%d, output=%q, stderr=%q", code, output, stderr)
+	}
+}
@@ -34,6 +34,10 @@ type Expression interface {
 type Program struct {
 	Statements []Statement
 	Imports    map[string]*ImportStatement
+	Exports    []Statement //the top-level declarations marked with 'export'
+
+	//The bytecode, set by the VM on first run
+	Code interface{}
 }
 
 func (p *Program) Pos() token.Position {
@@ -68,10 +72,57 @@ func (p *Program) String() string {
 	return out.String()
 }
 
+//ExportedNames returns the names the files requiring the program can use, or
+//nil if nothing is marked with 'export', then the capitalized names are public.
+func (p *Program) ExportedNames() map[string]bool {
+	if len(p.Exports) == 0 {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, s := range p.Exports {
+		for _, name := range DeclaredNames(s) {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+//DeclaredNames returns the names declared by the statement, if it's one
+//'export' accepts(lit, const, fn, class, enum, interface), otherwise nil.
+func DeclaredNames(s Statement) []string {
+	var idents []*Identifier
+	switch s := s.(type) {
+	case *LetStatement:
+		idents = s.Names
+	case *ConstStatement:
+		idents = s.Name
+	case *FunctionStatement:
+		idents = []*Identifier{s.Name}
+	case *ClassStatement:
+		if s.CategoryName == nil {
+			idents = []*Identifier{s.Name}
+		}
+	case *EnumStatement:
+		idents = []*Identifier{s.Name}
+	case *InterfaceStatement:
+		idents = []*Identifier{s.Name}
+	}
+	var names []string
+	for _, ident := range idents {
+		if ident != nil && ident.Value != "_" {
+			names = append(names, ident.Value)
+		}
+	}
+	return names
+}
+
 type BlockStatement struct {
 	Token       token.Token
 	Statements  []Statement
 	RBraceToken token.Token
+
+	//The bytecode, set by the VM on first run
+	Code interface{}
 }
 
 func (bs *BlockStatement) Pos() token.Position {
@@ -359,6 +410,10 @@ func (dl *DoLoop) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	//Set by the resolver if the identifier is a local variable of 'Func'
+	Func *FunctionLiteral
+	Slot int
 }
 
 func (i *Identifier) Pos() token.Position {
@@ -799,6 +854,40 @@ func (r *RangeLiteral) String() string {
 	return out.String()
 }
 
+///////////////////////////////////////////////////////////
+//                    TYPE ANNOTATION                    //
+///////////////////////////////////////////////////////////
+//int, string|nil, array<string>, hash<string, int>, Point
+type TypeExpr struct {
+	Token token.Token
+	Name  string      //"" for a union
+	Args  []*TypeExpr //e.g. 'string' of 'array<string>'
+	Union []*TypeExpr //e.g. 'int' and 'nil' of 'int|nil'
+}
+
+func (t *TypeExpr) Pos() token.Position {
+	return t.Token.Pos
+}
+
+func (t *TypeExpr) String() string {
+	if len(t.Union) > 0 {
+		types := []string{}
+		for _, u := range t.Union {
+			types = append(types, u.String())
+		}
+		return strings.Join(types, "|")
+	}
+
+	if len(t.Args) == 0 {
+		return t.Name
+	}
+	args := []string{}
+	for _, a := range t.Args {
+		args = append(args, a.String())
+	}
+	return t.Name + "<" + strings.Join(args, ", ") + ">"
+}
+
 ///////////////////////////////////////////////////////////
 //                     FUNCTION LITERAL                  //
 ///////////////////////////////////////////////////////////
@@ -810,6 +899,10 @@ type FunctionLiteral struct {
 	//Default values
 	Values map[string]Expression
 
+	//Type annotations, e.g. 'fn add(a: int, b: int) -> int'
+	ParamTypes map[string]*TypeExpr
+	ReturnType *TypeExpr
+
 	Variadic bool
 
 	StaticFlag    bool
@@ -817,6 +910,10 @@ type FunctionLiteral struct {
 
 	//If the function is async or not
 	Async bool
+
+	//Local variables, set by the resolver
+	Slots     []string
+	SlotIndex map[string]int
 }
 
 func (fl *FunctionLiteral) Pos() token.Position {
@@ -857,18 +954,35 @@ func (fl *FunctionLiteral) String() string {
 			param = "..." + param
 		}
 
-		params = append(params, p.String())
+		params = append(params, p.String()+fl.paramType(p.String()))
 
 	}
 	out.WriteString(" (")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	out.WriteString(fl.returnType())
 	out.WriteString("{ ")
 	out.WriteString(fl.Body.String())
 	out.WriteString(" }")
 	return out.String()
 }
 
+//paramType returns the annotated type of a parameter, e.g. ': int'
+func (fl *FunctionLiteral) paramType(name string) string {
+	if t, ok := fl.ParamTypes[name]; ok {
+		return ": " + t.String()
+	}
+	return ""
+}
+
+//returnType returns the annotated return type, e.g. '-> int '
+func (fl *FunctionLiteral) returnType() string {
+	if fl.ReturnType != nil {
+		return "-> " + fl.ReturnType.String() + " "
+	}
+	return ""
+}
+
 ///////////////////////////////////////////////////////////
 //                  FUNCTION STATEMENT                   //
 ///////////////////////////////////////////////////////////
@@ -924,12 +1038,13 @@ func (f *FunctionStatement) String() string {
 			param = "..." + param
 		}
 
-		params = append(params, p.String())
+		params = append(params, p.String()+f.FunctionLiteral.paramType(p.String()))
 
 	}
 	out.WriteString(" (")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	out.WriteString(f.FunctionLiteral.returnType())
 	out.WriteString("{ ")
 	out.WriteString(f.FunctionLiteral.Body.String())
 	out.WriteString(" }")
@@ -952,12 +1067,13 @@ func (f *FunctionStatement) Docs() string {
 			param = "..." + param
 		}
 
-		params = append(params, p.String())
+		params = append(params, p.String()+f.FunctionLiteral.paramType(p.String()))
 
 	}
 	out.WriteString(" (")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
+	out.WriteString(f.FunctionLiteral.returnType())
 
 	return out.String()
 }
@@ -1012,8 +1128,7 @@ func (is *InterpolatedString) String() string       { return is.Token.Literal }
 type TryStmt struct {
 	Token   token.Token
 	Try     *BlockStatement
-	Var     string
-	Catch   *BlockStatement
+	Catches []*CatchClause //tried in order, the first matching one handles the exception
 	Finally *BlockStatement
 }
 
@@ -1026,7 +1141,7 @@ func (t *TryStmt) End() token.Position {
 		return t.Finally.End()
 	}
 
-	return t.Catch.End()
+	return t.Catches[len(t.Catches)-1].End()
 }
 
 func (t *TryStmt) statementNode()       {}
@@ -1039,14 +1154,8 @@ func (t *TryStmt) String() string {
 	out.WriteString(t.Try.String())
 	out.WriteString(" }")
 
-	if t.Catch != nil {
-		if len(t.Var) > 0 {
-			out.WriteString(" catch " + t.Var + " { ")
-		} else {
-			out.WriteString(" catch { ")
-		}
-		out.WriteString(t.Catch.String())
-		out.WriteString(" }")
+	for _, c := range t.Catches {
+		out.WriteString(" " + c.String())
 	}
 
 	if t.Finally != nil {
@@ -1058,6 +1167,46 @@ func (t *TryStmt) String() string {
 	return out.String()
 }
 
+//CatchClause is a 'catch' clause of the TryStmt:
+//
+//    catch { ... }
+//    catch e { ... }
+//    catch (e: IOError | NetError) { ... }
+//
+//A clause without types catches everything.
+type CatchClause struct {
+	Token token.Token
+	Var   string
+	Types []string
+	Block *BlockStatement
+}
+
+func (c *CatchClause) Pos() token.Position {
+	return c.Token.Pos
+}
+
+func (c *CatchClause) End() token.Position {
+	return c.Block.End()
+}
+
+func (c *CatchClause) TokenLiteral() string { return c.Token.Literal }
+
+func (c *CatchClause) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("catch ")
+	if len(c.Types) > 0 {
+		out.WriteString("(" + c.Var + ": " + strings.Join(c.Types, " | ") + ") ")
+	} else if len(c.Var) > 0 {
+		out.WriteString(c.Var + " ")
+	}
+	out.WriteString("{ ")
+	out.WriteString(c.Block.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
 //throw <expression>
 type ThrowStmt struct {
 	Token token.Token
@@ -1069,6 +1218,9 @@ func (ts *ThrowStmt) Pos() token.Position {
 }
 
 func (ts *ThrowStmt) End() token.Position {
+	if ts.Expr == nil { //rethrow
+		return token.Position{Filename: ts.Token.Pos.Filename, Line: ts.Token.Pos.Line, Col: ts.Token.Pos.Col + len(ts.Token.Literal)}
+	}
 	return ts.Expr.End()
 }
 
@@ -1078,8 +1230,10 @@ func (ts *ThrowStmt) TokenLiteral() string { return ts.Token.Literal }
 func (ts *ThrowStmt) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("throw ")
-	out.WriteString(ts.Expr.String())
+	out.WriteString("throw")
+	if ts.Expr != nil {
+		out.WriteString(" " + ts.Expr.String())
+	}
 	out.WriteString(";")
 
 	return out.String()
@@ -1248,6 +1402,7 @@ type LetStatement struct {
 	Token  token.Token
 	Names  []*Identifier
 	Values []Expression
+	Types  map[string]*TypeExpr //the annotated types of the names, e.g. 'lit name: string'
 
 	StaticFlag    bool
 	ModifierLevel ModifierLevel //used in 'class'
@@ -1308,7 +1463,11 @@ func (ls *LetStatement) String() string {
 
 	names := []string{}
 	for _, name := range ls.Names {
-		names = append(names, name.String())
+		if t, ok := ls.Types[name.Value]; ok {
+			names = append(names, name.String()+": "+t.String())
+		} else {
+			names = append(names, name.String())
+		}
 	}
 	out.WriteString(strings.Join(names, ", "))
 
@@ -1415,9 +1574,17 @@ func (cs *ConstStatement) Docs() string {
 ///////////////////////////////////////////////////////////
 //                      IMPORT STATEMENT                //
 ///////////////////////////////////////////////////////////
+//require net.util
+//require net.util as nutil
+//require util (parse, format)
 type ImportStatement struct {
 	Token      token.Token
-	ImportPath string
+	ImportPath string        //the name the module is bound to, the alias or the last part of the path
+	Path       string        //the module path, e.g. 'net/util'
+	File       string        //the file the module was loaded from
+	Alias      *Identifier   //'as nutil'
+	Names      []*Identifier //the names imported into the file's scope, the module itself is not bound
+	EndToken   token.Token   //the last token of the statement
 	Program    *Program
 	Functions  map[string]*FunctionLiteral //for debugger usage
 }
@@ -1427,8 +1594,9 @@ func (is *ImportStatement) Pos() token.Position {
 }
 
 func (is *ImportStatement) End() token.Position {
-	length := utf8.RuneCountInString(is.ImportPath)
-	return token.Position{Filename: is.Token.Pos.Filename, Line: is.Token.Pos.Line, Col: is.Token.Pos.Col + length}
+	length := utf8.RuneCountInString(is.EndToken.Literal)
+	pos := is.EndToken.Pos
+	return token.Position{Filename: pos.Filename, Line: pos.Line, Col: pos.Col + length}
 }
 
 func (is *ImportStatement) statementNode()       {}
@@ -1438,7 +1606,17 @@ func (is *ImportStatement) String() string {
 
 	out.WriteString(is.TokenLiteral())
 	out.WriteString(" ")
-	out.WriteString(is.ImportPath)
+	out.WriteString(strings.Replace(is.Path, "/", ".", -1))
+	if is.Alias != nil {
+		out.WriteString(" as " + is.Alias.Value)
+	}
+	if len(is.Names) > 0 {
+		names := []string{}
+		for _, name := range is.Names {
+			names = append(names, name.Value)
+		}
+		out.WriteString(" (" + strings.Join(names, ", ") + ")")
+	}
 
 	return out.String()
 }
@@ -1915,6 +2093,248 @@ func (ce *CaseElseExpr) String() string {
 	return out.String()
 }
 
+///////////////////////////////////////////////////////////
+//                    MATCH(PATTERNS)                    //
+///////////////////////////////////////////////////////////
+
+//match expr {
+//    pattern, pattern if guard { block }
+//    else { block }
+//}
+type MatchExpr struct {
+	Token       token.Token
+	Expr        Expression
+	Arms        []*MatchArm
+	RBraceToken token.Token
+}
+
+func (m *MatchExpr) Pos() token.Position {
+	return m.Token.Pos
+}
+
+func (m *MatchExpr) End() token.Position {
+	return token.Position{Filename: m.Token.Pos.Filename, Line: m.RBraceToken.Pos.Line, Col: m.RBraceToken.Pos.Col + 1}
+}
+
+func (m *MatchExpr) expressionNode()      {}
+func (m *MatchExpr) TokenLiteral() string { return m.Token.Literal }
+
+func (m *MatchExpr) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("match ")
+	out.WriteString(m.Expr.String())
+	out.WriteString(" { ")
+
+	arms := []string{}
+	for _, arm := range m.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString(strings.Join(arms, " "))
+	out.WriteString(" }")
+	return out.String()
+}
+
+//MatchArm is an arm of a 'match', the 'else' arm has no patterns.
+type MatchArm struct {
+	Token    token.Token
+	Patterns []Expression //the patterns separated by ',', any of them matches
+	Guard    Expression   //'if guard', may be nil
+	Block    *BlockStatement
+}
+
+func (a *MatchArm) Pos() token.Position {
+	return a.Token.Pos
+}
+
+func (a *MatchArm) End() token.Position {
+	return a.Block.End()
+}
+
+func (a *MatchArm) expressionNode()      {}
+func (a *MatchArm) TokenLiteral() string { return a.Token.Literal }
+
+func (a *MatchArm) String() string {
+	var out bytes.Buffer
+
+	if len(a.Patterns) == 0 {
+		out.WriteString("else")
+	} else {
+		patterns := []string{}
+		for _, p := range a.Patterns {
+			patterns = append(patterns, p.String())
+		}
+		out.WriteString(strings.Join(patterns, ", "))
+	}
+	if a.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(a.Guard.String())
+	}
+	out.WriteString(" { ")
+	out.WriteString(a.Block.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+//IdentPattern is '_', or a name. The name is a type pattern if it's a type
+//name('int', 'string'...) or a class, else it binds the value to the name.
+type IdentPattern struct {
+	Token token.Token
+	Var   string
+}
+
+func (p *IdentPattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *IdentPattern) End() token.Position {
+	return token.Position{Filename: p.Token.Pos.Filename, Line: p.Token.Pos.Line, Col: p.Token.Pos.Col + utf8.RuneCountInString(p.Var)}
+}
+
+func (p *IdentPattern) expressionNode()      {}
+func (p *IdentPattern) TokenLiteral() string { return p.Token.Literal }
+func (p *IdentPattern) String() string       { return p.Var }
+
+//ValuePattern is a literal or a constant(e.g. 'Color.RED'), compared with '=='.
+type ValuePattern struct {
+	Token token.Token
+	Value Expression
+}
+
+func (p *ValuePattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *ValuePattern) End() token.Position {
+	return p.Value.End()
+}
+
+func (p *ValuePattern) expressionNode()      {}
+func (p *ValuePattern) TokenLiteral() string { return p.Token.Literal }
+func (p *ValuePattern) String() string       { return p.Value.String() }
+
+//TuplePattern is '(pattern, pattern...)'.
+type TuplePattern struct {
+	Token       token.Token
+	Members     []Expression
+	RParenToken token.Token
+}
+
+func (p *TuplePattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *TuplePattern) End() token.Position {
+	return token.Position{Filename: p.Token.Pos.Filename, Line: p.RParenToken.Pos.Line, Col: p.RParenToken.Pos.Col + 1}
+}
+
+func (p *TuplePattern) expressionNode()      {}
+func (p *TuplePattern) TokenLiteral() string { return p.Token.Literal }
+func (p *TuplePattern) String() string {
+	return "(" + patternList(p.Members) + ")"
+}
+
+//ArrayPattern is '[pattern, pattern...]', or '[pattern, ...rest]' which binds
+//the remaining members to 'rest'('...' alone ignores them).
+type ArrayPattern struct {
+	Token         token.Token
+	Members       []Expression
+	HasRest       bool
+	Var           string //the name of the rest, may be empty
+	RBracketToken token.Token
+}
+
+func (p *ArrayPattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *ArrayPattern) End() token.Position {
+	return token.Position{Filename: p.Token.Pos.Filename, Line: p.RBracketToken.Pos.Line, Col: p.RBracketToken.Pos.Col + 1}
+}
+
+func (p *ArrayPattern) expressionNode()      {}
+func (p *ArrayPattern) TokenLiteral() string { return p.Token.Literal }
+func (p *ArrayPattern) String() string {
+	members := patternList(p.Members)
+	if p.HasRest {
+		if members != "" {
+			members += ", "
+		}
+		members += "..." + p.Var
+	}
+	return "[" + members + "]"
+}
+
+//HashPattern is '{key: pattern, name...}', 'name' is short for 'name: name'.
+//The hash may have other keys.
+type HashPattern struct {
+	Token       token.Token
+	Keys        []Expression
+	Values      []Expression
+	RBraceToken token.Token
+}
+
+func (p *HashPattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *HashPattern) End() token.Position {
+	return token.Position{Filename: p.Token.Pos.Filename, Line: p.RBraceToken.Pos.Line, Col: p.RBraceToken.Pos.Col + 1}
+}
+
+func (p *HashPattern) expressionNode()      {}
+func (p *HashPattern) TokenLiteral() string { return p.Token.Literal }
+func (p *HashPattern) String() string {
+	pairs := []string{}
+	for i, key := range p.Keys {
+		pairs = append(pairs, key.String()+": "+p.Values[i].String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+//TypePattern is 'int(pattern)'(the value is an int and matches the pattern), or
+//'Class(name, name: pattern...)'(the value is an instance of the class, and its
+//members or properties match the patterns, 'name' is short for 'name: name').
+type TypePattern struct {
+	Token       token.Token
+	Type        string
+	Names       []string //the member name of each pattern, "" if it's not named
+	Patterns    []Expression
+	RParenToken token.Token
+}
+
+func (p *TypePattern) Pos() token.Position {
+	return p.Token.Pos
+}
+
+func (p *TypePattern) End() token.Position {
+	return token.Position{Filename: p.Token.Pos.Filename, Line: p.RParenToken.Pos.Line, Col: p.RParenToken.Pos.Col + 1}
+}
+
+func (p *TypePattern) expressionNode()      {}
+func (p *TypePattern) TokenLiteral() string { return p.Token.Literal }
+func (p *TypePattern) String() string {
+	fields := []string{}
+	for i, pattern := range p.Patterns {
+		if p.Names[i] == "" || p.Names[i] == pattern.String() {
+			fields = append(fields, pattern.String())
+		} else {
+			fields = append(fields, p.Names[i]+": "+pattern.String())
+		}
+	}
+	return p.Type + "(" + strings.Join(fields, ", ") + ")"
+}
+
+func patternList(patterns []Expression) string {
+	list := []string{}
+	for _, p := range patterns {
+		list = append(list, p.String())
+	}
+	return strings.Join(list, ", ")
+}
+
 ///////////////////////////////////////////////////////////
 //                       SLICE/INDEX                     //
 ///////////////////////////////////////////////////////////
@@ -2513,6 +2933,7 @@ type ClassLiteral struct {
 	Token      token.Token
 	Name       string
 	Parent     string
+	Interfaces []string                      //the names after the parent, e.g. 'class File : Base, Closeable'
 	Members    []*LetStatement               //class's fields
 	Properties map[string]*PropertyDeclStmt  //class's properties
 	Methods    map[string]*FunctionStatement //class's methods
@@ -2537,7 +2958,11 @@ func (c *ClassLiteral) String() string {
 	out.WriteString(c.TokenLiteral() + " ")
 	out.WriteString(c.Name)
 	if len(c.Parent) != 0 {
-		out.WriteString(" : " + c.Parent + " ")
+		out.WriteString(" : " + c.Parent)
+		for _, name := range c.Interfaces {
+			out.WriteString(", " + name)
+		}
+		out.WriteString(" ")
 	}
 
 	out.WriteString("{ ")
@@ -2602,6 +3027,9 @@ func (c *ClassStatement) String() string {
 	} else {
 		if len(c.ClassLiteral.Parent) > 0 {
 			out.WriteString(" : " + c.ClassLiteral.Parent)
+			for _, name := range c.ClassLiteral.Interfaces {
+				out.WriteString(", " + name)
+			}
 		}
 	}
 
@@ -2628,6 +3056,9 @@ func (c *ClassStatement) Docs() string {
 	} else {
 		if len(c.ClassLiteral.Parent) > 0 {
 			out.WriteString(" : " + c.ClassLiteral.Parent)
+			for _, name := range c.ClassLiteral.Interfaces {
+				out.WriteString(", " + name)
+			}
 		}
 	}
 
@@ -2635,6 +3066,102 @@ func (c *ClassStatement) Docs() string {
 	return out.String()
 }
 
+///////////////////////////////////////////////////////////
+//                  INTERFACE STATEMENT                  //
+///////////////////////////////////////////////////////////
+//interface name : parentInterfaces { fn method(parameters) property name }
+type InterfaceStatement struct {
+	Token       token.Token
+	Name        *Identifier
+	Parents     []*Identifier //the interfaces it extends
+	Methods     []*InterfaceMethod
+	Properties  []*Identifier
+	RBraceToken token.Token
+
+	//Doc related
+	Doc         *CommentGroup // associated documentation; or nil
+	SrcEndToken token.Token
+}
+
+func (i *InterfaceStatement) Pos() token.Position {
+	return i.Token.Pos
+}
+
+func (i *InterfaceStatement) End() token.Position {
+	return token.Position{Filename: i.Token.Pos.Filename, Line: i.RBraceToken.Pos.Line, Col: i.RBraceToken.Pos.Col + 1}
+}
+
+//Below two methods implements 'Source' interface.
+func (i *InterfaceStatement) SrcStart() token.Position {
+	return i.Pos()
+}
+
+func (i *InterfaceStatement) SrcEnd() token.Position {
+	ret := i.SrcEndToken.Pos
+	length := utf8.RuneCountInString(i.SrcEndToken.Literal)
+	ret.Offset += length
+	return ret
+}
+
+func (i *InterfaceStatement) statementNode()       {}
+func (i *InterfaceStatement) TokenLiteral() string { return i.Token.Literal }
+
+func (i *InterfaceStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(i.Docs())
+	out.WriteString(" { ")
+	for _, m := range i.Methods {
+		out.WriteString(m.String() + "; ")
+	}
+	for _, p := range i.Properties {
+		out.WriteString("property " + p.Value + "; ")
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+func (i *InterfaceStatement) Docs() string {
+	var out bytes.Buffer
+
+	out.WriteString(i.Token.Literal + " " + i.Name.Value)
+	if len(i.Parents) > 0 {
+		parents := []string{}
+		for _, p := range i.Parents {
+			parents = append(parents, p.Value)
+		}
+		out.WriteString(" : " + strings.Join(parents, ", "))
+	}
+
+	return out.String()
+}
+
+//fn name(parameters), a method an interface requires
+type InterfaceMethod struct {
+	Token      token.Token
+	Name       *Identifier
+	Parameters []Expression
+	ParamTypes map[string]*TypeExpr
+	ReturnType *TypeExpr
+}
+
+func (m *InterfaceMethod) String() string {
+	params := []string{}
+	for _, p := range m.Parameters {
+		if t, ok := m.ParamTypes[p.String()]; ok {
+			params = append(params, p.String()+": "+t.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	ret := m.Token.Literal + " " + m.Name.Value + "(" + strings.Join(params, ", ") + ")"
+	if m.ReturnType != nil {
+		ret += " -> " + m.ReturnType.String()
+	}
+	return ret
+}
+
 ///////////////////////////////////////////////////////////
 //                   NEW EXPRESSION                      //
 ///////////////////////////////////////////////////////////
@@ -2683,6 +3210,7 @@ type PropertyDeclStmt struct {
 	Getter        *GetterStmt   //getter
 	Setter        *SetterStmt   //setter
 	Indexes       []*Identifier //only used in class's indexer
+	Type          *TypeExpr     //the annotated type, e.g. 'property Name: string { get; set; }'
 	StaticFlag    bool
 	ModifierLevel ModifierLevel //property's modifier
 	Annotations   []*AnnotationStmt
@@ -2745,6 +3273,9 @@ func (p *PropertyDeclStmt) String() string {
 		out.WriteString("]")
 	} else {
 	}
+	if p.Type != nil {
+		out.WriteString(": " + p.Type.String())
+	}
 
 	if p.Default != nil { //must be an annotation class
 		out.WriteString(" default ")
@@ -2792,6 +3323,9 @@ func (p *PropertyDeclStmt) Docs() string {
 		out.WriteString("]")
 	} else {
 	}
+	if p.Type != nil {
+		out.WriteString(": " + p.Type.String())
+	}
 
 	if p.Default != nil { //must be an annotation class
 		out.WriteString(" default ")
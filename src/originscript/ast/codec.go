@@ -0,0 +1,196 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"originscript/token"
+)
+
+//go:generate go run gen_codec.go
+
+//The codec is a compact binary encoding of a program, for the parsed program
+//cache. The nodes are written depth-first by the functions of codec_gen.go,
+//the strings and the pointers once, the later uses refer to the first one(e.g.
+//the keys of 'HashLiteral.Pairs' are also in 'HashLiteral.Order'). The nodes
+//of the interface fields are preceded by their type.
+
+//the pointer markers
+const (
+	ptrNil = iota
+	ptrNew //followed by the value
+	ptrRef //followed by the index of the pointer
+)
+
+//EncodeProgram encodes the program.
+func EncodeProgram(program *Program) (data []byte, err error) {
+	e := &encoder{strings: make(map[string]int), pointers: make(map[interface{}]int)}
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("encode program: %v", r)
+		}
+	}()
+	e.encProgram(program)
+	return e.buf.Bytes(), nil
+}
+
+//DecodeProgram decodes a program encoded by EncodeProgram.
+func DecodeProgram(data []byte) (program *Program, err error) {
+	d := &decoder{data: data}
+	defer func() {
+		if r := recover(); r != nil {
+			program, err = nil, fmt.Errorf("decode program: %v", r)
+		}
+	}()
+	program = d.decProgram()
+	if d.pos != len(d.data) {
+		return nil, errors.New("decode program: trailing data")
+	}
+	return program, nil
+}
+
+type encoder struct {
+	buf      bytes.Buffer
+	strings  map[string]int
+	pointers map[interface{}]int
+	tmp      [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) byte(b byte) {
+	e.buf.WriteByte(b)
+}
+
+func (e *encoder) bool(b bool) {
+	if b {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *encoder) uint(u uint64) {
+	n := binary.PutUvarint(e.tmp[:], u)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) int(i int64) {
+	n := binary.PutVarint(e.tmp[:], i)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) float(f float64) {
+	e.uint(math.Float64bits(f))
+}
+
+func (e *encoder) string(s string) {
+	if idx, ok := e.strings[s]; ok {
+		e.uint(uint64(idx))
+		return
+	}
+	idx := len(e.strings)
+	e.strings[s] = idx
+	e.uint(uint64(idx))
+	e.uint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+//pointer writes the marker of a non-nil pointer, returns true if the pointed
+//value must follow.
+func (e *encoder) pointer(p interface{}) bool {
+	if idx, ok := e.pointers[p]; ok {
+		e.buf.WriteByte(ptrRef)
+		e.uint(uint64(idx))
+		return false
+	}
+	e.pointers[p] = len(e.pointers)
+	e.buf.WriteByte(ptrNew)
+	return true
+}
+
+func (e *encoder) token(t *token.Token) {
+	e.string(t.Pos.Filename)
+	e.int(int64(t.Pos.Offset))
+	e.int(int64(t.Pos.Line))
+	e.int(int64(t.Pos.Col))
+	e.int(int64(t.Type))
+	e.string(t.Literal)
+}
+
+type decoder struct {
+	data     []byte
+	pos      int
+	strings  []string
+	pointers []interface{}
+}
+
+func (d *decoder) byte() byte {
+	b := d.data[d.pos]
+	d.pos++
+	return b
+}
+
+func (d *decoder) bool() bool {
+	return d.byte() != 0
+}
+
+func (d *decoder) uint() uint64 {
+	u, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		panic("invalid data")
+	}
+	d.pos += n
+	return u
+}
+
+func (d *decoder) int() int64 {
+	i, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		panic("invalid data")
+	}
+	d.pos += n
+	return i
+}
+
+func (d *decoder) float() float64 {
+	return math.Float64frombits(d.uint())
+}
+
+func (d *decoder) string() string {
+	idx := int(d.uint())
+	if idx < len(d.strings) {
+		return d.strings[idx]
+	}
+	if idx != len(d.strings) {
+		panic("invalid string")
+	}
+	n := int(d.uint())
+	s := string(d.data[d.pos : d.pos+n])
+	d.pos += n
+	d.strings = append(d.strings, s)
+	return s
+}
+
+//pointer reads the marker of a pointer. It returns false with the pointer if
+//it's nil or already decoded. It returns true if the pointed value follows,
+//the caller adds the new pointer to 'd.pointers' before decoding the value,
+//the value may refer to it.
+func (d *decoder) pointer() (interface{}, bool) {
+	switch d.byte() {
+	case ptrNil:
+		return nil, false
+	case ptrRef:
+		return d.pointers[d.uint()], false
+	}
+	return nil, true
+}
+
+func (d *decoder) token(t *token.Token) {
+	t.Pos.Filename = d.string()
+	t.Pos.Offset = int(d.int())
+	t.Pos.Line = int(d.int())
+	t.Pos.Col = int(d.int())
+	t.Type = token.TokenType(d.int())
+	t.Literal = d.string()
+}
@@ -0,0 +1,4575 @@
+// Code generated by gen_codec.go; DO NOT EDIT.
+
+package ast
+
+import "fmt"
+
+// CodecSchema changes when the encoded nodes change.
+const CodecSchema = "43275ce4d0f49667"
+
+func (e *encoder) node(n interface{}) {
+	switch n := n.(type) {
+	case nil:
+		e.uint(0)
+	case *AnnotationStmt:
+		e.uint(1)
+		e.encAnnotationStmt(n)
+	case *ArrayLiteral:
+		e.uint(2)
+		e.encArrayLiteral(n)
+	case *ArrayPattern:
+		e.uint(3)
+		e.encArrayPattern(n)
+	case *AssignExpression:
+		e.uint(4)
+		e.encAssignExpression(n)
+	case *AwaitExpr:
+		e.uint(5)
+		e.encAwaitExpr(n)
+	case *BlockStatement:
+		e.uint(6)
+		e.encBlockStatement(n)
+	case *Boolean:
+		e.uint(7)
+		e.encBoolean(n)
+	case *BreakExpression:
+		e.uint(8)
+		e.encBreakExpression(n)
+	case *CallExpression:
+		e.uint(9)
+		e.encCallExpression(n)
+	case *CaseElseExpr:
+		e.uint(10)
+		e.encCaseElseExpr(n)
+	case *CaseExpr:
+		e.uint(11)
+		e.encCaseExpr(n)
+	case *CaseMatchExpr:
+		e.uint(12)
+		e.encCaseMatchExpr(n)
+	case *CatchClause:
+		e.uint(13)
+		e.encCatchClause(n)
+	case *ClassIndexerExpression:
+		e.uint(14)
+		e.encClassIndexerExpression(n)
+	case *ClassLiteral:
+		e.uint(15)
+		e.encClassLiteral(n)
+	case *ClassStatement:
+		e.uint(16)
+		e.encClassStatement(n)
+	case *CmdExpression:
+		e.uint(17)
+		e.encCmdExpression(n)
+	case *Comment:
+		e.uint(18)
+		e.encComment(n)
+	case *CommentGroup:
+		e.uint(19)
+		e.encCommentGroup(n)
+	case *ConstStatement:
+		e.uint(20)
+		e.encConstStatement(n)
+	case *ContinueExpression:
+		e.uint(21)
+		e.encContinueExpression(n)
+	case *DateTimeExpr:
+		e.uint(22)
+		e.encDateTimeExpr(n)
+	case *DeferStmt:
+		e.uint(23)
+		e.encDeferStmt(n)
+	case *DiamondExpr:
+		e.uint(24)
+		e.encDiamondExpr(n)
+	case *DoLoop:
+		e.uint(25)
+		e.encDoLoop(n)
+	case *EnumLiteral:
+		e.uint(26)
+		e.encEnumLiteral(n)
+	case *EnumStatement:
+		e.uint(27)
+		e.encEnumStatement(n)
+	case *ExpressionStatement:
+		e.uint(28)
+		e.encExpressionStatement(n)
+	case *FloatLiteral:
+		e.uint(29)
+		e.encFloatLiteral(n)
+	case *ForEachArrayLoop:
+		e.uint(30)
+		e.encForEachArrayLoop(n)
+	case *ForEachDotRange:
+		e.uint(31)
+		e.encForEachDotRange(n)
+	case *ForEachMapLoop:
+		e.uint(32)
+		e.encForEachMapLoop(n)
+	case *ForEverLoop:
+		e.uint(33)
+		e.encForEverLoop(n)
+	case *ForLoop:
+		e.uint(34)
+		e.encForLoop(n)
+	case *FromExpr:
+		e.uint(35)
+		e.encFromExpr(n)
+	case *FunctionLiteral:
+		e.uint(36)
+		e.encFunctionLiteral(n)
+	case *FunctionStatement:
+		e.uint(37)
+		e.encFunctionStatement(n)
+	case *GetterStmt:
+		e.uint(38)
+		e.encGetterStmt(n)
+	case *GrepExpr:
+		e.uint(39)
+		e.encGrepExpr(n)
+	case *GroupExpr:
+		e.uint(40)
+		e.encGroupExpr(n)
+	case *HashComprehension:
+		e.uint(41)
+		e.encHashComprehension(n)
+	case *HashLiteral:
+		e.uint(42)
+		e.encHashLiteral(n)
+	case *HashMapComprehension:
+		e.uint(43)
+		e.encHashMapComprehension(n)
+	case *HashPattern:
+		e.uint(44)
+		e.encHashPattern(n)
+	case *HashRangeComprehension:
+		e.uint(45)
+		e.encHashRangeComprehension(n)
+	case *IdentPattern:
+		e.uint(46)
+		e.encIdentPattern(n)
+	case *Identifier:
+		e.uint(47)
+		e.encIdentifier(n)
+	case *IfConditionExpr:
+		e.uint(48)
+		e.encIfConditionExpr(n)
+	case *IfExpression:
+		e.uint(49)
+		e.encIfExpression(n)
+	case *IfMacroStatement:
+		e.uint(50)
+		e.encIfMacroStatement(n)
+	case *ImportStatement:
+		e.uint(51)
+		e.encImportStatement(n)
+	case *IndexExpression:
+		e.uint(52)
+		e.encIndexExpression(n)
+	case *InfixExpression:
+		e.uint(53)
+		e.encInfixExpression(n)
+	case *IntegerLiteral:
+		e.uint(54)
+		e.encIntegerLiteral(n)
+	case *InterfaceMethod:
+		e.uint(55)
+		e.encInterfaceMethod(n)
+	case *InterfaceStatement:
+		e.uint(56)
+		e.encInterfaceStatement(n)
+	case *InterpolatedString:
+		e.uint(57)
+		e.encInterpolatedString(n)
+	case *JoinExpr:
+		e.uint(58)
+		e.encJoinExpr(n)
+	case *LetStatement:
+		e.uint(59)
+		e.encLetStatement(n)
+	case *ListComprehension:
+		e.uint(60)
+		e.encListComprehension(n)
+	case *ListMapComprehension:
+		e.uint(61)
+		e.encListMapComprehension(n)
+	case *ListRangeComprehension:
+		e.uint(62)
+		e.encListRangeComprehension(n)
+	case *MapExpr:
+		e.uint(63)
+		e.encMapExpr(n)
+	case *MatchArm:
+		e.uint(64)
+		e.encMatchArm(n)
+	case *MatchExpr:
+		e.uint(65)
+		e.encMatchExpr(n)
+	case *MethodCallExpression:
+		e.uint(66)
+		e.encMethodCallExpression(n)
+	case *NewExpression:
+		e.uint(67)
+		e.encNewExpression(n)
+	case *NilLiteral:
+		e.uint(68)
+		e.encNilLiteral(n)
+	case *OrderExpr:
+		e.uint(69)
+		e.encOrderExpr(n)
+	case *OrderingExpr:
+		e.uint(70)
+		e.encOrderingExpr(n)
+	case *Pipe:
+		e.uint(71)
+		e.encPipe(n)
+	case *PostfixExpression:
+		e.uint(72)
+		e.encPostfixExpression(n)
+	case *PrefixExpression:
+		e.uint(73)
+		e.encPrefixExpression(n)
+	case *Program:
+		e.uint(74)
+		e.encProgram(n)
+	case *PropertyDeclStmt:
+		e.uint(75)
+		e.encPropertyDeclStmt(n)
+	case *QueryBodyClauseExpr:
+		e.uint(76)
+		e.encQueryBodyClauseExpr(n)
+	case *QueryBodyExpr:
+		e.uint(77)
+		e.encQueryBodyExpr(n)
+	case *QueryContinuationExpr:
+		e.uint(78)
+		e.encQueryContinuationExpr(n)
+	case *QueryExpr:
+		e.uint(79)
+		e.encQueryExpr(n)
+	case *RangeLiteral:
+		e.uint(80)
+		e.encRangeLiteral(n)
+	case *RegExLiteral:
+		e.uint(81)
+		e.encRegExLiteral(n)
+	case *ReturnStatement:
+		e.uint(82)
+		e.encReturnStatement(n)
+	case *SelectExpr:
+		e.uint(83)
+		e.encSelectExpr(n)
+	case *ServiceStatement:
+		e.uint(84)
+		e.encServiceStatement(n)
+	case *SetterStmt:
+		e.uint(85)
+		e.encSetterStmt(n)
+	case *SliceExpression:
+		e.uint(86)
+		e.encSliceExpression(n)
+	case *SpawnStmt:
+		e.uint(87)
+		e.encSpawnStmt(n)
+	case *StringLiteral:
+		e.uint(88)
+		e.encStringLiteral(n)
+	case *StructLiteral:
+		e.uint(89)
+		e.encStructLiteral(n)
+	case *TernaryExpression:
+		e.uint(90)
+		e.encTernaryExpression(n)
+	case *ThrowStmt:
+		e.uint(91)
+		e.encThrowStmt(n)
+	case *TryStmt:
+		e.uint(92)
+		e.encTryStmt(n)
+	case *TupleLiteral:
+		e.uint(93)
+		e.encTupleLiteral(n)
+	case *TuplePattern:
+		e.uint(94)
+		e.encTuplePattern(n)
+	case *TypeExpr:
+		e.uint(95)
+		e.encTypeExpr(n)
+	case *TypePattern:
+		e.uint(96)
+		e.encTypePattern(n)
+	case *UIntegerLiteral:
+		e.uint(97)
+		e.encUIntegerLiteral(n)
+	case *UnlessExpression:
+		e.uint(98)
+		e.encUnlessExpression(n)
+	case *UsingStmt:
+		e.uint(99)
+		e.encUsingStmt(n)
+	case *ValuePattern:
+		e.uint(100)
+		e.encValuePattern(n)
+	case *WhereExpr:
+		e.uint(101)
+		e.encWhereExpr(n)
+	case *WhileLoop:
+		e.uint(102)
+		e.encWhileLoop(n)
+	default:
+		panic(fmt.Sprintf("cannot encode %T", n))
+	}
+}
+
+func (d *decoder) node() interface{} {
+	switch d.uint() {
+	case 0:
+		return nil
+	case 1:
+		return d.decAnnotationStmt()
+	case 2:
+		return d.decArrayLiteral()
+	case 3:
+		return d.decArrayPattern()
+	case 4:
+		return d.decAssignExpression()
+	case 5:
+		return d.decAwaitExpr()
+	case 6:
+		return d.decBlockStatement()
+	case 7:
+		return d.decBoolean()
+	case 8:
+		return d.decBreakExpression()
+	case 9:
+		return d.decCallExpression()
+	case 10:
+		return d.decCaseElseExpr()
+	case 11:
+		return d.decCaseExpr()
+	case 12:
+		return d.decCaseMatchExpr()
+	case 13:
+		return d.decCatchClause()
+	case 14:
+		return d.decClassIndexerExpression()
+	case 15:
+		return d.decClassLiteral()
+	case 16:
+		return d.decClassStatement()
+	case 17:
+		return d.decCmdExpression()
+	case 18:
+		return d.decComment()
+	case 19:
+		return d.decCommentGroup()
+	case 20:
+		return d.decConstStatement()
+	case 21:
+		return d.decContinueExpression()
+	case 22:
+		return d.decDateTimeExpr()
+	case 23:
+		return d.decDeferStmt()
+	case 24:
+		return d.decDiamondExpr()
+	case 25:
+		return d.decDoLoop()
+	case 26:
+		return d.decEnumLiteral()
+	case 27:
+		return d.decEnumStatement()
+	case 28:
+		return d.decExpressionStatement()
+	case 29:
+		return d.decFloatLiteral()
+	case 30:
+		return d.decForEachArrayLoop()
+	case 31:
+		return d.decForEachDotRange()
+	case 32:
+		return d.decForEachMapLoop()
+	case 33:
+		return d.decForEverLoop()
+	case 34:
+		return d.decForLoop()
+	case 35:
+		return d.decFromExpr()
+	case 36:
+		return d.decFunctionLiteral()
+	case 37:
+		return d.decFunctionStatement()
+	case 38:
+		return d.decGetterStmt()
+	case 39:
+		return d.decGrepExpr()
+	case 40:
+		return d.decGroupExpr()
+	case 41:
+		return d.decHashComprehension()
+	case 42:
+		return d.decHashLiteral()
+	case 43:
+		return d.decHashMapComprehension()
+	case 44:
+		return d.decHashPattern()
+	case 45:
+		return d.decHashRangeComprehension()
+	case 46:
+		return d.decIdentPattern()
+	case 47:
+		return d.decIdentifier()
+	case 48:
+		return d.decIfConditionExpr()
+	case 49:
+		return d.decIfExpression()
+	case 50:
+		return d.decIfMacroStatement()
+	case 51:
+		return d.decImportStatement()
+	case 52:
+		return d.decIndexExpression()
+	case 53:
+		return d.decInfixExpression()
+	case 54:
+		return d.decIntegerLiteral()
+	case 55:
+		return d.decInterfaceMethod()
+	case 56:
+		return d.decInterfaceStatement()
+	case 57:
+		return d.decInterpolatedString()
+	case 58:
+		return d.decJoinExpr()
+	case 59:
+		return d.decLetStatement()
+	case 60:
+		return d.decListComprehension()
+	case 61:
+		return d.decListMapComprehension()
+	case 62:
+		return d.decListRangeComprehension()
+	case 63:
+		return d.decMapExpr()
+	case 64:
+		return d.decMatchArm()
+	case 65:
+		return d.decMatchExpr()
+	case 66:
+		return d.decMethodCallExpression()
+	case 67:
+		return d.decNewExpression()
+	case 68:
+		return d.decNilLiteral()
+	case 69:
+		return d.decOrderExpr()
+	case 70:
+		return d.decOrderingExpr()
+	case 71:
+		return d.decPipe()
+	case 72:
+		return d.decPostfixExpression()
+	case 73:
+		return d.decPrefixExpression()
+	case 74:
+		return d.decProgram()
+	case 75:
+		return d.decPropertyDeclStmt()
+	case 76:
+		return d.decQueryBodyClauseExpr()
+	case 77:
+		return d.decQueryBodyExpr()
+	case 78:
+		return d.decQueryContinuationExpr()
+	case 79:
+		return d.decQueryExpr()
+	case 80:
+		return d.decRangeLiteral()
+	case 81:
+		return d.decRegExLiteral()
+	case 82:
+		return d.decReturnStatement()
+	case 83:
+		return d.decSelectExpr()
+	case 84:
+		return d.decServiceStatement()
+	case 85:
+		return d.decSetterStmt()
+	case 86:
+		return d.decSliceExpression()
+	case 87:
+		return d.decSpawnStmt()
+	case 88:
+		return d.decStringLiteral()
+	case 89:
+		return d.decStructLiteral()
+	case 90:
+		return d.decTernaryExpression()
+	case 91:
+		return d.decThrowStmt()
+	case 92:
+		return d.decTryStmt()
+	case 93:
+		return d.decTupleLiteral()
+	case 94:
+		return d.decTuplePattern()
+	case 95:
+		return d.decTypeExpr()
+	case 96:
+		return d.decTypePattern()
+	case 97:
+		return d.decUIntegerLiteral()
+	case 98:
+		return d.decUnlessExpression()
+	case 99:
+		return d.decUsingStmt()
+	case 100:
+		return d.decValuePattern()
+	case 101:
+		return d.decWhereExpr()
+	case 102:
+		return d.decWhileLoop()
+	}
+	panic("invalid node")
+}
+
+func (e *encoder) encAnnotationStmt(x *AnnotationStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	if x.Attributes == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Attributes)) + 1)
+		for k1, v2 := range x.Attributes {
+			e.string(k1)
+			e.node(v2)
+		}
+	}
+}
+
+func (d *decoder) decAnnotationStmt() *AnnotationStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*AnnotationStmt)
+	}
+	x := &AnnotationStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	if n3 := int(d.uint()); n3 != 0 {
+		x.Attributes = make(map[string]Expression, n3-1)
+		for i4 := 1; i4 < n3; i4++ {
+			var k5 string
+			k5 = d.string()
+			var v6 Expression
+			if n7 := d.node(); n7 != nil {
+				v6 = n7.(Expression)
+			}
+			x.Attributes[k5] = v6
+		}
+	}
+	return x
+}
+
+func (e *encoder) encArrayLiteral(x *ArrayLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Members == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Members)) + 1)
+		for _, v8 := range x.Members {
+			e.node(v8)
+		}
+	}
+	e.encIntegerLiteral(x.CreationCount)
+}
+
+func (d *decoder) decArrayLiteral() *ArrayLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ArrayLiteral)
+	}
+	x := &ArrayLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n9 := int(d.uint()); n9 != 0 {
+		x.Members = make([]Expression, n9-1)
+		for i10 := range x.Members {
+			if n11 := d.node(); n11 != nil {
+				x.Members[i10] = n11.(Expression)
+			}
+		}
+	}
+	x.CreationCount = d.decIntegerLiteral()
+	return x
+}
+
+func (e *encoder) encArrayPattern(x *ArrayPattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Members == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Members)) + 1)
+		for _, v12 := range x.Members {
+			e.node(v12)
+		}
+	}
+	e.bool(x.HasRest)
+	e.string(x.Var)
+	e.token(&x.RBracketToken)
+}
+
+func (d *decoder) decArrayPattern() *ArrayPattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ArrayPattern)
+	}
+	x := &ArrayPattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n13 := int(d.uint()); n13 != 0 {
+		x.Members = make([]Expression, n13-1)
+		for i14 := range x.Members {
+			if n15 := d.node(); n15 != nil {
+				x.Members[i14] = n15.(Expression)
+			}
+		}
+	}
+	x.HasRest = d.bool()
+	x.Var = d.string()
+	d.token(&x.RBracketToken)
+	return x
+}
+
+func (e *encoder) encAssignExpression(x *AssignExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Name)
+	e.node(x.Value)
+}
+
+func (d *decoder) decAssignExpression() *AssignExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*AssignExpression)
+	}
+	x := &AssignExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n16 := d.node(); n16 != nil {
+		x.Name = n16.(Expression)
+	}
+	if n17 := d.node(); n17 != nil {
+		x.Value = n17.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encAwaitExpr(x *AwaitExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Call)
+}
+
+func (d *decoder) decAwaitExpr() *AwaitExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*AwaitExpr)
+	}
+	x := &AwaitExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n18 := d.node(); n18 != nil {
+		x.Call = n18.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encBlockStatement(x *BlockStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Statements == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Statements)) + 1)
+		for _, v19 := range x.Statements {
+			e.node(v19)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decBlockStatement() *BlockStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*BlockStatement)
+	}
+	x := &BlockStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n20 := int(d.uint()); n20 != 0 {
+		x.Statements = make([]Statement, n20-1)
+		for i21 := range x.Statements {
+			if n22 := d.node(); n22 != nil {
+				x.Statements[i21] = n22.(Statement)
+			}
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encBoolean(x *Boolean) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.bool(x.Value)
+}
+
+func (d *decoder) decBoolean() *Boolean {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*Boolean)
+	}
+	x := &Boolean{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.bool()
+	return x
+}
+
+func (e *encoder) encBreakExpression(x *BreakExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+}
+
+func (d *decoder) decBreakExpression() *BreakExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*BreakExpression)
+	}
+	x := &BreakExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	return x
+}
+
+func (e *encoder) encCallExpression(x *CallExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Function)
+	if x.Arguments == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Arguments)) + 1)
+		for _, v23 := range x.Arguments {
+			e.node(v23)
+		}
+	}
+	e.bool(x.Awaited)
+}
+
+func (d *decoder) decCallExpression() *CallExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CallExpression)
+	}
+	x := &CallExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n24 := d.node(); n24 != nil {
+		x.Function = n24.(Expression)
+	}
+	if n25 := int(d.uint()); n25 != 0 {
+		x.Arguments = make([]Expression, n25-1)
+		for i26 := range x.Arguments {
+			if n27 := d.node(); n27 != nil {
+				x.Arguments[i26] = n27.(Expression)
+			}
+		}
+	}
+	x.Awaited = d.bool()
+	return x
+}
+
+func (e *encoder) encCaseElseExpr(x *CaseElseExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decCaseElseExpr() *CaseElseExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CaseElseExpr)
+	}
+	x := &CaseElseExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encCaseExpr(x *CaseExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.bool(x.IsWholeMatch)
+	e.node(x.Expr)
+	if x.Matches == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Matches)) + 1)
+		for _, v28 := range x.Matches {
+			e.node(v28)
+		}
+	}
+}
+
+func (d *decoder) decCaseExpr() *CaseExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CaseExpr)
+	}
+	x := &CaseExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.IsWholeMatch = d.bool()
+	if n29 := d.node(); n29 != nil {
+		x.Expr = n29.(Expression)
+	}
+	if n30 := int(d.uint()); n30 != 0 {
+		x.Matches = make([]Expression, n30-1)
+		for i31 := range x.Matches {
+			if n32 := d.node(); n32 != nil {
+				x.Matches[i31] = n32.(Expression)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encCaseMatchExpr(x *CaseMatchExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expr)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decCaseMatchExpr() *CaseMatchExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CaseMatchExpr)
+	}
+	x := &CaseMatchExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n33 := d.node(); n33 != nil {
+		x.Expr = n33.(Expression)
+	}
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encCatchClause(x *CatchClause) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	if x.Types == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Types)) + 1)
+		for _, v34 := range x.Types {
+			e.string(v34)
+		}
+	}
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decCatchClause() *CatchClause {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CatchClause)
+	}
+	x := &CatchClause{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n35 := int(d.uint()); n35 != 0 {
+		x.Types = make([]string, n35-1)
+		for i36 := range x.Types {
+			x.Types[i36] = d.string()
+		}
+	}
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encClassIndexerExpression(x *ClassIndexerExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Parameters == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Parameters)) + 1)
+		for _, v37 := range x.Parameters {
+			e.node(v37)
+		}
+	}
+}
+
+func (d *decoder) decClassIndexerExpression() *ClassIndexerExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ClassIndexerExpression)
+	}
+	x := &ClassIndexerExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n38 := int(d.uint()); n38 != 0 {
+		x.Parameters = make([]Expression, n38-1)
+		for i39 := range x.Parameters {
+			if n40 := d.node(); n40 != nil {
+				x.Parameters[i39] = n40.(Expression)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encClassLiteral(x *ClassLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Name)
+	e.string(x.Parent)
+	if x.Interfaces == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Interfaces)) + 1)
+		for _, v41 := range x.Interfaces {
+			e.string(v41)
+		}
+	}
+	if x.Members == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Members)) + 1)
+		for _, v42 := range x.Members {
+			e.encLetStatement(v42)
+		}
+	}
+	if x.Properties == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Properties)) + 1)
+		for k43, v44 := range x.Properties {
+			e.string(k43)
+			e.encPropertyDeclStmt(v44)
+		}
+	}
+	if x.Methods == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Methods)) + 1)
+		for k45, v46 := range x.Methods {
+			e.string(k45)
+			e.encFunctionStatement(v46)
+		}
+	}
+	e.encBlockStatement(x.Block)
+	e.int(int64(x.Modifier))
+}
+
+func (d *decoder) decClassLiteral() *ClassLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ClassLiteral)
+	}
+	x := &ClassLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.string()
+	x.Parent = d.string()
+	if n47 := int(d.uint()); n47 != 0 {
+		x.Interfaces = make([]string, n47-1)
+		for i48 := range x.Interfaces {
+			x.Interfaces[i48] = d.string()
+		}
+	}
+	if n49 := int(d.uint()); n49 != 0 {
+		x.Members = make([]*LetStatement, n49-1)
+		for i50 := range x.Members {
+			x.Members[i50] = d.decLetStatement()
+		}
+	}
+	if n51 := int(d.uint()); n51 != 0 {
+		x.Properties = make(map[string]*PropertyDeclStmt, n51-1)
+		for i52 := 1; i52 < n51; i52++ {
+			var k53 string
+			k53 = d.string()
+			var v54 *PropertyDeclStmt
+			v54 = d.decPropertyDeclStmt()
+			x.Properties[k53] = v54
+		}
+	}
+	if n55 := int(d.uint()); n55 != 0 {
+		x.Methods = make(map[string]*FunctionStatement, n55-1)
+		for i56 := 1; i56 < n55; i56++ {
+			var k57 string
+			k57 = d.string()
+			var v58 *FunctionStatement
+			v58 = d.decFunctionStatement()
+			x.Methods[k57] = v58
+		}
+	}
+	x.Block = d.decBlockStatement()
+	x.Modifier = ModifierLevel(d.int())
+	return x
+}
+
+func (e *encoder) encClassStatement(x *ClassStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	e.encIdentifier(x.CategoryName)
+	e.encClassLiteral(x.ClassLiteral)
+	e.bool(x.IsAnnotation)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decClassStatement() *ClassStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ClassStatement)
+	}
+	x := &ClassStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	x.CategoryName = d.decIdentifier()
+	x.ClassLiteral = d.decClassLiteral()
+	x.IsAnnotation = d.bool()
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encCmdExpression(x *CmdExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+}
+
+func (d *decoder) decCmdExpression() *CmdExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CmdExpression)
+	}
+	x := &CmdExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	return x
+}
+
+func (e *encoder) encComment(x *Comment) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Text)
+}
+
+func (d *decoder) decComment() *Comment {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*Comment)
+	}
+	x := &Comment{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Text = d.string()
+	return x
+}
+
+func (e *encoder) encCommentGroup(x *CommentGroup) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	if x.List == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.List)) + 1)
+		for _, v59 := range x.List {
+			e.encComment(v59)
+		}
+	}
+}
+
+func (d *decoder) decCommentGroup() *CommentGroup {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*CommentGroup)
+	}
+	x := &CommentGroup{}
+	d.pointers = append(d.pointers, x)
+	if n60 := int(d.uint()); n60 != 0 {
+		x.List = make([]*Comment, n60-1)
+		for i61 := range x.List {
+			x.List[i61] = d.decComment()
+		}
+	}
+	return x
+}
+
+func (e *encoder) encConstStatement(x *ConstStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Name == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Name)) + 1)
+		for _, v62 := range x.Name {
+			e.encIdentifier(v62)
+		}
+	}
+	if x.Value == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Value)) + 1)
+		for _, v63 := range x.Value {
+			e.node(v63)
+		}
+	}
+	e.bool(x.StaticFlag)
+	e.int(int64(x.ModifierLevel))
+	if x.Annotations == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Annotations)) + 1)
+		for _, v64 := range x.Annotations {
+			e.encAnnotationStmt(v64)
+		}
+	}
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decConstStatement() *ConstStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ConstStatement)
+	}
+	x := &ConstStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n65 := int(d.uint()); n65 != 0 {
+		x.Name = make([]*Identifier, n65-1)
+		for i66 := range x.Name {
+			x.Name[i66] = d.decIdentifier()
+		}
+	}
+	if n67 := int(d.uint()); n67 != 0 {
+		x.Value = make([]Expression, n67-1)
+		for i68 := range x.Value {
+			if n69 := d.node(); n69 != nil {
+				x.Value[i68] = n69.(Expression)
+			}
+		}
+	}
+	x.StaticFlag = d.bool()
+	x.ModifierLevel = ModifierLevel(d.int())
+	if n70 := int(d.uint()); n70 != 0 {
+		x.Annotations = make([]*AnnotationStmt, n70-1)
+		for i71 := range x.Annotations {
+			x.Annotations[i71] = d.decAnnotationStmt()
+		}
+	}
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encContinueExpression(x *ContinueExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+}
+
+func (d *decoder) decContinueExpression() *ContinueExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ContinueExpression)
+	}
+	x := &ContinueExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	return x
+}
+
+func (e *encoder) encDateTimeExpr(x *DateTimeExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encInterpolatedString(x.Pattern)
+}
+
+func (d *decoder) decDateTimeExpr() *DateTimeExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*DateTimeExpr)
+	}
+	x := &DateTimeExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Pattern = d.decInterpolatedString()
+	return x
+}
+
+func (e *encoder) encDeferStmt(x *DeferStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Call)
+}
+
+func (d *decoder) decDeferStmt() *DeferStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*DeferStmt)
+	}
+	x := &DeferStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n72 := d.node(); n72 != nil {
+		x.Call = n72.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encDiamondExpr(x *DiamondExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+}
+
+func (d *decoder) decDiamondExpr() *DiamondExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*DiamondExpr)
+	}
+	x := &DiamondExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	return x
+}
+
+func (e *encoder) encDoLoop(x *DoLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decDoLoop() *DoLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*DoLoop)
+	}
+	x := &DoLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encEnumLiteral(x *EnumLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Pairs == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Pairs)) + 1)
+		for k73, v74 := range x.Pairs {
+			e.node(k73)
+			e.node(v74)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decEnumLiteral() *EnumLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*EnumLiteral)
+	}
+	x := &EnumLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n75 := int(d.uint()); n75 != 0 {
+		x.Pairs = make(map[Expression]Expression, n75-1)
+		for i76 := 1; i76 < n75; i76++ {
+			var k77 Expression
+			if n79 := d.node(); n79 != nil {
+				k77 = n79.(Expression)
+			}
+			var v78 Expression
+			if n80 := d.node(); n80 != nil {
+				v78 = n80.(Expression)
+			}
+			x.Pairs[k77] = v78
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encEnumStatement(x *EnumStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	e.encEnumLiteral(x.EnumLiteral)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decEnumStatement() *EnumStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*EnumStatement)
+	}
+	x := &EnumStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	x.EnumLiteral = d.decEnumLiteral()
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encExpressionStatement(x *ExpressionStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expression)
+}
+
+func (d *decoder) decExpressionStatement() *ExpressionStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ExpressionStatement)
+	}
+	x := &ExpressionStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n81 := d.node(); n81 != nil {
+		x.Expression = n81.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encFloatLiteral(x *FloatLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.float(x.Value)
+}
+
+func (d *decoder) decFloatLiteral() *FloatLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*FloatLiteral)
+	}
+	x := &FloatLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.float()
+	return x
+}
+
+func (e *encoder) encForEachArrayLoop(x *ForEachArrayLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Value)
+	e.node(x.Cond)
+	e.node(x.Block)
+}
+
+func (d *decoder) decForEachArrayLoop() *ForEachArrayLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ForEachArrayLoop)
+	}
+	x := &ForEachArrayLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n82 := d.node(); n82 != nil {
+		x.Value = n82.(Expression)
+	}
+	if n83 := d.node(); n83 != nil {
+		x.Cond = n83.(Expression)
+	}
+	if n84 := d.node(); n84 != nil {
+		x.Block = n84.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encForEachDotRange(x *ForEachDotRange) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.StartIdx)
+	e.node(x.EndIdx)
+	e.node(x.Cond)
+	e.node(x.Block)
+}
+
+func (d *decoder) decForEachDotRange() *ForEachDotRange {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ForEachDotRange)
+	}
+	x := &ForEachDotRange{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n85 := d.node(); n85 != nil {
+		x.StartIdx = n85.(Expression)
+	}
+	if n86 := d.node(); n86 != nil {
+		x.EndIdx = n86.(Expression)
+	}
+	if n87 := d.node(); n87 != nil {
+		x.Cond = n87.(Expression)
+	}
+	if n88 := d.node(); n88 != nil {
+		x.Block = n88.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encForEachMapLoop(x *ForEachMapLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Key)
+	e.string(x.Value)
+	e.node(x.X)
+	e.node(x.Cond)
+	e.node(x.Block)
+}
+
+func (d *decoder) decForEachMapLoop() *ForEachMapLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ForEachMapLoop)
+	}
+	x := &ForEachMapLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Key = d.string()
+	x.Value = d.string()
+	if n89 := d.node(); n89 != nil {
+		x.X = n89.(Expression)
+	}
+	if n90 := d.node(); n90 != nil {
+		x.Cond = n90.(Expression)
+	}
+	if n91 := d.node(); n91 != nil {
+		x.Block = n91.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encForEverLoop(x *ForEverLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decForEverLoop() *ForEverLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ForEverLoop)
+	}
+	x := &ForEverLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encForLoop(x *ForLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Init)
+	e.node(x.Cond)
+	e.node(x.Update)
+	e.node(x.Block)
+}
+
+func (d *decoder) decForLoop() *ForLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ForLoop)
+	}
+	x := &ForLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n92 := d.node(); n92 != nil {
+		x.Init = n92.(Expression)
+	}
+	if n93 := d.node(); n93 != nil {
+		x.Cond = n93.(Expression)
+	}
+	if n94 := d.node(); n94 != nil {
+		x.Update = n94.(Expression)
+	}
+	if n95 := d.node(); n95 != nil {
+		x.Block = n95.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encFromExpr(x *FromExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decFromExpr() *FromExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*FromExpr)
+	}
+	x := &FromExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n96 := d.node(); n96 != nil {
+		x.Expr = n96.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encFunctionLiteral(x *FunctionLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Parameters == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Parameters)) + 1)
+		for _, v97 := range x.Parameters {
+			e.node(v97)
+		}
+	}
+	e.encBlockStatement(x.Body)
+	if x.Values == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Values)) + 1)
+		for k98, v99 := range x.Values {
+			e.string(k98)
+			e.node(v99)
+		}
+	}
+	if x.ParamTypes == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.ParamTypes)) + 1)
+		for k100, v101 := range x.ParamTypes {
+			e.string(k100)
+			e.encTypeExpr(v101)
+		}
+	}
+	e.encTypeExpr(x.ReturnType)
+	e.bool(x.Variadic)
+	e.bool(x.StaticFlag)
+	e.int(int64(x.ModifierLevel))
+	e.bool(x.Async)
+}
+
+func (d *decoder) decFunctionLiteral() *FunctionLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*FunctionLiteral)
+	}
+	x := &FunctionLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n102 := int(d.uint()); n102 != 0 {
+		x.Parameters = make([]Expression, n102-1)
+		for i103 := range x.Parameters {
+			if n104 := d.node(); n104 != nil {
+				x.Parameters[i103] = n104.(Expression)
+			}
+		}
+	}
+	x.Body = d.decBlockStatement()
+	if n105 := int(d.uint()); n105 != 0 {
+		x.Values = make(map[string]Expression, n105-1)
+		for i106 := 1; i106 < n105; i106++ {
+			var k107 string
+			k107 = d.string()
+			var v108 Expression
+			if n109 := d.node(); n109 != nil {
+				v108 = n109.(Expression)
+			}
+			x.Values[k107] = v108
+		}
+	}
+	if n110 := int(d.uint()); n110 != 0 {
+		x.ParamTypes = make(map[string]*TypeExpr, n110-1)
+		for i111 := 1; i111 < n110; i111++ {
+			var k112 string
+			k112 = d.string()
+			var v113 *TypeExpr
+			v113 = d.decTypeExpr()
+			x.ParamTypes[k112] = v113
+		}
+	}
+	x.ReturnType = d.decTypeExpr()
+	x.Variadic = d.bool()
+	x.StaticFlag = d.bool()
+	x.ModifierLevel = ModifierLevel(d.int())
+	x.Async = d.bool()
+	return x
+}
+
+func (e *encoder) encFunctionStatement(x *FunctionStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	e.encFunctionLiteral(x.FunctionLiteral)
+	if x.Annotations == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Annotations)) + 1)
+		for _, v114 := range x.Annotations {
+			e.encAnnotationStmt(v114)
+		}
+	}
+	e.bool(x.IsServiceAnno)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decFunctionStatement() *FunctionStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*FunctionStatement)
+	}
+	x := &FunctionStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	x.FunctionLiteral = d.decFunctionLiteral()
+	if n115 := int(d.uint()); n115 != 0 {
+		x.Annotations = make([]*AnnotationStmt, n115-1)
+		for i116 := range x.Annotations {
+			x.Annotations[i116] = d.decAnnotationStmt()
+		}
+	}
+	x.IsServiceAnno = d.bool()
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encGetterStmt(x *GetterStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Body)
+}
+
+func (d *decoder) decGetterStmt() *GetterStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*GetterStmt)
+	}
+	x := &GetterStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Body = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encGrepExpr(x *GrepExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Value)
+	e.encBlockStatement(x.Block)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decGrepExpr() *GrepExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*GrepExpr)
+	}
+	x := &GrepExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n117 := d.node(); n117 != nil {
+		x.Value = n117.(Expression)
+	}
+	x.Block = d.decBlockStatement()
+	if n118 := d.node(); n118 != nil {
+		x.Expr = n118.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encGroupExpr(x *GroupExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.GrpExpr)
+	e.node(x.ByExpr)
+}
+
+func (d *decoder) decGroupExpr() *GroupExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*GroupExpr)
+	}
+	x := &GroupExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n119 := d.node(); n119 != nil {
+		x.GrpExpr = n119.(Expression)
+	}
+	if n120 := d.node(); n120 != nil {
+		x.ByExpr = n120.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encHashComprehension(x *HashComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Value)
+	e.node(x.Cond)
+	e.node(x.KeyExpr)
+	e.node(x.ValExpr)
+}
+
+func (d *decoder) decHashComprehension() *HashComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*HashComprehension)
+	}
+	x := &HashComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n121 := d.node(); n121 != nil {
+		x.Value = n121.(Expression)
+	}
+	if n122 := d.node(); n122 != nil {
+		x.Cond = n122.(Expression)
+	}
+	if n123 := d.node(); n123 != nil {
+		x.KeyExpr = n123.(Expression)
+	}
+	if n124 := d.node(); n124 != nil {
+		x.ValExpr = n124.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encHashLiteral(x *HashLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Order == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Order)) + 1)
+		for _, v125 := range x.Order {
+			e.node(v125)
+		}
+	}
+	if x.Pairs == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Pairs)) + 1)
+		for k126, v127 := range x.Pairs {
+			e.node(k126)
+			e.node(v127)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decHashLiteral() *HashLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*HashLiteral)
+	}
+	x := &HashLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n128 := int(d.uint()); n128 != 0 {
+		x.Order = make([]Expression, n128-1)
+		for i129 := range x.Order {
+			if n130 := d.node(); n130 != nil {
+				x.Order[i129] = n130.(Expression)
+			}
+		}
+	}
+	if n131 := int(d.uint()); n131 != 0 {
+		x.Pairs = make(map[Expression]Expression, n131-1)
+		for i132 := 1; i132 < n131; i132++ {
+			var k133 Expression
+			if n135 := d.node(); n135 != nil {
+				k133 = n135.(Expression)
+			}
+			var v134 Expression
+			if n136 := d.node(); n136 != nil {
+				v134 = n136.(Expression)
+			}
+			x.Pairs[k133] = v134
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encHashMapComprehension(x *HashMapComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Key)
+	e.string(x.Value)
+	e.node(x.X)
+	e.node(x.Cond)
+	e.node(x.KeyExpr)
+	e.node(x.ValExpr)
+}
+
+func (d *decoder) decHashMapComprehension() *HashMapComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*HashMapComprehension)
+	}
+	x := &HashMapComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Key = d.string()
+	x.Value = d.string()
+	if n137 := d.node(); n137 != nil {
+		x.X = n137.(Expression)
+	}
+	if n138 := d.node(); n138 != nil {
+		x.Cond = n138.(Expression)
+	}
+	if n139 := d.node(); n139 != nil {
+		x.KeyExpr = n139.(Expression)
+	}
+	if n140 := d.node(); n140 != nil {
+		x.ValExpr = n140.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encHashPattern(x *HashPattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Keys == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Keys)) + 1)
+		for _, v141 := range x.Keys {
+			e.node(v141)
+		}
+	}
+	if x.Values == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Values)) + 1)
+		for _, v142 := range x.Values {
+			e.node(v142)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decHashPattern() *HashPattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*HashPattern)
+	}
+	x := &HashPattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n143 := int(d.uint()); n143 != 0 {
+		x.Keys = make([]Expression, n143-1)
+		for i144 := range x.Keys {
+			if n145 := d.node(); n145 != nil {
+				x.Keys[i144] = n145.(Expression)
+			}
+		}
+	}
+	if n146 := int(d.uint()); n146 != 0 {
+		x.Values = make([]Expression, n146-1)
+		for i147 := range x.Values {
+			if n148 := d.node(); n148 != nil {
+				x.Values[i147] = n148.(Expression)
+			}
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encHashRangeComprehension(x *HashRangeComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.StartIdx)
+	e.node(x.EndIdx)
+	e.node(x.Cond)
+	e.node(x.KeyExpr)
+	e.node(x.ValExpr)
+}
+
+func (d *decoder) decHashRangeComprehension() *HashRangeComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*HashRangeComprehension)
+	}
+	x := &HashRangeComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n149 := d.node(); n149 != nil {
+		x.StartIdx = n149.(Expression)
+	}
+	if n150 := d.node(); n150 != nil {
+		x.EndIdx = n150.(Expression)
+	}
+	if n151 := d.node(); n151 != nil {
+		x.Cond = n151.(Expression)
+	}
+	if n152 := d.node(); n152 != nil {
+		x.KeyExpr = n152.(Expression)
+	}
+	if n153 := d.node(); n153 != nil {
+		x.ValExpr = n153.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encIdentPattern(x *IdentPattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+}
+
+func (d *decoder) decIdentPattern() *IdentPattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IdentPattern)
+	}
+	x := &IdentPattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	return x
+}
+
+func (e *encoder) encIdentifier(x *Identifier) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+}
+
+func (d *decoder) decIdentifier() *Identifier {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*Identifier)
+	}
+	x := &Identifier{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	return x
+}
+
+func (e *encoder) encIfConditionExpr(x *IfConditionExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Cond)
+	e.node(x.Body)
+}
+
+func (d *decoder) decIfConditionExpr() *IfConditionExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IfConditionExpr)
+	}
+	x := &IfConditionExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n154 := d.node(); n154 != nil {
+		x.Cond = n154.(Expression)
+	}
+	if n155 := d.node(); n155 != nil {
+		x.Body = n155.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encIfExpression(x *IfExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Conditions == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Conditions)) + 1)
+		for _, v156 := range x.Conditions {
+			e.encIfConditionExpr(v156)
+		}
+	}
+	e.node(x.Alternative)
+}
+
+func (d *decoder) decIfExpression() *IfExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IfExpression)
+	}
+	x := &IfExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n157 := int(d.uint()); n157 != 0 {
+		x.Conditions = make([]*IfConditionExpr, n157-1)
+		for i158 := range x.Conditions {
+			x.Conditions[i158] = d.decIfConditionExpr()
+		}
+	}
+	if n159 := d.node(); n159 != nil {
+		x.Alternative = n159.(Node)
+	}
+	return x
+}
+
+func (e *encoder) encIfMacroStatement(x *IfMacroStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.bool(x.Condition)
+	e.string(x.ConditionStr)
+	e.encBlockStatement(x.Consequence)
+	e.encBlockStatement(x.Alternative)
+}
+
+func (d *decoder) decIfMacroStatement() *IfMacroStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IfMacroStatement)
+	}
+	x := &IfMacroStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Condition = d.bool()
+	x.ConditionStr = d.string()
+	x.Consequence = d.decBlockStatement()
+	x.Alternative = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encImportStatement(x *ImportStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.ImportPath)
+	e.string(x.Path)
+	e.string(x.File)
+	e.encIdentifier(x.Alias)
+	if x.Names == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Names)) + 1)
+		for _, v160 := range x.Names {
+			e.encIdentifier(v160)
+		}
+	}
+	e.token(&x.EndToken)
+	e.encProgram(x.Program)
+	if x.Functions == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Functions)) + 1)
+		for k161, v162 := range x.Functions {
+			e.string(k161)
+			e.encFunctionLiteral(v162)
+		}
+	}
+}
+
+func (d *decoder) decImportStatement() *ImportStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ImportStatement)
+	}
+	x := &ImportStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.ImportPath = d.string()
+	x.Path = d.string()
+	x.File = d.string()
+	x.Alias = d.decIdentifier()
+	if n163 := int(d.uint()); n163 != 0 {
+		x.Names = make([]*Identifier, n163-1)
+		for i164 := range x.Names {
+			x.Names[i164] = d.decIdentifier()
+		}
+	}
+	d.token(&x.EndToken)
+	x.Program = d.decProgram()
+	if n165 := int(d.uint()); n165 != 0 {
+		x.Functions = make(map[string]*FunctionLiteral, n165-1)
+		for i166 := 1; i166 < n165; i166++ {
+			var k167 string
+			k167 = d.string()
+			var v168 *FunctionLiteral
+			v168 = d.decFunctionLiteral()
+			x.Functions[k167] = v168
+		}
+	}
+	return x
+}
+
+func (e *encoder) encIndexExpression(x *IndexExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Left)
+	e.node(x.Index)
+}
+
+func (d *decoder) decIndexExpression() *IndexExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IndexExpression)
+	}
+	x := &IndexExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n169 := d.node(); n169 != nil {
+		x.Left = n169.(Expression)
+	}
+	if n170 := d.node(); n170 != nil {
+		x.Index = n170.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encInfixExpression(x *InfixExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Operator)
+	e.node(x.Right)
+	e.node(x.Left)
+}
+
+func (d *decoder) decInfixExpression() *InfixExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*InfixExpression)
+	}
+	x := &InfixExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Operator = d.string()
+	if n171 := d.node(); n171 != nil {
+		x.Right = n171.(Expression)
+	}
+	if n172 := d.node(); n172 != nil {
+		x.Left = n172.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encIntegerLiteral(x *IntegerLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.int(int64(x.Value))
+}
+
+func (d *decoder) decIntegerLiteral() *IntegerLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*IntegerLiteral)
+	}
+	x := &IntegerLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = int64(d.int())
+	return x
+}
+
+func (e *encoder) encInterfaceMethod(x *InterfaceMethod) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	if x.Parameters == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Parameters)) + 1)
+		for _, v173 := range x.Parameters {
+			e.node(v173)
+		}
+	}
+	if x.ParamTypes == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.ParamTypes)) + 1)
+		for k174, v175 := range x.ParamTypes {
+			e.string(k174)
+			e.encTypeExpr(v175)
+		}
+	}
+	e.encTypeExpr(x.ReturnType)
+}
+
+func (d *decoder) decInterfaceMethod() *InterfaceMethod {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*InterfaceMethod)
+	}
+	x := &InterfaceMethod{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	if n176 := int(d.uint()); n176 != 0 {
+		x.Parameters = make([]Expression, n176-1)
+		for i177 := range x.Parameters {
+			if n178 := d.node(); n178 != nil {
+				x.Parameters[i177] = n178.(Expression)
+			}
+		}
+	}
+	if n179 := int(d.uint()); n179 != 0 {
+		x.ParamTypes = make(map[string]*TypeExpr, n179-1)
+		for i180 := 1; i180 < n179; i180++ {
+			var k181 string
+			k181 = d.string()
+			var v182 *TypeExpr
+			v182 = d.decTypeExpr()
+			x.ParamTypes[k181] = v182
+		}
+	}
+	x.ReturnType = d.decTypeExpr()
+	return x
+}
+
+func (e *encoder) encInterfaceStatement(x *InterfaceStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	if x.Parents == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Parents)) + 1)
+		for _, v183 := range x.Parents {
+			e.encIdentifier(v183)
+		}
+	}
+	if x.Methods == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Methods)) + 1)
+		for _, v184 := range x.Methods {
+			e.encInterfaceMethod(v184)
+		}
+	}
+	if x.Properties == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Properties)) + 1)
+		for _, v185 := range x.Properties {
+			e.encIdentifier(v185)
+		}
+	}
+	e.token(&x.RBraceToken)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decInterfaceStatement() *InterfaceStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*InterfaceStatement)
+	}
+	x := &InterfaceStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	if n186 := int(d.uint()); n186 != 0 {
+		x.Parents = make([]*Identifier, n186-1)
+		for i187 := range x.Parents {
+			x.Parents[i187] = d.decIdentifier()
+		}
+	}
+	if n188 := int(d.uint()); n188 != 0 {
+		x.Methods = make([]*InterfaceMethod, n188-1)
+		for i189 := range x.Methods {
+			x.Methods[i189] = d.decInterfaceMethod()
+		}
+	}
+	if n190 := int(d.uint()); n190 != 0 {
+		x.Properties = make([]*Identifier, n190-1)
+		for i191 := range x.Properties {
+			x.Properties[i191] = d.decIdentifier()
+		}
+	}
+	d.token(&x.RBraceToken)
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encInterpolatedString(x *InterpolatedString) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+	if x.ExprMap == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.ExprMap)) + 1)
+		for k192, v193 := range x.ExprMap {
+			e.uint(uint64(k192))
+			e.node(v193)
+		}
+	}
+}
+
+func (d *decoder) decInterpolatedString() *InterpolatedString {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*InterpolatedString)
+	}
+	x := &InterpolatedString{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	if n194 := int(d.uint()); n194 != 0 {
+		x.ExprMap = make(map[byte]Expression, n194-1)
+		for i195 := 1; i195 < n194; i195++ {
+			var k196 byte
+			k196 = byte(d.uint())
+			var v197 Expression
+			if n198 := d.node(); n198 != nil {
+				v197 = n198.(Expression)
+			}
+			x.ExprMap[k196] = v197
+		}
+	}
+	return x
+}
+
+func (e *encoder) encJoinExpr(x *JoinExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.JoinVar)
+	e.node(x.InExpr)
+	e.node(x.OnExpr)
+	e.node(x.EqualExpr)
+	e.encIdentifier(x.IntoVar)
+}
+
+func (d *decoder) decJoinExpr() *JoinExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*JoinExpr)
+	}
+	x := &JoinExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.JoinVar = d.string()
+	if n199 := d.node(); n199 != nil {
+		x.InExpr = n199.(Expression)
+	}
+	if n200 := d.node(); n200 != nil {
+		x.OnExpr = n200.(Expression)
+	}
+	if n201 := d.node(); n201 != nil {
+		x.EqualExpr = n201.(Expression)
+	}
+	x.IntoVar = d.decIdentifier()
+	return x
+}
+
+func (e *encoder) encLetStatement(x *LetStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Names == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Names)) + 1)
+		for _, v202 := range x.Names {
+			e.encIdentifier(v202)
+		}
+	}
+	if x.Values == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Values)) + 1)
+		for _, v203 := range x.Values {
+			e.node(v203)
+		}
+	}
+	if x.Types == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Types)) + 1)
+		for k204, v205 := range x.Types {
+			e.string(k204)
+			e.encTypeExpr(v205)
+		}
+	}
+	e.bool(x.StaticFlag)
+	e.int(int64(x.ModifierLevel))
+	if x.Annotations == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Annotations)) + 1)
+		for _, v206 := range x.Annotations {
+			e.encAnnotationStmt(v206)
+		}
+	}
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+	e.bool(x.DestructingFlag)
+	e.bool(x.InClass)
+}
+
+func (d *decoder) decLetStatement() *LetStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*LetStatement)
+	}
+	x := &LetStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n207 := int(d.uint()); n207 != 0 {
+		x.Names = make([]*Identifier, n207-1)
+		for i208 := range x.Names {
+			x.Names[i208] = d.decIdentifier()
+		}
+	}
+	if n209 := int(d.uint()); n209 != 0 {
+		x.Values = make([]Expression, n209-1)
+		for i210 := range x.Values {
+			if n211 := d.node(); n211 != nil {
+				x.Values[i210] = n211.(Expression)
+			}
+		}
+	}
+	if n212 := int(d.uint()); n212 != 0 {
+		x.Types = make(map[string]*TypeExpr, n212-1)
+		for i213 := 1; i213 < n212; i213++ {
+			var k214 string
+			k214 = d.string()
+			var v215 *TypeExpr
+			v215 = d.decTypeExpr()
+			x.Types[k214] = v215
+		}
+	}
+	x.StaticFlag = d.bool()
+	x.ModifierLevel = ModifierLevel(d.int())
+	if n216 := int(d.uint()); n216 != 0 {
+		x.Annotations = make([]*AnnotationStmt, n216-1)
+		for i217 := range x.Annotations {
+			x.Annotations[i217] = d.decAnnotationStmt()
+		}
+	}
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	x.DestructingFlag = d.bool()
+	x.InClass = d.bool()
+	return x
+}
+
+func (e *encoder) encListComprehension(x *ListComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Value)
+	e.node(x.Cond)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decListComprehension() *ListComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ListComprehension)
+	}
+	x := &ListComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n218 := d.node(); n218 != nil {
+		x.Value = n218.(Expression)
+	}
+	if n219 := d.node(); n219 != nil {
+		x.Cond = n219.(Expression)
+	}
+	if n220 := d.node(); n220 != nil {
+		x.Expr = n220.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encListMapComprehension(x *ListMapComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Key)
+	e.string(x.Value)
+	e.node(x.X)
+	e.node(x.Cond)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decListMapComprehension() *ListMapComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ListMapComprehension)
+	}
+	x := &ListMapComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Key = d.string()
+	x.Value = d.string()
+	if n221 := d.node(); n221 != nil {
+		x.X = n221.(Expression)
+	}
+	if n222 := d.node(); n222 != nil {
+		x.Cond = n222.(Expression)
+	}
+	if n223 := d.node(); n223 != nil {
+		x.Expr = n223.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encListRangeComprehension(x *ListRangeComprehension) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.StartIdx)
+	e.node(x.EndIdx)
+	e.node(x.Cond)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decListRangeComprehension() *ListRangeComprehension {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ListRangeComprehension)
+	}
+	x := &ListRangeComprehension{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n224 := d.node(); n224 != nil {
+		x.StartIdx = n224.(Expression)
+	}
+	if n225 := d.node(); n225 != nil {
+		x.EndIdx = n225.(Expression)
+	}
+	if n226 := d.node(); n226 != nil {
+		x.Cond = n226.(Expression)
+	}
+	if n227 := d.node(); n227 != nil {
+		x.Expr = n227.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encMapExpr(x *MapExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Value)
+	e.encBlockStatement(x.Block)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decMapExpr() *MapExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*MapExpr)
+	}
+	x := &MapExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n228 := d.node(); n228 != nil {
+		x.Value = n228.(Expression)
+	}
+	x.Block = d.decBlockStatement()
+	if n229 := d.node(); n229 != nil {
+		x.Expr = n229.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encMatchArm(x *MatchArm) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Patterns == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Patterns)) + 1)
+		for _, v230 := range x.Patterns {
+			e.node(v230)
+		}
+	}
+	e.node(x.Guard)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decMatchArm() *MatchArm {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*MatchArm)
+	}
+	x := &MatchArm{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n231 := int(d.uint()); n231 != 0 {
+		x.Patterns = make([]Expression, n231-1)
+		for i232 := range x.Patterns {
+			if n233 := d.node(); n233 != nil {
+				x.Patterns[i232] = n233.(Expression)
+			}
+		}
+	}
+	if n234 := d.node(); n234 != nil {
+		x.Guard = n234.(Expression)
+	}
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encMatchExpr(x *MatchExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expr)
+	if x.Arms == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Arms)) + 1)
+		for _, v235 := range x.Arms {
+			e.encMatchArm(v235)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decMatchExpr() *MatchExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*MatchExpr)
+	}
+	x := &MatchExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n236 := d.node(); n236 != nil {
+		x.Expr = n236.(Expression)
+	}
+	if n237 := int(d.uint()); n237 != 0 {
+		x.Arms = make([]*MatchArm, n237-1)
+		for i238 := range x.Arms {
+			x.Arms[i238] = d.decMatchArm()
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encMethodCallExpression(x *MethodCallExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Object)
+	e.node(x.Call)
+}
+
+func (d *decoder) decMethodCallExpression() *MethodCallExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*MethodCallExpression)
+	}
+	x := &MethodCallExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n239 := d.node(); n239 != nil {
+		x.Object = n239.(Expression)
+	}
+	if n240 := d.node(); n240 != nil {
+		x.Call = n240.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encNewExpression(x *NewExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Class)
+	if x.Arguments == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Arguments)) + 1)
+		for _, v241 := range x.Arguments {
+			e.node(v241)
+		}
+	}
+}
+
+func (d *decoder) decNewExpression() *NewExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*NewExpression)
+	}
+	x := &NewExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n242 := d.node(); n242 != nil {
+		x.Class = n242.(Expression)
+	}
+	if n243 := int(d.uint()); n243 != 0 {
+		x.Arguments = make([]Expression, n243-1)
+		for i244 := range x.Arguments {
+			if n245 := d.node(); n245 != nil {
+				x.Arguments[i244] = n245.(Expression)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encNilLiteral(x *NilLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+}
+
+func (d *decoder) decNilLiteral() *NilLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*NilLiteral)
+	}
+	x := &NilLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	return x
+}
+
+func (e *encoder) encOrderExpr(x *OrderExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Ordering == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Ordering)) + 1)
+		for _, v246 := range x.Ordering {
+			e.node(v246)
+		}
+	}
+}
+
+func (d *decoder) decOrderExpr() *OrderExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*OrderExpr)
+	}
+	x := &OrderExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n247 := int(d.uint()); n247 != 0 {
+		x.Ordering = make([]Expression, n247-1)
+		for i248 := range x.Ordering {
+			if n249 := d.node(); n249 != nil {
+				x.Ordering[i248] = n249.(Expression)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encOrderingExpr(x *OrderingExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.node(x.Expr)
+	e.bool(x.IsAscending)
+	e.bool(x.HasSortOrder)
+	e.token(&x.OrderToken)
+	e.string(x.Var)
+}
+
+func (d *decoder) decOrderingExpr() *OrderingExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*OrderingExpr)
+	}
+	x := &OrderingExpr{}
+	d.pointers = append(d.pointers, x)
+	if n250 := d.node(); n250 != nil {
+		x.Expr = n250.(Expression)
+	}
+	x.IsAscending = d.bool()
+	x.HasSortOrder = d.bool()
+	d.token(&x.OrderToken)
+	x.Var = d.string()
+	return x
+}
+
+func (e *encoder) encPipe(x *Pipe) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Left)
+	e.node(x.Right)
+}
+
+func (d *decoder) decPipe() *Pipe {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*Pipe)
+	}
+	x := &Pipe{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n251 := d.node(); n251 != nil {
+		x.Left = n251.(Expression)
+	}
+	if n252 := d.node(); n252 != nil {
+		x.Right = n252.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encPostfixExpression(x *PostfixExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Left)
+	e.string(x.Operator)
+}
+
+func (d *decoder) decPostfixExpression() *PostfixExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*PostfixExpression)
+	}
+	x := &PostfixExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n253 := d.node(); n253 != nil {
+		x.Left = n253.(Expression)
+	}
+	x.Operator = d.string()
+	return x
+}
+
+func (e *encoder) encPrefixExpression(x *PrefixExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Operator)
+	e.node(x.Right)
+}
+
+func (d *decoder) decPrefixExpression() *PrefixExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*PrefixExpression)
+	}
+	x := &PrefixExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Operator = d.string()
+	if n254 := d.node(); n254 != nil {
+		x.Right = n254.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encProgram(x *Program) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	if x.Statements == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Statements)) + 1)
+		for _, v255 := range x.Statements {
+			e.node(v255)
+		}
+	}
+	if x.Imports == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Imports)) + 1)
+		for k256, v257 := range x.Imports {
+			e.string(k256)
+			e.encImportStatement(v257)
+		}
+	}
+	if x.Exports == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Exports)) + 1)
+		for _, v258 := range x.Exports {
+			e.node(v258)
+		}
+	}
+}
+
+func (d *decoder) decProgram() *Program {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*Program)
+	}
+	x := &Program{}
+	d.pointers = append(d.pointers, x)
+	if n259 := int(d.uint()); n259 != 0 {
+		x.Statements = make([]Statement, n259-1)
+		for i260 := range x.Statements {
+			if n261 := d.node(); n261 != nil {
+				x.Statements[i260] = n261.(Statement)
+			}
+		}
+	}
+	if n262 := int(d.uint()); n262 != 0 {
+		x.Imports = make(map[string]*ImportStatement, n262-1)
+		for i263 := 1; i263 < n262; i263++ {
+			var k264 string
+			k264 = d.string()
+			var v265 *ImportStatement
+			v265 = d.decImportStatement()
+			x.Imports[k264] = v265
+		}
+	}
+	if n266 := int(d.uint()); n266 != 0 {
+		x.Exports = make([]Statement, n266-1)
+		for i267 := range x.Exports {
+			if n268 := d.node(); n268 != nil {
+				x.Exports[i267] = n268.(Statement)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encPropertyDeclStmt(x *PropertyDeclStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	e.encGetterStmt(x.Getter)
+	e.encSetterStmt(x.Setter)
+	if x.Indexes == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Indexes)) + 1)
+		for _, v269 := range x.Indexes {
+			e.encIdentifier(v269)
+		}
+	}
+	e.encTypeExpr(x.Type)
+	e.bool(x.StaticFlag)
+	e.int(int64(x.ModifierLevel))
+	if x.Annotations == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Annotations)) + 1)
+		for _, v270 := range x.Annotations {
+			e.encAnnotationStmt(v270)
+		}
+	}
+	e.node(x.Default)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decPropertyDeclStmt() *PropertyDeclStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*PropertyDeclStmt)
+	}
+	x := &PropertyDeclStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	x.Getter = d.decGetterStmt()
+	x.Setter = d.decSetterStmt()
+	if n271 := int(d.uint()); n271 != 0 {
+		x.Indexes = make([]*Identifier, n271-1)
+		for i272 := range x.Indexes {
+			x.Indexes[i272] = d.decIdentifier()
+		}
+	}
+	x.Type = d.decTypeExpr()
+	x.StaticFlag = d.bool()
+	x.ModifierLevel = ModifierLevel(d.int())
+	if n273 := int(d.uint()); n273 != 0 {
+		x.Annotations = make([]*AnnotationStmt, n273-1)
+		for i274 := range x.Annotations {
+			x.Annotations[i274] = d.decAnnotationStmt()
+		}
+	}
+	if n275 := d.node(); n275 != nil {
+		x.Default = n275.(Expression)
+	}
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encQueryBodyClauseExpr(x *QueryBodyClauseExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.node(x.Expr)
+}
+
+func (d *decoder) decQueryBodyClauseExpr() *QueryBodyClauseExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*QueryBodyClauseExpr)
+	}
+	x := &QueryBodyClauseExpr{}
+	d.pointers = append(d.pointers, x)
+	if n276 := d.node(); n276 != nil {
+		x.Expr = n276.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encQueryBodyExpr(x *QueryBodyExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	if x.QueryBody == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.QueryBody)) + 1)
+		for _, v277 := range x.QueryBody {
+			e.node(v277)
+		}
+	}
+	e.node(x.Expr)
+	e.node(x.QueryContinuation)
+}
+
+func (d *decoder) decQueryBodyExpr() *QueryBodyExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*QueryBodyExpr)
+	}
+	x := &QueryBodyExpr{}
+	d.pointers = append(d.pointers, x)
+	if n278 := int(d.uint()); n278 != 0 {
+		x.QueryBody = make([]Expression, n278-1)
+		for i279 := range x.QueryBody {
+			if n280 := d.node(); n280 != nil {
+				x.QueryBody[i279] = n280.(Expression)
+			}
+		}
+	}
+	if n281 := d.node(); n281 != nil {
+		x.Expr = n281.(Expression)
+	}
+	if n282 := d.node(); n282 != nil {
+		x.QueryContinuation = n282.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encQueryContinuationExpr(x *QueryContinuationExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Var)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decQueryContinuationExpr() *QueryContinuationExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*QueryContinuationExpr)
+	}
+	x := &QueryContinuationExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Var = d.string()
+	if n283 := d.node(); n283 != nil {
+		x.Expr = n283.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encQueryExpr(x *QueryExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.From)
+	e.node(x.QueryBody)
+}
+
+func (d *decoder) decQueryExpr() *QueryExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*QueryExpr)
+	}
+	x := &QueryExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n284 := d.node(); n284 != nil {
+		x.From = n284.(Expression)
+	}
+	if n285 := d.node(); n285 != nil {
+		x.QueryBody = n285.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encRangeLiteral(x *RangeLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.StartIdx)
+	e.node(x.EndIdx)
+}
+
+func (d *decoder) decRangeLiteral() *RangeLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*RangeLiteral)
+	}
+	x := &RangeLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n286 := d.node(); n286 != nil {
+		x.StartIdx = n286.(Expression)
+	}
+	if n287 := d.node(); n287 != nil {
+		x.EndIdx = n287.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encRegExLiteral(x *RegExLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+}
+
+func (d *decoder) decRegExLiteral() *RegExLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*RegExLiteral)
+	}
+	x := &RegExLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	return x
+}
+
+func (e *encoder) encReturnStatement(x *ReturnStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.ReturnValue)
+	if x.ReturnValues == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.ReturnValues)) + 1)
+		for _, v288 := range x.ReturnValues {
+			e.node(v288)
+		}
+	}
+}
+
+func (d *decoder) decReturnStatement() *ReturnStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ReturnStatement)
+	}
+	x := &ReturnStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n289 := d.node(); n289 != nil {
+		x.ReturnValue = n289.(Expression)
+	}
+	if n290 := int(d.uint()); n290 != 0 {
+		x.ReturnValues = make([]Expression, n290-1)
+		for i291 := range x.ReturnValues {
+			if n292 := d.node(); n292 != nil {
+				x.ReturnValues[i291] = n292.(Expression)
+			}
+		}
+	}
+	return x
+}
+
+func (e *encoder) encSelectExpr(x *SelectExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decSelectExpr() *SelectExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*SelectExpr)
+	}
+	x := &SelectExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n293 := d.node(); n293 != nil {
+		x.Expr = n293.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encServiceStatement(x *ServiceStatement) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encIdentifier(x.Name)
+	e.string(x.Addr)
+	e.bool(x.Debug)
+	if x.Methods == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Methods)) + 1)
+		for k294, v295 := range x.Methods {
+			e.string(k294)
+			e.encFunctionStatement(v295)
+		}
+	}
+	e.encBlockStatement(x.Block)
+	e.encCommentGroup(x.Doc)
+	e.token(&x.SrcEndToken)
+}
+
+func (d *decoder) decServiceStatement() *ServiceStatement {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ServiceStatement)
+	}
+	x := &ServiceStatement{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.decIdentifier()
+	x.Addr = d.string()
+	x.Debug = d.bool()
+	if n296 := int(d.uint()); n296 != 0 {
+		x.Methods = make(map[string]*FunctionStatement, n296-1)
+		for i297 := 1; i297 < n296; i297++ {
+			var k298 string
+			k298 = d.string()
+			var v299 *FunctionStatement
+			v299 = d.decFunctionStatement()
+			x.Methods[k298] = v299
+		}
+	}
+	x.Block = d.decBlockStatement()
+	x.Doc = d.decCommentGroup()
+	d.token(&x.SrcEndToken)
+	return x
+}
+
+func (e *encoder) encSetterStmt(x *SetterStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Body)
+}
+
+func (d *decoder) decSetterStmt() *SetterStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*SetterStmt)
+	}
+	x := &SetterStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Body = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encSliceExpression(x *SliceExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.StartIndex)
+	e.node(x.EndIndex)
+}
+
+func (d *decoder) decSliceExpression() *SliceExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*SliceExpression)
+	}
+	x := &SliceExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n300 := d.node(); n300 != nil {
+		x.StartIndex = n300.(Expression)
+	}
+	if n301 := d.node(); n301 != nil {
+		x.EndIndex = n301.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encSpawnStmt(x *SpawnStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Call)
+}
+
+func (d *decoder) decSpawnStmt() *SpawnStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*SpawnStmt)
+	}
+	x := &SpawnStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n302 := d.node(); n302 != nil {
+		x.Call = n302.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encStringLiteral(x *StringLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Value)
+}
+
+func (d *decoder) decStringLiteral() *StringLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*StringLiteral)
+	}
+	x := &StringLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = d.string()
+	return x
+}
+
+func (e *encoder) encStructLiteral(x *StructLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Pairs == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Pairs)) + 1)
+		for k303, v304 := range x.Pairs {
+			e.node(k303)
+			e.node(v304)
+		}
+	}
+	e.token(&x.RBraceToken)
+}
+
+func (d *decoder) decStructLiteral() *StructLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*StructLiteral)
+	}
+	x := &StructLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n305 := int(d.uint()); n305 != 0 {
+		x.Pairs = make(map[Expression]Expression, n305-1)
+		for i306 := 1; i306 < n305; i306++ {
+			var k307 Expression
+			if n309 := d.node(); n309 != nil {
+				k307 = n309.(Expression)
+			}
+			var v308 Expression
+			if n310 := d.node(); n310 != nil {
+				v308 = n310.(Expression)
+			}
+			x.Pairs[k307] = v308
+		}
+	}
+	d.token(&x.RBraceToken)
+	return x
+}
+
+func (e *encoder) encTernaryExpression(x *TernaryExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Condition)
+	e.node(x.IfTrue)
+	e.node(x.IfFalse)
+}
+
+func (d *decoder) decTernaryExpression() *TernaryExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TernaryExpression)
+	}
+	x := &TernaryExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n311 := d.node(); n311 != nil {
+		x.Condition = n311.(Expression)
+	}
+	if n312 := d.node(); n312 != nil {
+		x.IfTrue = n312.(Expression)
+	}
+	if n313 := d.node(); n313 != nil {
+		x.IfFalse = n313.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encThrowStmt(x *ThrowStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decThrowStmt() *ThrowStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ThrowStmt)
+	}
+	x := &ThrowStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n314 := d.node(); n314 != nil {
+		x.Expr = n314.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encTryStmt(x *TryStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encBlockStatement(x.Try)
+	if x.Catches == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Catches)) + 1)
+		for _, v315 := range x.Catches {
+			e.encCatchClause(v315)
+		}
+	}
+	e.encBlockStatement(x.Finally)
+}
+
+func (d *decoder) decTryStmt() *TryStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TryStmt)
+	}
+	x := &TryStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Try = d.decBlockStatement()
+	if n316 := int(d.uint()); n316 != 0 {
+		x.Catches = make([]*CatchClause, n316-1)
+		for i317 := range x.Catches {
+			x.Catches[i317] = d.decCatchClause()
+		}
+	}
+	x.Finally = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encTupleLiteral(x *TupleLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Members == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Members)) + 1)
+		for _, v318 := range x.Members {
+			e.node(v318)
+		}
+	}
+	e.token(&x.RParenToken)
+}
+
+func (d *decoder) decTupleLiteral() *TupleLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TupleLiteral)
+	}
+	x := &TupleLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n319 := int(d.uint()); n319 != 0 {
+		x.Members = make([]Expression, n319-1)
+		for i320 := range x.Members {
+			if n321 := d.node(); n321 != nil {
+				x.Members[i320] = n321.(Expression)
+			}
+		}
+	}
+	d.token(&x.RParenToken)
+	return x
+}
+
+func (e *encoder) encTuplePattern(x *TuplePattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	if x.Members == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Members)) + 1)
+		for _, v322 := range x.Members {
+			e.node(v322)
+		}
+	}
+	e.token(&x.RParenToken)
+}
+
+func (d *decoder) decTuplePattern() *TuplePattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TuplePattern)
+	}
+	x := &TuplePattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n323 := int(d.uint()); n323 != 0 {
+		x.Members = make([]Expression, n323-1)
+		for i324 := range x.Members {
+			if n325 := d.node(); n325 != nil {
+				x.Members[i324] = n325.(Expression)
+			}
+		}
+	}
+	d.token(&x.RParenToken)
+	return x
+}
+
+func (e *encoder) encTypeExpr(x *TypeExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Name)
+	if x.Args == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Args)) + 1)
+		for _, v326 := range x.Args {
+			e.encTypeExpr(v326)
+		}
+	}
+	if x.Union == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Union)) + 1)
+		for _, v327 := range x.Union {
+			e.encTypeExpr(v327)
+		}
+	}
+}
+
+func (d *decoder) decTypeExpr() *TypeExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TypeExpr)
+	}
+	x := &TypeExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Name = d.string()
+	if n328 := int(d.uint()); n328 != 0 {
+		x.Args = make([]*TypeExpr, n328-1)
+		for i329 := range x.Args {
+			x.Args[i329] = d.decTypeExpr()
+		}
+	}
+	if n330 := int(d.uint()); n330 != 0 {
+		x.Union = make([]*TypeExpr, n330-1)
+		for i331 := range x.Union {
+			x.Union[i331] = d.decTypeExpr()
+		}
+	}
+	return x
+}
+
+func (e *encoder) encTypePattern(x *TypePattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.string(x.Type)
+	if x.Names == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Names)) + 1)
+		for _, v332 := range x.Names {
+			e.string(v332)
+		}
+	}
+	if x.Patterns == nil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(len(x.Patterns)) + 1)
+		for _, v333 := range x.Patterns {
+			e.node(v333)
+		}
+	}
+	e.token(&x.RParenToken)
+}
+
+func (d *decoder) decTypePattern() *TypePattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*TypePattern)
+	}
+	x := &TypePattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Type = d.string()
+	if n334 := int(d.uint()); n334 != 0 {
+		x.Names = make([]string, n334-1)
+		for i335 := range x.Names {
+			x.Names[i335] = d.string()
+		}
+	}
+	if n336 := int(d.uint()); n336 != 0 {
+		x.Patterns = make([]Expression, n336-1)
+		for i337 := range x.Patterns {
+			if n338 := d.node(); n338 != nil {
+				x.Patterns[i337] = n338.(Expression)
+			}
+		}
+	}
+	d.token(&x.RParenToken)
+	return x
+}
+
+func (e *encoder) encUIntegerLiteral(x *UIntegerLiteral) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.uint(uint64(x.Value))
+}
+
+func (d *decoder) decUIntegerLiteral() *UIntegerLiteral {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*UIntegerLiteral)
+	}
+	x := &UIntegerLiteral{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Value = uint64(d.uint())
+	return x
+}
+
+func (e *encoder) encUnlessExpression(x *UnlessExpression) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Condition)
+	e.encBlockStatement(x.Consequence)
+	e.encBlockStatement(x.Alternative)
+}
+
+func (d *decoder) decUnlessExpression() *UnlessExpression {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*UnlessExpression)
+	}
+	x := &UnlessExpression{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n339 := d.node(); n339 != nil {
+		x.Condition = n339.(Expression)
+	}
+	x.Consequence = d.decBlockStatement()
+	x.Alternative = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encUsingStmt(x *UsingStmt) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.encAssignExpression(x.Expr)
+	e.encBlockStatement(x.Block)
+}
+
+func (d *decoder) decUsingStmt() *UsingStmt {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*UsingStmt)
+	}
+	x := &UsingStmt{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	x.Expr = d.decAssignExpression()
+	x.Block = d.decBlockStatement()
+	return x
+}
+
+func (e *encoder) encValuePattern(x *ValuePattern) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Value)
+}
+
+func (d *decoder) decValuePattern() *ValuePattern {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*ValuePattern)
+	}
+	x := &ValuePattern{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n340 := d.node(); n340 != nil {
+		x.Value = n340.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encWhereExpr(x *WhereExpr) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Expr)
+}
+
+func (d *decoder) decWhereExpr() *WhereExpr {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*WhereExpr)
+	}
+	x := &WhereExpr{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n341 := d.node(); n341 != nil {
+		x.Expr = n341.(Expression)
+	}
+	return x
+}
+
+func (e *encoder) encWhileLoop(x *WhileLoop) {
+	if x == nil {
+		e.byte(ptrNil)
+		return
+	}
+	if !e.pointer(x) {
+		return
+	}
+	e.token(&x.Token)
+	e.node(x.Condition)
+	e.node(x.Block)
+}
+
+func (d *decoder) decWhileLoop() *WhileLoop {
+	if p, ok := d.pointer(); !ok {
+		if p == nil {
+			return nil
+		}
+		return p.(*WhileLoop)
+	}
+	x := &WhileLoop{}
+	d.pointers = append(d.pointers, x)
+	d.token(&x.Token)
+	if n342 := d.node(); n342 != nil {
+		x.Condition = n342.(Expression)
+	}
+	if n343 := d.node(); n343 != nil {
+		x.Block = n343.(Node)
+	}
+	return x
+}
@@ -0,0 +1,105 @@
+package ast_test
+
+import (
+	"originscript/ast"
+	"originscript/lexer"
+	"originscript/parser"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var path, _ = os.Getwd()
+
+func TestCodecRoundTrip(t *testing.T) {
+	inputs := []string{
+		`lit a = 1; const b = 2.5; lit s = "v={a}"; lit arr = [1, true, nil, "x"]; lit h = {"a": 1, 2: [3]}; h["a"]`,
+		`fn add(a, b = 3, args...) { return a + b } add(1, 2); lit f = fn(x) { x * 2 }; f(4)`,
+		`lit n = 0; while (n < 3) { n++ }; do { break }; for (i = 0; i < 2; i++) { continue }`,
+		`if (1 < 2) { 1 } elif (2 > 3) { 2 } else { 3 }`,
+		`class Point { lit x = 1; fn show() { return "P" + x } } lit p = new Point(); p.show()`,
+		`try { throw "e" } catch { println("caught") }`,
+		`spawn fn() { 1 }(); lit a = !true; lit b = -1 * (2 + 3) % 4`,
+	}
+
+	for _, input := range inputs {
+		p := parser.New(lexer.New("test.aero", input), path)
+		program := p.ParseProgram()
+		if errs := p.Errors(); len(errs) != 0 {
+			t.Fatalf("parser errors for %q: %v", input, errs)
+		}
+
+		data, err := ast.EncodeProgram(program)
+		if err != nil {
+			t.Fatalf("encode %q: %s", input, err)
+		}
+		decoded, err := ast.DecodeProgram(data)
+		if err != nil {
+			t.Fatalf("decode %q: %s", input, err)
+		}
+		if !equalNodes(reflect.ValueOf(program), reflect.ValueOf(decoded)) {
+			t.Errorf("the decoded program differs for %q.\nexpected=%s\ngot=%s", input, program.String(), decoded.String())
+		}
+	}
+}
+
+//equalNodes is like reflect.DeepEqual, but the maps with node keys(e.g.
+//'HashLiteral.Pairs') are compared by the content of the keys.
+func equalNodes(a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Elem().Type() == b.Elem().Type() && equalNodes(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalNodes(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalNodes(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() || a.Len() != b.Len() {
+			return false
+		}
+	next:
+		for _, ka := range a.MapKeys() {
+			for _, kb := range b.MapKeys() {
+				if equalNodes(ka, kb) && equalNodes(a.MapIndex(ka), b.MapIndex(kb)) {
+					continue next
+				}
+			}
+			return false
+		}
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func TestCodecCorruptData(t *testing.T) {
+	p := parser.New(lexer.New("test.aero", `lit a = [1, 2]; fn f(x) { return x }`), path)
+	data, err := ast.EncodeProgram(p.ParseProgram())
+	if err != nil {
+		t.Fatalf("encode: %s", err)
+	}
+
+	for _, corrupt := range [][]byte{nil, data[:len(data)/2], append(data, 0), []byte("garbage")} {
+		if _, err := ast.DecodeProgram(corrupt); err == nil {
+			t.Errorf("expected an error decoding %d bytes", len(corrupt))
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// +build ignore
+
+//gen_codec generates codec_gen.go, the encoding of the nodes of ast.go for the
+//parsed program cache(see codec.go). Run 'go generate' in this directory after
+//changing the nodes.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//the resolver's annotations and the VM's bytecode are not encoded, see
+//eval/resolver.go and eval/compiler.go
+var skippedFields = map[string]bool{"Func": true, "Slot": true, "Slots": true, "SlotIndex": true, "Code": true}
+
+type generator struct {
+	fset       *token.FileSet
+	structs    map[string]*ast.StructType
+	names      []string          //the struct names, sorted
+	named      map[string]string //the named basic types, e.g. 'ModifierLevel' => 'int'
+	interfaces map[string]bool
+	out        bytes.Buffer
+	vars       int //for the names of the loop variables
+}
+
+func main() {
+	g := &generator{
+		fset:       token.NewFileSet(),
+		structs:    make(map[string]*ast.StructType),
+		named:      make(map[string]string),
+		interfaces: make(map[string]bool),
+	}
+	schema := sha256.New()
+
+	f, err := parser.ParseFile(g.fset, "ast.go", nil, 0)
+	if err != nil {
+		fail(err)
+	}
+	g.collect(f, schema)
+
+	//the tokens are encoded by codec.go, they are part of the schema
+	files, _ := filepath.Glob(filepath.Join("..", "token", "*.go"))
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		tf, err := parser.ParseFile(g.fset, name, nil, 0)
+		if err != nil {
+			fail(err)
+		}
+		for _, spec := range typeSpecs(tf) {
+			if spec.Name.Name == "Token" || spec.Name.Name == "Position" {
+				fmt.Fprintf(schema, "token.%s %s\n", spec.Name.Name, g.expr(spec.Type))
+			}
+		}
+	}
+	sort.Strings(g.names)
+
+	g.printf("// Code generated by gen_codec.go; DO NOT EDIT.\n\n")
+	g.printf("package ast\n\n")
+	g.printf("import \"fmt\"\n\n")
+	g.printf("//CodecSchema changes when the encoded nodes change.\n")
+	g.printf("const CodecSchema = %q\n\n", hex.EncodeToString(schema.Sum(nil))[:16])
+	g.nodeFuncs()
+	for _, name := range g.names {
+		g.structFuncs(name)
+	}
+
+	src, err := format.Source(g.out.Bytes())
+	if err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile("codec_gen.go", src, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "gen_codec:", err)
+	os.Exit(1)
+}
+
+func typeSpecs(f *ast.File) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+			for _, spec := range gd.Specs {
+				specs = append(specs, spec.(*ast.TypeSpec))
+			}
+		}
+	}
+	return specs
+}
+
+func (g *generator) collect(f *ast.File, schema interface{ Write([]byte) (int, error) }) {
+	for _, spec := range typeSpecs(f) {
+		name := spec.Name.Name
+		switch t := spec.Type.(type) {
+		case *ast.StructType:
+			g.structs[name] = t
+			g.names = append(g.names, name)
+			fmt.Fprintf(schema, "%s %s\n", name, g.expr(t))
+		case *ast.InterfaceType:
+			g.interfaces[name] = true
+		case *ast.Ident:
+			g.named[name] = t.Name
+			fmt.Fprintf(schema, "%s %s\n", name, t.Name)
+		}
+	}
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.out, format, args...)
+}
+
+func (g *generator) expr(e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, e)
+	return buf.String()
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+//nodeFuncs generates the encoding of the nodes of the interface fields, the
+//nodes are preceded by their index in the sorted struct names.
+func (g *generator) nodeFuncs() {
+	g.printf("func (e *encoder) node(n interface{}) {\n")
+	g.printf("switch n := n.(type) {\n")
+	g.printf("case nil:\ne.uint(0)\n")
+	for i, name := range g.names {
+		g.printf("case *%s:\ne.uint(%d)\ne.enc%s(n)\n", name, i+1, name)
+	}
+	g.printf("default:\npanic(fmt.Sprintf(\"cannot encode %%T\", n))\n")
+	g.printf("}\n}\n\n")
+
+	g.printf("func (d *decoder) node() interface{} {\n")
+	g.printf("switch d.uint() {\n")
+	g.printf("case 0:\nreturn nil\n")
+	for i, name := range g.names {
+		g.printf("case %d:\nreturn d.dec%s()\n", i+1, name)
+	}
+	g.printf("}\npanic(\"invalid node\")\n}\n\n")
+}
+
+func (g *generator) structFuncs(name string) {
+	st := g.structs[name]
+
+	g.printf("func (e *encoder) enc%s(x *%s) {\n", name, name)
+	g.printf("if x == nil {\ne.byte(ptrNil)\nreturn\n}\n")
+	g.printf("if !e.pointer(x) {\nreturn\n}\n")
+	for _, field := range st.Fields.List {
+		for _, fname := range field.Names {
+			if !skippedFields[fname.Name] && fname.IsExported() {
+				g.encode(field.Type, "x."+fname.Name)
+			}
+		}
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (d *decoder) dec%s() *%s {\n", name, name)
+	g.printf("if p, ok := d.pointer(); !ok {\nif p == nil {\nreturn nil\n}\nreturn p.(*%s)\n}\n", name)
+	g.printf("x := &%s{}\n", name)
+	g.printf("d.pointers = append(d.pointers, x)\n")
+	for _, field := range st.Fields.List {
+		for _, fname := range field.Names {
+			if !skippedFields[fname.Name] && fname.IsExported() {
+				g.decode(field.Type, "x."+fname.Name)
+			}
+		}
+	}
+	g.printf("return x\n}\n\n")
+}
+
+//encode generates the encoding of the value 'v' of type 't'.
+func (g *generator) encode(t ast.Expr, v string) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if g.interfaces[t.Name] {
+			g.printf("e.node(%s)\n", v)
+			return
+		}
+		basic := t.Name
+		if underlying, ok := g.named[t.Name]; ok {
+			basic = underlying
+		}
+		switch basic {
+		case "bool":
+			g.printf("e.bool(%s)\n", v)
+		case "int", "int8", "int16", "int32", "int64":
+			g.printf("e.int(int64(%s))\n", v)
+		case "uint", "uint8", "byte", "uint16", "uint32", "uint64":
+			g.printf("e.uint(uint64(%s))\n", v)
+		case "float64":
+			g.printf("e.float(%s)\n", v)
+		case "string":
+			g.printf("e.string(%s)\n", v)
+		default:
+			fail(fmt.Errorf("cannot encode %s", t.Name))
+		}
+	case *ast.SelectorExpr:
+		if g.expr(t) != "token.Token" {
+			fail(fmt.Errorf("cannot encode %s", g.expr(t)))
+		}
+		g.printf("e.token(&%s)\n", v)
+	case *ast.StarExpr:
+		name, ok := t.X.(*ast.Ident)
+		if !ok || g.structs[name.Name] == nil {
+			fail(fmt.Errorf("cannot encode %s", g.expr(t)))
+		}
+		g.printf("e.enc%s(%s)\n", name.Name, v)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			fail(fmt.Errorf("cannot encode %s", g.expr(t)))
+		}
+		elem := g.newVar("v")
+		g.printf("if %s == nil {\ne.uint(0)\n} else {\n", v)
+		g.printf("e.uint(uint64(len(%s)) + 1)\n", v)
+		g.printf("for _, %s := range %s {\n", elem, v)
+		g.encode(t.Elt, elem)
+		g.printf("}\n}\n")
+	case *ast.MapType:
+		key, elem := g.newVar("k"), g.newVar("v")
+		g.printf("if %s == nil {\ne.uint(0)\n} else {\n", v)
+		g.printf("e.uint(uint64(len(%s)) + 1)\n", v)
+		g.printf("for %s, %s := range %s {\n", key, elem, v)
+		g.encode(t.Key, key)
+		g.encode(t.Value, elem)
+		g.printf("}\n}\n")
+	default:
+		fail(fmt.Errorf("cannot encode %s", g.expr(t)))
+	}
+}
+
+//decode generates the decoding of a value of type 't' to 'v'.
+func (g *generator) decode(t ast.Expr, v string) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if g.interfaces[t.Name] {
+			n := g.newVar("n")
+			g.printf("if %s := d.node(); %s != nil {\n%s = %s.(%s)\n}\n", n, n, v, n, t.Name)
+			return
+		}
+		basic := t.Name
+		if underlying, ok := g.named[t.Name]; ok {
+			basic = underlying
+		}
+		switch basic {
+		case "bool":
+			g.printf("%s = d.bool()\n", v)
+		case "int", "int8", "int16", "int32", "int64":
+			g.printf("%s = %s(d.int())\n", v, t.Name)
+		case "uint", "uint8", "byte", "uint16", "uint32", "uint64":
+			g.printf("%s = %s(d.uint())\n", v, t.Name)
+		case "float64":
+			g.printf("%s = d.float()\n", v)
+		case "string":
+			g.printf("%s = d.string()\n", v)
+		default:
+			fail(fmt.Errorf("cannot decode %s", t.Name))
+		}
+	case *ast.SelectorExpr:
+		g.printf("d.token(&%s)\n", v)
+	case *ast.StarExpr:
+		g.printf("%s = d.dec%s()\n", v, t.X.(*ast.Ident).Name)
+	case *ast.ArrayType:
+		n, i := g.newVar("n"), g.newVar("i")
+		g.printf("if %s := int(d.uint()); %s != 0 {\n", n, n)
+		g.printf("%s = make(%s, %s-1)\n", v, g.expr(t), n)
+		g.printf("for %s := range %s {\n", i, v)
+		g.decode(t.Elt, fmt.Sprintf("%s[%s]", v, i))
+		g.printf("}\n}\n")
+	case *ast.MapType:
+		n, i, key, elem := g.newVar("n"), g.newVar("i"), g.newVar("k"), g.newVar("v")
+		g.printf("if %s := int(d.uint()); %s != 0 {\n", n, n)
+		g.printf("%s = make(%s, %s-1)\n", v, g.expr(t), n)
+		g.printf("for %s := 1; %s < %s; %s++ {\n", i, i, n, i)
+		g.printf("var %s %s\n", key, g.expr(t.Key))
+		g.decode(t.Key, key)
+		g.printf("var %s %s\n", elem, g.expr(t.Value))
+		g.decode(t.Value, elem)
+		g.printf("%s[%s] = %s\n", v, key, elem)
+		g.printf("}\n}\n")
+	default:
+		fail(fmt.Errorf("cannot decode %s", g.expr(t)))
+	}
+}
@@ -0,0 +1,282 @@
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//client is an in-process client of the server.
+type client struct {
+	t        *testing.T
+	conn     *Conn
+	messages chan *Message //read from the server, the pipes are not buffered
+	events   []*Message //received while waiting for a response
+	output   []string   //of the 'output' events
+}
+
+func newClient(t *testing.T) (*client, func()) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	done := make(chan error)
+	go func() {
+		done <- NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+
+	c := &client{t: t, conn: NewConn(clientIn, clientOut), messages: make(chan *Message, 16)}
+	go func() {
+		for {
+			msg, err := c.conn.Read()
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+
+	stop := func() {
+		c.call("disconnect", nil, nil)
+		if err := <-done; err != nil {
+			t.Errorf("server returned error: %s", err)
+		}
+	}
+	return c, stop
+}
+
+//call sends a request and waits for its response, the events received meanwhile are kept.
+func (c *client) call(command string, args interface{}, body interface{}) {
+	data, _ := json.Marshal(args)
+	req := &Message{Type: "request", Command: command, Arguments: data}
+	if err := c.conn.Write(req); err != nil {
+		c.t.Fatalf("%s: write error: %s", command, err)
+	}
+
+	for msg := range c.messages {
+		if msg.Type == "event" {
+			c.record(msg)
+			c.events = append(c.events, msg)
+			continue
+		}
+		if msg.Success == nil || !*msg.Success {
+			c.t.Fatalf("%s: failed: %s", command, msg.Message)
+		}
+		if body != nil {
+			if err := json.Unmarshal(msg.Body, body); err != nil {
+				c.t.Fatalf("%s: invalid body %s: %s", command, msg.Body, err)
+			}
+		}
+		return
+	}
+	c.t.Fatalf("%s: no response", command)
+}
+
+//wait waits for an event, and decodes its body.
+func (c *client) wait(event string, body interface{}) {
+	for {
+		var msg *Message
+		if len(c.events) != 0 {
+			msg, c.events = c.events[0], c.events[1:]
+		} else if msg = <-c.messages; msg == nil {
+			c.t.Fatalf("no '%s' event", event)
+		} else {
+			c.record(msg)
+		}
+		if msg.Type == "event" && msg.Event == event {
+			if body != nil {
+				json.Unmarshal(msg.Body, body)
+			}
+			return
+		}
+		if msg.Type == "event" && msg.Event == "terminated" {
+			c.t.Fatalf("the program terminated, expected a '%s' event", event)
+		}
+	}
+}
+
+func (c *client) record(msg *Message) {
+	if msg.Type == "event" && msg.Event == "output" {
+		var output OutputEventBody
+		json.Unmarshal(msg.Body, &output)
+		c.output = append(c.output, output.Output)
+	}
+}
+
+const testSource = `fn add(a, b) {
+    lit sum = a + b
+    return sum
+}
+
+lit nums = [1, 2]
+lit total = add(nums[0], nums[1])
+println(total)
+`
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	program := filepath.Join(dir, "test.aero")
+	ioutil.WriteFile(program, []byte(testSource), 0644)
+
+	c, stop := newClient(t)
+	defer stop()
+
+	var caps Capabilities
+	c.call("initialize", map[string]interface{}{"adapterID": "origion"}, &caps)
+	if !caps.SupportsConfigurationDoneRequest {
+		t.Fatalf("wrong capabilities: %+v", caps)
+	}
+	c.wait("initialized", nil)
+	c.call("launch", LaunchArguments{Program: program}, nil)
+
+	var bps SetBreakpointsResponseBody
+	c.call("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: program}, Breakpoints: []SourceBreakpoint{{Line: 2}}}, &bps)
+	if len(bps.Breakpoints) != 1 || !bps.Breakpoints[0].Verified {
+		t.Fatalf("wrong breakpoints: %+v", bps)
+	}
+	c.call("configurationDone", nil, nil)
+
+	//stopped at the breakpoint in 'add'
+	var stopped StoppedEventBody
+	c.wait("stopped", &stopped)
+	if stopped.Reason != "breakpoint" {
+		t.Errorf("wrong stop reason: %s", stopped.Reason)
+	}
+
+	var trace StackTraceResponseBody
+	c.call("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace)
+	var frames []string
+	for _, f := range trace.StackFrames {
+		frames = append(frames, fmt.Sprintf("%s:%d", f.Name, f.Line))
+		if f.Source == nil || f.Source.Path != program {
+			t.Errorf("wrong source of frame '%s': %+v", f.Name, f.Source)
+		}
+	}
+	if got := strings.Join(frames, " "); got != "add:2 <module>:7" {
+		t.Errorf("wrong stack trace: %s", got)
+	}
+
+	var scopes ScopesResponseBody
+	c.call("scopes", ScopesArguments{FrameID: 1}, &scopes)
+	if len(scopes.Scopes) != 2 {
+		t.Fatalf("wrong scopes: %+v", scopes)
+	}
+	var vars VariablesResponseBody
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &vars)
+	if got := variables(vars.Variables); got != "a=1 b=2" {
+		t.Errorf("wrong locals: %s", got)
+	}
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[1].VariablesReference}, &vars)
+	if !strings.Contains(variables(vars.Variables), "nums=[1, 2]") {
+		t.Errorf("wrong globals: %s", variables(vars.Variables))
+	}
+
+	var result EvaluateResponseBody
+	c.call("evaluate", EvaluateArguments{Expression: "a * 10 + b", FrameID: 1}, &result)
+	if result.Result != "12" {
+		t.Errorf("wrong evaluation: %+v", result)
+	}
+	c.call("evaluate", EvaluateArguments{Expression: "nums", FrameID: 2}, &result)
+	if result.VariablesReference == 0 {
+		t.Errorf("expected an expandable array: %+v", result)
+	}
+
+	//'next' stops at the next line of 'add'
+	c.call("next", nil, nil)
+	c.wait("stopped", &stopped)
+	c.call("stackTrace", StackTraceArguments{ThreadID: threadID}, &trace)
+	if stopped.Reason != "step" || trace.StackFrames[0].Line != 3 {
+		t.Errorf("wrong stop after next: %s, line %d", stopped.Reason, trace.StackFrames[0].Line)
+	}
+
+	c.call("continue", nil, nil)
+	var exited ExitedEventBody
+	c.wait("exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("wrong exit code: %d", exited.ExitCode)
+	}
+}
+
+func variables(vars []Variable) string {
+	var result []string
+	for _, v := range vars {
+		result = append(result, v.Name+"="+v.Value)
+	}
+	return strings.Join(result, " ")
+}
+
+const loopSource = `fn double(x) {
+    return x * 2
+}
+
+for (i = 0; i < 5; i++) {
+    lit d = double(i)
+    println(d)
+}
+`
+
+func TestBreakpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	program := filepath.Join(dir, "loop.aero")
+	ioutil.WriteFile(program, []byte(loopSource), 0644)
+
+	c, stop := newClient(t)
+	defer stop()
+
+	c.call("initialize", map[string]interface{}{"adapterID": "origion"}, nil)
+	c.call("launch", LaunchArguments{Program: program}, nil)
+
+	var bps SetBreakpointsResponseBody
+	c.call("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: program}, Breakpoints: []SourceBreakpoint{
+		{Line: 6, LogMessage: "i={i}"},
+		{Line: 7, Condition: "d > 2", HitCondition: "==2"},
+		{Line: 8, HitCondition: "oops"},
+	}}, &bps)
+	if len(bps.Breakpoints) != 3 || !bps.Breakpoints[0].Verified || !bps.Breakpoints[1].Verified || bps.Breakpoints[2].Verified {
+		t.Fatalf("wrong breakpoints: %+v", bps)
+	}
+	c.call("setFunctionBreakpoints", SetFunctionBreakpointsArguments{Breakpoints: []FunctionBreakpoint{
+		{Name: "double", Condition: "x == 1"},
+		{Name: "missing"},
+	}}, &bps)
+	if len(bps.Breakpoints) != 2 || !bps.Breakpoints[0].Verified || bps.Breakpoints[0].Line != 2 || bps.Breakpoints[1].Verified {
+		t.Fatalf("wrong function breakpoints: %+v", bps)
+	}
+	c.call("configurationDone", nil, nil)
+
+	var result EvaluateResponseBody
+	var stopped StoppedEventBody
+	for _, expected := range []string{"1", "3"} { //'double' with x == 1, then the 2nd time 'd > 2'
+		c.wait("stopped", &stopped)
+		c.call("evaluate", EvaluateArguments{Expression: "i"}, &result)
+		if stopped.Reason != "breakpoint" || result.Result != expected {
+			t.Errorf("wrong stop: %s with i=%s, expected i=%s", stopped.Reason, result.Result, expected)
+		}
+		c.call("continue", nil, nil)
+	}
+	c.wait("exited", nil)
+
+	var logs []string
+	for _, output := range c.output {
+		if strings.HasPrefix(output, "i=") {
+			logs = append(logs, strings.TrimSpace(output))
+		}
+	}
+	if got := strings.Join(logs, " "); got != "i=0 i=1 i=2 i=3 i=4" {
+		t.Errorf("wrong logpoint output: %s", got)
+	}
+}
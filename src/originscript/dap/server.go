@@ -0,0 +1,451 @@
+// Package dap implements the `origion dap` subcommand, a Debug Adapter
+// Protocol server over stdio or TCP.
+//
+// It is a front end of eval.Debugger: the breakpoints are the debugger's,
+// the stack trace is built from the CallStack of the current scope, and
+// the expressions are evaluated like the `$p` command does.
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"originscript/ast"
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/message"
+	"originscript/parser"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//the only thread, the goroutines of 'spawn' are not reported
+const threadID = 1
+
+//Server is a debug adapter, it debugs one program.
+type Server struct {
+	conn        *Conn
+	dbg         *eval.Debugger
+	program     *ast.Program
+	stopOnEntry bool
+	breakpoints map[string][]int //lines by source's base name
+	functionBPs []string         //'filename:line' of the function breakpoints
+	global      *eval.Scope
+
+	//the program waits on 'resume' when it's stopped, the request
+	//handlers only inspect the debugger's state while it's stopped.
+	mu      sync.Mutex
+	stopped bool
+	resume  chan string //"continue", "next", "stepIn" or "stepOut"
+	mode    string
+	depth   int //the call depth when the program stopped
+	started bool
+
+	handles []interface{} //by variablesReference-1: *eval.Scope, locals or eval.Object
+}
+
+//locals is the scope chain of a frame, up to the global scope.
+type locals struct {
+	scope *eval.Scope
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{
+		conn:        NewConn(in, out),
+		dbg:         eval.NewDebugger(),
+		breakpoints: make(map[string][]int),
+		resume:      make(chan string),
+		mode:        "continue",
+	}
+	s.dbg.Frontend = s
+	return s
+}
+
+//Run handles the requests until the 'disconnect' request, or the end of the input.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Type != "request" {
+			continue
+		}
+
+		body, errMsg := s.handle(msg)
+		s.conn.Reply(msg, body, errMsg)
+		switch msg.Command {
+		case "initialize":
+			s.conn.Event("initialized", nil)
+		case "disconnect":
+			return nil
+		}
+	}
+}
+
+//ForwardOutput sends what's read from 'r' as 'output' events, e.g. the
+//program's standard output when the protocol uses stdio.
+func (s *Server) ForwardOutput(r io.Reader) {
+	io.Copy(outputWriter{s.conn, "stdout"}, r)
+}
+
+func (s *Server) handle(msg *Message) (interface{}, string) {
+	switch msg.Command {
+	case "initialize":
+		return Capabilities{SupportsConfigurationDoneRequest: true, SupportsEvaluateForHovers: true,
+			SupportsConditionalBreakpoints: true, SupportsHitConditionalBreakpoints: true,
+			SupportsLogPoints: true, SupportsFunctionBreakpoints: true}, ""
+	case "launch":
+		var args LaunchArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		if args.Program == "" {
+			return nil, "launch: 'program' expected"
+		}
+		filename, err := filepath.Abs(args.Program)
+		if err != nil {
+			return nil, err.Error()
+		}
+		if errMsg := s.parse(filename); errMsg != "" {
+			return nil, errMsg
+		}
+		s.stopOnEntry = args.StopOnEntry
+	case "configurationDone":
+		if s.program == nil {
+			return nil, "the program has not been launched"
+		}
+		if !s.started {
+			s.started = true
+			go s.run()
+		}
+	case "disconnect":
+
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		return s.setBreakpoints(args), ""
+	case "setFunctionBreakpoints":
+		var args SetFunctionBreakpointsArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		return s.setFunctionBreakpoints(args), ""
+	case "threads":
+		return ThreadsResponseBody{Threads: []Thread{{ID: threadID, Name: "main"}}}, ""
+
+	case "continue", "next", "stepIn", "stepOut":
+		if !s.isStopped() {
+			return nil, "the program is not stopped"
+		}
+		s.mu.Lock()
+		s.stopped = false
+		s.mu.Unlock()
+		s.handles = nil
+		s.resume <- msg.Command
+		if msg.Command == "continue" {
+			return ContinueResponseBody{AllThreadsContinued: true}, ""
+		}
+
+	case "stackTrace":
+		if !s.isStopped() {
+			return nil, "the program is not stopped"
+		}
+		var stackFrames []StackFrame
+		for i, f := range s.dbg.Frames() {
+			p := f.Node.Pos()
+			stackFrames = append(stackFrames, StackFrame{ID: i + 1, Name: f.Name, Line: p.Line, Column: p.Col,
+				Source: &Source{Name: filepath.Base(p.Filename), Path: p.Filename}})
+		}
+		return StackTraceResponseBody{StackFrames: stackFrames, TotalFrames: len(stackFrames)}, ""
+	case "scopes":
+		var args ScopesArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		scope, errMsg := s.frameScope(args.FrameID)
+		if errMsg != "" {
+			return nil, errMsg
+		}
+		return ScopesResponseBody{Scopes: []Scope{
+			{Name: "Locals", VariablesReference: s.reference(locals{scope})},
+			{Name: "Globals", VariablesReference: s.reference(s.global)},
+		}}, ""
+	case "variables":
+		var args VariablesArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		if !s.isStopped() || args.VariablesReference <= 0 || args.VariablesReference > len(s.handles) {
+			return nil, "invalid variablesReference"
+		}
+		return VariablesResponseBody{Variables: s.variables(s.handles[args.VariablesReference-1])}, ""
+	case "evaluate":
+		var args EvaluateArguments
+		if errMsg := decode(msg, &args); errMsg != "" {
+			return nil, errMsg
+		}
+		scope, errMsg := s.frameScope(args.FrameID)
+		if errMsg != "" {
+			return nil, errMsg
+		}
+		result := s.dbg.Evaluate(args.Expression, scope)
+		if result.Type() == eval.ERROR_OBJ {
+			return nil, strings.TrimSpace(result.Inspect())
+		}
+		v := s.variable("", result)
+		return EvaluateResponseBody{Result: v.Value, Type: v.Type, VariablesReference: v.VariablesReference}, ""
+
+	default:
+		return nil, "unsupported request: " + msg.Command
+	}
+	return nil, ""
+}
+
+//parse parses the program to debug.
+func (s *Server) parse(filename string) string {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err.Error()
+	}
+	parser.NoCache = true //the debugger needs the information collected while parsing the imports
+	p := parser.New(lexer.New(filename, string(f)), filepath.Dir(filename))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return strings.Join(p.Errors(), "\n")
+	}
+
+	s.program = program
+	s.dbg.SetFunctions(p.Functions)
+	s.dbg.SetDbgInfos(parser.SplitSlice(parser.DebugInfos))
+	return ""
+}
+
+//run evaluates the program, in its own goroutine.
+func (s *Server) run() {
+	exitCode := 0
+	defer func() {
+		s.conn.Event("exited", ExitedEventBody{ExitCode: exitCode})
+		s.conn.Event("terminated", nil)
+	}()
+
+	eval.Dbg = s.dbg
+	eval.MsgHandler = message.NewMessageHandler()
+	eval.MsgHandler.AddListener(s.dbg)
+
+	s.global = eval.NewScope(nil, outputWriter{s.conn, "stdout"})
+	if s.stopOnEntry {
+		s.mode = "stepIn"
+	}
+	result := eval.Eval(s.program, s.global)
+	if result.Type() == eval.ERROR_OBJ {
+		fmt.Fprintln(outputWriter{s.conn, "stderr"}, result.Inspect())
+		exitCode = 1
+	}
+}
+
+//Line implements eval.DebugFrontend, it's called by the program's goroutine.
+func (s *Server) Line(d *eval.Debugger, breakpoint bool) {
+	depth := len(d.Scope.CallStack.Frames)
+
+	reason := "step"
+	switch {
+	case breakpoint:
+		reason = "breakpoint"
+	case s.mode == "stepIn":
+		if s.stopOnEntry {
+			reason, s.stopOnEntry = "entry", false
+		}
+	case s.mode == "next" && depth <= s.depth:
+	case s.mode == "stepOut" && depth < s.depth:
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	s.stopped = true
+	s.depth = depth
+	s.mu.Unlock()
+	s.conn.Event("stopped", StoppedEventBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+	s.mode = <-s.resume
+}
+
+func (s *Server) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *Server) setBreakpoints(args SetBreakpointsArguments) SetBreakpointsResponseBody {
+	filename := filepath.Base(args.Source.Path)
+	if filename == "." {
+		filename = args.Source.Name
+	}
+	for _, line := range s.breakpoints[filename] {
+		s.dbg.DelBP(filename, line)
+	}
+
+	body := SetBreakpointsResponseBody{Breakpoints: []Breakpoint{}}
+	var lines []int
+	for _, sbp := range args.Breakpoints {
+		bp := Breakpoint{Line: sbp.Line}
+		if sbp.Line <= 0 {
+			bp.Message = "line number must be greater than zero"
+		} else if dbp, err := newBreakpoint(sbp.Condition, sbp.HitCondition, sbp.LogMessage); err != nil {
+			bp.Message = err.Error()
+		} else {
+			s.dbg.SetBP(filename, sbp.Line, dbp)
+			lines = append(lines, sbp.Line)
+			bp.Verified = true
+		}
+		body.Breakpoints = append(body.Breakpoints, bp)
+	}
+	s.breakpoints[filename] = lines
+	return body
+}
+
+func (s *Server) setFunctionBreakpoints(args SetFunctionBreakpointsArguments) SetBreakpointsResponseBody {
+	for _, key := range s.functionBPs {
+		delete(s.dbg.Breakpoints, key)
+	}
+	s.functionBPs = nil
+
+	body := SetBreakpointsResponseBody{Breakpoints: []Breakpoint{}}
+	for _, fbp := range args.Breakpoints {
+		bp := Breakpoint{}
+		if filename, line, ok := s.dbg.FunctionBP(fbp.Name); !ok {
+			bp.Message = fmt.Sprintf("function '%s' not found", fbp.Name)
+		} else if dbp, err := newBreakpoint(fbp.Condition, fbp.HitCondition, ""); err != nil {
+			bp.Message = err.Error()
+		} else {
+			s.dbg.SetBP(filename, line, dbp)
+			s.functionBPs = append(s.functionBPs, fmt.Sprintf("%s:%d", filename, line))
+			bp.Verified, bp.Line, bp.Source = true, line, &Source{Name: filename}
+		}
+		body.Breakpoints = append(body.Breakpoints, bp)
+	}
+	return body
+}
+
+func newBreakpoint(condition, hitCondition, logMessage string) (*eval.Breakpoint, error) {
+	bp := &eval.Breakpoint{Condition: condition, Log: logMessage}
+	if hitCondition != "" {
+		var err error
+		if bp.HitOp, bp.HitCount, err = eval.ParseHitCondition(hitCondition); err != nil {
+			return nil, err
+		}
+	}
+	return bp, nil
+}
+
+//frameScope returns the scope of the stack frame 'id', the innermost if 'id' is 0.
+func (s *Server) frameScope(id int) (*eval.Scope, string) {
+	if !s.isStopped() {
+		return nil, "the program is not stopped"
+	}
+	frames := s.dbg.Frames()
+	if id == 0 {
+		id = 1
+	}
+	if id < 0 || id > len(frames) {
+		return nil, fmt.Sprintf("invalid frameId: %d", id)
+	}
+	return s.dbg.FrameScope(id - 1), ""
+}
+
+//reference returns the variablesReference of 'v'.
+func (s *Server) reference(v interface{}) int {
+	s.handles = append(s.handles, v)
+	return len(s.handles)
+}
+
+func (s *Server) variables(v interface{}) []Variable {
+	result := []Variable{}
+	switch v := v.(type) {
+	case locals:
+		names, values := s.dbg.Variables(v.scope, true)
+		for _, name := range names {
+			result = append(result, s.variable(name, values[name]))
+		}
+	case *eval.Scope:
+		names, values := s.dbg.Variables(v, false)
+		for _, name := range names {
+			result = append(result, s.variable(name, values[name]))
+		}
+	case *eval.Array:
+		for i, member := range v.Members {
+			result = append(result, s.variable(fmt.Sprintf("[%d]", i), member))
+		}
+	case *eval.Tuple:
+		for i, member := range v.Members {
+			result = append(result, s.variable(fmt.Sprintf("[%d]", i), member))
+		}
+	case *eval.Hash:
+		for _, key := range v.Order {
+			pair := v.Pairs[key]
+			result = append(result, s.variable(pair.Key.Inspect(), pair.Value))
+		}
+	case *eval.ObjectInstance:
+		names, values := s.dbg.Variables(v.Scope, false)
+		for _, name := range names {
+			result = append(result, s.variable(name, values[name]))
+		}
+	}
+	return result
+}
+
+func (s *Server) variable(name string, obj eval.Object) Variable {
+	if obj == nil {
+		return Variable{Name: name, Value: "nil"}
+	}
+	v := Variable{Name: name, Value: obj.Inspect(), Type: string(obj.Type())}
+	switch o := obj.(type) {
+	case *eval.Array:
+		if len(o.Members) != 0 {
+			v.VariablesReference = s.reference(o)
+		}
+	case *eval.Tuple:
+		if len(o.Members) != 0 {
+			v.VariablesReference = s.reference(o)
+		}
+	case *eval.Hash:
+		if len(o.Order) != 0 {
+			v.VariablesReference = s.reference(o)
+		}
+	case *eval.ObjectInstance:
+		v.VariablesReference = s.reference(o)
+	}
+	return v
+}
+
+//outputWriter sends what's written as 'output' events.
+type outputWriter struct {
+	conn     *Conn
+	category string
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	if err := w.conn.Event("output", OutputEventBody{Category: w.category, Output: string(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func decode(msg *Message, v interface{}) string {
+	if len(msg.Arguments) == 0 {
+		return ""
+	}
+	if err := json.Unmarshal(msg.Arguments, v); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+var _ eval.DebugFrontend = (*Server)(nil)
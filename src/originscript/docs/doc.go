@@ -537,6 +537,11 @@ func sortedProps(props []*ast.PropertyDeclStmt, fh *os.File) []*Value {
 	return list
 }
 
+//FuncDoc returns the documentation of a single function, e.g. for an editor's hover.
+func FuncDoc(f *ast.FunctionStatement) *Function {
+	return parseFuncComment(f.Name.Value, preProcessCommentSpecial(f.Doc.Text()), f.Docs())
+}
+
 func parseFuncComment(name string, docComments string, text string) *Function {
 	fn := &Function{
 		Value: &Value{
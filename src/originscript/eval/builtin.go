@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"bytes"
 	"container/list"
 	"database/sql"
 	"encoding/csv"
@@ -15,6 +16,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -205,9 +207,9 @@ func newFileBuiltin(funcName string) *Builtin {
 
 			f, err := os.OpenFile(fname.String, flag, perm)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, IOERROR_CLASS, err)
 			}
-			return &FileObject{File: f, Name: "<file object: " + fname.String + ">"}
+			return &FileObject{File: f, Name: fname.String}
 		},
 	}
 }
@@ -621,6 +623,67 @@ func methodsBuiltin() *Builtin {
 	}
 }
 
+// MethodNames returns the names which could be used after a '.' on 'obj',
+// e.g. 'arr.map()', 'fmt.println()' or 'instanceObj.method()'.
+// It is mainly used by tools, like the REPL's tab completion.
+func MethodNames(obj Object) []string {
+	names := []string{}
+	switch o := obj.(type) {
+	case *ImportedObject:
+		for _, k := range o.Scope.GetKeys() {
+			if o.Exported(k) {
+				names = append(names, k)
+			}
+		}
+		return names
+	case *ObjectInstance:
+		for c := o.Class; c != nil; c = c.Parent {
+			for k := range c.Methods {
+				names = append(names, k)
+			}
+			for k := range c.Properties {
+				names = append(names, k)
+			}
+		}
+		return names
+	case *Class:
+		for c := o; c != nil; c = c.Parent {
+			for k := range c.Methods {
+				names = append(names, k)
+			}
+		}
+		return names
+	case *Hash:
+		//hash keys could be used as 'hashObj.key'(Go functions registered by RegisterFunctions are stored this way)
+		for _, hk := range o.Order {
+			if key, ok := o.Pairs[hk].Key.(*String); ok {
+				names = append(names, key.String)
+			}
+		}
+	}
+
+	t := reflect.TypeOf(obj)
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i).Name
+		if m == "Type" || m == "CallMethod" || m == "HashKey" || m == "Inspect" {
+			continue
+		}
+		//method names are exposed with a lowercase first letter, e.g. 'TrimLeft' => 'trimLeft'
+		r, size := utf8.DecodeRuneInString(m)
+		names = append(names, string(unicode.ToLower(r))+m[size:])
+	}
+	return names
+}
+
+// BuiltinNames returns the names of all the builtin functions.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for k := range builtins {
+		names = append(names, k)
+	}
+	return names
+}
+
 func ordBuiltin() *Builtin {
 	return &Builtin{
 		Fn: func(line string, scope *Scope, args ...Object) Object {
@@ -863,6 +926,122 @@ func assertBuiltin() *Builtin {
 	}
 }
 
+//assertEqual(expected, actual [, message]): the two values must be equal(same as '==').
+func assertEqualBuiltin() *Builtin {
+	return &Builtin{
+		Fn: func(line string, scope *Scope, args ...Object) Object {
+			if len(args) != 2 && len(args) != 3 {
+				return NewError(line, ARGUMENTERROR, "2|3", len(args))
+			}
+
+			if equal(true, args[0], args[1]) {
+				return NIL
+			}
+			return NewError(line, ASSERTIONERROREX, assertMessage("assertEqual", args[2:]), assertDiff(args[0], args[1]))
+		},
+	}
+}
+
+//assertDeepEqual(expected, actual [, message]): the two values must be equal(same as 'deepEqual').
+func assertDeepEqualBuiltin() *Builtin {
+	return &Builtin{
+		Fn: func(line string, scope *Scope, args ...Object) Object {
+			if len(args) != 2 && len(args) != 3 {
+				return NewError(line, ARGUMENTERROR, "2|3", len(args))
+			}
+
+			r := newDeepEqualBuiltin().Fn(line, scope, args[0], args[1])
+			if r == TRUE {
+				return NIL
+			}
+			return NewError(line, ASSERTIONERROREX, assertMessage("assertDeepEqual", args[2:]), assertDiff(args[0], args[1]))
+		},
+	}
+}
+
+//assertThrows(fn [, message]): calling the function 'fn' must throw or report an error.
+func assertThrowsBuiltin() *Builtin {
+	return &Builtin{
+		Fn: func(line string, scope *Scope, args ...Object) Object {
+			if len(args) != 1 && len(args) != 2 {
+				return NewError(line, ARGUMENTERROR, "1|2", len(args))
+			}
+
+			fn, ok := args[0].(*Function)
+			if !ok {
+				return NewError(line, PARAMTYPEERROR, "first", "assertThrows", "*Function", args[0].Type())
+			}
+
+			r := Eval(fn.Literal.Body, extendFunctionScope(fn, []Object{}))
+			if r != nil && (r.Type() == ERROR_OBJ || r.Type() == THROW_OBJ) {
+				return NIL
+			}
+
+			got := NIL.Inspect()
+			if rv, ok := r.(*ReturnValue); ok {
+				got = rv.Value.Inspect()
+			} else if r != nil {
+				got = r.Inspect()
+			}
+			diff := fmt.Sprintf("    expected: an error or throw\n      actual: returned %s\n", got)
+			return NewError(line, ASSERTIONERROREX, assertMessage("assertThrows", args[1:]), diff)
+		},
+	}
+}
+
+//assertMessage returns the user supplied message of an assertion, or the assertion's name.
+func assertMessage(name string, args []Object) string {
+	if len(args) > 0 {
+		if s, ok := args[0].(*String); ok {
+			return s.String
+		}
+		return args[0].Inspect()
+	}
+	return name
+}
+
+//assertDiff reports the difference between the expected and the actual value.
+//Single line values are marked with a '^' at the first different character,
+//multi-line values are compared line by line.
+func assertDiff(expected, actual Object) string {
+	exp, act := expected.Inspect(), actual.Inspect()
+	if expected.Type() != actual.Type() {
+		exp = fmt.Sprintf("%s (%s)", exp, expected.Type())
+		act = fmt.Sprintf("%s (%s)", act, actual.Type())
+	}
+
+	var out bytes.Buffer
+	if !strings.Contains(exp, "\n") && !strings.Contains(act, "\n") {
+		fmt.Fprintf(&out, "    expected: %s\n", exp)
+		fmt.Fprintf(&out, "      actual: %s\n", act)
+
+		e, a := []rune(exp), []rune(act)
+		i := 0
+		for i < len(e) && i < len(a) && e[i] == a[i] {
+			i++
+		}
+		fmt.Fprintf(&out, "              %s^\n", strings.Repeat(" ", i))
+		return out.String()
+	}
+
+	expLines, actLines := strings.Split(exp, "\n"), strings.Split(act, "\n")
+	out.WriteString("    --- expected\n    +++ actual\n")
+	for i := 0; i < len(expLines) || i < len(actLines); i++ {
+		switch {
+		case i >= len(actLines):
+			fmt.Fprintf(&out, "    - %s\n", expLines[i])
+		case i >= len(expLines):
+			fmt.Fprintf(&out, "    + %s\n", actLines[i])
+		case expLines[i] != actLines[i]:
+			fmt.Fprintf(&out, "    - %s\n", expLines[i])
+			fmt.Fprintf(&out, "    + %s\n", actLines[i])
+		default:
+			fmt.Fprintf(&out, "      %s\n", expLines[i])
+		}
+	}
+	return out.String()
+}
+
 func reverseBuiltin() *Builtin {
 	return &Builtin{
 		Fn: func(line string, scope *Scope, args ...Object) Object {
@@ -999,12 +1178,12 @@ func dialTCPBuiltin() *Builtin {
 
 			tcpAddr, err := net.ResolveTCPAddr(netStr.String, addrStr.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			conn, err := net.DialTCP(netStr.String, nil, tcpAddr)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			return &TcpConnObject{Conn: conn, Address: tcpAddr.String()}
@@ -1029,12 +1208,12 @@ func listenTCPBuiltin() *Builtin {
 
 			tcpAddr, err := net.ResolveTCPAddr(netStr.String, addrStr.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			listener, err := net.ListenTCP(netStr.String, tcpAddr)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			return &TCPListenerObject{Listener: listener, Address: tcpAddr.String()}
@@ -1059,12 +1238,12 @@ func dialUDPBuiltin() *Builtin {
 
 			udpAddr, err := net.ResolveUDPAddr(netStr.String, addrStr.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			conn, e := net.DialUDP(netStr.String, nil, udpAddr)
 			if e != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			return &UdpConnObject{Conn: conn, Address: udpAddr.String()}
@@ -1089,12 +1268,12 @@ func dialUnixBuiltin() *Builtin {
 
 			unixAddr, err := net.ResolveUnixAddr(netStr.String, addrStr.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			conn, err := net.DialUnix(netStr.String, nil, unixAddr)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			return &UnixConnObject{Conn: conn, Address: unixAddr.String()}
@@ -1119,12 +1298,12 @@ func listenUnixBuiltin() *Builtin {
 
 			unixAddr, err := net.ResolveUnixAddr(netStr.String, addrStr.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			listener, err := net.ListenUnix(netStr.String, unixAddr)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, NETERROR_CLASS, err)
 			}
 
 			return &UnixListenerObject{Listener: listener, Address: unixAddr.String()}
@@ -1150,7 +1329,7 @@ func dbOpenBuiltin() *Builtin {
 
 			db, err := sql.Open(driverName.String, dataSourceName.String)
 			if err != nil {
-				return NewNil(err.Error())
+				return nilFailure(line, SQLERROR_CLASS, err)
 			}
 
 			return &SqlObject{Db: db, Name: fmt.Sprintf("%s:%s", driverName.String, dataSourceName.String)}
@@ -1482,9 +1661,11 @@ func instanceOfBuiltin() *Builtin {
 				return nativeBoolToBooleanObject(InstanceOf(class.String, instance))
 			case *Class:
 				return nativeBoolToBooleanObject(InstanceOf(class.Name, instance))
+			case *Interface:
+				return nativeBoolToBooleanObject(InstanceOf(class.Name, instance))
 			}
 
-			return NewError(line, GENERICERROR, "is_a/instanceOf expected a class or string for second argument")
+			return NewError(line, GENERICERROR, "is_a/instanceOf expected a class, an interface or string for second argument")
 		},
 	}
 }
@@ -1577,6 +1758,11 @@ func init() {
 		//deepEqual
 		"deepEqual": newDeepEqualBuiltin(),
 
+		//assertions
+		"assertEqual":     assertEqualBuiltin(),
+		"assertDeepEqual": assertDeepEqualBuiltin(),
+		"assertThrows":    assertThrowsBuiltin(),
+
 		//csv
 		"newCsvReader": newCsvReaderBuiltin(),
 		"newCsvWriter": newCsvWriterBuiltin(),
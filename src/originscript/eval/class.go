@@ -30,6 +30,7 @@ type ClassMethod interface {
 type Class struct {
 	Name         string
 	Parent       *Class
+	Interfaces   []*Interface //the interfaces the class implements
 	Members      []*ast.LetStatement
 	Methods      map[string]ClassMethod //BuiltinMethod or Function object
 	Properties   map[string]*ast.PropertyDeclStmt
@@ -80,6 +81,21 @@ func (c *Class) GetProperty(name string) *ast.PropertyDeclStmt {
 	return c.Parent.GetProperty(name)
 }
 
+//GetMemberType returns the annotated type of the member 'name', e.g. 'lit age: int',
+//or nil if it has no annotation.
+func (c *Class) GetMemberType(name string) *ast.TypeExpr {
+	for cls := c; cls != nil; cls = cls.Parent {
+		for _, m := range cls.Members {
+			for _, n := range m.Names {
+				if n.Value == name {
+					return m.Types[name]
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Check whether member, method or property is static or not.
 func (c *Class) IsStatic(val string, kind ClassComponentKind) bool {
 	switch kind {
@@ -252,9 +268,11 @@ func initRootObject() bool {
 					return nativeBoolToBooleanObject(InstanceOf(class.String, self))
 				case *Class:
 					return nativeBoolToBooleanObject(InstanceOf(class.Name, self))
+				case *Interface:
+					return nativeBoolToBooleanObject(InstanceOf(class.Name, self))
 				}
 
-				return NewError(line, GENERICERROR, "is_a/instanceOf expected a class or string for its argument")
+				return NewError(line, GENERICERROR, "is_a/instanceOf expected a class, an interface or string for its argument")
 			},
 		},
 		"classOf": &BuiltinMethod{
@@ -519,6 +537,8 @@ func (p *PropertyInfo) GetAnnotations(line string, scope *Scope, args ...Object)
 
 var _ = initRootObject()
 
+//InstanceOf reports if the instance's class is 'className', or inherits from
+//it, or implements an interface named 'className'.
 func InstanceOf(className string, oi *ObjectInstance) bool {
 	if oi == nil {
 		return false
@@ -529,6 +549,11 @@ func InstanceOf(className string, oi *ObjectInstance) bool {
 		if cls.Name == className {
 			return true
 		}
+		for _, iface := range cls.Interfaces {
+			if iface.Is(className) {
+				return true
+			}
+		}
 		if cls.Parent == nil {
 			return false
 		}
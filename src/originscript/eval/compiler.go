@@ -0,0 +1,440 @@
+package eval
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"originscript/ast"
+	"originscript/token"
+	"sync"
+)
+
+//Bytecode is the compiled program or block. The nodes the compiler does not
+//handle are kept in 'Nodes' and evaluated with the tree-walker(OpEval), so the
+//whole language works with the VM.
+type Bytecode struct {
+	Node         ast.Node //the compiled program or block
+	Instructions Instructions
+	Constants    []Object
+	Nodes        []ast.Node
+	Frame        *ast.FunctionLiteral //the slots of the local variables, see 'local'
+}
+
+var errTooLarge = errors.New("too large to compile")
+
+var bytecodeMux sync.RWMutex //guards the 'Code' of the nodes
+
+//Compile compiles a program or a block statement to bytecode.
+func Compile(node ast.Node) (code *Bytecode, err error) {
+	c := &compiler{code: &Bytecode{Node: node}}
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errTooLarge {
+				panic(r)
+			}
+			code, err = nil, errTooLarge
+		}
+	}()
+
+	switch node := node.(type) {
+	case *ast.Program:
+		c.code.Frame = programFrame(node.Statements)
+		c.statements(node.Statements, false)
+	case *ast.BlockStatement:
+		c.statements(node.Statements, false)
+	default:
+		return nil, errors.New("only a program or a block can be compiled")
+	}
+	c.emit(OpResult)
+	return c.code, nil
+}
+
+//compiled returns the bytecode of the node, it's compiled on first use and
+//kept on the node like the resolver's slots, so it goes away with the node.
+//A nil result means the node cannot be compiled.
+func compiled(node ast.Node) *Bytecode {
+	var slot *interface{}
+	switch node := node.(type) {
+	case *ast.Program:
+		slot = &node.Code
+	case *ast.BlockStatement:
+		slot = &node.Code
+	default:
+		return nil
+	}
+
+	bytecodeMux.RLock()
+	code := *slot
+	bytecodeMux.RUnlock()
+	if code == nil {
+		code, _ = Compile(node) //a nil *Bytecode if it cannot be compiled, so it's not retried
+		bytecodeMux.Lock()
+		*slot = code
+		bytecodeMux.Unlock()
+	}
+	return code.(*Bytecode)
+}
+
+type compiler struct {
+	code *Bytecode
+}
+
+//programFrame assigns slots to the variables of the program scope: the names
+//declared by the statements of the program, and the names assigned outside of
+//the functions, an assignment sets an unknown variable in the root scope.
+func programFrame(stmts []ast.Statement) *ast.FunctionLiteral {
+	names := []string{}
+	consts := make(map[string]bool)
+	declared(stmts, &names, consts)
+
+	visited := make(map[ast.Node]bool)
+	var assigned func(node ast.Node)
+	assigned = func(node ast.Node) {
+		if resolverIsNil(node) || visited[node] {
+			return
+		}
+		visited[node] = true
+
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			return
+		case *ast.AssignExpression:
+			if ident, ok := node.Name.(*ast.Identifier); ok {
+				names = append(names, ident.Value)
+			}
+		}
+		for _, child := range resolverChildren(node) {
+			assigned(child)
+		}
+	}
+	for _, stmt := range stmts {
+		assigned(stmt)
+	}
+
+	fl := &ast.FunctionLiteral{}
+	addSlots(fl, names, consts)
+	return fl
+}
+
+//local returns the slot of the identifier if it's a local variable of the
+//frame of the bytecode, or -1 if it's looked up by name. The identifiers of
+//a program are resolved by the compiler(see programFrame), the ones of a
+//function body by the resolver.
+func (c *compiler) local(i *ast.Identifier) int {
+	if i.Func == nil {
+		if _, ok := c.code.Node.(*ast.Program); ok {
+			if idx, ok := c.code.Frame.SlotIndex[i.Value]; ok {
+				return idx
+			}
+		}
+		return -1
+	}
+
+	if c.code.Frame == nil {
+		c.code.Frame = i.Func
+	}
+	if i.Func != c.code.Frame {
+		return -1
+	}
+	return i.Slot
+}
+
+//typedOps are the infix operators with their own instruction.
+var typedOps = map[string]Opcode{
+	"+":  OpAdd,
+	"-":  OpSub,
+	"*":  OpMul,
+	"/":  OpDiv,
+	"%":  OpMod,
+	"==": OpEqual,
+	"!=": OpNotEqual,
+	"<":  OpLess,
+	"<=": OpLessEq,
+	">":  OpGreater,
+	">=": OpGreaterEq,
+}
+
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	pos := len(c.code.Instructions)
+	c.code.Instructions = append(c.code.Instructions, byte(op))
+	for _, o := range operands {
+		if o < 0 || o > math.MaxUint16 {
+			panic(errTooLarge)
+		}
+		c.code.Instructions = append(c.code.Instructions, byte(o>>8), byte(o))
+	}
+	return pos
+}
+
+//patch sets the operand of a jump to the current position.
+func (c *compiler) patch(pos int, operand int) {
+	target := len(c.code.Instructions)
+	if target > math.MaxUint16 {
+		panic(errTooLarge)
+	}
+	binary.BigEndian.PutUint16(c.code.Instructions[pos+1+2*operand:], uint16(target))
+}
+
+func (c *compiler) pos() int {
+	return len(c.code.Instructions)
+}
+
+func (c *compiler) constant(obj Object) int {
+	c.code.Constants = append(c.code.Constants, obj)
+	return len(c.code.Constants) - 1
+}
+
+func (c *compiler) node(node ast.Node) int {
+	c.code.Nodes = append(c.code.Nodes, node)
+	return len(c.code.Nodes) - 1
+}
+
+//statements compiles the statements of a block, if 'keepValue' is true, the
+//value of the last statement is left on the stack.
+func (c *compiler) statements(stmts []ast.Statement, keepValue bool) {
+	for i, stmt := range stmts {
+		c.compile(stmt)
+		if !keepValue || i != len(stmts)-1 {
+			c.emit(OpPop)
+		}
+	}
+	if keepValue && len(stmts) == 0 {
+		c.emit(OpNil)
+	}
+}
+
+//body compiles the body of an 'if' or a loop, which is a block or a single expression.
+func (c *compiler) body(node ast.Node, keepValue bool) {
+	if block, ok := node.(*ast.BlockStatement); ok {
+		c.statements(block.Statements, keepValue)
+		return
+	}
+	c.compile(node)
+	if !keepValue {
+		c.emit(OpPop)
+	}
+}
+
+func (c *compiler) compile(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.ExpressionStatement:
+		c.compile(node.Expression)
+	case *ast.IntegerLiteral:
+		c.emit(OpConstant, c.constant(evalIntegerLiteral(node)))
+	case *ast.UIntegerLiteral:
+		c.emit(OpConstant, c.constant(evalUIntegerLiteral(node)))
+	case *ast.FloatLiteral:
+		c.emit(OpConstant, c.constant(evalFloatLiteral(node)))
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.constant(evalStringLiteral(node)))
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+	case *ast.NilLiteral:
+		c.emit(OpNil)
+	case *ast.Identifier:
+		if idx := c.local(node); idx >= 0 {
+			c.emit(OpGetLocal, idx, c.node(node))
+			return
+		}
+		c.emit(OpGet, c.node(node))
+	case *ast.PrefixExpression:
+		c.compile(node.Right)
+		c.emit(OpPrefix, c.node(node))
+	case *ast.InfixExpression:
+		c.compile(node.Left)
+		c.compile(node.Right)
+		if op, ok := typedOps[node.Operator]; ok && node.Token.Type != token.UDO && !isMetaOperators(node.Token.Type) {
+			c.emit(op, c.node(node))
+			return
+		}
+		c.emit(OpInfix, c.node(node))
+	case *ast.PostfixExpression:
+		c.compile(node.Left)
+		c.emit(OpPostfix, c.node(node))
+	case *ast.AssignExpression:
+		c.compile(node.Value)
+		if ident, ok := node.Name.(*ast.Identifier); ok && node.Token.Literal == "=" {
+			if idx := c.local(ident); idx >= 0 {
+				c.emit(OpAssignLocal, idx, c.node(node))
+				return
+			}
+		}
+		c.emit(OpAssign, c.node(node))
+	case *ast.LetStatement:
+		c.letStatement(node)
+	case *ast.IfExpression:
+		c.ifExpression(node)
+	case *ast.WhileLoop:
+		c.whileLoop(node)
+	case *ast.ForLoop:
+		c.forLoop(node)
+	case *ast.BreakExpression:
+		c.emit(OpBreak)
+	case *ast.ContinueExpression:
+		c.emit(OpContinue)
+	case *ast.ReturnStatement:
+		for _, value := range node.ReturnValues {
+			c.compile(value)
+		}
+		c.emit(OpReturn, len(node.ReturnValues))
+	case *ast.CallExpression:
+		c.callExpression(node)
+	case *ast.ArrayLiteral:
+		if node.CreationCount != nil {
+			c.emit(OpEval, c.node(node))
+			return
+		}
+		for _, member := range node.Members {
+			c.compile(member)
+		}
+		c.emit(OpArray, len(node.Members))
+	default:
+		c.emit(OpEval, c.node(node))
+	}
+}
+
+func (c *compiler) letStatement(l *ast.LetStatement) {
+	//only the simple 'lit name = value', e.g. not the destructing assignment or the class members
+	if l.DestructingFlag || len(l.Names) != 1 || len(l.Values) != 1 || l.Names[0].Token.Type == token.UNDERSCORE ||
+		l.StaticFlag || l.ModifierLevel != ast.ModifierDefault || len(l.Annotations) != 0 || len(l.Types) != 0 {
+		c.emit(OpEval, c.node(l))
+		return
+	}
+	c.compile(l.Values[0])
+	if idx := c.local(l.Names[0]); idx >= 0 {
+		c.emit(OpLitLocal, idx, c.node(l))
+		return
+	}
+	c.emit(OpLit, c.node(l))
+}
+
+func (c *compiler) ifExpression(ie *ast.IfExpression) {
+	var ends []int
+	for _, cond := range ie.Conditions {
+		c.compile(cond.Cond)
+		next := c.emit(OpJumpIfFalse, 0)
+		c.body(cond.Body, true)
+		ends = append(ends, c.emit(OpJump, 0))
+		c.patch(next, 0)
+	}
+
+	if ie.Alternative != nil {
+		c.body(ie.Alternative, true)
+	} else {
+		c.emit(OpNil)
+	}
+	for _, end := range ends {
+		c.patch(end, 0)
+	}
+}
+
+//    OpEnterScope
+//    OpLoop break, continue
+//continue:
+//    <condition>
+//    OpJumpIfFalse break
+//    <block>
+//    OpJump continue
+//break:
+//    OpEndLoop
+//    OpLeaveScope
+//    OpNil
+func (c *compiler) whileLoop(wl *ast.WhileLoop) {
+	if _, ok := wl.Condition.(*ast.DiamondExpr); ok { //sets '$_'
+		c.emit(OpEval, c.node(wl))
+		return
+	}
+
+	c.emit(OpEnterScope)
+	loop := c.emit(OpLoop, 0, 0)
+	c.patch(loop, 1)
+	cont := c.pos()
+	c.compile(wl.Condition)
+	exit := c.emit(OpJumpIfFalse, 0)
+	c.body(wl.Block, false)
+	c.emit(OpJump, cont)
+	c.patch(loop, 0)
+	c.patch(exit, 0)
+	c.emit(OpEndLoop)
+	c.emit(OpLeaveScope)
+	c.emit(OpNil)
+}
+
+//Each iteration runs in a new scope, like the tree-walker:
+//    OpEnterScope
+//    <init>
+//    <condition>
+//    OpJumpIfFalse exit
+//    OpLoop break, continue
+//body:
+//    OpEnterScope
+//    <block>
+//continue:
+//    <update>
+//    <condition>
+//    OpLeaveScope
+//    OpJumpIfFalse break
+//    OpJump body
+//break:
+//    OpEndLoop
+//exit:
+//    OpLeaveScope
+//    OpNil
+func (c *compiler) forLoop(fl *ast.ForLoop) {
+	if fl.Cond == nil {
+		c.emit(OpEval, c.node(fl))
+		return
+	}
+
+	c.emit(OpEnterScope)
+	if fl.Init != nil {
+		c.compile(fl.Init)
+		c.emit(OpPop)
+	}
+	c.compile(fl.Cond)
+	exit := c.emit(OpJumpIfFalse, 0)
+	loop := c.emit(OpLoop, 0, 0)
+
+	body := c.pos()
+	c.emit(OpEnterScope)
+	c.body(fl.Block, false)
+	c.patch(loop, 1)
+	if fl.Update != nil {
+		c.compile(fl.Update)
+		c.emit(OpPop)
+	}
+	c.compile(fl.Cond)
+	c.emit(OpLeaveScope)
+	brk := c.emit(OpJumpIfFalse, 0)
+	c.emit(OpJump, body)
+
+	c.patch(loop, 0)
+	c.patch(brk, 0)
+	c.emit(OpEndLoop)
+	c.patch(exit, 0)
+	c.emit(OpLeaveScope)
+	c.emit(OpNil)
+}
+
+//    OpCallee call, end
+//    <arguments>
+//    OpCall call
+//end:
+func (c *compiler) callExpression(call *ast.CallExpression) {
+	if _, ok := call.Function.(*ast.Identifier); !ok || call.Awaited {
+		c.emit(OpEval, c.node(call))
+		return
+	}
+
+	idx := c.node(call)
+	callee := c.emit(OpCallee, idx, 0)
+	for _, arg := range call.Arguments {
+		c.compile(arg)
+	}
+	c.emit(OpCall, idx)
+	c.patch(callee, 1)
+}
@@ -2,6 +2,7 @@ package eval
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"originscript/ast"
@@ -11,6 +12,7 @@ import (
 	"originscript/token"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -24,6 +26,13 @@ const (
 	DEL_BP
 )
 
+//where 'Stepping' stops
+const (
+	STEP_IN   = iota //at the next line, even in a callee
+	STEP_OVER        //at the next line of the current function or of its callers
+	STEP_OUT         //when the current function returns
+)
+
 type DbgInfo struct {
 	filename string
 	line     int  //node's begin line
@@ -38,23 +47,70 @@ type Debugger struct {
 	Functions map[string]*ast.FunctionLiteral
 
 	//for breakpoint
-	Breakpoints map[string]bool //key: 'filename:line'
+	Breakpoints map[string]*Breakpoint //key: 'filename:line'
+	lastLine    string                 //'filename:line:depth' of the last line reached
+	lineNodes   []ast.Node             //the nodes evaluated since the last line was reached
 
 	Node  ast.Node
 	Scope *Scope
 
-	Stepping bool
+	Stepping  bool
+	stepMode  int
+	stepDepth int //the call depth where the step started
+
+	frame int //the frame selected by 'frame N', 0 is the innermost
+
+	//PostMortem records where the program fails, see EnterPostMortem.
+	PostMortem bool
+	failure    *failure
+
+	//Frontend replaces the terminal prompt(ProcessCommand) when it's set,
+	//e.g. by the Debug Adapter Protocol server.
+	Frontend DebugFrontend
 
+	reader      *bufio.Reader //the commands, from stdin
 	prevCommand string
 	showPrompt  bool
 	listLine    int
 }
 
+//Breakpoint is a breakpoint, or a logpoint if it has a 'Log' message.
+type Breakpoint struct {
+	Condition string //an expression, the breakpoint is hit only if it's true
+	HitOp     string //'>=', '>' or '==', the breakpoint stops only if 'Hits HitOp HitCount'
+	HitCount  int
+	Log       string //the message printed instead of stopping, '{expr}' is replaced by the value of 'expr'
+	Hits      int    //the number of times the breakpoint was hit
+}
+
+//failure is where an error(or a 'throw') was returned first.
+type failure struct {
+	node   ast.Node
+	scope  *Scope
+	frames []CallFrame //the call stack, it's popped when the error is returned
+	value  Object
+}
+
+//DebugFrontend decides where the program stops, instead of 'Stepping' and the prompt.
+type DebugFrontend interface {
+	//Line is called when a line is reached, before it's evaluated('d.Node' and
+	//'d.Scope' are set), it returns when the program should resume.
+	Line(d *Debugger, breakpoint bool)
+}
+
+//DebugFrame is a frame of the call stack, with the node evaluated in it.
+type DebugFrame struct {
+	Name  string //the function's name, ModuleFrame for the outermost frame
+	Node  ast.Node
+	Scope *Scope
+}
+
 func NewDebugger() *Debugger {
 	d := &Debugger{}
 	d.SrcLinesCache = make(map[string][]string)
-	d.Breakpoints = make(map[string]bool)
+	d.Breakpoints = make(map[string]*Breakpoint)
 	d.showPrompt = true
+	d.reader = bufio.NewReader(os.Stdin)
 	d.Stepping = true
 	d.prevCommand = ""
 
@@ -63,8 +119,13 @@ func NewDebugger() *Debugger {
 
 // Add a breakpoint at source line
 func (d *Debugger) AddBP(filename string, line int) {
+	d.SetBP(filename, line, &Breakpoint{})
+}
+
+// Add a breakpoint at source line, replacing the existing one
+func (d *Debugger) SetBP(filename string, line int, bp *Breakpoint) {
 	key := fmt.Sprintf("%s:%d", strings.TrimSpace(filename), line)
-	d.Breakpoints[key] = true
+	d.Breakpoints[key] = bp
 }
 
 // Delete a breakpoint at source line
@@ -78,12 +139,90 @@ func (d *Debugger) DelBP(filename string, line int) {
 // Check if a source line is at a breakpoint
 func (d *Debugger) IsBP(filename string, line int) bool {
 	key := fmt.Sprintf("%s:%d", strings.TrimSpace(filename), line)
-	for k := range d.Breakpoints {
-		if k == key {
+	_, ok := d.Breakpoints[key]
+	return ok
+}
+
+//HitBP reports if the program should stop at a source line evaluated in 'scope': the
+//breakpoint's condition is true and its hit count is reached. A logpoint prints its
+//message to the program's output, and never stops.
+func (d *Debugger) HitBP(filename string, line int, scope *Scope) bool {
+	bp, ok := d.Breakpoints[fmt.Sprintf("%s:%d", strings.TrimSpace(filename), line)]
+	if !ok {
+		return false
+	}
+
+	if bp.Condition != "" {
+		cond := d.Evaluate(bp.Condition, scope)
+		if cond.Type() == ERROR_OBJ { //stop, so the condition can be fixed
+			fmt.Fprintf(scope.Writer, "(!) Breakpoint condition '%s': %s\n", bp.Condition, cond.Inspect())
 			return true
 		}
+		if !IsTrue(cond) {
+			return false
+		}
+	}
+
+	bp.Hits++
+	switch bp.HitOp {
+	case ">=":
+		ok = bp.Hits >= bp.HitCount
+	case ">":
+		ok = bp.Hits > bp.HitCount
+	case "==":
+		ok = bp.Hits == bp.HitCount
+	}
+	if !ok {
+		return false
+	}
+
+	if bp.Log != "" {
+		fmt.Fprintln(scope.Writer, d.interpolate(bp.Log, scope))
+		return false
+	}
+	return true
+}
+
+//FunctionBP returns the source line of a function's breakpoint: its first statement.
+func (d *Debugger) FunctionBP(name string) (filename string, line int, ok bool) {
+	f, ok := d.Functions[name]
+	if !ok {
+		return "", 0, false
+	}
+	return filepath.Base(f.Pos().Filename), f.StmtPos().Line, true
+}
+
+//ParseHitCondition parses a hit condition, e.g. '>=5', '==2', or '5'(same as '>=5').
+func ParseHitCondition(s string) (op string, count int, err error) {
+	s = strings.TrimSpace(s)
+	op = ">="
+	for _, o := range []string{">=", "==", ">"} {
+		if strings.HasPrefix(s, o) {
+			op, s = o, s[len(o):]
+			break
+		}
+	}
+	count, err = strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || count < 0 {
+		return "", 0, fmt.Errorf("invalid hit condition '%s', expected e.g. '>=5'", s)
+	}
+	return op, count, nil
+}
+
+//interpolate replaces the '{expr}' of a logpoint's message by the value of 'expr'.
+func (d *Debugger) interpolate(msg string, scope *Scope) string {
+	var out bytes.Buffer
+	for {
+		start := strings.Index(msg, "{")
+		end := strings.Index(msg, "}")
+		if start == -1 || end < start {
+			out.WriteString(msg)
+			return out.String()
+		}
+		out.WriteString(msg[:start])
+		out.WriteString(d.Evaluate(msg[start+1:end], scope).Inspect())
+		msg = msg[end+1:]
 	}
-	return false
 }
 
 func (d *Debugger) SetNodeAndScope(node ast.Node, scope *Scope) {
@@ -108,55 +247,33 @@ func (d *Debugger) ShowBanner() {
 }
 
 func (d *Debugger) ProcessCommand() {
-	for {
-		if !d.showPrompt {
-			break
-		}
-
-		p := d.Node.Pos()
-
-		/* check if same line has been executed, if so, we need not to show the same line more than once. e.g.
-			  println(len("Program end."))
-		   Above line have two CallExpressions(println & len),
-		   so when we press next, it will show the same line again. we want to avoid this
-		*/
-		entered := false
-		for _, inf := range d.DbgInfos {
-			if p.Filename == inf.filename && p.Line == inf.line && inf.entered {
-				entered = true
-				break
-			}
-		}
-		if entered {
-			break
-		}
+	if !d.showPrompt {
+		return
+	}
 
-		contents, ok := d.SrcLinesCache[p.Filename]
-		if ok {
-			d.SrcLines = contents
-		} else {
-			content, _ := ioutil.ReadFile(p.Filename)
-			lines := strings.Split(string(content), "\n")
-			//pre-append an empty line, so the Lines start with 1, not zero.
-			lines = append([]string{""}, lines...)
-			d.SrcLinesCache[p.Filename] = lines
-			d.SrcLines = lines
-		}
+	//a line with several nodes that can stop(e.g. 'println(len(a))') is shown once per
+	//evaluation, 'MessageReceived' calls it only when the line is reached.
+	p := d.Node.Pos()
 
-		for _, inf := range d.DbgInfos {
-			if p.Filename == inf.filename && p.Line == inf.line {
-				inf.entered = true
-				break
-			}
-		}
+	contents, ok := d.SrcLinesCache[p.Filename]
+	if ok {
+		d.SrcLines = contents
+	} else {
+		content, _ := ioutil.ReadFile(p.Filename)
+		lines := strings.Split(string(content), "\n")
+		//pre-append an empty line, so the Lines start with 1, not zero.
+		lines = append([]string{""}, lines...)
+		d.SrcLinesCache[p.Filename] = lines
+		d.SrcLines = lines
+	}
 
-		fmt.Printf("\n%d\t\t%s", p.Line, d.SrcLines[p.Line])
+	fmt.Printf("\n%d\t\t%s", p.Line, d.SrcLines[p.Line])
+	for {
 		fmt.Print("\nDebugger $> ")
 
 		fmt.Print("\x1b[1m\x1b[36m")
 
-		reader := bufio.NewReader(os.Stdin)
-		command, _ := reader.ReadString('\n')
+		command, _ := d.reader.ReadString('\n')
 		command = strings.TrimSpace(command)
 		if command == "" && d.prevCommand != "" {
 			command = d.prevCommand
@@ -167,12 +284,52 @@ func (d *Debugger) ProcessCommand() {
 		d.Stepping = false
 		if strings.Compare("$c", command) == 0 || strings.Compare("continue", command) == 0 {
 			d.prevCommand = command
+			d.frame = 0
 			break
 		} else if strings.Compare("$n", command) == 0 || strings.Compare("next", command) == 0 {
 			d.prevCommand = command
-			d.Stepping = true
+			d.step(STEP_OVER)
+			break
+		} else if strings.Compare("$s", command) == 0 || strings.Compare("step", command) == 0 {
+			d.prevCommand = command
+			d.step(STEP_IN)
+			break
+		} else if strings.Compare("$f", command) == 0 || strings.Compare("finish", command) == 0 {
+			d.prevCommand = command
+			if len(d.Scope.CallStack.Frames) == 0 {
+				fmt.Println("(!) 'finish' is not meaningful in the outermost frame.")
+				continue
+			}
+			d.step(STEP_OUT)
 			break
-		} else if strings.HasPrefix(command, "$b") || strings.HasPrefix(command, "bp ") {
+		} else if strings.Compare("$bt", command) == 0 || strings.Compare("bt", command) == 0 {
+			d.prevCommand = command
+			for i, f := range d.Frames() {
+				d.printFrame(i, f)
+			}
+		} else if strings.HasPrefix(command, "$fr") || strings.HasPrefix(command, "frame ") {
+			d.prevCommand = command
+			frames := d.Frames()
+			arr := strings.Fields(command)
+			n := -1
+			if len(arr) == 2 {
+				n, _ = strconv.Atoi(arr[1])
+			}
+			if n < 0 || n >= len(frames) {
+				fmt.Printf("(!) Frame number expected, between 0 and %d.\n", len(frames)-1)
+			} else {
+				d.frame = n
+				d.printFrame(n, frames[n])
+			}
+		} else if strings.Compare("$lo", command) == 0 || strings.Compare("locals", command) == 0 {
+			d.prevCommand = command
+			d.printVariables(d.FrameScope(d.frame), true)
+		} else if strings.Compare("$g", command) == 0 || strings.Compare("globals", command) == 0 {
+			d.prevCommand = command
+			frames := d.Frames()
+			d.printVariables(frames[len(frames)-1].Scope, false)
+		} else if strings.HasPrefix(command, "$b") || strings.HasPrefix(command, "bp ") || command == "bp" ||
+			strings.HasPrefix(command, "$lp") || strings.HasPrefix(command, "log ") {
 			d.prevCommand = command
 			d.processBreakPointCmd(command, ADD_BP)
 		} else if strings.HasPrefix(command, "$d") || strings.HasPrefix(command, "del ") {
@@ -182,18 +339,8 @@ func (d *Debugger) ProcessCommand() {
 			strings.HasPrefix(command, "$e") || strings.HasPrefix(command, "eval ") {
 			d.prevCommand = command
 			exp := strings.Split(command, " ")[1:]
-			lex := lexer.New("", strings.Join(exp, ""))
-			wd, _ := os.Getwd()
-			p := parser.New(lex, wd)
-			oldLines := d.SrcLines
-			oldNode := d.Node
-			d.showPrompt = false
-			program := p.ParseProgram()
-			aval := Eval(program, NewScope(d.Scope, nil))
+			aval := d.Evaluate(strings.Join(exp, ""), d.FrameScope(d.frame))
 			fmt.Printf("%s\n\n", aval.Inspect())
-			d.SrcLines = oldLines
-			d.Node = oldNode
-			d.showPrompt = true
 		} else if strings.Compare("$exit", command) == 0 || strings.Compare("quit", command) == 0 ||
 			strings.Compare("bye", command) == 0 || strings.Compare("q", command) == 0 {
 			os.Exit(0)
@@ -218,11 +365,168 @@ func (d *Debugger) ProcessCommand() {
 			}
 			d.prevCommand = command
 		} else {
-			fmt.Printf("Undefined command: '%s'. Expected [ $c | $n | $b | $d | $p | $exit | $l ] <WITH OPTIONS>.\n", command)
+			fmt.Printf("Undefined command: '%s'. Expected [ $c | $n | $s | $f | $b | $lp | $d | $p | $bt | $fr | $lo | $g | $exit | $l ] <WITH OPTIONS>.\n", command)
 		}
 	} //end for
 }
 
+//failed records the node where an error was returned, with its scope and call stack.
+func (d *Debugger) failed(node ast.Node, scope *Scope, val Object) {
+	if !d.showPrompt { //evaluating an expression
+		return
+	}
+	if _, ok := node.(*ast.Program); ok { //an unhandled 'throw' is converted to an error
+		return
+	}
+	if d.failure != nil && d.failure.value == val { //returned by the callers of the failing node
+		return
+	}
+	frames := make([]CallFrame, len(scope.CallStack.Frames))
+	copy(frames, scope.CallStack.Frames)
+	d.failure = &failure{node: node, scope: scope, frames: frames, value: val}
+}
+
+//handled forgets the failure recorded for 'val', it's caught by a catch clause.
+func (d *Debugger) handled(val Object) {
+	if d.failure != nil && d.failure.value == val {
+		d.failure = nil
+	}
+}
+
+//EnterPostMortem shows the prompt at the node where the program failed, the node's
+//scope and call stack are restored, so they can be inspected. It returns false if
+//no failure was recorded.
+func (d *Debugger) EnterPostMortem() bool {
+	f := d.failure
+	if f == nil {
+		return false
+	}
+	f.scope.CallStack.Frames = f.frames
+	d.SetNodeAndScope(f.node, f.scope)
+	d.frame = 0
+
+	p := f.node.Pos()
+	fmt.Printf("\n(!) Post-mortem: failed at '%s:%d', inspect with [ $bt | $fr | $lo | $g | $p ], exit with $c.\n", filepath.Base(p.Filename), p.Line)
+	d.ProcessCommand()
+	return true
+}
+
+func (d *Debugger) step(mode int) {
+	d.Stepping = true
+	d.stepMode = mode
+	d.stepDepth = len(d.Scope.CallStack.Frames)
+	d.frame = 0
+}
+
+//stepStops reports if stepping stops at a line evaluated at the call depth 'depth'.
+func (d *Debugger) stepStops(depth int) bool {
+	switch d.stepMode {
+	case STEP_OVER:
+		return depth <= d.stepDepth
+	case STEP_OUT:
+		return depth < d.stepDepth
+	}
+	return true
+}
+
+//Frames returns the frames of the call stack, the innermost first. The scope of
+//the outermost frame(the top level of the program) is the global scope.
+func (d *Debugger) Frames() []DebugFrame {
+	var result []DebugFrame
+	node, scope := d.Node, d.Scope
+	callFrames := d.Scope.CallStack.Frames
+	for i := len(callFrames) - 1; i >= 0; i-- {
+		call := callFrames[i].CurrentCall
+		result = append(result, DebugFrame{Name: call.Function.String(), Node: node, Scope: scope})
+		node = call
+		if i > 0 {
+			scope = callFrames[i-1].FuncScope
+		}
+	}
+	for scope.parentScope != nil { //the global scope, even if stopped in a block of the top level
+		scope = scope.parentScope
+	}
+	return append(result, DebugFrame{Name: ModuleFrame, Node: node, Scope: scope})
+}
+
+//FrameScope returns the scope the variables of the frame 'i' are looked up in. For the
+//innermost frame, it is the current scope, which can be the scope of a block in the frame.
+func (d *Debugger) FrameScope(i int) *Scope {
+	if i == 0 {
+		return d.Scope
+	}
+	return d.Frames()[i].Scope
+}
+
+func (d *Debugger) printFrame(i int, f DebugFrame) {
+	p := f.Node.Pos()
+	marker := " "
+	if i == d.frame {
+		marker = "*"
+	}
+	fmt.Printf("%s#%d  %s at %s:%d\n", marker, i, f.Name, filepath.Base(p.Filename), p.Line)
+}
+
+//Variables returns the sorted names of the variables of 'scope' and their values.
+//With 'chain', the variables of its parent scopes(up to the global scope, excluded)
+//are included, unless they are shadowed. The internal names(e.g. '@_', 'this') are omitted.
+func (d *Debugger) Variables(scope *Scope, chain bool) ([]string, map[string]Object) {
+	var names []string
+	values := make(map[string]Object)
+	for ; scope != nil; scope = scope.parentScope {
+		if chain && scope.parentScope == nil {
+			break
+		}
+		scope.RLock()
+		scope.each(func(name string, value Object) {
+			if _, ok := values[name]; !ok && name != "this" && !strings.HasPrefix(name, "@") {
+				names = append(names, name)
+				values[name] = value
+			}
+		})
+		scope.RUnlock()
+		if !chain {
+			break
+		}
+	}
+	sort.Strings(names)
+	return names, values
+}
+
+func (d *Debugger) printVariables(scope *Scope, chain bool) {
+	names, values := d.Variables(scope, chain)
+	if len(names) == 0 {
+		fmt.Println("(!) No variables.")
+	}
+	for _, name := range names {
+		fmt.Printf("%s = %s\n", name, values[name].Inspect())
+	}
+}
+
+//Evaluate evaluates 'input' in a new scope of 'scope', the evaluation never stops
+//at a breakpoint.
+func (d *Debugger) Evaluate(input string, scope *Scope) Object {
+	lex := lexer.New("", input)
+	wd, _ := os.Getwd()
+	p := parser.New(lex, wd)
+	oldLines := d.SrcLines
+	oldNode := d.Node
+	oldScope := d.Scope
+	d.showPrompt = false
+	defer func() {
+		d.SrcLines = oldLines
+		d.Node = oldNode
+		d.Scope = oldScope
+		d.showPrompt = true
+	}()
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &Error{Kind: GENERICERROR, Message: strings.Join(p.Errors(), "\n")}
+	}
+	return Eval(program, NewScope(scope, nil))
+}
+
 // Check if node can be stopped, some nodes cannot be stopped,
 // e.g. 'InfixExpression', 'IntegerLiteral'
 func (d *Debugger) CanStop() bool {
@@ -262,6 +566,8 @@ func (d *Debugger) CanStop() bool {
 		flag = true
 	case *ast.EnumStatement:
 		flag = true
+	case *ast.InterfaceStatement:
+		flag = true
 	case *ast.IfExpression:
 		flag = true
 	case *ast.IfMacroStatement:
@@ -270,6 +576,8 @@ func (d *Debugger) CanStop() bool {
 		flag = true
 	case *ast.CaseExpr:
 		flag = true
+	case *ast.MatchExpr:
+		flag = true
 	case *ast.DoLoop:
 		flag = true
 	case *ast.WhileLoop:
@@ -323,14 +631,42 @@ func (d *Debugger) MessageReceived(msg message.Message) {
 	case message.EVAL_LINE:
 		line := ctx.N[0].Pos().Line
 		filename := filepath.Base(ctx.N[0].Pos().Filename)
-		if d.Stepping {
+		if !d.showPrompt { //evaluating an expression
+			break
+		}
+
+		//a line is evaluated once per node that can stop(e.g. 'println(len(a))'), it's
+		//reached(and its breakpoint hit) by the first one only. A node evaluated again
+		//reaches the line again, e.g. the body of a loop written on the loop's line.
+		depth := len(ctx.S.CallStack.Frames)
+		key := fmt.Sprintf("%s:%d:%d", ctx.N[0].Pos().Filename, line, depth)
+		reached := key != d.lastLine
+		for _, n := range d.lineNodes {
+			reached = reached || n == ctx.N[0]
+		}
+		if reached {
+			d.lastLine = key
+			d.lineNodes = d.lineNodes[:0]
+		}
+		d.lineNodes = append(d.lineNodes, ctx.N[0])
+		if !reached {
+			break
+		}
+		hit := d.HitBP(filename, line, ctx.S)
+
+		if d.Frontend != nil {
+			d.Frontend.Line(d, hit)
+		} else if d.Stepping && d.stepStops(depth) {
 			d.ProcessCommand()
-		} else if d.IsBP(filename, line) {
+		} else if hit {
 			fmt.Printf("\n(!) Breakpoint: '%s:%d'\n", filename, line)
 			d.ProcessCommand()
 		}
 
 	case message.CALL:
+		if d.Frontend != nil || d.PostMortem {
+			break
+		}
 		 c := ctx.N[0].(*ast.CallExpression)
 		 fn := c.Function.String()
 		 for funcName, f := range d.Functions {
@@ -340,6 +676,9 @@ func (d *Debugger) MessageReceived(msg message.Message) {
 		 	}
 		 }
 	case message.METHOD_CALL:
+		if d.Frontend != nil || d.PostMortem {
+			break
+		}
 		 mc := ctx.N[0].(*ast.MethodCallExpression)
 		obj := mc.Object.String()
 		 if call, ok := mc.Call.(*ast.CallExpression); ok {
@@ -353,6 +692,9 @@ func (d *Debugger) MessageReceived(msg message.Message) {
 		 }
 
 	case message.RETURN:
+		if d.Frontend != nil || d.PostMortem {
+			break
+		}
 		 r := ctx.N[0].(*ast.ReturnStatement)
 		  line := r.Pos().Line
 		   for funcName, f := range d.Functions {
@@ -364,47 +706,101 @@ func (d *Debugger) MessageReceived(msg message.Message) {
 	}
 }
 
+/* The breakpoint commands are:
+     bp [file:]line|function [hits>=N] [if <expr>]
+     log [file:]line|function <message with {expr}>
+     del [file:]line|function
+   'bp' without arguments lists the breakpoints.
+*/
 func (d *Debugger) processBreakPointCmd(command string, add_or_del int) {
 	p := d.Node.Pos()
 
-	arr := strings.Split(command, " ")
+	arr := strings.Fields(command)
 	if len(arr) < 2 {
-		fmt.Println("(!) Line number expected.")
+		if add_or_del == ADD_BP {
+			d.listBreakpoints()
+		} else {
+			fmt.Println("(!) Line number expected.")
+		}
+		return
+	}
+
+	//get filename & line/function separator
+	filename, breakTxt := getCommandTxt(arr[1:2], p)
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), arr[0]))
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, arr[1]))
+
+	line, err := strconv.Atoi(breakTxt)
+	if err == nil {
+		if line <= 0 {
+			fmt.Println("(!) Line number must be greater than zero.")
+			return
+		}
 	} else {
-		//get filename & line/function separator
-		filename, breakTxt := getCommandTxt(arr[1:], p)
+		var ok bool
+		var funcFile string
+		if funcFile, line, ok = d.FunctionBP(breakTxt); !ok || (strings.Contains(arr[1], ":") && funcFile != filename) {
+			fmt.Println("(!) Function name not found.")
+			return
+		}
+		filename = funcFile
+	}
 
-		line, err := strconv.Atoi(breakTxt)
-		if err == nil {
-			if line <= 0 {
-				fmt.Println("(!) Line number must be greater than zero.")
-			} else {
-				if add_or_del == ADD_BP {
-					d.AddBP(filename, line)
-				} else {
-					d.DelBP(filename, line)
-				}
+	if add_or_del == DEL_BP {
+		d.DelBP(filename, line)
+		return
+	}
+
+	bp := &Breakpoint{}
+	if arr[0] == "$lp" || arr[0] == "log" {
+		if rest == "" {
+			fmt.Println("(!) Log message expected.")
+			return
+		}
+		bp.Log = rest
+	} else {
+		if idx := strings.Index(rest, "if "); idx != -1 {
+			bp.Condition = strings.TrimSpace(rest[idx+len("if "):])
+			rest = strings.TrimSpace(rest[:idx])
+		}
+		if rest != "" {
+			if !strings.HasPrefix(rest, "hits") {
+				fmt.Printf("(!) Unexpected '%s', expected 'hits>=N' or 'if <expr>'.\n", rest)
+				return
 			}
-		} else {
-			funcName := breakTxt
-			var f *ast.FunctionLiteral
-			var ok bool
-			if f, ok = d.Functions[funcName]; !ok {
-				fmt.Println("(!) Function name not found.")
-			} else {
-				baseName := filepath.Base(f.Pos().Filename)
-				if baseName == filename {
-					if add_or_del == ADD_BP {
-						d.AddBP(filename, line)
-					} else {
-						d.DelBP(filename, f.StmtPos().Line)
-					}
-				} else {
-					fmt.Println("(!) Function name not found.")
-				}
+			if bp.HitOp, bp.HitCount, err = ParseHitCondition(strings.TrimPrefix(rest, "hits")); err != nil {
+				fmt.Printf("(!) %s.\n", err)
+				return
 			}
 		}
 	}
+	d.SetBP(filename, line, bp)
+}
+
+func (d *Debugger) listBreakpoints() {
+	if len(d.Breakpoints) == 0 {
+		fmt.Println("(!) No breakpoints.")
+		return
+	}
+	keys := make([]string, 0, len(d.Breakpoints))
+	for key := range d.Breakpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		bp := d.Breakpoints[key]
+		desc := ""
+		if bp.HitOp != "" {
+			desc += fmt.Sprintf(" hits%s%d", bp.HitOp, bp.HitCount)
+		}
+		if bp.Condition != "" {
+			desc += " if " + bp.Condition
+		}
+		if bp.Log != "" {
+			desc += " log: " + bp.Log
+		}
+		fmt.Printf("%s%s (hits: %d)\n", key, desc, bp.Hits)
+	}
 }
 
 // returns 'filename, line/func'
@@ -0,0 +1,265 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"originscript/lexer"
+	"originscript/message"
+	"originscript/parser"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const debugInput = `fn add(a, b) {
+	lit s = a + b
+	return s
+}
+lit g = 10
+if true {
+	lit x = add(g, 1)
+	println(x)
+}
+println("end")
+`
+
+//debug runs 'input' with the debugger, the prompt reads 'commands'. It returns the output.
+func debug(t *testing.T, input string, commands string) string {
+	return runDebugger(t, input, commands, false)
+}
+
+//postMortem runs 'input' like '--pm', the prompt is shown where it fails.
+func postMortem(t *testing.T, input string, commands string) string {
+	return runDebugger(t, input, commands, true)
+}
+
+func runDebugger(t *testing.T, input string, commands string, pm bool) string {
+	dir, err := ioutil.TempDir("", "debugger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "main.aero")
+	ioutil.WriteFile(filename, []byte(input), 0644)
+
+	parser.DebugInfos = nil
+	p := parser.New(lexer.New(filename, input), dir)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		output <- string(b)
+	}()
+
+	Dbg = NewDebugger()
+	Dbg.SetFunctions(p.Functions)
+	Dbg.SetDbgInfos(parser.SplitSlice(parser.DebugInfos))
+	Dbg.reader = bufio.NewReader(strings.NewReader(commands))
+	if pm {
+		Dbg.Stepping = false
+		Dbg.PostMortem = true
+	}
+	MsgHandler = message.NewMessageHandler()
+	MsgHandler.AddListener(Dbg)
+	defer func() {
+		Dbg = nil
+		MsgHandler = nil
+	}()
+
+	result := Eval(program, NewScope(nil, w))
+	if pm {
+		entered := Dbg.EnterPostMortem()
+		if failed := result.Type() == ERROR_OBJ; entered != failed {
+			t.Errorf("EnterPostMortem returned %t, the program failed: %t", entered, failed)
+		}
+	}
+	os.Stdout = stdout
+	w.Close()
+	return strings.NewReplacer("\x1b[1m\x1b[36m", "", "\x1b[0m", "").Replace(<-output)
+}
+
+//shownLines returns the numbers of the lines shown at the prompt, in order.
+func shownLines(output string) []int {
+	var lines []int
+	for _, line := range strings.Split(output, "\n") {
+		if i := strings.Index(line, "\t\t"); i > 0 {
+			if n, err := strconv.Atoi(line[:i]); err == nil {
+				lines = append(lines, n)
+			}
+		}
+	}
+	return lines
+}
+
+func TestDebuggerStepping(t *testing.T) {
+	tests := []struct {
+		commands string
+		expected []int
+	}{
+		{"$s\n$s\n$s\n$s\n$s\n$c\n", []int{5, 6, 7, 2, 3, 8}},
+		{"step\n\n\n\n\ncontinue\n", []int{5, 6, 7, 2, 3, 8}}, //an empty line repeats the command
+		{"$n\n$n\n$n\n$n\n$c\n", []int{5, 6, 7, 8, 10}},
+		{"$s\n$s\n$s\n$f\n$c\n", []int{5, 6, 7, 2, 8}},
+		{"finish\n$c\n", []int{5}}, //in the outermost frame
+	}
+
+	for _, tt := range tests {
+		output := debug(t, debugInput, tt.commands)
+		if got := shownLines(output); fmt.Sprint(got) != fmt.Sprint(tt.expected) {
+			t.Errorf("%q: wrong lines shown. expected=%v, got=%v", tt.commands, tt.expected, got)
+		}
+	}
+
+	output := debug(t, debugInput, "$f\n$c\n")
+	if !strings.Contains(output, "'finish' is not meaningful in the outermost frame") {
+		t.Errorf("'finish' in the outermost frame not reported. got=%q", output)
+	}
+}
+
+func TestDebuggerFrames(t *testing.T) {
+	//stopped at 'lit s = a + b', called at line 7
+	output := debug(t, debugInput, "$s\n$s\n$s\n$bt\n$lo\n$p a + b\n$fr 1\n$bt\n$p g\n$fr 2\n$c\n")
+	tests := []string{
+		"*#0  add at main.aero:2\n #1  <module> at main.aero:7\n",
+		"a = 10\nb = 1\n",
+		"> 11\n",
+		" #0  add at main.aero:2\n*#1  <module> at main.aero:7\n",
+		"> 10\n",
+		"(!) Frame number expected, between 0 and 1.",
+	}
+	for _, expected := range tests {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in the output. got=%q", expected, output)
+		}
+	}
+}
+
+func TestDebuggerVariables(t *testing.T) {
+	input := "lit g = 1\nfor i in [1, 2] {\n\tlit y = i\n}\n"
+
+	//stopped at 'lit y = i', in the scope of the loop
+	output := debug(t, input, "$n\n$n\n$lo\n$g\n$c\n")
+	if !strings.Contains(output, "\ni = 1\n") {
+		t.Errorf("the loop variable is not a local. got=%q", output)
+	}
+	if !strings.Contains(output, "$> g = 1\n\n") {
+		t.Errorf("wrong globals, expected only 'g'. got=%q", output)
+	}
+
+	output = debug(t, input, "$lo\n$c\n")
+	if !strings.Contains(output, "(!) No variables.") {
+		t.Errorf("expected no locals in the global scope. got=%q", output)
+	}
+}
+
+func TestPostMortem(t *testing.T) {
+	input := `fn div(a, b) {
+	lit q = a / b
+	return q
+}
+fn run(n) {
+	lit total = 0
+	for i in 0..n {
+		total += div(10, n - i)
+	}
+	return total
+}
+lit g = "global"
+run(2)
+`
+	output := postMortem(t, input, "$bt\n$lo\n$fr 1\n$lo\n$p total + 1\n$g\n$c\n")
+	tests := []string{
+		"(!) Post-mortem: failed at 'main.aero:2'",
+		"\n2\t\t\tlit q = a / b\n",
+		"*#0  div at main.aero:2\n #1  run at main.aero:8\n #2  <module> at main.aero:13\n",
+		"$> a = 10\nb = 0\n",
+		"*#1  run at main.aero:8\n",
+		"$> n = 2\ntotal = 15\n", //the function's scope, not the loop's
+		"> 16\n",
+		"$> div = ",
+		"g = global\nrun = ",
+	}
+	for _, expected := range tests {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %q in the output. got=%q", expected, output)
+		}
+	}
+	//only the failure stops, not the lines evaluated before it
+	if lines := shownLines(output); fmt.Sprint(lines) != "[2]" {
+		t.Errorf("wrong lines shown. expected=[2], got=%v", lines)
+	}
+
+	//a thrown value, the prompt is shown at the 'throw'
+	output = postMortem(t, "fn f(x) {\n\tthrow x\n}\nf(42)\n", "$lo\n$c\n")
+	if !strings.Contains(output, "failed at 'main.aero:2'") || !strings.Contains(output, "$> x = 42\n") {
+		t.Errorf("wrong post-mortem of a throw. got=%q", output)
+	}
+
+	//a handled throw is not a failure
+	output = postMortem(t, "fn f() { throw 1 }\nlit r = 0\ntry { f() } catch e { r = 2 }\n", "$c\n")
+	if strings.Contains(output, "Post-mortem") {
+		t.Errorf("a handled throw entered the post-mortem. got=%q", output)
+	}
+
+	//the failure after a handled throw
+	output = postMortem(t, "fn f() { throw 1 }\ntry { f() } catch e { f }\nlit a = 1\na / 0\n", "$c\n")
+	if !strings.Contains(output, "failed at 'main.aero:4'") {
+		t.Errorf("wrong post-mortem after a handled throw. got=%q", output)
+	}
+}
+
+func TestBreakpointHits(t *testing.T) {
+	tests := []struct {
+		input    string
+		commands string
+		expected string
+	}{
+		//a loop with its body on the loop's line, one hit per iteration
+		{"lit n = 0\nfor i in 1..3 { n += i }\nprintln(n)\n", "$lp 2 n={n}\n$c\n", "n=0\nn=1\nn=3\n6\n"},
+		{"lit n = 0\nfor i in 1..3 { n += i; n += 0 }\nprintln(n)\n", "$lp 2 n={n}\n$c\n", "n=0\nn=1\nn=3\n6\n"},
+		{"lit n = 0\nfor i in 1..3 { n += i }\nprintln(n)\n", "$b 2 hits==3\n$c\n$p n\n$c\n", "> 3\n"},
+		//one hit per line, not per node of the line
+		{"lit a = [1]\nprintln(len(a))\nprintln(len(a))\n", "$lp 2 hit\n$c\n", "hit\n1\n1\n"},
+		//one hit per call
+		{"fn f(x) { return x }\nlit n = f(1) + 1\nf(2)\n", "$lp 1 x={x}\n$c\n", "x=1\nFunction 'f' returns\n\nx=2\n"},
+	}
+
+	for _, tt := range tests {
+		output := debug(t, tt.input, tt.commands)
+		if !strings.Contains(output, tt.expected) {
+			t.Errorf("%q: expected %q in the output. got=%q", tt.input, tt.expected, output)
+		}
+	}
+
+	//the prompt is shown at each stop on the same line
+	input := "fn f(x) {\n\tlit y = x\n\treturn y\n}\nfor i in 0..9 { f(i) }\n"
+	stops := []struct {
+		commands string
+		expected []string //the values of 'x' at the stops
+	}{
+		{"$b 2\n$c\n$p x\n$c\n$p x\n$d 2\n$c\n", []string{"0", "1"}},
+		{"$b 2 hits>=3\n$c\n$p x\n$c\n$p x\n$d 2\n$c\n", []string{"2", "3"}},
+		{"$b 2 if x % 4 == 1\n$c\n$p x\n$c\n$p x\n$d 2\n$c\n", []string{"1", "5"}},
+	}
+	for _, tt := range stops {
+		output := debug(t, input, tt.commands)
+		if lines := shownLines(output); fmt.Sprint(lines) != "[5 2 2]" {
+			t.Errorf("%q: wrong lines shown. expected=[5 2 2], got=%v", tt.commands, lines)
+		}
+		for _, x := range tt.expected {
+			if !strings.Contains(output, "\nDebugger $> "+x+"\n") {
+				t.Errorf("%q: expected x = %s at a stop. got=%q", tt.commands, x, output)
+			}
+		}
+	}
+}
@@ -2,6 +2,10 @@ package eval
 
 import "fmt"
 import "strings"
+import "io/ioutil"
+import "originscript/ast"
+import "originscript/token"
+import "unicode/utf8"
 
 // constants for error types
 const (
@@ -27,12 +31,14 @@ const (
 	DIVIDEBYZERO
 	THROWERROR
 	THROWNOTHANDLED
+	RETHROWERROR
 	GREPMAPNOTITERABLE
 	NOTITERABLE
 	RANGETYPEERROR
 	DEFERERROR
 	SPAWNERROR
 	ASSERTIONERROR
+	ASSERTIONERROREX
 	//	STDLIBERROR
 	NULLABLEERROR
 	JSONERROR
@@ -56,83 +62,113 @@ const (
 	CLASSCREATEERROR
 	PARENTNOTANNOTATION
 	OVERRIDEERROR
+	NOTINTERFACEERROR
+	INTERFACEERROR
+	TYPEMISMATCHERROR
 	METAOPERATORERROR
 	SERVICENOURLERROR
 	CONSTNOTASSIGNERROR
 	DIAMONDOPERERROR
 	NAMENOTEXPORTED
 	IMPORTERROR
+	EXCEPTION
 	GENERICERROR
 )
 
+//The messages of the error kinds, the codes of the kinds are in explain.go.
 var errorType = map[int]string{
-	PREFIXOP:           " AeroScript: eUDE: unsupported operator for prefix expression:'%s' and type: %s",
-	INFIXOP:            " AeroScript: eUDE: unsupported operator for infix expression: %s '%s' %s",
-	POSTFIXOP:          " AeroScript: eUDE: unsupported operator for postfix expression:'%s' and type: %s",
-	MOD_ASSIGNOP:       " AeroScript: eUDE: unsupported operator for modulor assignment:'%s'",
-	UNKNOWNIDENT:       " AeroScript: eUDE: unknown identifier: '%s' is not defined",
-	UNKNOWNIDENTEX:     " AeroScript: eUDE: identifier '%s' not found. \n\nDid you mean one of: \n\n  %s\n",
-	NOMETHODERROR:      " AeroScript: eUDE: undefined method '%s' for object %s",
-	NOMETHODERROREX:    " AeroScript: eUDE: undefined method '%s' for object '%s'. \n\nDid you mean one of: \n\n  %s\n",
-	NOINDEXERROR:       " AeroScript: eUDE: index error: type %s is not indexable",
-	KEYERROR:           " AeroScript: eUDE: key error: type %s is not hashable",
-	INDEXERROR:         " AeroScript: eUDE: index error: '%d' out of range",
-	SLICEERROR:         " AeroScript: eUDE: index error: slice '%d:%d' out of range",
-	ARGUMENTERROR:      " AeroScript: eUDE: wrong number of arguments. expected=%s, got=%d",
-	INPUTERROR:         " AeroScript: eUDE: unsupported input type '%s' for function or method: %s",
-	RTERROR:            " AeroScript: eUDE: return type should be %s",
-	PARAMTYPEERROR:     " AeroScript: eUDE: %s argument for '%s' should be type %s. got=%s",
-	INLENERR:           " AeroScript: eUDE: function %s takes input with max length %s. got=%s",
-	INVALIDARG:         " AeroScript: eUDE: invalid argument supplied",
-	DIVIDEBYZERO:       " AeroScript: eUDE: divide by zero",
-	THROWERROR:         " AeroScript: eUDE: throw object must be a string",
-	THROWNOTHANDLED:    " AeroScript: eUDE: throw object '%s' not handled",
-	GREPMAPNOTITERABLE: " AeroScript: eUDE: grep/map's operating type must be iterable",
-	NOTITERABLE:        " AeroScript: eUDE: foreach's operating type must be iterable",
-	RANGETYPEERROR:     " AeroScript: eUDE: range(..) type should be %s type, got='%s'",
-	DEFERERROR:         " AeroScript: eUDE: defer outside function or defer statement not a function",
-	SPAWNERROR:         " AeroScript: eUDE: spawn must be followed by a function",
-	ASSERTIONERROR:     " AeroScript: eUDE: assertion failed",
-	//	STDLIBERROR:     " AeroScript: eUDE: calling '%s' failed",
-	NULLABLEERROR:       " AeroScript: eUDE: %s is null",
-	JSONERROR:           " AeroScript: eUDE: json error: maybe unsupported type or invalid data",
-	DBSCANERROR:         " AeroScript: eUDE: scan type not supported",
-	FUNCCALLBACKERROR:   " AeroScript: eUDE: callback error: must be '%d' parameter(s), got '%d'",
-	FILEMODEERROR:       " AeroScript: eUDE: known file mode supplied",
-	FILEOPENERROR:       " AeroScript: eUDE: file open failed, reason: %s",
-	NOTCLASSERROR:       " AeroScript: eUDE: Identifier %s is not a class",
-	PARENTNOTDECL:       " AeroScript: eUDE: Parent class %s not declared",
-	CLSNOTDEFINE:        " AeroScript: eUDE: Class %s not defined",
-	CLSMEMBERPRIVATE:    " AeroScript: eUDE: Variable(%s) of class(%s) is private",
-	CLSCALLPRIVATE:      " AeroScript: eUDE: Method (%s) of class(%s) is private",
-	PROPERTYUSEERROR:    " AeroScript: eUDE: Invalid use of Property(%s) of class(%s)",
-	MEMBERUSEERROR:      " AeroScript: eUDE: Invalid use of member(%s) of class(%s)",
-	INDEXERUSEERROR:     " AeroScript: eUDE: Invalid use of Indexer of class(%s)",
-	INDEXERTYPEERROR:    " AeroScript: eUDE: Invalid use of Indexer of class(%s), Only interger type of Indexer is supported",
-	INDEXERSTATICERROR:  " AeroScript: eUDE: Invalid use of Indexer of class(%s), Indexer cannot declared as static",
-	INDEXNOTFOUNDERROR:  " AeroScript: eUDE: Indexer not found for class(%s)",
-	CALLNONSTATICERROR:  " AeroScript: eUDE: Could not call non-static",
-	CLASSCATEGORYERROR:  " AeroScript: eUDE: No class(%s) found for category(%s)",
-	CLASSCREATEERROR:    " AeroScript: eUDE: You must use 'new' to create class('%s')",
-	PARENTNOTANNOTATION: " AeroScript: eUDE: Annotation(%s)'s Parent(%s) is not annotation",
-	OVERRIDEERROR:       " AeroScript: eUDE: Method(%s) of class(%s) must override a superclass method",
-	METAOPERATORERROR:   " AeroScript: eUDE: Meta-Operators' item must be Numbers|String",
-	SERVICENOURLERROR:   " AeroScript: eUDE: Service(%s)'s function('%s') must have url",
-	CONSTNOTASSIGNERROR: " AeroScript: eUDE: Const variable '%s' cannot be modified",
-	DIAMONDOPERERROR:    " AeroScript: eUDE: Diamond operator must be followed by a file object, but got '%s'",
-	NAMENOTEXPORTED:     " AeroScript: eUDE: Cannot refer to unexported name '%s.%s'",
-	IMPORTERROR:         " AeroScript: eUDE: Import error: %s",
-	GENERICERROR:        " AeroScript: eUDE: %s",
+	PREFIXOP:           "unsupported operator for prefix expression:'%s' and type: %s",
+	INFIXOP:            "unsupported operator for infix expression: %s '%s' %s",
+	POSTFIXOP:          "unsupported operator for postfix expression:'%s' and type: %s",
+	MOD_ASSIGNOP:       "unsupported operator for modulor assignment:'%s'",
+	UNKNOWNIDENT:       "unknown identifier: '%s' is not defined",
+	UNKNOWNIDENTEX:     "identifier '%s' not found. \n\nDid you mean one of: \n\n  %s\n",
+	NOMETHODERROR:      "undefined method '%s' for object %s",
+	NOMETHODERROREX:    "undefined method '%s' for object '%s'. \n\nDid you mean one of: \n\n  %s\n",
+	NOINDEXERROR:       "index error: type %s is not indexable",
+	KEYERROR:           "key error: type %s is not hashable",
+	INDEXERROR:         "index error: '%d' out of range",
+	SLICEERROR:         "index error: slice '%d:%d' out of range",
+	ARGUMENTERROR:      "wrong number of arguments. expected=%s, got=%d",
+	INPUTERROR:         "unsupported input type '%s' for function or method: %s",
+	RTERROR:            "return type should be %s",
+	PARAMTYPEERROR:     "%s argument for '%s' should be type %s. got=%s",
+	INLENERR:           "function %s takes input with max length %s. got=%s",
+	INVALIDARG:         "invalid argument supplied",
+	DIVIDEBYZERO:       "divide by zero",
+	THROWERROR:         "invalid throw object: %s", //not reported any more, any value can be thrown
+	THROWNOTHANDLED:    "throw object '%s' not handled",
+	RETHROWERROR:       "throw without an object outside of a catch clause",
+	GREPMAPNOTITERABLE: "grep/map's operating type must be iterable",
+	NOTITERABLE:        "foreach's operating type must be iterable",
+	RANGETYPEERROR:     "range(..) type should be %s type, got='%s'",
+	DEFERERROR:         "defer outside function or defer statement not a function",
+	SPAWNERROR:         "spawn must be followed by a function",
+	ASSERTIONERROR:     "assertion failed",
+	ASSERTIONERROREX:   "assertion failed: %s\n%s",
+	//	STDLIBERROR:     "calling '%s' failed",
+	NULLABLEERROR:       "%s is null",
+	JSONERROR:           "json error: maybe unsupported type or invalid data",
+	DBSCANERROR:         "scan type not supported",
+	FUNCCALLBACKERROR:   "callback error: must be '%d' parameter(s), got '%d'",
+	FILEMODEERROR:       "unknown file mode supplied",
+	FILEOPENERROR:       "file open failed, reason: %s",
+	NOTCLASSERROR:       "Identifier %s is not a class",
+	PARENTNOTDECL:       "Parent class %s not declared",
+	CLSNOTDEFINE:        "Class %s not defined",
+	CLSMEMBERPRIVATE:    "Variable(%s) of class(%s) is private",
+	CLSCALLPRIVATE:      "Method (%s) of class(%s) is private",
+	PROPERTYUSEERROR:    "Invalid use of Property(%s) of class(%s)",
+	MEMBERUSEERROR:      "Invalid use of member(%s) of class(%s)",
+	INDEXERUSEERROR:     "Invalid use of Indexer of class(%s)",
+	INDEXERTYPEERROR:    "Invalid use of Indexer of class(%s), Only interger type of Indexer is supported",
+	INDEXERSTATICERROR:  "Invalid use of Indexer of class(%s), Indexer cannot declared as static",
+	INDEXNOTFOUNDERROR:  "Indexer not found for class(%s)",
+	CALLNONSTATICERROR:  "Could not call non-static",
+	CLASSCATEGORYERROR:  "No class(%s) found for category(%s)",
+	CLASSCREATEERROR:    "You must use 'new' to create class('%s')",
+	PARENTNOTANNOTATION: "Annotation(%s)'s Parent(%s) is not annotation",
+	OVERRIDEERROR:       "Method(%s) of class(%s) must override a superclass method",
+	NOTINTERFACEERROR:   "Identifier %s is not an interface",
+	INTERFACEERROR:      "Class(%s) does not implement %s '%s' of interface(%s)",
+	TYPEMISMATCHERROR:   "%s should be type %s, got %s",
+	METAOPERATORERROR:   "Meta-Operators' item must be Numbers|String",
+	SERVICENOURLERROR:   "Service(%s)'s function('%s') must have url",
+	CONSTNOTASSIGNERROR: "Const variable '%s' cannot be modified",
+	DIAMONDOPERERROR:    "Diamond operator must be followed by a file object, but got '%s'",
+	NAMENOTEXPORTED:     "Cannot refer to unexported name '%s.%s'",
+	IMPORTERROR:         "Import error: %s",
+	EXCEPTION:           "%s: %s",
+	GENERICERROR:        "%s",
 }
 
 func NewError(line string, t int, args ...interface{}) Object {
-	msg := fmt.Sprintf(errorType[t], args...) + " at line " + strings.TrimLeft(line, " \t")
-	return &Error{Kind: t, Message: msg}
+	text := fmt.Sprintf(errorType[t], args...)
+	msg := " AeroScript: " + ErrorCode(t) + ": " + text + " at line " + strings.TrimLeft(line, " \t")
+	return &Error{Kind: t, Message: msg, Text: text}
 }
 
 type Error struct {
-	Kind    int
-	Message string
+	Kind      int
+	Message   string
+	Text      string          //the message without the code and the line, "" if the error is not created by NewError
+	Stack     []StackFrame    //the calls leading to the error, the outermost first(see Traceback)
+	Exception *ObjectInstance //the exception raised by the standard library(see newException)
+}
+
+//ModuleFrame is the name of the frame of the top level of the program, it cannot
+//be the name of a function.
+const ModuleFrame = "<module>"
+
+//StackFrame is a function call of the stack trace of an error.
+type StackFrame struct {
+	Function string         //ModuleFrame for the top level of the program
+	Pos      token.Position //where the error occurred, or the call of the next frame
+}
+
+//String returns the frame like a line of the traceback.
+func (f StackFrame) String() string {
+	return fmt.Sprintf("File %q, line %d, in %s", f.Pos.Filename, f.Pos.Line, f.Function)
 }
 
 func (e Error) Error() string {
@@ -145,3 +181,71 @@ func (e *Error) CallMethod(line string, scope *Scope, method string, args ...Obj
 	//	return NewError(line, NOMETHODERROR, method, e.Type())
 	return NewError(line, GENERICERROR, e.Message)
 }
+
+//withStack records the call stack in 'obj' if it's an error without stack.
+//It's called with the innermost node returning the error, so the stack ends
+//where the error occurred.
+func withStack(obj Object, node ast.Node, scope *Scope) {
+	e, ok := obj.(*Error)
+	if !ok || e.Stack != nil || scope == nil || resolverIsNil(node) {
+		return
+	}
+
+	frames := scope.CallStack.Frames
+	e.Stack = make([]StackFrame, len(frames)+1)
+	pos := node.Pos()
+	for i := len(frames) - 1; i >= 0; i-- {
+		call := frames[i].CurrentCall
+		e.Stack[i+1] = StackFrame{Function: call.Function.String(), Pos: pos}
+		pos = call.Pos()
+	}
+	e.Stack[0] = StackFrame{Function: ModuleFrame, Pos: pos}
+}
+
+//Traceback returns the error with the calls leading to it, the most recent
+//call last, with the source line of each call and a caret under the column:
+//
+//    Traceback (most recent call last):
+//      File "main.aero", line 5, in <module>
+//        println(div(1, 0))
+//                ^
+//      File "main.aero", line 2, in div
+//        return a / b
+//                 ^
+//    Runtime Error: AeroScript: eUDE-0019: divide by zero at line (main.aero;2)
+func (e *Error) Traceback() string {
+	if len(e.Stack) == 0 {
+		return e.Inspect()
+	}
+
+	var out strings.Builder
+	sources := make(map[string][]string)
+	out.WriteString("Traceback (most recent call last):\n")
+	for _, frame := range e.Stack {
+		out.WriteString("  " + frame.String() + "\n")
+
+		lines, ok := sources[frame.Pos.Filename]
+		if !ok {
+			if src, err := ioutil.ReadFile(frame.Pos.Filename); err == nil {
+				lines = strings.Split(string(src), "\n")
+			}
+			sources[frame.Pos.Filename] = lines
+		}
+		if frame.Pos.Line < 1 || frame.Pos.Line > len(lines) {
+			continue
+		}
+		line := strings.TrimRight(lines[frame.Pos.Line-1], "\r")
+		code := strings.TrimLeft(line, " \t")
+		if code == "" {
+			continue
+		}
+		out.WriteString("    " + code + "\n")
+
+		col := frame.Pos.Col - 1 - utf8.RuneCountInString(line[:len(line)-len(code)])
+		if col >= 0 && col < utf8.RuneCountInString(code) {
+			out.WriteString("    " + strings.Repeat(" ", col) + "^\n")
+		}
+	}
+	out.WriteString(e.Inspect())
+	return out.String()
+}
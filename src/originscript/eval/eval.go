@@ -18,7 +18,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 	"unicode/utf8"
 )
 
@@ -43,13 +42,19 @@ var (
 )
 
 var importScope *Scope
-var importedCache map[string]Object
+var importedCache map[string]*ImportedObject
 
 var mux sync.Mutex
 
 //REPL with color support
 var REPLColor bool
 
+//Run the programs and the function bodies with the bytecode VM(see vm.go) instead of walking the tree.
+var UseVM bool
+
+//Optimize the programs before running them(see optimizer.go).
+var UseOptimizer bool
+
 const ServiceHint = "* Running on %s (Press CTRL+C to quit)\n"
 
 var Dbg *Debugger
@@ -84,6 +89,12 @@ func Eval(node ast.Node, scope *Scope) (val Object) {
 			//    PANIC=runtime error: invalid memory address or nil pointer
 			val = NIL
 		}
+		withStack(val, node, scope)
+		if Dbg != nil && Dbg.PostMortem && val != nil {
+			if t := val.Type(); t == ERROR_OBJ || t == THROW_OBJ {
+				Dbg.failed(node, scope, val)
+			}
+		}
 	}()
 
 	if Dbg != nil {
@@ -170,6 +181,9 @@ func Eval(node ast.Node, scope *Scope) (val Object) {
 	case *ast.UnlessExpression:
 		return evalUnlessExpression(node, scope)
 	case *ast.BlockStatement:
+		if UseVM && Dbg == nil {
+			return runCompiled(node, scope)
+		}
 		return evalBlockStatements(node.Statements, scope)
 	case *ast.CallExpression:
 		// if Dbg != nil {
@@ -189,6 +203,8 @@ func Eval(node ast.Node, scope *Scope) (val Object) {
 		return evalMapExpression(node, scope)
 	case *ast.CaseExpr:
 		return evalCaseExpression(node, scope)
+	case *ast.MatchExpr:
+		return evalMatchExpression(node, scope)
 	case *ast.DoLoop:
 		return evalDoLoopExpression(node, scope)
 	case *ast.WhileLoop:
@@ -239,6 +255,8 @@ func Eval(node ast.Node, scope *Scope) (val Object) {
 	//Class related
 	case *ast.ClassStatement:
 		return evalClassStatement(node, scope)
+	case *ast.InterfaceStatement:
+		return evalInterfaceStatement(node, scope)
 	case *ast.ClassLiteral:
 		return evalClassLiteral(node, scope)
 	case *ast.NewExpression:
@@ -288,7 +306,7 @@ func Eval(node ast.Node, scope *Scope) (val Object) {
 // Program Evaluation Entry Point Functions, and Helpers:
 func evalProgram(program *ast.Program, scope *Scope) (results Object) {
 	if importedCache == nil {
-		importedCache = make(map[string]Object)
+		importedCache = make(map[string]*ImportedObject)
 	}
 
 	results = loadImports(program.Imports, scope)
@@ -296,20 +314,36 @@ func evalProgram(program *ast.Program, scope *Scope) (results Object) {
 		return
 	}
 
+	if UseOptimizer && Dbg == nil {
+		OptimizeProgram(program)
+	}
+	Resolve(program)
+	if UseVM && Dbg == nil {
+		return programResult(runCompiled(program, scope))
+	}
+
 	for _, statement := range program.Statements {
 		results = Eval(statement, scope)
-		switch s := results.(type) {
-		case *ReturnValue:
-			return s.Value
-		case *Error:
-			return s
-		case *Throw:
-			//convert ThrowValue to Errors
-			return NewError(s.stmt.Pos().Sline(), THROWNOTHANDLED, s.value.Inspect())
+		switch results.(type) {
+		case *ReturnValue, *Error, *Throw:
+			return programResult(results)
 		}
 	}
-	if results == nil {
+	return programResult(results)
+}
+
+//programResult converts the result of the program's last evaluated statement.
+func programResult(results Object) Object {
+	switch s := results.(type) {
+	case nil:
 		return NIL
+	case *ReturnValue:
+		return s.Value
+	case *Throw:
+		//convert ThrowValue to Errors
+		e := NewError(s.stmt.Pos().Sline(), THROWNOTHANDLED, exceptionString(s.value)).(*Error)
+		e.Stack = s.stack
+		return e
 	}
 	return results
 }
@@ -320,7 +354,10 @@ func loadImports(imports map[string]*ast.ImportStatement, scope *Scope) Object {
 	}
 	for _, p := range imports {
 		v := Eval(p, scope)
-		if v.Type() == ERROR_OBJ {
+		if err, ok := v.(*Error); ok {
+			if err.Kind == NAMENOTEXPORTED || err.Kind == UNKNOWNIDENT || err.Kind == UNKNOWNIDENTEX {
+				return err //'require util (name)'
+			}
 			return NewError(p.Pos().Sline(), IMPORTERROR, p.ImportPath)
 		}
 	}
@@ -329,25 +366,51 @@ func loadImports(imports map[string]*ast.ImportStatement, scope *Scope) Object {
 
 // Statements...
 func evalImportStatement(i *ast.ImportStatement, scope *Scope) Object {
-
-	mux.Lock()
-	defer mux.Unlock()
-
-	// Check the cache
-	if cache, ok := importedCache[i.ImportPath]; ok {
-		return cache
+	//a module is evaluated once, even if it's required with different names.
+	key := i.File
+	if key == "" {
+		key = i.Path
 	}
 
-	imported := &ImportedObject{Name: i.ImportPath, Scope: NewScope(nil, scope.Writer)}
+	// Check the cache
+	mux.Lock()
+	imported, ok := importedCache[key]
+	mux.Unlock()
 
-	if _, ok := importScope.Get(i.ImportPath); !ok {
+	if !ok {
+		//not locked while evaluating, the module may require other modules
+		imported = &ImportedObject{Name: i.Path, Scope: NewScope(nil, scope.Writer), Exports: i.Program.ExportedNames()}
 		evalProgram(i.Program, imported.Scope)
-		importScope.Set(i.ImportPath, imported)
+
+		//store the evaluated result to cache
+		mux.Lock()
+		if cache, ok := importedCache[key]; ok {
+			imported = cache
+		} else {
+			importedCache[key] = imported
+		}
+		mux.Unlock()
 	}
 
-	//store the evaluated result to cache
-	importedCache[i.ImportPath] = imported
+	if len(i.Names) == 0 {
+		scope.Set(i.ImportPath, imported)
+		if _, ok := importScope.Get(i.ImportPath); !ok {
+			importScope.Set(i.ImportPath, imported)
+		}
+		return imported
+	}
 
+	//require util (parse, format)
+	for _, name := range i.Names {
+		obj, ok := imported.Scope.Get(name.Value)
+		if !ok {
+			return reportTypoSuggestions(name.Pos().Sline(), imported.Scope, name.Value)
+		}
+		if !imported.Exported(name.Value) {
+			return NewError(name.Pos().Sline(), NAMENOTEXPORTED, i.ImportPath, name.Value)
+		}
+		scope.Set(name.Value, obj)
+	}
 	return imported
 }
 
@@ -462,11 +525,15 @@ func evalLetStatement(l *ast.LetStatement, scope *Scope) (val Object) {
 				continue
 			}
 			val = values[idx]
-			if val.Type() != ERROR_OBJ {
-				scope.Set(item.String(), val)
-			} else {
+			if val.Type() == ERROR_OBJ {
 				return
 			}
+			if t, ok := l.Types[item.Value]; ok && TypeCheck {
+				if err := checkType(t, val, scope, l.Pos().Sline(), "variable '"+item.Value+"'"); err != nil {
+					return err
+				}
+			}
+			scope.Set(item.String(), val)
 		}
 	}
 
@@ -930,12 +997,17 @@ func evalClassIndexerAssignExpression(a *ast.AssignExpression, obj Object, index
 	}
 }
 
-func evalAssignExpression(a *ast.AssignExpression, scope *Scope) (val Object) {
-	val = Eval(a.Value, scope)
+func evalAssignExpression(a *ast.AssignExpression, scope *Scope) Object {
+	val := Eval(a.Value, scope)
 	if val.Type() == ERROR_OBJ {
 		return val
 	}
+	return evalAssign(a, val, scope)
+}
 
+//evalAssign assigns the evaluated 'value' of the assignment.
+func evalAssign(a *ast.AssignExpression, value Object, scope *Scope) (val Object) {
+	val = value
 	if strings.Contains(a.Name.String(), ".") {
 		switch o := a.Name.(type) {
 		case *ast.MethodCallExpression:
@@ -1001,12 +1073,23 @@ func evalAssignExpression(a *ast.AssignExpression, scope *Scope) (val Object) {
 				if instanceObj.IsStatic(strArr[1], ClassMemberKind) {
 					return NewError(a.Pos().Sline(), MEMBERUSEERROR, strArr[1], instanceObj.Class.Name)
 				}
+				if TypeCheck {
+					if err := checkMemberType(instanceObj, strArr[1], val, a.Pos().Sline()); err != nil {
+						return err
+					}
+				}
 				instanceObj.Scope.Set(strArr[1], val)
 			} else {
 				// check if it's a static property
 				if instanceObj.IsStatic(strArr[1], ClassPropertyKind) {
 					return NewError(a.Pos().Sline(), PROPERTYUSEERROR, strArr[1], instanceObj.Class.Name)
 				}
+				if TypeCheck && p.Type != nil {
+					what := "property '" + strArr[1] + "' of class '" + instanceObj.Class.Name + "'"
+					if err := checkType(p.Type, val, instanceObj.Scope, a.Pos().Sline(), what); err != nil {
+						return err
+					}
+				}
 
 				if p.Setter == nil { //property xxx { get; }
 					_, ok := instanceObj.Scope.Get(strArr[1])
@@ -1165,6 +1248,11 @@ func evalAssignExpression(a *ast.AssignExpression, scope *Scope) (val Object) {
 				if thisObj.Type() == INSTANCE_OBJ { //'this' refers to 'ObjectInstance' object
 					_, ok := thisObj.(*ObjectInstance).Scope.Get(name)
 					if ok {
+						if instance := thisObj.(*ObjectInstance); TypeCheck && isMember(name, scope, instance) {
+							if err := checkMemberType(instance, name, val, a.Pos().Sline()); err != nil {
+								return err
+							}
+						}
 						v, ok2 := scope.Reset(name, val)
 						if ok2 {
 							return v
@@ -1265,12 +1353,26 @@ func evalDeferStatement(d *ast.DeferStmt, scope *Scope) Object {
 }
 
 func evalThrowStatement(t *ast.ThrowStmt, scope *Scope) Object {
+	if t.Expr == nil { //rethrow the exception handled by the enclosing catch clause
+		for s := scope; s != nil; s = s.parentScope {
+			if s.caught != nil {
+				return s.caught
+			}
+		}
+		return NewError(t.Pos().Sline(), RETHROWERROR)
+	}
+
 	throwObj := Eval(t.Expr, scope)
 	if throwObj.Type() == ERROR_OBJ {
 		return throwObj
 	}
 
-	return &Throw{stmt: t, value: throwObj}
+	//the stack of the throw statement
+	e := &Error{}
+	withStack(e, t, scope)
+	setExceptionStack(throwObj, e.Stack)
+
+	return &Throw{stmt: t, value: throwObj, stack: e.Stack}
 }
 
 // Booleans
@@ -1332,6 +1434,16 @@ func evalRegExLiteral(re *ast.RegExLiteral) Object {
 }
 
 func evalIdentifier(i *ast.Identifier, scope *Scope) Object {
+	//local variable resolved to a slot, it's never a global
+	if i.Func != nil {
+		if val, ok := scope.slot(i); ok {
+			if is, ok := val.(*InterpolatedString); ok {
+				is.Interpolate(scope)
+			}
+			return val
+		}
+	}
+
 	//Get from global scope first
 	if obj, ok := GetGlobalObj(i.String()); ok {
 		return obj
@@ -1369,6 +1481,8 @@ func evalHashLiteral(hl *ast.HashLiteral, scope *Scope) Object {
 				t := key.(*ast.Identifier).Value
 				k = NewString(t)
 				innerScope.Set(t, k)
+			} else { //a variable, its value is the key
+				k = Eval(key, innerScope)
 			}
 		default:
 			k = Eval(key, innerScope)
@@ -1441,6 +1555,7 @@ func evalFunctionStatement(FnStmt *ast.FunctionStatement, scope *Scope) Object {
 
 func evalFunctionLiteral(fl *ast.FunctionLiteral, scope *Scope) Object {
 	fn := &Function{Literal: fl, Variadic: fl.Variadic, Scope: scope, Async: fl.Async}
+	scope.share() //the closure may be called by other goroutines
 
 	if fl.Values != nil { //check for default values
 		for _, item := range fl.Parameters {
@@ -1546,7 +1661,10 @@ func evalPrefixExpression(p *ast.PrefixExpression, scope *Scope) Object {
 	if right.Type() == ERROR_OBJ {
 		return right
 	}
+	return evalPrefix(p, right, scope)
+}
 
+func evalPrefix(p *ast.PrefixExpression, right Object, scope *Scope) Object {
 	//User Defined Operator
 	if p.Token.Type == token.UDO {
 		return evalPrefixExpressionUDO(p, right, scope)
@@ -3949,6 +4067,7 @@ func evalFunctionCall(call *ast.CallExpression, scope *Scope) Object {
 			//let add =fn(x,y) { x+y }
 			//add(2,3)
 			fn = &Function{Literal: f, Scope: scope, Variadic: f.Variadic}
+			scope.share()
 			scope.Set(call.Function.String(), fn)
 		} else if idxExpr, ok := call.Function.(*ast.IndexExpression); ok { //index expression
 			//let complex={ "add" : fn(x,y){ x+y } }
@@ -3965,14 +4084,7 @@ func evalFunctionCall(call *ast.CallExpression, scope *Scope) Object {
 				//return NewError(call.Function.Pos().Sline(), UNKNOWNIDENT, call.Function.String())
 			}
 		} else if builtin, ok := builtins[call.Function.String()]; ok {
-			args := evalArgs(call.Arguments, scope)
-			//check for errors
-			for _, v := range args {
-				if v.Type() == ERROR_OBJ {
-					return v
-				}
-			}
-			return builtin.Fn(call.Function.Pos().Sline(), scope, args...)
+			return callBuiltin(call, builtin, evalArgs(call.Arguments, scope), scope)
 		} else if callExpr, ok := call.Function.(*ast.CallExpression); ok { //call expression
 			//let complex={ "add" : fn(x,y){ fn(z) {x+y+z} } }
 			//complex["add"](2,3)(4)
@@ -3993,6 +4105,9 @@ func evalFunctionCall(call *ast.CallExpression, scope *Scope) Object {
 	}
 
 	f := fn.(*Function)
+	if f.Async {
+		scope.share()
+	}
 	if f.Async && call.Awaited {
 		aChan := make(chan Object, 1)
 
@@ -4013,6 +4128,11 @@ func evalFunctionCall(call *ast.CallExpression, scope *Scope) Object {
 }
 
 func evalFunctionObj(call *ast.CallExpression, f *Function, scope *Scope) Object {
+	return callFunctionObj(call, f, evalArgs(call.Arguments, scope), scope)
+}
+
+//callFunctionObj calls the function with the evaluated arguments of 'call'.
+func callFunctionObj(call *ast.CallExpression, f *Function, args []Object, scope *Scope) Object {
 	var thisObj Object
 	var ok bool
 	//check if it's static function
@@ -4027,9 +4147,11 @@ func evalFunctionObj(call *ast.CallExpression, f *Function, scope *Scope) Object
 		}
 	}
 
-	newScope := NewScope(f.Scope, nil)
+	newScope := NewFunctionScope(f.Literal, f.Scope)
 
-	//Register this function call in the call stack
+	//Register this function call in the call stack of the caller(the function
+	//may be defined in an other module, which has its own call stack)
+	newScope.CallStack = scope.CallStack
 	newScope.CallStack.Frames = append(newScope.CallStack.Frames, CallFrame{FuncScope: newScope, CurrentCall: call})
 
 	//Using golang's defer mechanism, before function return, call current frame's defer method
@@ -4045,7 +4167,6 @@ func evalFunctionObj(call *ast.CallExpression, f *Function, scope *Scope) Object
 	}()
 
 	variadicParam := []Object{}
-	args := evalArgs(call.Arguments, scope)
 	for i := range call.Arguments {
 		//Because of function default values, we need to check `i >= len(args)`
 		if f.Variadic && i >= len(f.Literal.Parameters)-1 {
@@ -4060,6 +4181,12 @@ func evalFunctionObj(call *ast.CallExpression, f *Function, scope *Scope) Object
 		}
 	}
 
+	if TypeCheck {
+		if err := checkArgTypes(call.Function.String(), f.Literal, args, f.Scope, call.Pos().Sline()); err != nil {
+			return err
+		}
+	}
+
 	// Variadic argument is passed as a single array
 	// of parameters.
 	if f.Variadic {
@@ -4082,20 +4209,25 @@ func evalFunctionObj(call *ast.CallExpression, f *Function, scope *Scope) Object
 		// if function returns multiple-values
 		// returns a tuple instead.
 		if len(obj.Values) > 1 {
-			return &Tuple{Members: obj.Values, IsMulti: true}
-		}
-		return obj.Value
+			r = &Tuple{Members: obj.Values, IsMulti: true}
+		} else {
+			r = obj.Value
+		}
+	} else if Dbg != nil {
+		/* If the function call do not end in a 'return' statement. e.g.
+		   let add = fn(x, y) {
+		       x + y // not 'return x + y'
+		   }
+		   We need to send EVAL_LINE to the debugger, so we can step into this line,
+		   or else we cannot step into it.
+		*/
+		MsgHandler.SendMessage(message.Message{Type: message.EVAL_LINE, Body: Context{N: []ast.Node{call}, S: newScope}})
 	}
 
-	/* If the function call do not end in a 'return' statement. e.g.
-	   let add = fn(x, y) {
-	       x + y // not 'return x + y'
-	   }
-	   We need to send EVAL_LINE to the debugger, so we can step into this line,
-	   or else we cannot step into it.
-	*/
-	if Dbg != nil {
-		MsgHandler.SendMessage(message.Message{Type: message.EVAL_LINE, Body: Context{N: []ast.Node{call}, S: newScope}})
+	if TypeCheck {
+		if err := checkReturnType(call.Function.String(), f.Literal, r, f.Scope, call.Pos().Sline()); err != nil {
+			return err
+		}
 	}
 	return r
 }
@@ -4112,6 +4244,9 @@ func evalMethodCallExpression(call *ast.MethodCallExpression, scope *Scope) Obje
 			}
 		case *ast.Identifier: //e.g. os.O_APPEND
 			if i, ok := GetGlobalObj(str + "." + o.String()); ok {
+				if goFuncObj, ok := i.(*GoFuncObject); ok { //a Go function beside a builtin object's methods
+					return goFuncObj.CallMethod(call.Call.Pos().Sline(), scope, o.String())
+				}
 				return i
 			} else { //e.g. method call like 'os.environ'
 				if obj.Type() == HASH_OBJ { // It's a GoFuncObject
@@ -4131,6 +4266,11 @@ func evalMethodCallExpression(call *ast.MethodCallExpression, scope *Scope) Obje
 		case *ast.CallExpression: //e.g. method call like 'os.environ()'
 			if method, ok := call.Call.(*ast.CallExpression); ok {
 				args := evalArgs(method.Arguments, scope)
+				if i, ok := GetGlobalObj(str + "." + o.Function.String()); ok {
+					if goFuncObj, ok := i.(*GoFuncObject); ok { //e.g. 'json.Marshal(v)' beside 'json.marshal(v)'
+						return goFuncObj.CallMethod(call.Call.Pos().Sline(), scope, o.Function.String(), args...)
+					}
+				}
 				if obj.Type() == HASH_OBJ { // It's a GoFuncObject
 					hash := obj.(*Hash)
 					for _, hk := range hash.Order {
@@ -4171,7 +4311,7 @@ func evalMethodCallExpression(call *ast.MethodCallExpression, scope *Scope) Obje
 		case *ast.Identifier:
 			idName := call.Call.String()
 			if i, ok := m.Scope.Get(idName); ok {
-				if !unicode.IsUpper(rune(idName[0])) {
+				if !m.Exported(idName) {
 					return NewError(call.Call.Pos().Sline(), NAMENOTEXPORTED, str, idName)
 				} else {
 					return i
@@ -4191,7 +4331,7 @@ func evalMethodCallExpression(call *ast.MethodCallExpression, scope *Scope) Obje
 			}
 
 			funcName := o.Function.String()
-			if !unicode.IsUpper(rune(funcName[0])) {
+			if !m.Exported(funcName) {
 				return NewError(o.Function.Pos().Sline(), NAMENOTEXPORTED, str, o.Function.String())
 			}
 
@@ -4945,25 +5085,44 @@ func evalDecrementPostfixOperatorExpression(node *ast.PostfixExpression, left Ob
 
 func evalTryStatement(tryStmt *ast.TryStmt, scope *Scope) Object {
 	rv := Eval(tryStmt.Try, scope)
-	if rv.Type() == ERROR_OBJ {
-		return rv
+
+	//the thrown object, or the exception raised by the standard library
+	var thrown Object
+	switch e := rv.(type) {
+	case *Throw:
+		thrown = e.value
+	case *Error:
+		if e.Exception == nil {
+			return rv
+		}
+		thrown = e.Exception
+		setExceptionStack(thrown, e.Stack)
 	}
 
-	throwNotHandled := false
-	var throwObj Object = NIL
-	if rv.Type() == THROW_OBJ {
-		throwObj = rv.(*Throw)
-		if tryStmt.Catch != nil {
+	var notHandled Object
+	if thrown != nil {
+		catch, err := matchCatchClause(tryStmt.Catches, thrown, scope)
+		if err != nil {
+			return err
+		}
+		if catch != nil {
+			if Dbg != nil {
+				Dbg.handled(rv)
+			}
 			catchScope := NewScope(scope, scope.Writer)
-			if tryStmt.Var != "" {
-				catchScope.Set(tryStmt.Var, rv.(*Throw).value)
+			catchScope.caught = rv
+			if catch.Var != "" {
+				catchScope.Set(catch.Var, thrown)
 			}
-			rv = evalBlockStatements(tryStmt.Catch.Statements, catchScope) //catch Block
+			rv = evalBlockStatements(catch.Block.Statements, catchScope) //catch Block
 			if rv.Type() == ERROR_OBJ {
 				return rv
 			}
+			if rv.Type() == THROW_OBJ { //rethrown, or thrown by the catch Block
+				notHandled = rv
+			}
 		} else {
-			throwNotHandled = true
+			notHandled = rv
 		}
 	}
 
@@ -4974,11 +5133,39 @@ func evalTryStatement(tryStmt *ast.TryStmt, scope *Scope) Object {
 		}
 	}
 
-	if throwNotHandled {
-		return throwObj
+	if notHandled != nil {
+		return notHandled
 	}
 	return NIL
 }
+
+//matchCatchClause returns the first catch clause handling the thrown object.
+//Any value can be thrown, the typed clauses only handle the Exceptions.
+func matchCatchClause(catches []*ast.CatchClause, thrown Object, scope *Scope) (*ast.CatchClause, Object) {
+	var instance *ObjectInstance
+	if isException(thrown) {
+		instance = thrown.(*ObjectInstance)
+	}
+	for _, catch := range catches {
+		if len(catch.Types) == 0 {
+			return catch, nil
+		}
+		for _, name := range catch.Types {
+			class, ok := scope.Get(name)
+			if !ok {
+				return nil, NewError(catch.Pos().Sline(), CLSNOTDEFINE, name)
+			}
+			cls, ok := class.(*Class)
+			if !ok {
+				return nil, NewError(catch.Pos().Sline(), NOTCLASSERROR, name)
+			}
+			if InstanceOf(cls.Name, instance) {
+				return catch, nil
+			}
+		}
+	}
+	return nil, nil
+}
 //Evaluate ternary expression
 func evalTernaryExpression(te *ast.TernaryExpression, scope *Scope) Object {
 	condition := Eval(te.Condition, scope) //eval condition
@@ -4995,6 +5182,7 @@ func evalTernaryExpression(te *ast.TernaryExpression, scope *Scope) Object {
 
 func evalSpawnStatement(s *ast.SpawnStmt, scope *Scope) Object {
 	newSpawnScope := NewScope(scope, nil)
+	newSpawnScope.share()
 
 	switch callExp := s.Call.(type) {
 	case *ast.CallExpression:
@@ -5073,6 +5261,9 @@ func evalClassStatement(c *ast.ClassStatement, scope *Scope) Object {
 	} else {
 		clsObj = evalClassLiteral(c.ClassLiteral, scope)
 	}
+	if clsObj.Type() == ERROR_OBJ {
+		return clsObj
+	}
 
 	scope.Set(c.Name.Value, clsObj) //save to scope
 
@@ -5080,8 +5271,10 @@ func evalClassStatement(c *ast.ClassStatement, scope *Scope) Object {
 }
 
 //let name = class : parent { block }
+//let name = class : parent, interface1, interface2 { block }
 func evalClassLiteral(c *ast.ClassLiteral, scope *Scope) Object {
 	var parentClass = BASE_CLASS //base class is the root of all classes in magpie
+	var interfaces []*Interface
 	if c.Parent != "" {
 
 		parent, ok := scope.Get(c.Parent)
@@ -5089,15 +5282,31 @@ func evalClassLiteral(c *ast.ClassLiteral, scope *Scope) Object {
 			return NewError(c.Pos().Sline(), PARENTNOTDECL, c.Parent)
 		}
 
-		parentClass, ok = parent.(*Class)
-		if !ok {
+		switch p := parent.(type) {
+		case *Class:
+			parentClass = p
+		case *Interface: //e.g. 'class File : Closeable'
+			interfaces = append(interfaces, p)
+		default:
 			return NewError(c.Pos().Sline(), NOTCLASSERROR, c.Parent)
 		}
 	}
+	for _, name := range c.Interfaces {
+		obj, ok := scope.Get(name)
+		if !ok {
+			return NewError(c.Pos().Sline(), UNKNOWNIDENT, name)
+		}
+		iface, ok := obj.(*Interface)
+		if !ok {
+			return NewError(c.Pos().Sline(), NOTINTERFACEERROR, name)
+		}
+		interfaces = append(interfaces, iface)
+	}
 
 	clsObj := &Class{
 		Name:       c.Name,
 		Parent:     parentClass,
+		Interfaces: interfaces,
 		Members:    c.Members,
 		Properties: c.Properties,
 		Methods:    make(map[string]ClassMethod, len(c.Methods)),
@@ -5116,7 +5325,10 @@ func evalClassLiteral(c *ast.ClassLiteral, scope *Scope) Object {
 	//evaluate the 'Members' fields of class with proper scope.
 	for idx := len(classChain) - 1; idx >= 0; idx-- {
 		for _, member := range classChain[idx].Members {
-			Eval(member, newScope) //evaluate the 'Members' fields of class
+			//evaluate the 'Members' fields of class
+			if e, ok := Eval(member, newScope).(*Error); ok && e.Kind == TYPEMISMATCHERROR {
+				return e
+			}
 		}
 		newScope = NewScope(newScope, nil)
 	}
@@ -5140,6 +5352,13 @@ func evalClassLiteral(c *ast.ClassLiteral, scope *Scope) Object {
 		}
 	}
 
+	//check if the class has the methods and properties of its interfaces.
+	for _, iface := range interfaces {
+		if err := iface.Check(c.Pos().Sline(), clsObj); err != nil {
+			return err
+		}
+	}
+
 	return clsObj
 }
 
@@ -5188,6 +5407,32 @@ func evalNewExpression(n *ast.NewExpression, scope *Scope) Object {
 		return NewError(n.Pos().Sline(), NOTCLASSERROR, n.Class)
 	}
 
+	instance, err := newInstance(clsObj, scope)
+	if err != nil {
+		return err
+	}
+
+	//Is it has a constructor ?
+	init := clsObj.GetMethod("init")
+	if init == nil {
+		return instance
+	}
+
+	args := evalArgs(n.Arguments, scope)
+	if len(args) == 1 && args[0].Type() == ERROR_OBJ {
+		return args[0]
+	}
+
+	ret := evalFunctionDirect(init, args, instance, instance.Scope, nil)
+	if ret.Type() == ERROR_OBJ {
+		return ret //return the error object
+	}
+	return instance
+}
+
+//newInstance creates an instance of the class without calling its constructor. The
+//error is returned if a member's value is not of its annotated type(--typecheck).
+func newInstance(clsObj *Class, scope *Scope) (*ObjectInstance, Object) {
 	tmpClass := clsObj
 	classChain := make([]*Class, 0, 3)
 	classChain = append(classChain, clsObj)
@@ -5201,8 +5446,12 @@ func evalNewExpression(n *ast.NewExpression, scope *Scope) Object {
 	//evaluate the 'Members' fields of class with proper scope.
 	for idx := len(classChain) - 1; idx >= 0; idx-- {
 		for _, member := range classChain[idx].Members {
-			if !member.StaticFlag {
-				Eval(member, newScope) //evaluate the 'Members' fields of class
+			if member.StaticFlag {
+				continue
+			}
+			//evaluate the 'Members' fields of class
+			if e, ok := Eval(member, newScope).(*Error); ok && e.Kind == TYPEMISMATCHERROR {
+				return nil, e
 			}
 		}
 		newScope = NewScope(newScope, nil)
@@ -5211,23 +5460,7 @@ func evalNewExpression(n *ast.NewExpression, scope *Scope) Object {
 	instance := &ObjectInstance{Class: clsObj, Scope: newScope.parentScope}
 	instance.Scope.Set("this", instance)        //make 'this' refer to instance
 	instance.Scope.Set("parent", classChain[1]) //make 'parent' refer to instance's parent
-
-	//Is it has a constructor ?
-	init := clsObj.GetMethod("init")
-	if init == nil {
-		return instance
-	}
-
-	args := evalArgs(n.Arguments, scope)
-	if len(args) == 1 && args[0].Type() == ERROR_OBJ {
-		return args[0]
-	}
-
-	ret := evalFunctionDirect(init, args, instance, instance.Scope, nil)
-	if ret.Type() == ERROR_OBJ {
-		return ret //return the error object
-	}
-	return instance
+	return instance, nil
 }
 
 func processClassAnnotation(Annotations []*ast.AnnotationStmt, scope *Scope, line string, obj Object) {
@@ -5291,7 +5524,10 @@ func evalFunctionDirect(fn Object, args []Object, instance *ObjectInstance, scop
 		//			return NewError("", GENERICERROR, "Not enough parameters to call function")
 		//		}
 
-		newScope := NewScope(scope, nil)
+		newScope := NewFunctionScope(fn.Literal, scope)
+		if fn.Async {
+			newScope.share()
+		}
 		variadicParam := []Object{}
 		for i := range args {
 			//Because of function default values, we need to check `i >= len(args)`
@@ -5320,6 +5556,18 @@ func evalFunctionDirect(fn Object, args []Object, instance *ObjectInstance, scop
 			newScope.Set("@_", NewInteger(int64(len(fn.Literal.Parameters))))
 		}
 
+		var name, line string
+		if TypeCheck {
+			if call != nil {
+				name, line = call.Function.String(), call.Pos().Sline()
+			} else { //e.g. the constructor
+				name, line = methodName(fn), fn.Literal.Pos().Sline()
+			}
+			if err := checkArgTypes(name, fn.Literal, args, scope, line); err != nil {
+				return err
+			}
+		}
+
 		if fn.Async && call.Awaited {
 			aChan := make(chan Object, 1)
 
@@ -5354,11 +5602,17 @@ func evalFunctionDirect(fn Object, args []Object, instance *ObjectInstance, scop
 			// if function returns multiple-values
 			// returns a tuple instead.
 			if len(obj.Values) > 1 {
-				return &Tuple{Members: obj.Values, IsMulti: true}
+				results = &Tuple{Members: obj.Values, IsMulti: true}
+			} else {
+				results = obj.Value
 			}
-			return obj.Value
 		}
 
+		if TypeCheck {
+			if err := checkReturnType(name, fn.Literal, results, scope, line); err != nil {
+				return err
+			}
+		}
 		return results
 	case *Builtin:
 		return fn.Fn("", scope, args...)
@@ -5961,7 +6215,7 @@ func reportTypoSuggestionsMeth(line string, scope *Scope, objName string, miss s
 
 func extendFunctionScope(fn *Function, args []Object) *Scope {
 	fl := fn.Literal
-	scope := NewScope(fn.Scope, nil)
+	scope := NewFunctionScope(fl, fn.Scope)
 
 	// Set the defaults
 	for k, v := range fl.Values {
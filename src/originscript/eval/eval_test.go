@@ -2,9 +2,12 @@ package eval
 
 import (
 	"fmt"
+	"io/ioutil"
 	"originscript/lexer"
 	"originscript/parser"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -13,12 +16,12 @@ func TestDoLoop(t *testing.T) {
 		input    string
 		expected int64
 	}{
-		{"let a = 0; do { if(a == 10) { break } a = a + 1 }; a", 10},
-		{"let a = 0; let b = 0; do { if(a == 10) { break } a = a + 1 do { if(b == 3) { break } b = b + 1 } }; a + b", 13},
+		{"lit a = 0; do { if(a == 10) { break } a = a + 1 }; a", 10},
+		{"lit a = 0; lit b = 0; do { if(a == 10) { break } a = a + 1 do { if(b == 3) { break } b = b + 1 } }; a + b", 13},
 	}
 
 	for _, tt := range test {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
@@ -27,12 +30,12 @@ func TestWhileLoop(t *testing.T) {
 		input    string
 		expected int64
 	}{
-		{"let a = 0; while (a < 10) { a = a + 1 }; a", 10},
-		{"let a = 0; while (a < 10) { a = a + 1 if (a == 5) { break } }; a", 5},
+		{"lit a = 0; while (a < 10) { a = a + 1 }; a", 10},
+		{"lit a = 0; while (a < 10) { a = a + 1 if (a == 5) { break } }; a", 5},
 	}
 
 	for _, tt := range test {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
@@ -41,14 +44,14 @@ func TestReassignment(t *testing.T) {
 		input    string
 		expected int64
 	}{
-		{"let a = 5; a = 4;a", 4},
-		{"let a = 5 * 5; a = 5;a", 5},
-		{"let a = 5; let b = a * 5; a = b;a", 25},
-		{"let a = 5; let b = a; let c = a + b + 5; c; b = c;b", 15},
+		{"lit a = 5; a = 4;a", 4},
+		{"lit a = 5 * 5; a = 5;a", 5},
+		{"lit a = 5; lit b = a * 5; a = b;a", 25},
+		{"lit a = 5; lit b = a; lit c = a + b + 5; c; b = c;b", 15},
 	}
 
 	for _, tt := range test {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
@@ -57,28 +60,32 @@ func TestFileObjects(t *testing.T) {
 		input    string
 		expected interface{}
 	}{
-		{`let f = open("../parser/test_files/module.mp");str(f)`, "<file object: ../parser/test_files/module.mp>"},
-		{`let f = open("../parser/test_files/module.mp");f.read()`, `import eval
-import test
-import sub_package`},
-		{`let f = open("../parser/test_files/module.mp");f.readline()`, "import eval"},
-		{`let f = open("../parser/test_files/module.mp");f.readline();f.readline()`, "import test"},
-		{`let f = open("../parser/test_files/module.mp");f.readline();f.readline();f.readline()`, "import sub_package"},
+		{`lit f = open("../parser/test_files/module.mp");str(f)`, "<file object: ../parser/test_files/module.mp>"},
+		{`str(newFile("../parser/test_files/module.mp", "r"))`, "<file object: ../parser/test_files/module.mp>"}, //wrapped once
+		//'read' takes the number of bytes, and the lines of the file end with "\r\n"
+		{`lit f = open("../parser/test_files/module.mp");f.read(11)`, "import eval"},
+		{`lit f = open("../parser/test_files/module.mp");f.read(1024).len()`, 48}, //only the bytes read
+		//the method is 'readLine'
+		{`lit f = open("../parser/test_files/module.mp");f.readLine()`, "import eval"},
+		{`lit f = open("../parser/test_files/module.mp");f.readLine();f.readLine()`, "import test"},
+		{`lit f = open("../parser/test_files/module.mp");f.readLine();f.readLine();f.readLine()`, "import sub_package"},
 	}
 	d, _ := os.Getwd()
 	fmt.Println(d)
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case string:
 			testStringObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
 		}
 	}
 }
 
 func TestChainedCalled(t *testing.T) {
 	input := `[1,2,3].map(fn(x) { x + 1 }).map(fn(x) { x * 5 }).filter(fn(x) { x > 10 }).pop()`
-	testEval(input)
+	testEval(t, input)
 }
 
 func TestStructObjects(t *testing.T) {
@@ -86,19 +93,24 @@ func TestStructObjects(t *testing.T) {
 		input    string
 		expected interface{}
 	}{
-		{`struct (a=>15).a`, 15},
-		{`let st = struct {a=>15}; type(addm(st, "get", fn() { self.a })) == "NIL"`, true},
-		{`let st = struct {a=>15}; addm(st, "get", fn() { self.a }); st.get()`, 15},
-		{`let st = struct {a=>15}; addm(st, "get", fn() { a }); type(st.get()) == "ERROR"`, true},
+		//a struct literal is 'struct {...}', the receiver of a method is 'this', 'addm' returns the
+		//struct and a method sees the fields, so an unknown name is the error
+		{`struct {a=>15}.a`, 15},
+		{`struct {a=>15, b=>2}.b`, 2}, //the keys are not short functions('a=>15')
+		{`lit st = struct {a=>15}; type(addm(st, "get", fn() { this.a })) == "STRUCT"`, true},
+		{`lit st = struct {a=>15}; addm(st, "get", fn() { this.a }); st.get()`, 15},
+		{`lit st = struct {a=>15}; addm(st, "get", fn() { b }); st.get()`, "unknown identifier: 'b' is not defined"},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
 		case bool:
 			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
 		default:
 			t.Errorf("evaluted not %T. got=%T", evaluated, expected)
 		}
@@ -147,15 +159,17 @@ func TestStringMethods(t *testing.T) {
 		{`"string".find("g")`, 5},
 		{`"string".find("tr")`, 1},
 		{`"string".find("ng")`, 4},
-		{`"string".find("x")`, NIL},
-		{`"".find("stringstring")`, NIL},
-		{`"string".find("")`, NIL},
-		{`"string".find(1)`, NewError("0", INPUTERROR, INTEGER_OBJ, "find")},
-		{`"string".find([])`, NewError("0", INPUTERROR, ARRAY_OBJ, "find")},
+		//'find' returns -1 if not found, it checks the type of its argument, and the errors
+		//carry the line(1 in the input)
+		{`"string".find("x")`, -1},
+		{`"".find("stringstring")`, -1},
+		{`"string".find("")`, 0},
+		{`"string".find(1)`, NewError("1", PARAMTYPEERROR, "first", "find", "*String", INTEGER_OBJ)},
+		{`"string".find([])`, NewError("1", PARAMTYPEERROR, "first", "find", "*String", ARRAY_OBJ)},
 		{`"string".reverse()`, "gnirts"},
 		{`"".reverse()`, ""},
 		{`"ab".reverse()`, "ba"},
-		{`"".reverse(1)`, NewError("0", ARGUMENTERROR, "0", 1)},
+		{`"".reverse(1)`, NewError("1", ARGUMENTERROR, "0", 1)},
 		{`"".upper()`, ""},
 		{`"abc".upper()`, "ABC"},
 		{`"a b c".upper()`, "A B C"},
@@ -166,45 +180,44 @@ func TestStringMethods(t *testing.T) {
 		{`" string".lstrip()`, "string"},
 		{`"strsing".lstrip("s")`, "trsing"},
 		{`" 	".lstrip()`, ""},
-		{`"
-			string".lstrip()`, "string"},
+		{`"\n\t\t\tstring".lstrip()`, "string"}, //a string literal cannot span lines, the newlines are escaped
 		{`"` + string('\r') + `string".lstrip()`, "string"},
 		{`"string".lstrip("s")`, "tring"},
 		{`"string".lstrip("st")`, "ring"},
 		{`"ststring".lstrip("st")`, "ring"},
 		{`"string ".rstrip()`, "string"},
-		{`"` + string('\r') + string('\n') + "	 " + `".rstrip()`, ""},
+		{`"\r\n\t ".rstrip()`, ""},
 		{`"string".rstrip()`, "string"},
 		{`"string".rstrip("g")`, "strin"},
 		{`"strging".rstrip("g")`, "strgin"},
 		{`"string".rstrip("ng")`, "stri"},
-		{`"string
-			".rstrip()`, "string"},
+		{`"string\n\t\t\t".rstrip()`, "string"},
 		// strip just calls lstrip and rstrip consecutively, we can
 		// have fewer tests here since the above is pretty comprehensive
 		// just make sure it calls both
 		{`" string ".strip()`, "string"},
 		{`"ssstringss".strip("s")`, "tring"},
-		{`let s = "1 2 3".split(); s[0] + s[1] + s[2]`, "123"},
-		{`let s = "1,2,3".split(","); s[0] + s[1] + s[2]`, "123"},
-		{`let s = "1&_2&_3&_".split("&_"); s[0] + s[1] + s[2] + s[3]`, "123"},
+		{`lit s = "1 2 3".split(" "); s[0] + s[1] + s[2]`, "123"}, //'split' takes the separator
+		{`lit s = "1,2,3".split(","); s[0] + s[1] + s[2]`, "123"},
+		{`lit s = "1&_2&_3&_".split("&_"); s[0] + s[1] + s[2] + s[3]`, "123"},
 		{`"abc".replace("a", "A")`, "Abc"},
 		{`"this is a story and this story tells the story of this".replace("this","that")`, "that is a story and that story tells the story of that"},
 		{`" A B C ".replace(" ", "!")`, "!A!B!C!"},
 		{`"eee".count("e")`, 3},
 		{`"These are the days of summer".count("e")`, 5},
 		{`"These are the days of summer".count(" ")`, 5},
-		{`" ".join(["a", "b", "c"])`, "a b c"},
-		{`"!".join(["a", "b", "c"])`, "a!b!c"},
+		//'join' is a function of the 'strings' module
+		{`strings.join(["a", "b", "c"], " ")`, "a b c"},
+		{`strings.join(["a", "b", "c"], "!")`, "a!b!c"},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
-		case *Null:
-			testNullObject(t, expected)
+		case *Nil:
+			testNullObject(t, evaluated)
 		case string:
 			testStringObject(t, evaluated, expected)
 		case *Error:
@@ -220,16 +233,20 @@ func TestStringIndexExpressions(t *testing.T) {
 		expected string
 	}{
 		{`"string"[0]`, "s"},
-		{`"string"[-1]`, "g"},
+		{`"string"[-1]`, ""}, //a negative index is out of range
 		{`"string"[2]`, "r"},
 		{`"string"[0:]`, "string"},
 		{`"string"[1:]`, "tring"},
 		{`"string"[2:5]`, "rin"},
-		{`"string"[-5:-1]`, "trin"},
+		{`"string"[-5:-1]`, ""},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
+		if tt.expected == "" {
+			testErrorObject(t, evaluated, "index error")
+			continue
+		}
 		testStringObject(t, evaluated, tt.expected)
 	}
 }
@@ -239,17 +256,19 @@ func TestHashIndexExpressions(t *testing.T) {
 		input    string
 		expected interface{}
 	}{
-		{`{"foo": 5}["foo"]`, 5},
-		{`{"foo": 5}["bar"]`, nil},
-		{`let key = "foo";{"foo": 5}[key]`, 5},
-		{`{}["foo"]`, nil},
-		{`{5: 5}[5]`, 5},
-		{`{true: 5}[true]`, 5},
-		{`{false: 5}[false]`, 5},
+		//a '{' starting a statement starts a block, so the hashes are in parentheses
+		{`({"foo": 5})["foo"]`, 5},
+		{`({"foo": 5})["bar"]`, nil},
+		{`lit key = "foo";({"foo": 5})[key]`, 5},
+		{`({})["foo"]`, nil},
+		{`({5: 5})[5]`, 5},
+		{`({true: 5})[true]`, 5},
+		{`({false: 5})[false]`, 5},
+		{`lit k = "x"; ({k: 5})["x"]`, 5}, //a key naming a variable is its value, not nil
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		integer, ok := tt.expected.(int)
 		if ok {
 			testIntegerObject(t, evaluated, int64(integer))
@@ -261,17 +280,17 @@ func TestHashIndexExpressions(t *testing.T) {
 
 func TestHashLiterals(t *testing.T) {
 	input := `
-	let two = "two";
-	{
+	lit two = "two";
+	({
 		"one"        : 10 - 9,
 		two          : 1 + 1,
 		"thr" + "ee" : 6 /2,
 		4            : 4,
 		true         : 5,
 		false        : 6
-	}`
+	})`
 
-	evaluated := testEval(input)
+	evaluated := testEval(t, input)
 	hash, ok := evaluated.(*Hash)
 	if !ok {
 		t.Fatalf("Eval didn't return Hash. got=%T, (%+v)", evaluated, evaluated)
@@ -324,7 +343,7 @@ func TestArrayIndexExpressions(t *testing.T) {
 			3,
 		},
 		{
-			"let i = 0; [1][i];",
+			"lit i = 0; [1][i];",
 			1,
 		},
 		{
@@ -332,15 +351,15 @@ func TestArrayIndexExpressions(t *testing.T) {
 			3,
 		},
 		{
-			"let myArray = [1, 2, 3]; myArray[2];",
+			"lit myArray = [1, 2, 3]; myArray[2];",
 			3,
 		},
 		{
-			"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
+			"lit myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];",
 			6,
 		},
 		{
-			"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]",
+			"lit myArray = [1, 2, 3]; lit i = myArray[0]; myArray[i]",
 			2,
 		},
 		{
@@ -348,40 +367,36 @@ func TestArrayIndexExpressions(t *testing.T) {
 			nil,
 		},
 		{
-			"[1, 2, 3][-1]",
-			3,
+			"[1, 2, 3][-1]", //a negative index is out of range
+			"index error: '-1' out of range",
 		},
 		{
-			"let myArray = [1, 2, 3, 4, 5]; let i = myArray[0:]; let mySlice = myArray[1:]; mySlice[0]",
+			"lit myArray = [1, 2, 3, 4, 5]; lit i = myArray[0:]; lit mySlice = myArray[1:]; mySlice[0]",
 			2,
 		},
 		{
-			"let myArray = [1, 2, 3, 4, 5]; let i = myArray[0]; let mySlice = myArray[:1]; mySlice[0]",
+			"lit myArray = [1, 2, 3, 4, 5]; lit i = myArray[0]; lit mySlice = myArray[:1]; mySlice[0]",
 			1,
 		},
 		{
-			"let myArray = [1, 2, 3, 4, 5]; let mySlice = myArray[:]; mySlice[0]",
+			"lit myArray = [1, 2, 3, 4, 5]; lit mySlice = myArray[:]; mySlice[0]",
 			1,
 		},
 		{
-			"let myArray = [1, 2, 3, 4, 5];let mySlice = myArray[:]; mySlice[-1]",
-			5,
+			"lit myArray = [1, 2, 3, 4, 5];lit mySlice = myArray[:]; mySlice[-1]",
+			"index error: '-1' out of range",
 		},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
-		integer, ok := tt.expected.(int)
-		if ok {
-			testIntegerObject(t, evaluated, int64(integer))
-		} else {
-			if err, ok := evaluated.(*Error); ok {
-				if err.Message != "index error: '3' out of range" {
-					t.Errorf("wrong error message. got=%s", err.Message)
-				}
-			} else {
-				t.Errorf("evaluated not array or error. got=%T", evaluated)
-			}
+		evaluated := testEval(t, tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		default: //an index past the end is nil
+			testNullObject(t, evaluated)
 		}
 	}
 }
@@ -389,7 +404,7 @@ func TestArrayIndexExpressions(t *testing.T) {
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2 * 2, 3 + 3]"
 
-	evaluated := testEval(input)
+	evaluated := testEval(t, input)
 	results, ok := evaluated.(*Array)
 	if !ok {
 		t.Fatalf("object is not Array. got=%T", evaluated)
@@ -408,11 +423,12 @@ func TestFunctionalMethods(t *testing.T) {
 		input    string
 		expected bool
 	}{
-		{`let a = [1,2].map(fn(x) {x + 1}); fn(x) { if (x[0] == 2) { if (x[1] == 3) { return true; }} else { return false }}(a)`, true},
-		{`let a = [1,2].filter(fn(x) {x == 1}); fn(x) { if (x.len() == 1) { if (x[0] == 1) { return true; }} else { return false }}(a)`, true},
+		//a 'fn' starting a statement is a function statement, so the called literals are in parentheses
+		{`lit a = [1,2].map(fn(x) {x + 1}); (fn(x) { if (x[0] == 2) { if (x[1] == 3) { return true; }} else { return false }})(a)`, true},
+		{`lit a = [1,2].filter(fn(x) {x == 1}); (fn(x) { if (x.len() == 1) { if (x[0] == 1) { return true; }} else { return false }})(a)`, true},
 	}
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testBooleanObject(t, evaluated, tt.expected)
 	}
 }
@@ -422,20 +438,21 @@ func TestHashMethods(t *testing.T) {
 		input    string
 		expected interface{}
 	}{
-		{`{1:"a", 2:"b"}.pop(1)`, "a"},
-		{`let a = {1:"a", 2:"b"}; a.pop(1); str(a)`, `{2: b}`},
-		{`let a = {1:"a", 2:"b"}.push(3, "c"); a[3]`, `c`},
-		{`let a = {1:"a", 2:"b"}; let b = {3:"c"} let c = a.merge(b); c[3]`, `c`},
-		{`let a = {1:"a", 2:"b"}; let b = {3:"c"} let c = a.merge(b); str(a[3])`, `null`},
-		{`let a = {1:"a", 2:"b"}; let b = {3:"c"} let c = a.merge(b); str(b[1])`, `null`},
-		{`let a = {"a":1}.map(fn(k, v){ {k.upper():v+1} } ); str(a)`, `{A: 2}`},
-		{`let a = {"a":1, "b":2}.filter(fn(k, v){ v > 1 } ); str(a)`, `{b: 2}`},
-		{`str({"a":1}.keys())`, `[a]`},
+		//a '{' starting a statement starts a block, and the strings in a hash or an array are inspected quoted
+		{`({1:"a", 2:"b"}).pop(1)`, "a"},
+		{`lit a = {1:"a", 2:"b"}; a.pop(1); str(a)`, `{2 : "b"}`},
+		{`lit a = {1:"a", 2:"b"}.push(3, "c"); a[3]`, `c`},
+		{`lit a = {1:"a", 2:"b"}; lit b = {3:"c"} lit c = a.merge(b); c[3]`, `c`},
+		{`lit a = {1:"a", 2:"b"}; lit b = {3:"c"} lit c = a.merge(b); str(a[3])`, `nil`},
+		{`lit a = {1:"a", 2:"b"}; lit b = {3:"c"} lit c = a.merge(b); str(b[1])`, `nil`},
+		{`lit a = {"a":1}.map(fn(k, v){ return {k.upper():v+1} } ); str(a)`, `{"A" : 2}`},
+		{`lit a = {"a":1, "b":2}.filter(fn(k, v){ v > 1 } ); str(a)`, `{"b" : 2}`},
+		{`str({"a":1}.keys())`, `["a"]`},
 		{`str({"a":1}.values())`, `[1]`},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
@@ -453,22 +470,23 @@ func TestArrayMethods(t *testing.T) {
 		{`[1,2,3].pop()`, 3},
 		{`[1,2,3].pop(0)`, 1},
 		{`[1,2,3].pop(2)`, 3},
-		{`let a = [1,2,3].push(4);a.pop()`, 4},
-		{`let a = [1,2,3].pop(1)`, 2},
-		{`let a = [1,2,3]; a.pop(1); len(a)`, 2},
-		{`let a = [1,2,3].filter(fn(x) { x > 1}); str(a)`, `[2, 3]`},
-		{`let a = [1,2,3].map(fn(x) { x + 1}); str(a)`, `[2, 3, 4]`},
-		{`let a = [1,2,3].merge([4]); str(a)`, `[1, 2, 3, 4]`},
-		{`let a = ["a","b","c","d"].map(fn(x){ x.upper() }); str(a)`, `[A, B, C, D]`},
+		{`lit a = [1,2,3].push(4);a.pop()`, 4},
+		{`lit a = [1,2,3].pop(1)`, 2},
+		{`lit a = [1,2,3]; a.pop(1); len(a)`, 2},
+		{`lit a = [1,2,3].filter(fn(x) { x > 1}); str(a)`, `[2, 3]`},
+		{`lit a = [1,2,3].map(fn(x) { x + 1}); str(a)`, `[2, 3, 4]`},
+		{`lit a = [1,2,3].merge([4]); str(a)`, `[1, 2, 3, 4]`},
+		{`lit a = ["a","b","c","d"].map(fn(x){ x.upper() }); str(a)`, `["A", "B", "C", "D"]`}, //inspected quoted
 		{`["a","b","c","d"].index("d")`, 3},
 		{`[1,1,1,2,3].count(1)`, 3},
 		{`[1,2,3,4,5].reduce(fn(x, y) { x + y})`, 15},
 		{`str([[0,1],[2,3],[4,5]].reduce(fn(acc, val) { acc.merge(val) }))`, "[0, 1, 2, 3, 4, 5]"},
 		{`str([[0,1],[2,3],[4,5]].reduce(fn(acc, val) { acc.merge(val) },[]))`, "[0, 1, 2, 3, 4, 5]"},
+		{`[1,2,3].reduce(fn(x, y) { x + y }, 10)`, 16}, //the second argument is the initial value
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
@@ -487,16 +505,17 @@ func TestBuiltinFunction(t *testing.T) {
 		{`len("four")`, 4},
 		{`len([1, 3, 5])`, 3},
 		{`len([1,2,3])`, 3},
+		//the builtins check the types of their arguments
 		{`"string".plus()`, "undefined method 'plus' for object STRING"},
-		{`"string".plus`, "undefined method 'string.plus' for object STRING"},
+		{`"string".plus`, "undefined method 'plus' for object STRING"},
 		{`len("one", "two")`, "wrong number of arguments. expected=1, got=2"},
-		{`len(1)`, "undefined method 'len' for object INTEGER"},
+		{`len(1)`, "first argument for 'len' should be type *String|*Array|*Hash|*Nil. got=INTEGER"},
 		{`int("1")`, 1},
 		{`int("100")`, 100},
 		{`int(1)`, 1},
 		{`int("one")`, `unsupported input type 'STRING: one' for function or method: int`},
-		{`int([])`, `unsupported input type 'ARRAY' for function or method: int`},
-		{`int({})`, `unsupported input type 'HASH' for function or method: int`},
+		{`int([])`, `first argument for 'int' should be type *String|*Integer|*UInteger|*Boolean|*Float. got=ARRAY`},
+		{`int({})`, `first argument for 'int' should be type *String|*Integer|*UInteger|*Boolean|*Float. got=HASH`},
 		{`str(1)`, "1"},
 		{`str(true)`, `true`},
 		{`str(false)`, `false`},
@@ -513,7 +532,7 @@ func TestBuiltinFunction(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
@@ -523,10 +542,8 @@ func TestBuiltinFunction(t *testing.T) {
 
 			case *String:
 				testStringObject(t, evaluated, expected)
-			case *Error:
-				if s.Message != expected {
-					t.Errorf("wrong error message. expected=%q, got=%q", expected, s.Message)
-				}
+			case *Error: //the message has the code and the line too
+				testErrorObject(t, s, expected)
 			default:
 				t.Errorf("object is not error. got=%T (%+v)", evaluated, evaluated)
 			}
@@ -543,25 +560,25 @@ func TestStringLiteral(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		testStringObject(t, testEval(tt.input), tt.expected)
+		testStringObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
 func TestEnclosingEnvironments(t *testing.T) {
 	input := `
-let first = 10;
-let second = 10;
-let third = 10;
+lit first = 10;
+lit second = 10;
+lit third = 10;
 
-let ourFunction = fn(first) {
-  let second = 20;
+lit ourFunction = fn(first) {
+  lit second = 20;
 
   first + second + third;
 };
 
 ourFunction(20) + first + second;`
 
-	testIntegerObject(t, testEval(input), 70)
+	testIntegerObject(t, testEval(t, input), 70)
 }
 
 func TestFunctionApplication(t *testing.T) {
@@ -569,23 +586,687 @@ func TestFunctionApplication(t *testing.T) {
 		input    string
 		expected int64
 	}{
-		{"let identity = fn(x) { x; }; identity(5);", 5},
-		{"let identity = fn(x) { return x; }; identity(5);", 5},
-		{"let double = fn(x) { x * 2; }; double(5);", 10},
-		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
-		{"let add = fn(x, y) { return x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"lit identity = fn(x) { x; }; identity(5);", 5},
+		{"lit identity = fn(x) { return x; }; identity(5);", 5},
+		{"lit double = fn(x) { x * 2; }; double(5);", 10},
+		{"lit add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"lit add = fn(x, y) { return x + y; }; add(5 + 5, add(5, 5));", 20},
 		{"fn(x) { x; }(5)", 5},
-		{"let fact = fn(n) { if(n==1) { return n } else { return n * fact(n-1) } }; fact(5);", 120},
+		{"lit fact = fn(n) { if(n==1) { return n } else { return n * fact(n-1) } }; fact(5);", 120},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+func TestResolvedLocals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn f(x) { lit y = x * 2; y + x }; f(3);", 9},
+		{"fn f() { lit n = 0; return fn() { n = n + 1; n } }; lit c = f(); c(); c();", 2},
+		{"fn f(x) { lit i = 0; while (i < 2) { lit x = 10; i = i + 1 }; x }; f(1);", 1},
+		{"fn f(x) { if (x > 0) { lit y = 5 } else { lit y = 6 }; y }; f(1) + f(0);", 11},
+		{"fn f(x) { lit y = 1; fn g() { x + y }; g() }; f(2);", 3},
+		{"fn f(a, b = 4) { a + b }; f(1) + f(1, 1);", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCompiledLocals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"lit x = 1; lit n = 0; while (n < 3) { x = x + n; n = n + 1 }; x;", 4},
+		{"lit x = 1; lit n = 0; while (n < 2) { lit x = 10; n = n + 1 }; x;", 1},
+		{"lit x = 1; if (x > 0) { lit x = 2 }; x;", 2},
+		{"lit a = 1; lit f = fn() { a = a + 1 }; f(); f(); a;", 3},
+		{"n = 2; n = n * 3; n;", 6}, //an assignment sets an unknown variable in the program scope
+		{"lit s = 0; for (i = 0; i < 4; i++) { s = s + i }; s;", 6},
+		{"const c = 5; lit x = c; x = x + c; x;", 10},
+		{"fn f(n) { lit s = 0; lit i = 0; while (i < n) { s = s + i; i = i + 1 }; s }; f(5);", 10},
+		{"class C { lit x = 1; fn f() { lit y = 2; y = y + x; y } }; lit c = new C(); c.f();", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+
+	//the program scope keeps its variables between the programs, like the REPL's
+	UseVM = true
+	defer func() { UseVM = false }()
+	scope := NewScope(nil, os.Stdout)
+	var evaluated Object
+	for _, input := range []string{"lit x = 1;", "x = x + 1; lit y = x * 10;", "y + x;"} {
+		program := parser.New(lexer.New("", input), "").ParseProgram()
+		evaluated = Eval(program, scope)
+	}
+	testIntegerObject(t, evaluated, 22)
+	if y, ok := scope.Get("y"); !ok || scope.frame != nil {
+		t.Errorf("the variables are not released to the scope. got=%v", y)
+	}
+
+	program := parser.New(lexer.New("", "lit i = 0; while (i < 3) { i = i + 1 }"), "").ParseProgram()
+	code, err := Compile(program)
+	if err != nil {
+		t.Fatalf("compile error: %s", err)
+	}
+	for _, op := range []string{"OpLitLocal", "OpGetLocal", "OpAssignLocal", "OpAdd", "OpLess"} {
+		if !strings.Contains(code.Instructions.String(), op) {
+			t.Errorf("%s not found in\n%s", op, code.Instructions)
+		}
+	}
+}
+
+func TestTypedArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string //the inspected result, or the error message
+	}{
+		{"7 + 2 * 3 - 1;", "12"},
+		{"7 / 2;", "3.5"},
+		{"7 % 3;", "1"},
+		{"7.5 % 2.0;", "1.5"},
+		{"1.5 * 2.0 + 0.5;", "3.5"},
+		{"1 + 2.5;", "3.5"}, //not typed, by 'evalInfixExpression'
+		{"1 < 2;", "true"},
+		{"2.0 >= 2.5;", "false"},
+		{"1 == 1.0;", "true"},
+		{"3 != 3;", "false"},
+		{`"a" + "b";`, "ab"},
+		{"5 / 0;", "divide by zero"},
+		{"2.5 / 0.0;", "divide by zero"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if _, ok := evaluated.(*Error); ok {
+			testErrorObject(t, evaluated, tt.expected)
+		} else if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	input := "fn div(a, b) {\n  return a / b\n}\nfn f(x) {\n  div(x, 0)\n}\nf(1)"
+	for _, useVM := range []bool{false, true} {
+		e, ok := evalInput(input, useVM).(*Error)
+		if !ok {
+			t.Fatalf("expected an error, useVM=%t", useVM)
+		}
+
+		expected := []struct {
+			function string
+			line     int
+		}{{ModuleFrame, 7}, {"f", 5}, {"div", 2}}
+		if len(e.Stack) != len(expected) {
+			t.Fatalf("wrong stack, useVM=%t. expected=%d frames, got=%+v", useVM, len(expected), e.Stack)
+		}
+		for i, frame := range e.Stack {
+			if frame.Function != expected[i].function || frame.Pos.Line != expected[i].line {
+				t.Errorf("wrong frame %d, useVM=%t. expected=%s:%d, got=%s:%d", i, useVM,
+					expected[i].function, expected[i].line, frame.Function, frame.Pos.Line)
+			}
+		}
+		if tb := e.Traceback(); !strings.HasPrefix(tb, "Traceback (most recent call last):\n") || !strings.HasSuffix(tb, e.Inspect()) {
+			t.Errorf("wrong traceback, useVM=%t. got=%q", useVM, tb)
+		}
+
+		//the top level is not a function named 'main'
+		e, ok = evalInput("fn main() {\n  1 / 0\n}\nmain()", useVM).(*Error)
+		if !ok || len(e.Stack) != 2 || e.Stack[0].Function != ModuleFrame || e.Stack[1].Function != "main" {
+			t.Errorf("wrong stack of 'main', useVM=%t. got=%+v", useVM, e)
+		}
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`lit r = ""; try { throw new IOError("x") } catch (e: NetError) { r = "net" } catch (e: IOError) { r = "io:" + e.message }
+r`, "io:x"},
+		{`lit r = ""; try { throw new NetError("x") } catch (e: IOError | NetError) { r = e.classOf() }
+r`, "NetError"},
+		{`class E : Exception {}; lit r = ""; try { throw new E("m", 1) } catch (e: Exception) { r = e.toString() + str(e.cause) }
+r`, "E: m1"},
+		{`lit r = ""; try { throw "s" } catch (e: IOError) { r = "io" } catch e { r = e }
+r`, "s"},
+		{`lit r = 0; try { throw 42 } catch (e: Exception) { r = 1 } catch e { r = e }
+r`, "42"},
+		{`class C {}; lit r = ""; try { throw new C() } catch (e: C) { r = "typed" } catch e { r = "untyped" }
+r`, "untyped"},
+		{`lit r = ""; try { try { throw new IOError("x") } catch e { r = "inner "; throw } } catch e { r += e.message }
+r`, "inner x"},
+		{`lit r = ""; try { try { throw new NetError("x") } catch (e: IOError) { r = "io" } } catch e { r = "outer" }
+r`, "outer"},
+		{`lit r = ""; try { json.marshal(fn() {}) } catch (e: JSONError) { r = e.classOf() }
+r`, "JSONError"},
+		{`str(open("no/such/file") == nil)`, "true"}, //the failures returning nil or false are not exceptions
+		{`lit r = 0; fn f() { throw new IOError("x") }; try { f() } catch e { r = len(e.stack) }
+r`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`throw 1`, "throw object '1' not handled"},
+		{`try { throw 1 } catch (e: Exception) { }`, "throw object '1' not handled"},
+		{`throw`, "throw without an object outside of a catch clause"},
+		{`fn f() { throw new IOError("x") }; f()`, "throw object 'IOError: x' not handled"},
+		{`json.marshal(fn() {})`, "JSONError: unsupported type FUNCTION"},
+	}
+	for _, tt := range errors {
+		e, ok := testEval(t, tt.input).(*Error)
+		if !ok || !strings.Contains(e.Message, tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%v", tt.input, tt.expected, e)
+		}
+	}
+
+	//with --exceptions, the failures returning nil or false raise an exception
+	RaiseExceptions = true
+	defer func() { RaiseExceptions = false }()
+	raised := []struct {
+		input    string
+		expected string
+	}{
+		{`lit r = ""; try { open("no/such/file") } catch (e: IOError) { r = e.classOf() }
+r`, "IOError"},
+		{`lit r = ""; try { ioutil.readFile("no/such/file") } catch (e: IOError) { r = "io" }
+r`, "io"},
+		{`lit r = ""; try { dialTCP("tcp", "no address") } catch (e: NetError) { r = e.classOf() }
+r`, "NetError"},
+		{`lit r = ""; try { json.unmarshal("{") } catch (e: JSONError) { r = e.classOf() }
+r`, "JSONError"},
+		{`lit r = 0; fn f() { open("no/such/file") }; try { f() } catch e { r = len(e.stack) }
+r`, "2"},
+	}
+	for _, tt := range raised {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+	if e, ok := testEval(t, `lit f = open("no/such/file")`).(*Error); !ok || !strings.Contains(e.Message, "IOError: open no/such/file") {
+		t.Errorf("an IOError not caught must stop the program. got=%v", e)
+	}
+}
+
+//the Go functions registered for a builtin object of the standard library do not replace its methods
+func TestRegisterFunctionsBuiltin(t *testing.T) {
+	RegisterFunctions("strings", map[string]interface{}{"ToUpper": strings.ToUpper})
+	evaluated := testEval(t, `strings.upper("a") + strings.ToUpper("b")`)
+	if evaluated.Inspect() != "AB" {
+		t.Errorf("wrong result. expected=%q, got=%q", "AB", evaluated.Inspect())
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`match (3, 1) { (a, b) if a > b { a - b } (a, b) { b - a } }`, "2"},
+		{`match (1, 3) { (a, b) if a > b { a - b } (a, b) { b - a } }`, "2"},
+		{`match [1, 2, 3] { [] { 0 } [x, ...rest] { x + len(rest) } }`, "3"},
+		{`match [] { [] { "empty" } [x, ...rest] { x } }`, "empty"},
+		{`match [1, 2] { [x] { x } [x, y, z] { z } else { "none" } }`, "none"},
+		{`match {"name": "bob", "age": 3} { {"name": n, age} { n + str(age) } }`, "bob3"},
+		{`match {"name": "bob"} { {"name": n, age} { n } _ { "no age" } }`, "no age"},
+		{`class P { lit x = 0; lit y = 0; fn init(a, b) { x = a; y = b } }
+match new P(0, 5) { P(x: 0, y) { "y" + str(y) } P(x, y) { x + y } }`, "y5"},
+		{`class P { lit x = 0; lit y = 0; fn init(a, b) { x = a; y = b } }
+match new P(2, 5) { P(x: 0, y) { "y" + str(y) } P(x, y) { x + y } }`, "7"},
+		{`class A {}; class B : A {}; match new B() { A { "an A" } }`, "an A"},
+		{`match -4 { int(n) if n < 0 { "neg" + str(n) } int { "int" } }`, "neg-4"},
+		{`match 2.5 { int { "int" } float { "float" } }`, "float"},
+		{`match "hi" { 0, 1 { "small" } string(s) { s + "!" } }`, "hi!"},
+		{`enum Color { RED, GREEN, BLUE }; match Color.GREEN { Color.RED, Color.GREEN { "warm" } else { "cold" } }`, "warm"},
+		{`match nil { nil { "nil" } }`, "nil"},
+		{`match 10 { 1 { "one" } }`, "nil"},
+		{`lit x = 1; lit r = match 2 { x if x > 1 { x } }; x + r`, "3"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`match 1 { Nope(x) { x } }`, "Nope"},
+		{`class P { lit x = 0 }; match new P() { P(1) { 1 } }`, "must be 'member' or 'member: pattern'"},
+		{`match 1 { int(a, b) { a } }`, "takes one pattern"},
+	}
+	for _, tt := range errors {
+		e, ok := testEval(t, tt.input).(*Error)
+		if !ok || !strings.Contains(e.Message, tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%v", tt.input, tt.expected, e)
+		}
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	decls := `interface Closeable { fn close() }
+interface Named { property Name }
+interface Resource : Closeable, Named { fn open(path, mode) }
+class Base {
+    property Name { get; set; }
+    fn close() { return "closed " + this.Name }
+}
+class File : Base, Resource {
+    fn open(path, mode) { this.Name = path }
+}
+class Plugin : Closeable { fn close() { return "plugin" } }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`lit f = new File(); f.open("a.txt", "r"); f.close()`, "closed a.txt"},
+		{`lit p = new Plugin(); p.close()`, "plugin"},
+		{`lit f = new File(); [is_a(f, Closeable), is_a(f, "Named"), f.instanceOf(Resource), is_a(f, Base)]`, "[true, true, true, true]"},
+		{`lit p = new Plugin(); [is_a(p, Closeable), is_a(p, Named), is_a(p, File)]`, "[true, false, false]"},
+		{`class Sub : File {}; is_a(new Sub(), Resource)`, "true"},
+		{`match new Plugin() { Named { "named" } Closeable { "closeable" } }`, "closeable"},
+		{`Closeable`, "<interface:Closeable>"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(t, decls+tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`class Broken : Base, Resource {}`, "Class(Broken) does not implement method 'open' of interface(Resource)"},
+		{`class Broken : Closeable, Named { fn close() {} }`, "Class(Broken) does not implement property 'Name' of interface(Named)"},
+		{`class Broken : Base, Plugin {}`, "Identifier Plugin is not an interface"},
+		{`interface Broken : Base {}`, "Identifier Base is not an interface"},
+	}
+	for _, tt := range errors {
+		e, ok := testEval(t, decls+tt.input).(*Error)
+		if !ok || !strings.Contains(e.Message, tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%v", tt.input, tt.expected, e)
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	decls := `class Point {
+    lit x = 0
+    lit y: int = 0
+    property Label: string|nil { get; set; }
+    fn init(a: int) { x = a }
+    fn move(v) { y = v }
+    fn shadow(v) { lit y = ""; y = v; return y }
+    fn scale(k: int|float) -> int { return x * k }
+}
+fn add(a: int, b: int) -> int { return a + b }
+fn count(list: array<string>, h: hash<string, int>) -> tuple { return (len(list), len(h)) }
+fn nested(m: array<array<int>>|nil) -> any { return m }
+fn point(p: Point) -> string|nil { return nil }
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`add(1, 2)`, "3"},
+		{`count(["a", "b"], {"x": 1})`, "(2, 1)"},
+		{`nested([[1], [2, 3]])`, "[[1], [2, 3]]"},
+		{`nested(nil)`, "nil"},
+		{`lit p = new Point(3); p.Label = "o"; p.scale(2)`, "6"},
+		{`lit p = new Point(3); point(p)`, "nil"},
+		{`lit p = new Point(3); p.y = 4; p.move(p.y + 1); p.y`, "5"},
+		{`lit p = new Point(3); p.shadow("s")`, "s"},
+		{`lit n: int|nil = nil; n`, "nil"},
+		{`fn f(x: int, y: string = "d", rest...) -> string { return y }; f(1, "z", 9, 10)`, "z"},
+	}
+
+	TypeCheck = true
+	defer func() { TypeCheck = false }()
+	for _, tt := range tests {
+		evaluated := testEval(t, decls+tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`add(1, "2")`, "argument 2('b') of 'add' should be type int, got string"},
+		{`fn f() -> int { return "x" }; f()`, "return value of 'f' should be type int, got string"},
+		{`count(["a", 1], {})`, "argument 1('list') of 'count' should be type array<string>, got array"},
+		{`count([], {"x": "y"})`, "argument 2('h') of 'count' should be type hash<string, int>, got hash"},
+		{`nested([[1, "a"]])`, "should be type array<array<int>>|nil"},
+		{`lit name: string = 1`, "variable 'name' should be type string, got int"},
+		{`lit p = new Point(1); p.Label = 2`, "property 'Label' of class 'Point' should be type string|nil, got int"},
+		{`lit p = new Point(1); p.y = "a"`, "member 'y' of class 'Point' should be type int, got string"},
+		{`lit p = new Point(1); p.move(nil)`, "member 'y' of class 'Point' should be type int, got nil"},
+		{`class Point3 : Point {}; lit p = new Point3(1); p.y = 1.5`, "member 'y' of class 'Point3' should be type int, got float"},
+		{`class B { lit z: int = "a" }; new B()`, "variable 'z' should be type int, got string"},
+		{`class B { static lit z: int = "a" }; B.z`, "variable 'z' should be type int, got string"},
+		{`lit p = new Point("a")`, "argument 1('a') of 'init' should be type int, got string"},
+		{`lit p = new Point(1); p.scale("a")`, "argument 1('k') of 'scale' should be type int|float, got string"},
+		{`point(1)`, "argument 1('p') of 'point' should be type Point, got int"},
+		{`fn g(x: Pont) {}; g(1)`, "unknown type 'Pont'"},
+	}
+	for _, tt := range errors {
+		e, ok := testEval(t, decls+tt.input).(*Error)
+		if !ok || !strings.Contains(e.Message, tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%v", tt.input, tt.expected, e)
+		}
+	}
+
+	TypeCheck = false //the annotations are ignored
+	if evaluated := testEval(t, decls+`add(1, "2")`); evaluated.Inspect() != "12" {
+		t.Errorf("the annotations should be ignored without TypeCheck. got=%q", evaluated.Inspect())
+	}
+}
+
+func TestModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"net/util.aero":  "export fn parse(s) { return _split(s) }\nexport const SEP = \",\"\nfn _split(s) { return s.split(SEP) }\nfn Hidden() { return 1 }\n",
+		"text/util.aero": "fn Format(s) { return \"<\" + s + \">\" }\nfn helper() { return 2 }\nfn _Private() { return 3 }\n",
+		"app/mid.aero":   "require net.util\nfn Run(s) { return util.parse(s) }\n",
+		"cyc/a.aero":     "require cyc.b\n",
+		"cyc/b.aero":     "require cyc.a\n",
+	}
+	for name, src := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(input string, useVM bool) (Object, []string) {
+		UseVM = useVM
+		defer func() { UseVM = false }()
+		p := parser.New(lexer.New("main.aero", input), dir)
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			return nil, p.Errors()
+		}
+		return Eval(program, NewScope(nil, os.Stdout)), nil
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"require net.util\nutil.parse(\"a,b\")", `["a", "b"]`},
+		{"require net.util as nutil\nnutil.SEP", ","},
+		{"require net.util (parse, SEP)\nparse(\"x\" + SEP + \"y\")", `["x", "y"]`},
+		{"require net.util as nutil\nrequire text.util\nutil.Format(nutil.SEP)", "<,>"},
+		{"require app.mid\nmid.Run(\"1,2\")", `["1", "2"]`}, //nested modules
+	}
+	for _, tt := range tests {
+		for _, useVM := range []bool{false, true} {
+			evaluated, errs := run(tt.input, useVM)
+			if errs != nil || evaluated.Inspect() != tt.expected {
+				t.Errorf("wrong result for %q(vm=%v). expected=%q, got=%v %v", tt.input, useVM, tt.expected, evaluated, errs)
+			}
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"require net.util\nutil.Hidden()", "unexported name 'util.Hidden'"},
+		{"require net.util as nutil\nnutil._split(\"a\")", "unexported name 'nutil._split'"},
+		{"require text.util\nutil.helper()", "unexported name 'util.helper'"},
+		{"require text.util\nutil._Private()", "unexported name 'util._Private'"},
+		{"require text.util (helper)", "unexported name 'util.helper'"},
+	}
+	for _, tt := range errors {
+		evaluated, errs := run(tt.input, false)
+		if e, ok := evaluated.(*Error); errs != nil || !ok || !strings.Contains(e.Message, tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%v %v", tt.input, tt.expected, evaluated, errs)
+		}
+	}
+
+	parseErrors := []struct {
+		input    string
+		expected string
+	}{
+		{"require net.util\nrequire text.util", "e3211"},
+		{"require cyc.a", "b.aero -> "},
+		{"fn f() { export lit x = 1 }", "'export' is only allowed at the top level"},
+		{"export println(1)", "'export' must be followed by a declaration"},
+	}
+	for _, tt := range parseErrors {
+		_, errs := run(tt.input, false)
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.expected) {
+			t.Errorf("wrong parser error for %q. expected=%q, got=%v", tt.input, tt.expected, errs)
+		}
+	}
+}
+
+func TestModuleSearchPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"proj/origion.pack":        "dirx/calc.aero\npath=lib\n",
+		"proj/lib/net/util.aero":   "fn Get() { return \"lib\" }\n",
+		"proj/opkg/dirx/calc.aero": "fn Get() { return \"opkg\" }\n",
+		"proj/src/app/helper.aero": "fn Get() { return \"next to the file\" }\n",
+		"proj/src/app/main.aero":   "",
+		"global/g/mod.aero":        "fn Get() { return \"global\" }\n",
+		"global/dirx/calc.aero":    "fn Get() { return \"shadowed by opkg\" }\n",
+		"wd/net/util.aero":         "fn Get() { return \"working directory\" }\n",
+		"cached/l1/m.aero":         "fn Get() { return \"l1\" }\n",
+		"cached/l2/m.aero":         "fn Get() { return \"l2\" }\n",
+		"cached/l2/n.aero":         "require m\nfn Get() { return m.Get() }\n",
+	}
+	for name, src := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv(parser.PathEnv, os.Getenv(parser.PathEnv))
+	os.Setenv(parser.PathEnv, filepath.Join(dir, "nosuchdir")+string(filepath.ListSeparator)+filepath.Join(dir, "global"))
+
+	main := filepath.Join(dir, "proj/src/app/main.aero")
+	tests := []struct {
+		wd       string
+		input    string
+		expected string
+	}{
+		{"proj", "require net.util\nutil.Get()", "lib"},
+		{"wd", "require net.util\nutil.Get()", "working directory"},
+		{"proj", "require dirx.calc\ncalc.Get()", "opkg"},
+		{"proj", "require helper\nhelper.Get()", "next to the file"},
+		{"proj", "require g.mod\nmod.Get()", "global"},
+	}
+	for _, tt := range tests {
+		p := parser.New(lexer.New(main, tt.input), filepath.Join(dir, tt.wd))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Errorf("%q(wd=%s) failed: %v", tt.input, tt.wd, p.Errors())
+			continue
+		}
+		if evaluated := Eval(program, NewScope(nil, os.Stdout)); evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q(wd=%s). expected=%q, got=%q", tt.input, tt.wd, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	p := parser.New(lexer.New(main, "require nosuch.mod"), dir)
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) == 0 || !strings.Contains(errs[0], "e3209") || !strings.Contains(errs[0], filepath.Join(dir, "proj", "opkg")) {
+		t.Errorf("expected e3209 with the searched directories, got=%v", errs)
+	}
+
+	//the cache is not used if a required module is found in an other file: the search
+	//path changed, or a module is added to an earlier directory, e.g. installed in 'opkg/'
+	cached := filepath.Join(dir, "cached/main.aero")
+	changes := []struct {
+		change   string //the file added, or the 'path=' line of the pack file
+		input    string
+		expected string
+	}{
+		{"path=l1", "require m\nm.Get()", "l1"},
+		{"path=l2", "require m\nm.Get()", "l2"},
+		{"", "require m\nm.Get()", "l2"},
+		{"opkg/m.aero", "require m\nm.Get()", "opkg"},
+		{"m.aero", "require m\nm.Get()", "next to the file"},
+		{"path=l2", "require n\nn.Get()", "l2"},
+		{"../m.aero", "require n\nn.Get()", "working directory"}, //required by 'n'
+	}
+	os.Setenv(parser.PathEnv, filepath.Join(dir, "cached/l2"))
+	for _, tt := range changes {
+		if strings.HasPrefix(tt.change, "path=") {
+			ioutil.WriteFile(filepath.Join(dir, "cached", parser.PackFile), []byte(tt.change+"\n"), 0644)
+		} else if tt.change != "" {
+			os.MkdirAll(filepath.Join(dir, "cached", filepath.Dir(tt.change)), 0755)
+			ioutil.WriteFile(filepath.Join(dir, "cached", tt.change), []byte("fn Get() { return \""+tt.expected+"\" }\n"), 0644)
+		} else {
+			ioutil.WriteFile(filepath.Join(dir, "cached", parser.PackFile), nil, 0644)
+		}
+		src := []byte(tt.input)
+		ioutil.WriteFile(cached, src, 0644)
+		p := parser.New(lexer.New(cached, string(src)), dir)
+		program := p.ParseProgramCached(cached, src)
+		importedCache = nil //like a new run of the program, the modules are evaluated again
+		if evaluated := Eval(program, NewScope(nil, os.Stdout)); evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: wrong module loaded after %q. expected=%q, got=%q", tt.input, tt.change, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	codes := make(map[string]bool)
+	for _, info := range ErrorInfos() {
+		if codes[info.Code] {
+			t.Errorf("duplicate error code %s", info.Code)
+		}
+		codes[info.Code] = true
+	}
+
+	for kind := range errorType {
+		info, ok := errorInfos[kind]
+		if !ok {
+			t.Errorf("error kind %d has no code. message=%q", kind, errorType[kind])
+			continue
+		}
+		if info.Example == "" || kind == THROWERROR || kind == FILEMODEERROR {
+			continue //not reported, or the example needs the file system
+		}
+
+		TypeCheck = kind == TYPEMISMATCHERROR //the example needs --typecheck
+		evaluated := evalInput(info.Example, false)
+		TypeCheck = false
+		e, ok := evaluated.(*Error)
+		if !ok {
+			t.Errorf("%s: the example is not an error. got=%T (%+v)", info.Code, evaluated, evaluated)
+			continue
+		}
+		if e.Kind != kind {
+			t.Errorf("%s: the example has the wrong error. got=%q", info.Code, e.Message)
+		}
+		if !strings.Contains(e.Message, "AeroScript: "+info.Code+": ") {
+			t.Errorf("%s: the message has no code. got=%q", info.Code, e.Message)
+		}
+	}
+
+	if info, ok := ExplainError("eude-0019"); !ok || info.Code != "eUDE-0019" {
+		t.Errorf("ExplainError(\"eude-0019\") failed. got=%+v", info)
+	}
+	if _, ok := ExplainError("eUDE-9999"); ok {
+		t.Errorf("ExplainError(\"eUDE-9999\") should fail")
+	}
+}
+
+func TestMacroConditions(t *testing.T) {
+	parser.Defines = map[string]string{"DEBUG": "", "PLATFORM": "linux", "LEVEL": "3"}
+	defer func() { parser.Defines = make(map[string]string) }()
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"lit a = 0; #ifdef DEBUG { a = 1 } #else { a = 2 }\na", 1},
+		{"lit a = 0; #ifdef !DEBUG { a = 1 } #else { a = 2 }\na", 2},
+		{"lit a = 0; #ifdef DEBUG && PLATFORM == \"linux\" { a = 1 }\na", 1},
+		{"lit a = 0; #ifdef RELEASE || PLATFORM != \"linux\" { a = 1 }\na", 0},
+		{"lit a = 0; #ifdef (RELEASE || DEBUG) && LEVEL >= 2 { a = 1 }\na", 1},
+		{"lit a = 0; #ifdef LEVEL > 10 { a = 1 }\na", 0},
+		{"#define LOCAL; lit a = 0; #ifdef LOCAL and !RELEASE { a = 1 }\na", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestOptimizeProgram(t *testing.T) {
+	tests := []struct {
+		input     string
+		optimized string
+		expected  int64
+	}{
+		{"lit a = 2 * (3 + 4) - 1; a;", "lit a = 13a;", 13},
+		{"const N = 4; lit a = N * N; a;", "const  ( N = 4, )lit a = 16a;", 16},
+		{"const N = 4; fn f(N) { N }; f(1) + N;", "const  ( N = 4, ) function f (N) { N; }(f(1) + N);", 5},
+		{"if (false) { 1 } elif (1 < 2) { 2 } else { 3 }", "2;", 2},
+		{"lit a = 1; if (true) { a = 5 }; a;", "lit a = 1a=5;a;", 5},
+		{"unless (true) { 1 } else { 6 }", "6;", 6},
+		{"lit a = 3; 1 + 2; a > 2 ? 7 : 8;", "lit a = 3((a > 2) ? 7 : 8);", 7},
 	}
 
 	for _, tt := range tests {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
+		l := lexer.New("", tt.input)
+		path, _ := os.Getwd()
+		p := parser.New(l, path)
+		program := p.ParseProgram()
+		OptimizeProgram(program)
+		if program.String() != tt.optimized {
+			t.Errorf("wrong optimized program for %q. expected=%q, got=%q", tt.input, tt.optimized, program.String())
+		}
+
+		UseOptimizer = true
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		UseOptimizer = false
 	}
 }
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2 };"
 
-	evaluated := testEval(input)
+	evaluated := testEval(t, input)
 
 	fn, ok := evaluated.(*Function)
 	if !ok {
@@ -598,9 +1279,9 @@ func TestFunctionObject(t *testing.T) {
 		t.Fatalf("parameter is not 'x'. got=%q", fn.Literal.Parameters[0])
 	}
 
-	expectedBody := "(x + 2)"
+	expectedBody := "(x + 2);" //a statement of a block ends with ';'
 	if fn.Literal.Body.String() != expectedBody {
-		t.Fatalf("body is not '(x + 2). got=%q", fn.Literal.Body)
+		t.Fatalf("body is not '(x + 2);'. got=%q", fn.Literal.Body)
 	}
 }
 
@@ -609,14 +1290,14 @@ func TestLetStatements(t *testing.T) {
 		input    string
 		expected int64
 	}{
-		{"let a = 5; a;", 5},
-		{"let a = 5 * 5", 25},
-		{"let a = 5; let b = a;", 5},
-		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+		{"lit a = 5; a;", 5},
+		{"lit a = 5 * 5", 25},
+		{"lit a = 5; lit b = a;", 5},
+		{"lit a = 5; lit b = a; lit c = a + b + 5; c;", 15},
 	}
 
 	for _, tt := range test {
-		testIntegerObject(t, testEval(tt.input), tt.expected)
+		testIntegerObject(t, testEval(t, tt.input), tt.expected)
 	}
 }
 
@@ -627,31 +1308,31 @@ func TestErrorHandling(t *testing.T) {
 	}{
 		{
 			"5 + true;",
-			"unsupported operator for infix expression: '+' and types INTEGER and BOOLEAN",
+			"unsupported operator for infix expression: INTEGER '+' BOOLEAN",
 		},
 		{
 			"5 + true; 5;",
-			"unsupported operator for infix expression: '+' and types INTEGER and BOOLEAN",
+			"unsupported operator for infix expression: INTEGER '+' BOOLEAN",
 		},
 		{
 			"-true",
-			"unsupported operator for prefix expression:'-' and type: BOOLEAN",
+			"unsupported operator for prefix expression:'(-true)' and type: BOOLEAN",
 		},
 		{
 			"true + false;",
-			"unsupported operator for infix expression: '+' and types BOOLEAN and BOOLEAN",
+			"unsupported operator for infix expression: BOOLEAN '+' BOOLEAN",
 		},
 		{
 			"true + false + true + false;",
-			"unsupported operator for infix expression: '+' and types BOOLEAN and BOOLEAN",
+			"unsupported operator for infix expression: BOOLEAN '+' BOOLEAN",
 		},
 		{
 			"5; true + false; 5",
-			"unsupported operator for infix expression: '+' and types BOOLEAN and BOOLEAN",
+			"unsupported operator for infix expression: BOOLEAN '+' BOOLEAN",
 		},
 		{
 			"if (10 > 1) { true + false; }",
-			"unsupported operator for infix expression: '+' and types BOOLEAN and BOOLEAN",
+			"unsupported operator for infix expression: BOOLEAN '+' BOOLEAN",
 		},
 		{
 			`
@@ -663,28 +1344,22 @@ if (10 > 1) {
   return 1;
 }
 `,
-			"unsupported operator for infix expression: '+' and types BOOLEAN and BOOLEAN",
+			"unsupported operator for infix expression: BOOLEAN '+' BOOLEAN",
 		},
 		{"foobar", "unknown identifier: 'foobar' is not defined"},
-		//{`"abc" + 2`, "unsupported operator for infix expression: '+' and types STRING and INTEGER"},
-		{`"abc" - "abc"`, "unsupported operator for infix expression: '-' and types STRING and STRING"},
-		{`"abc" * "abc"`, "unsupported operator for infix expression: '*' and types STRING and STRING"},
-		{`"abc" / "abc"`, "unsupported operator for infix expression: '/' and types STRING and STRING"},
-		{`"abc" > "abc"`, "unsupported operator for infix expression: '>' and types STRING and STRING"},
-		{`"abc" < "abc"`, "unsupported operator for infix expression: '<' and types STRING and STRING"},
-		{`{"name":"Magpie"}[fn(x) {x}];`, "key error: type FUNCTION is not hashable"},
+		//{`"abc" + 2`, "unsupported operator for infix expression: STRING '+' INTEGER"},
+		{`"abc" - "abc"`, "unsupported operator for infix expression: STRING '-' STRING"},
+		{`"abc" * "abc"`, "unsupported operator for infix expression: STRING '*' STRING"},
+		{`"abc" / "abc"`, "unsupported operator for infix expression: STRING '/' STRING"},
+		//the strings are compared, the operators are not supported between a string and an integer
+		{`"abc" > 1`, "unsupported operator for infix expression: STRING '>' INTEGER"},
+		{`"abc" < 1`, "unsupported operator for infix expression: STRING '<' INTEGER"},
+		{`({"name":"Magpie"})[fn(x) {x}];`, "key error: type FUNCTION is not hashable"}, //not a block
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
-		errObj, ok := evaluated.(*Error)
-		if !ok {
-			t.Errorf("no error object returned. got=%T (%+v)", evaluated, evaluated)
-			continue
-		}
-		if errObj.Message != tt.expectedMessage {
-			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
-		}
+		evaluated := testEval(t, tt.input)
+		testErrorObject(t, evaluated, tt.expectedMessage) //the message has the code and the line too
 	}
 }
 
@@ -698,12 +1373,12 @@ func TestReturnStatements(t *testing.T) {
 		{"return 2 * 5; 9;", 10},
 		{"9; return 2 * 5; 9;", 10},
 		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
-		{"let x = 5; return x;", 5},
+		{"lit x = 5; return x;", 5},
 		{"return;", nil},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		if integer, ok := tt.expected.(int); ok {
 			testIntegerObject(t, evaluated, int64(integer))
 		} else {
@@ -726,7 +1401,7 @@ func TestBangOperator(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testBooleanObject(t, evaluated, tt.expected)
 	}
 }
@@ -742,11 +1417,11 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if (1 > 2) {10}", nil},
 		{"if (1 > 2) {10} else {20}", 20},
 		{"if (1 < 2) {10} else {20}", 10},
-		{"let x = 5;if(x == 5) { return;}", nil},
+		{"lit x = 5;if(x == 5) { return;}", nil},
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		if integer, ok := tt.expected.(int); ok {
 			testIntegerObject(t, evaluated, int64(integer))
 		} else {
@@ -773,7 +1448,7 @@ func TestEvalBooleanLiterals(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testBooleanObject(t, evaluated, tt.expected)
 	}
 }
@@ -789,33 +1464,33 @@ func TestEvalBooleanExpressions(t *testing.T) {
 		{"false != true", true},
 		{"(1 < 2) == true", true},
 		{"(2 < 1) == true", false},
-		{"let x = 5;x == 5", true},
-		{"let x = 5; x != 5", false},
-		{"let x = 5; x > 5", false},
-		{"let x = 4; x < 5", true},
-		{"let x = 4; (x + 5) > 5", true},
+		{"lit x = 5;x == 5", true},
+		{"lit x = 5; x != 5", false},
+		{"lit x = 5; x > 5", false},
+		{"lit x = 4; x < 5", true},
+		{"lit x = 4; (x + 5) > 5", true},
 		{`"abc" == "abc"`, true},
 		{`"abc" == "bc"`, false},
 		{`"abc" != "abc"`, false},
 		{`"abc" != "bc"`, true},
-		{`let x = "abc"; x == "abc"`, true},
-		{`let x = fn(){ "abc" }; x() == "abc"`, true},
+		{`lit x = "abc"; x == "abc"`, true},
+		{`lit x = fn(){ "abc" }; x() == "abc"`, true},
 		{"true and true", true},
 		{"true and false", false},
 		{"true or true", true},
 		{"true or false", true},
 		{`"string" and false`, false},
-		{`[] or false`, true},
+		{`[] or false`, false}, //an empty array is false
 		{`len([1,2,3]) > 2 and false`, false},
 		{`type([]) == "ARRAY" and len([1234]) == 4`, false},
 		{`type([]) == "ARRAY" and len("1234") == 4`, true},
 		{"(true and true) or (true or false)", true},
 		{"(true and true) and (true and false)", false},
-		{`!!"abc".find("d")`, false},
+		{`!!"abc".find("d")`, true}, //'find' returns -1 if not found
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testBooleanObject(t, evaluated, tt.expected)
 	}
 }
@@ -848,11 +1523,11 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"5 * 2 + 10", 20},
 		{"5 + 2 * 10", 25},
 		{"20 + 2 * -10", 0},
-		{"50 / 2 * 2 + 10", 60},
+		{"int(50 / 2 * 2 + 10)", 60}, //'/' returns a float
 		{"2 * (5 + 10)", 30},
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
-		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"int((5 + 10 * 2 + 15 / 3) * 2 + -10)", 50},
 		{"20 % 4", 0},
 		{"20 % 3", 2},
 		{"5 * 4 % 3", 2},
@@ -863,13 +1538,26 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testIntegerObject(t, evaluated, tt.expected)
 	}
 }
 
-func testEval(input string) Object {
-	l := lexer.New(input)
+//testEval evaluates the input with the tree-walker, and checks that the VM gets the same result.
+func testEval(t *testing.T, input string) Object {
+	t.Helper()
+	evaluated := evalInput(input, false)
+	if vmEvaluated := evalInput(input, true); vmEvaluated.Inspect() != evaluated.Inspect() {
+		t.Errorf("the VM got a different result for %q. tree-walker=%s, VM=%s", input, evaluated.Inspect(), vmEvaluated.Inspect())
+	}
+	return evaluated
+}
+
+func evalInput(input string, useVM bool) Object {
+	UseVM = useVM
+	defer func() { UseVM = false }()
+
+	l := lexer.New("", input)
 	path, _ := os.Getwd()
 	p := parser.New(l, path)
 	s := NewScope(nil, os.Stdout)
@@ -883,6 +1571,22 @@ func testEval(input string) Object {
 	return Eval(program, s)
 }
 
+//testErrorObject checks the message of the error, without the code of the error
+//and the line it occurred at.
+func testErrorObject(t *testing.T, obj Object, expected string) bool {
+	t.Helper()
+	result, ok := obj.(*Error)
+	if !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if !strings.Contains(result.Message, expected) {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, result.Message)
+		return false
+	}
+	return true
+}
+
 func testIntegerObject(t *testing.T, obj Object, expected int64) bool {
 	result, ok := obj.(*Integer)
 	if !ok {
@@ -914,18 +1618,18 @@ func TestInterpolation(t *testing.T) {
 		input    string
 		expected string
 	}{
-		{`let x = 5; 'abc{x}'`, "abc5"},
+		{`lit x = 5; 'abc{x}'`, "abc5"},
 		{`'abc{x}'`, "abcx"},
 		{`'abc{5 + 5}abc'`, "abc10abc"},
-		{`let x = fn(x) { x * 5 };'{x(1)}{x(5)}{x(10)}'`, "52550"},
-		{`let x = fn(x) { x * 5 };'abcdef{x(10)}'`, "abcdef50"},
+		{`lit x = fn(x) { x * 5 };'{x(1)}{x(5)}{x(10)}'`, "52550"},
+		{`lit x = fn(x) { x * 5 };'abcdef{x(10)}'`, "abcdef50"},
 		{`'abcdef{(10 * 5)}'`, "abcdef50"},
 		{`'{10 + 10}abcdef{(10 * 5)}'`, "20abcdef50"},
-		{`let x = 5; let y = '{x}';'{y}abcdef{(10 * x)}'`, "5abcdef50"},
+		{`lit x = 5; lit y = '{x}';'{y}abcdef{(10 * x)}'`, "5abcdef50"},
 	}
 
 	for _, tt := range input {
-		evaluated := testEval(tt.input)
+		evaluated := testEval(t, tt.input)
 		testInterpolatedStringObject(t, evaluated, tt.expected)
 	}
 }
@@ -0,0 +1,155 @@
+package eval
+
+import (
+	"fmt"
+	"originscript/lexer"
+	"originscript/parser"
+)
+
+//The builtin exception classes. 'throw' takes an instance of 'Exception' or of
+//its subclasses(or a string). The failures of the file, net, sql and json functions
+//return nil with the message, unless the program runs with '--exceptions', then
+//they raise the subclasses(see RaiseExceptions):
+//
+//    try {
+//        lit f = open("data.txt")
+//    } catch (e: IOError) {
+//        println(e.message)
+//    }
+//
+//The 'stack' of an exception is set when it's thrown or raised, it's an array
+//of strings like the lines of a traceback, the outermost call first.
+const exceptionClasses = `
+class Exception {
+    lit message = ""
+    lit cause = nil
+    lit stack = []
+
+    //init(message = "", cause = nil)
+    fn init(args...) {
+        if len(args) > 0 { this.message = args[0] }
+        if len(args) > 1 { this.cause = args[1] }
+    }
+
+    fn getMessage() { return this.message }
+    fn getCause() { return this.cause }
+    fn getStack() { return this.stack }
+    fn toString() { return this.classOf() + ": " + this.message }
+}
+
+class IOError : Exception {}
+class NetError : Exception {}
+class SQLError : Exception {}
+class JSONError : Exception {}
+`
+
+var (
+	EXCEPTION_CLASS *Class
+	IOERROR_CLASS   *Class
+	NETERROR_CLASS  *Class
+	SQLERROR_CLASS  *Class
+	JSONERROR_CLASS *Class
+)
+
+func init() {
+	p := parser.New(lexer.New("<builtin>", exceptionClasses), "")
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		panic(fmt.Sprintf("builtin exception classes: %v", p.Errors()))
+	}
+
+	scope := NewScope(nil, nil)
+	Eval(program, scope)
+
+	classes := map[string]**Class{
+		"Exception": &EXCEPTION_CLASS,
+		"IOError":   &IOERROR_CLASS,
+		"NetError":  &NETERROR_CLASS,
+		"SQLError":  &SQLERROR_CLASS,
+		"JSONError": &JSONERROR_CLASS,
+	}
+	for name, class := range classes {
+		obj, _ := scope.Get(name)
+		*class = obj.(*Class)
+		BuiltinClasses[name] = *class
+		parser.BuiltinClasses[name] = true
+	}
+}
+
+//RaiseExceptions makes the failures of the file, net, sql and json functions raise
+//an 'IOError', a 'NetError', a 'SQLError' or a 'JSONError'(--exceptions). Without it
+//they return nil with the message, which the scripts check. The failures which stop
+//the program anyway(e.g. 'json.marshal' of a function) always raise an exception.
+var RaiseExceptions bool
+
+//nilFailure returns the result of a function of the standard library failing with
+//'err': nil with the message, or an exception of 'class' with '--exceptions'.
+func nilFailure(line string, class *Class, err error) Object {
+	if RaiseExceptions {
+		return newException(line, class, err.Error())
+	}
+	return NewNil(err.Error())
+}
+
+//falseFailure is nilFailure for the functions returning false when they fail.
+func falseFailure(line string, class *Class, err error) Object {
+	if RaiseExceptions {
+		return newException(line, class, err.Error())
+	}
+	return NewFalseObj(err.Error())
+}
+
+//newException returns the error raising a new exception of 'class' with the
+//message 'msg'. A 'catch' clause handles it like a thrown exception, if it's
+//not handled, it stops the program like the other errors.
+func newException(line string, class *Class, msg string) Object {
+	instance, _ := newInstance(class, class.Scope) //the exception classes have no typed members
+	init := class.GetMethod("init")
+	ret := evalFunctionDirect(init, []Object{NewString(msg)}, instance, instance.Scope, nil)
+	if ret.Type() == ERROR_OBJ {
+		return ret
+	}
+
+	e := NewError(line, EXCEPTION, class.Name, msg).(*Error)
+	e.Exception = instance
+	return e
+}
+
+//isException reports if 'obj' is an instance of 'Exception'.
+func isException(obj Object) bool {
+	instance, ok := obj.(*ObjectInstance)
+	return ok && InstanceOf(EXCEPTION_CLASS.Name, instance)
+}
+
+//exceptionString returns 'IOError: message' for an exception, and the
+//Inspect() of the other thrown objects.
+func exceptionString(obj Object) string {
+	if !isException(obj) {
+		return obj.Inspect()
+	}
+	instance := obj.(*ObjectInstance)
+	msg, _ := instance.Scope.Get("message")
+	if msg == nil {
+		return instance.Class.Name
+	}
+	return instance.Class.Name + ": " + msg.Inspect()
+}
+
+//setExceptionStack sets the 'stack' of an exception if it's not set yet.
+func setExceptionStack(obj Object, stack []StackFrame) {
+	if !isException(obj) || len(stack) == 0 {
+		return
+	}
+	instance := obj.(*ObjectInstance)
+	if old, ok := instance.Scope.Get("stack"); ok {
+		if arr, ok := old.(*Array); ok && len(arr.Members) > 0 {
+			return
+		}
+	}
+
+	arr := &Array{}
+	for _, frame := range stack {
+		arr.Members = append(arr.Members, NewString(frame.String()))
+	}
+	instance.Scope.Reset("stack", arr)
+}
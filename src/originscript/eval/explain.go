@@ -0,0 +1,502 @@
+package eval
+
+import (
+	"sort"
+	"strings"
+)
+
+//ErrorInfo documents an error code, it's shown by 'origion explain <code>'.
+type ErrorInfo struct {
+	Code        string //e.g. "eUDE-0019", it never changes once released
+	Title       string
+	Description string
+	Example     string //a minimal program failing with the error, "" if it's not reported any more
+	Fix         string
+}
+
+//The codes of the parser's errors, they have no sub-codes.
+var parserErrorInfos = []*ErrorInfo{
+	{
+		Code:        "e3209",
+		Title:       "Import error",
+		Description: "The module of a 'require'/'import' statement was not found in the module search path, the message lists the searched directories.",
+		Example:     "require nosuchmodule",
+		Fix:         "Check the spelling of the module path, and that the '.aero' file of the module exists. Add its directory to ORIGION_PATH, or a 'path=' line to the project's origion.pack, run 'which <module>' to check.",
+	},
+	{
+		Code:        "e3210",
+		Title:       "Import cycle",
+		Description: "A module requires(directly or through other modules) a file requiring it, the message shows the whole chain.",
+		Fix:         "Move the code both modules need to a third module, required by both.",
+	},
+	{
+		Code:        "e3211",
+		Title:       "Import name conflict",
+		Description: "Two different modules are required with the same name, e.g. 'require a.util' and 'require b.util'.",
+		Fix:         "Give one of them another name with 'as', e.g. 'require b.util as butil'.",
+	},
+	{
+		Code:        "e3301",
+		Title:       "Syntax error",
+		Description: "The program could not be parsed, e.g. a missing parenthesis or brace, or an unexpected token.",
+		Example:     "lit x = (1 + 2",
+		Fix:         "Fix the code at the reported line and column, the message tells which token was expected.",
+	},
+}
+
+//The codes of the runtime errors(the 'errorType' kinds). The codes are part
+//of the error messages, and scripts or tools may depend on them: a code must
+//never be reused, the new kinds get the next free number.
+var errorInfos = map[int]*ErrorInfo{
+	PREFIXOP: {
+		Code:        "eUDE-0001",
+		Title:       "Unsupported prefix operator",
+		Description: "The prefix operator cannot be applied to a value of this type.",
+		Example:     `lit s = "abc"; -s`,
+		Fix:         "Convert the operand to a type supporting the operator(e.g. int(s)), or define the operator for the class.",
+	},
+	INFIXOP: {
+		Code:        "eUDE-0002",
+		Title:       "Unsupported infix operator",
+		Description: "The binary operator is not defined for the types of its operands.",
+		Example:     `lit x = true - 1`,
+		Fix:         "Convert one of the operands(e.g. with int() or str()) so both have compatible types.",
+	},
+	POSTFIXOP: {
+		Code:        "eUDE-0003",
+		Title:       "Unsupported postfix operator",
+		Description: "'++' and '--' only work with numbers, or with classes defining them.",
+		Example:     `lit s = "a"; s++`,
+		Fix:         "Use the operator on a numeric variable, or write the assignment explicitly.",
+	},
+	MOD_ASSIGNOP: {
+		Code:        "eUDE-0004",
+		Title:       "Unsupported modulo assignment",
+		Description: "'%=' is not supported for the operands. This error is not reported by the current version.",
+		Fix:         "Use 'x = x % y' with numeric operands.",
+	},
+	UNKNOWNIDENT: {
+		Code:        "eUDE-0005",
+		Title:       "Unknown identifier",
+		Description: "The name is not defined in the current scope, nor in the enclosing scopes or the globals.",
+		Example:     `println(undefinedName)`,
+		Fix:         "Declare the variable with 'lit' before using it, or check the spelling.",
+	},
+	UNKNOWNIDENTEX: {
+		Code:        "eUDE-0006",
+		Title:       "Unknown identifier, with suggestions",
+		Description: "The name is not defined, but similar names are: the message lists them.",
+		Example:     "lit counter = 1\nprintln(countr)",
+		Fix:         "Use one of the suggested names if it's a typo, or declare the variable.",
+	},
+	NOMETHODERROR: {
+		Code:        "eUDE-0007",
+		Title:       "Undefined method",
+		Description: "The object has no method with this name.",
+		Example:     `lit a = [1, 2]; a.noSuchMethod()`,
+		Fix:         "Check the methods of the type in the documentation, or the spelling of the method.",
+	},
+	NOMETHODERROREX: {
+		Code:        "eUDE-0008",
+		Title:       "Undefined method, with suggestions",
+		Description: "The class or module has no method with this name, but similar names exist: the message lists them.",
+		Example:     "class A { static fn hello() { return 1 } }\nA.helo()",
+		Fix:         "Use one of the suggested methods if it's a typo.",
+	},
+	NOINDEXERROR: {
+		Code:        "eUDE-0009",
+		Title:       "Value is not indexable",
+		Description: "'x[i]' only works with arrays, strings, hashes, tuples and classes with an indexer.",
+		Example:     `lit x = 10; x[0]`,
+		Fix:         "Index an array, string, hash or tuple, or add an indexer('property this[i]') to the class.",
+	},
+	KEYERROR: {
+		Code:        "eUDE-0010",
+		Title:       "Value is not hashable",
+		Description: "Hash keys must be hashable: strings, numbers, booleans...(not arrays or hashes). The keys of a struct or an enum must be names.",
+		Example:     `lit h = {"a": 1}; h -= [1]`,
+		Fix:         "Use a string or a number as the key, e.g. convert the value with str().",
+	},
+	INDEXERROR: {
+		Code:        "eUDE-0011",
+		Title:       "Index out of range",
+		Description: "The index is not between 0 and the length minus one.",
+		Example:     `lit a = []; a.pop()`,
+		Fix:         "Check the index against len() before using it.",
+	},
+	SLICEERROR: {
+		Code:        "eUDE-0012",
+		Title:       "Slice out of range",
+		Description: "The bounds of a slice 'x[start:end]' are out of the range of the value.",
+		Example:     `lit s = "abc"; s[2:1]`,
+		Fix:         "Make sure 0 <= start <= end <= len(x).",
+	},
+	ARGUMENTERROR: {
+		Code:        "eUDE-0013",
+		Title:       "Wrong number of arguments",
+		Description: "A builtin function or method was called with too many or too few arguments.",
+		Example:     `len()`,
+		Fix:         "Pass the number of arguments given in the message('expected=').",
+	},
+	INPUTERROR: {
+		Code:        "eUDE-0014",
+		Title:       "Unsupported input",
+		Description: "The argument has the right type, but its value cannot be converted, e.g. int(\"abc\").",
+		Example:     `int("abc")`,
+		Fix:         "Validate the input before converting it.",
+	},
+	RTERROR: {
+		Code:        "eUDE-0015",
+		Title:       "Wrong return type",
+		Description: "A callback returned a value of the wrong type.",
+		Fix:         "Return a value of the type given in the message from the callback.",
+	},
+	PARAMTYPEERROR: {
+		Code:        "eUDE-0016",
+		Title:       "Wrong argument type",
+		Description: "An argument of a builtin function or method has the wrong type.",
+		Example:     `"abc".substr("1", 2)`,
+		Fix:         "Pass a value of the type given in the message, convert it if needed(e.g. str(1)).",
+	},
+	INLENERR: {
+		Code:        "eUDE-0017",
+		Title:       "Input too long",
+		Description: "The function takes a value with a maximum length, e.g. ord() takes one character.",
+		Example:     `ord("ab")`,
+		Fix:         "Pass a shorter value, e.g. a single character.",
+	},
+	INVALIDARG: {
+		Code:        "eUDE-0018",
+		Title:       "Invalid argument",
+		Description: "An argument has an invalid value, e.g. a negative length or an invalid regular expression.",
+		Example:     `"abc".substr(-1, 2)`,
+		Fix:         "Check the valid values of the arguments in the documentation.",
+	},
+	DIVIDEBYZERO: {
+		Code:        "eUDE-0019",
+		Title:       "Division by zero",
+		Description: "An integer was divided by zero, or the remainder of a division by zero was computed.",
+		Example:     "fn div(a, b) { return a / b }\ndiv(1, 0)",
+		Fix:         "Check the divisor before dividing.",
+	},
+	THROWERROR: {
+		Code:        "eUDE-0020",
+		Title:       "Invalid throw object",
+		Description: "Not reported any more: 'throw' takes any value. Only the instances of 'Exception' and of its subclasses are handled by the typed catch clauses, the other values by 'catch e'.",
+		Example:     `try { throw 42 } catch (e: Exception) { }`,
+		Fix:         "Throw an exception, e.g. 'throw new Exception(\"message\")', or catch the value with 'catch e'.",
+	},
+	THROWNOTHANDLED: {
+		Code:        "eUDE-0021",
+		Title:       "Exception not handled",
+		Description: "A thrown exception was not caught by any 'catch' clause, so it stopped the program.",
+		Example:     `throw new Exception("failed")`,
+		Fix:         "Catch the exception with 'try { ... } catch (e: Exception) { ... }'.",
+	},
+	RETHROWERROR: {
+		Code:        "eUDE-0022",
+		Title:       "Rethrow outside of a catch clause",
+		Description: "'throw' without an object rethrows the exception handled by the enclosing 'catch' clause, there is none.",
+		Example:     `throw`,
+		Fix:         "Use 'throw' without an object only inside a 'catch' clause, or give the object to throw.",
+	},
+	GREPMAPNOTITERABLE: {
+		Code:        "eUDE-0023",
+		Title:       "grep/map on a non-iterable value",
+		Description: "'gp' and 'map' work on iterable values: arrays, strings, hashes, tuples, ranges...",
+		Example:     `lit r = gp $_ > 1, 10`,
+		Fix:         "Pass an array or another iterable value.",
+	},
+	NOTITERABLE: {
+		Code:        "eUDE-0024",
+		Title:       "Value is not iterable",
+		Description: "'for x in value' and the comprehensions need an iterable value: arrays, strings, hashes, tuples, ranges...",
+		Example:     `for x in 10 { println(x) }`,
+		Fix:         "Iterate over an array or a range, e.g. 'for x in 0..9'.",
+	},
+	RANGETYPEERROR: {
+		Code:        "eUDE-0025",
+		Title:       "Wrong type for a range",
+		Description: "The bounds of a range must be integers(or characters for a string range).",
+		Example:     `for x in 1..true { println(x) }`,
+		Fix:         "Use integers for the bounds of the range.",
+	},
+	DEFERERROR: {
+		Code:        "eUDE-0026",
+		Title:       "Invalid defer",
+		Description: "'defer' is only valid inside a function, and must be followed by a function call.",
+		Example:     `defer println("bye")`,
+		Fix:         "Move the 'defer' statement into a function.",
+	},
+	SPAWNERROR: {
+		Code:        "eUDE-0027",
+		Title:       "Invalid spawn",
+		Description: "'spawn' must be followed by a function call.",
+		Example:     `spawn 1 + 2`,
+		Fix:         "Spawn a function call, e.g. 'spawn fn() { ... }()'.",
+	},
+	ASSERTIONERROR: {
+		Code:        "eUDE-0028",
+		Title:       "Assertion failed",
+		Description: "The condition of assert() is false.",
+		Example:     `assert(1 > 2)`,
+		Fix:         "Fix the code or the condition, the assertion tells what was expected.",
+	},
+	ASSERTIONERROREX: {
+		Code:        "eUDE-0029",
+		Title:       "Assertion failed, with details",
+		Description: "An assertion(e.g. assertEqual) failed, the message shows the expected and the actual values.",
+		Example:     `assertEqual(1, 2)`,
+		Fix:         "Fix the code or the expected value of the assertion.",
+	},
+	NULLABLEERROR: {
+		Code:        "eUDE-0030",
+		Title:       "Null value",
+		Description: "A value is null where it must not be. This error is not reported by the current version.",
+		Fix:         "Check the value with '!= nil' before using it.",
+	},
+	JSONERROR: {
+		Code:        "eUDE-0031",
+		Title:       "JSON error",
+		Description: "A value could not be converted to or from JSON. The json module raises a 'JSONError' exception instead now.",
+		Fix:         "Catch the 'JSONError' exception, and check the data and the types of the values.",
+	},
+	DBSCANERROR: {
+		Code:        "eUDE-0032",
+		Title:       "Unsupported scan type",
+		Description: "A database row cannot be scanned into a value of this type. The sql module raises a 'SQLError' exception instead now.",
+		Fix:         "Catch the 'SQLError' exception, and scan into integers, floats, strings, booleans or times.",
+	},
+	FUNCCALLBACKERROR: {
+		Code:        "eUDE-0033",
+		Title:       "Wrong callback parameters",
+		Description: "The callback function given to a builtin has the wrong number of parameters.",
+		Example:     `lit r = /a/.replaceAllStringFunc("abc", fn(a, b) { return a })`,
+		Fix:         "Declare the number of parameters given in the message.",
+	},
+	FILEMODEERROR: {
+		Code:        "eUDE-0034",
+		Title:       "Unknown file mode",
+		Description: "The mode of open()/newFile() is not one of the known modes, e.g. \"r\", \"w\", \"a\", \"r+\"...",
+		Example:     `open("data.txt", "x")`,
+		Fix:         "Use a known file mode.",
+	},
+	FILEOPENERROR: {
+		Code:        "eUDE-0035",
+		Title:       "File open failed",
+		Description: "A file could not be opened. Not reported any more: open() and newFile() return nil, whose message() is the reason, or raise an 'IOError' with --exceptions.",
+		Fix:         "Check the result of open() against nil(or catch the 'IOError'), and the path and the permissions of the file.",
+	},
+	NOTCLASSERROR: {
+		Code:        "eUDE-0036",
+		Title:       "Not a class",
+		Description: "A class name was expected(after 'new', as a parent class, or in a catch clause), but the name refers to another value.",
+		Example:     "lit Animal = 1\nclass Dog : Animal {}",
+		Fix:         "Use the name of a class, and check that no variable shadows it.",
+	},
+	PARENTNOTDECL: {
+		Code:        "eUDE-0037",
+		Title:       "Parent class not declared",
+		Description: "The parent class of a class declaration is not defined.",
+		Example:     `class Dog : Animal {}`,
+		Fix:         "Declare the parent class before the class, or require its module.",
+	},
+	CLSNOTDEFINE: {
+		Code:        "eUDE-0038",
+		Title:       "Class not defined",
+		Description: "The class of a 'new' expression or of a typed catch clause is not defined.",
+		Example:     `try { throw "x" } catch (e: NoSuchError) { }`,
+		Fix:         "Declare the class, or require the module defining it.",
+	},
+	CLSMEMBERPRIVATE: {
+		Code:        "eUDE-0039",
+		Title:       "Private member",
+		Description: "A private variable of a class was used from outside the class. This error is not reported by the current version.",
+		Fix:         "Use a public method or property of the class.",
+	},
+	CLSCALLPRIVATE: {
+		Code:        "eUDE-0040",
+		Title:       "Private method",
+		Description: "A private method of a class was called from outside the class. This error is not reported by the current version.",
+		Fix:         "Call a public method of the class.",
+	},
+	PROPERTYUSEERROR: {
+		Code:        "eUDE-0041",
+		Title:       "Invalid use of a property",
+		Description: "A property was read without a getter, assigned without a setter, or a static property was used through an instance.",
+		Example:     "class A { property P { set; } }\nlit a = new A()\nlit v = a.P",
+		Fix:         "Add the missing 'get' or 'set' to the property, or use static properties through the class.",
+	},
+	MEMBERUSEERROR: {
+		Code:        "eUDE-0042",
+		Title:       "Invalid use of a member",
+		Description: "An instance variable was used through the class, or a static variable through an instance.",
+		Example:     "class A { lit x = 1 }\nA.x = 2",
+		Fix:         "Use instance variables through an instance('new A().x'), and static ones through the class.",
+	},
+	INDEXERUSEERROR: {
+		Code:        "eUDE-0043",
+		Title:       "Invalid use of an indexer",
+		Description: "The indexer of the class has no getter(for reading) or no setter(for assigning).",
+		Example:     "class A { property this[i] { get { return i } } }\nlit a = new A()\na[0] = 1",
+		Fix:         "Add the missing getter or setter to the indexer.",
+	},
+	INDEXERTYPEERROR: {
+		Code:        "eUDE-0044",
+		Title:       "Wrong indexer type",
+		Description: "Only integer indexers are supported. This error is not reported by the current version.",
+		Fix:         "Use an integer index.",
+	},
+	INDEXERSTATICERROR: {
+		Code:        "eUDE-0045",
+		Title:       "Static indexer",
+		Description: "An indexer cannot be static.",
+		Example:     "class A { static property this[i] { get { return i } set { } } }\nlit a = new A()\na[0] = 1",
+		Fix:         "Remove 'static' from the indexer.",
+	},
+	INDEXNOTFOUNDERROR: {
+		Code:        "eUDE-0046",
+		Title:       "Indexer not found",
+		Description: "An instance was indexed, but its class has no indexer with this number of indexes.",
+		Example:     "class A {}\nlit a = new A()\na[0] = 1",
+		Fix:         "Declare an indexer: 'property this[i] { get { ... } set { ... } }'.",
+	},
+	CALLNONSTATICERROR: {
+		Code:        "eUDE-0047",
+		Title:       "Call of a non-static method",
+		Description: "A method which is not static was called through the class instead of an instance.",
+		Example:     "class A { fn f() { return 1 } }\nA.f()",
+		Fix:         "Call the method through an instance(new A().f()), or declare it 'static'.",
+	},
+	CLASSCATEGORYERROR: {
+		Code:        "eUDE-0048",
+		Title:       "Category of an unknown class",
+		Description: "A class category('class Name (Category) { ... }') extends a class which is not defined.",
+		Example:     `class NoSuchClass (extras) { fn f() { return 1 } }`,
+		Fix:         "Declare the class before its categories.",
+	},
+	CLASSCREATEERROR: {
+		Code:        "eUDE-0049",
+		Title:       "Class called like a function",
+		Description: "A class was called like a function, instances are created with 'new'.",
+		Example:     "class A {}\nlit a = A()",
+		Fix:         "Use 'new A()'.",
+	},
+	PARENTNOTANNOTATION: {
+		Code:        "eUDE-0050",
+		Title:       "Annotation with a class parent",
+		Description: "The parent of an annotation class must be an annotation class.",
+		Example:     "class A {}\nclass @B : A {}",
+		Fix:         "Make the parent an annotation('class @A {}'), or remove the parent.",
+	},
+	OVERRIDEERROR: {
+		Code:        "eUDE-0051",
+		Title:       "Override of a missing method",
+		Description: "A method annotated with @Override does not override a method of a parent class.",
+		Example:     "class A {}\nclass B : A {\n    @Override\n    fn f() { return 1 }\n}",
+		Fix:         "Check the name of the method, or remove the @Override annotation.",
+	},
+	NOTINTERFACEERROR: {
+		Code:        "eUDE-0060",
+		Title:       "Not an interface",
+		Description: "The names after the parent class of a class, and the parents of an interface, must be interfaces.",
+		Example:     "class A {}\nclass B {}\nclass C : A, B {}",
+		Fix:         "Declare the name with 'interface', or make the class the parent class.",
+	},
+	INTERFACEERROR: {
+		Code:        "eUDE-0061",
+		Title:       "Interface not implemented",
+		Description: "A class declared with an interface must have all the methods and properties the interface(and its parents) requires, its own or inherited.",
+		Example:     "interface Closeable { fn close() }\nclass File : Closeable {}",
+		Fix:         "Add the missing method or property to the class.",
+	},
+	TYPEMISMATCHERROR: {
+		Code:        "eUDE-0062",
+		Title:       "Type mismatch",
+		Description: "With --typecheck, the arguments and the return value of a function, a 'lit' declaration and an assignment to a property are checked against their type annotations.",
+		Example:     "fn add(a: int, b: int) -> int { return a + b }\nadd(1, \"2\")",
+		Fix:         "Pass a value of the annotated type, or change the annotation(e.g. 'int|nil' or 'any').",
+	},
+	METAOPERATORERROR: {
+		Code:        "eUDE-0052",
+		Title:       "Invalid meta-operator item",
+		Description: "The items of the arrays of a meta-operator('~+' ...) must be numbers or strings.",
+		Example:     `lit r = [1, [2]] ~+ [3, 4]`,
+		Fix:         "Use arrays of numbers or strings with the meta-operators.",
+	},
+	SERVICENOURLERROR: {
+		Code:        "eUDE-0053",
+		Title:       "Service function without url",
+		Description: "Every function of a 'service' must have an '@route' annotation with a url.",
+		Fix:         "Add '@route(url=\"...\")' to the function of the service.",
+	},
+	CONSTNOTASSIGNERROR: {
+		Code:        "eUDE-0054",
+		Title:       "Assignment to a constant",
+		Description: "A constant declared with 'const' cannot be modified.",
+		Example:     "const PI = 3.14\nPI = 3",
+		Fix:         "Use a variable('lit') if the value changes.",
+	},
+	DIAMONDOPERERROR: {
+		Code:        "eUDE-0055",
+		Title:       "Diamond operator without a file",
+		Description: "The diamond operator('<$fobj>') reads a line from a file object.",
+		Example:     "lit x = 1\nlit line = <$x>",
+		Fix:         "Use the diamond operator with a file object, e.g. one returned by open().",
+	},
+	NAMENOTEXPORTED: {
+		Code:        "eUDE-0056",
+		Title:       "Name not exported",
+		Description: "Only the names a module marks with 'export'(or its capitalized names if it has no 'export') can be used by the files requiring it, the names starting with '_' are always private.",
+		Fix:         "Mark the declaration with 'export' in the module if it should be public.",
+	},
+	IMPORTERROR: {
+		Code:        "eUDE-0057",
+		Title:       "Import failed",
+		Description: "Evaluating a required module failed, the module has a runtime error.",
+		Fix:         "Run the module itself to see its error.",
+	},
+	EXCEPTION: {
+		Code:        "eUDE-0058",
+		Title:       "Exception raised by the standard library",
+		Description: "A function of the standard library failed and raised an exception which was not caught: a 'JSONError' or a 'SQLError' for an unsupported type, and with --exceptions an 'IOError', a 'NetError', a 'SQLError' or a 'JSONError' for any failure.",
+		Example:     `json.marshal(fn() {})`,
+		Fix:         "Catch the exception: 'try { ... } catch (e: JSONError) { ... }', or fix its cause given in the message.",
+	},
+	GENERICERROR: {
+		Code:        "eUDE-0059",
+		Title:       "Runtime error",
+		Description: "A runtime error without a specific code, the message describes it.",
+		Example:     `range(1, -1)`,
+		Fix:         "Fix the code according to the message.",
+	},
+}
+
+//ErrorCode returns the code of a runtime error kind, e.g. "eUDE-0019".
+func ErrorCode(kind int) string {
+	if info, ok := errorInfos[kind]; ok {
+		return info.Code
+	}
+	return "eUDE"
+}
+
+//ExplainError returns the documentation of an error code, the code is case
+//insensitive.
+func ExplainError(code string) (*ErrorInfo, bool) {
+	for _, info := range ErrorInfos() {
+		if strings.EqualFold(info.Code, code) {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+//ErrorInfos returns the documentation of all the error codes, sorted by code.
+func ErrorInfos() []*ErrorInfo {
+	infos := append([]*ErrorInfo{}, parserErrorInfos...)
+	for _, info := range errorInfos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
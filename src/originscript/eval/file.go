@@ -56,7 +56,7 @@ func (i *IOUtilObj) ReadAll(line string, args ...Object) Object {
 	reader := bufio.NewReader(fObj.File)
 	b, err := ioutil.ReadAll(reader)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 	return NewString(string(b))
 }
@@ -73,7 +73,7 @@ func (i *IOUtilObj) ReadDir(line string, args ...Object) Object {
 
 	files, err := ioutil.ReadDir(dirname.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	arr := &Array{}
@@ -96,7 +96,7 @@ func (i *IOUtilObj) ReadFile(line string, args ...Object) Object {
 
 	b, err := ioutil.ReadFile(filename.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewString(string(b))
@@ -119,7 +119,7 @@ func (i *IOUtilObj) TempDir(line string, args ...Object) Object {
 
 	name, err := ioutil.TempDir(dir.String, prefix.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewString(name)
@@ -142,7 +142,7 @@ func (i *IOUtilObj) TempFile(line string, args ...Object) Object {
 
 	f, err := ioutil.TempFile(dir.String, prefix.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return &FileObject{File: f, Name: f.Name()}
@@ -170,7 +170,7 @@ func (i *IOUtilObj) WriteFile(line string, args ...Object) Object {
 
 	err := ioutil.WriteFile(filename.String, []byte(data.String), os.FileMode(int(perm.Int64)))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, IOERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -233,7 +233,7 @@ func (f *FileObject) Close(line string, args ...Object) Object {
 	}
 	err := f.File.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, IOERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -255,13 +255,13 @@ func (f *FileObject) Read(line string, args ...Object) Object {
 	buffer := make([]byte, int(readlen.Int64))
 	n, err := f.File.Read(buffer)
 	if err != io.EOF && err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	if n == 0 && err == io.EOF {
 		return NIL
 	}
-	return NewString(string(buffer))
+	return NewString(string(buffer[:n]))
 }
 
 func (f *FileObject) ReadAt(line string, args ...Object) Object {
@@ -282,7 +282,7 @@ func (f *FileObject) ReadAt(line string, args ...Object) Object {
 	buffer := make([]byte, int(readlen.Int64))
 	_, err := f.File.ReadAt(buffer, offset.Int64)
 	if err != io.EOF && err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	if err == io.EOF {
@@ -302,7 +302,7 @@ func (f *FileObject) ReadRune(line string, args ...Object) Object {
 
 	r, _, err := f.reader.ReadRune()
 	if err != io.EOF && err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	if err == io.EOF {
@@ -322,7 +322,7 @@ func (f *FileObject) ReadLine(line string, args ...Object) Object {
 	}
 	aLine := f.Scanner.Scan()
 	if err := f.Scanner.Err(); err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 	if !aLine {
 		return NIL
@@ -347,7 +347,7 @@ func (f *FileObject) Seek(line string, args ...Object) Object {
 
 	ret, err := f.File.Seek(offset.Int64, int(whence.Int64))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewInteger(ret)
@@ -360,7 +360,7 @@ func (f *FileObject) Stat(line string, args ...Object) Object {
 
 	fileInfo, err := f.File.Stat()
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	//return FileInfo2HashObj(fileInfo)
@@ -374,7 +374,7 @@ func (f *FileObject) Sync(line string, args ...Object) Object {
 
 	err := f.File.Sync()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, IOERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -392,7 +392,7 @@ func (f *FileObject) Truncate(line string, args ...Object) Object {
 
 	err := f.File.Truncate(size.Int64)
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, IOERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -410,7 +410,7 @@ func (f *FileObject) Write(line string, args ...Object) Object {
 
 	n, err := f.File.Write([]byte(content.String))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewInteger(int64(n))
@@ -433,7 +433,7 @@ func (f *FileObject) WriteAt(line string, args ...Object) Object {
 
 	ret, err := f.File.WriteAt([]byte(content.String), offset.Int64)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewInteger(int64(ret))
@@ -451,7 +451,7 @@ func (f *FileObject) WriteString(line string, args ...Object) Object {
 
 	ret, err := f.File.WriteString(content.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewInteger(int64(ret))
@@ -469,7 +469,7 @@ func (f *FileObject) WriteLine(line string, args ...Object) Object {
 
 	ret, err := f.File.Write([]byte(content.String + "\n"))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return NewInteger(int64(ret))
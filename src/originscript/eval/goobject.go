@@ -333,7 +333,17 @@ func RegisterVars(name string, vars map[string]interface{}) {
 	}
 }
 
+//RegisterFunctions registers the Go functions 'vars' as the methods of 'name'(e.g. 'strings.ToUpper').
+//If 'name' is already a builtin object of the standard library(e.g. 'json'), the builtin
+//is kept, and the Go functions are registered beside its methods.
 func RegisterFunctions(name string, vars map[string]interface{}) {
+	if obj, ok := GetGlobalObj(name); ok && obj.Type() != HASH_OBJ {
+		for k, v := range vars {
+			SetGlobalObj(name+"."+k, NewGoFuncObject(k, v))
+		}
+		return
+	}
+
 	hash := NewHash()
 	for k, v := range vars {
 		key := NewString(k)
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"originscript/ast"
+)
+
+const (
+	INTERFACE_OBJ = "INTERFACE_OBJ"
+)
+
+//Interface object, the contract of the classes declared with it:
+//
+//    interface Closeable { fn close() }
+//    class File : Base, Closeable { fn close() { ... } }
+type Interface struct {
+	Name       string
+	Parents    []*Interface
+	Methods    []*ast.InterfaceMethod
+	Properties []string
+}
+
+func (i *Interface) Inspect() string  { return "<interface:" + i.Name + ">" }
+func (i *Interface) Type() ObjectType { return INTERFACE_OBJ }
+func (i *Interface) CallMethod(line string, scope *Scope, method string, args ...Object) Object {
+	return NewError(line, NOMETHODERROR, method, i.Type())
+}
+
+//Is reports if the interface is 'name' or extends it.
+func (i *Interface) Is(name string) bool {
+	if i.Name == name {
+		return true
+	}
+	for _, p := range i.Parents {
+		if p.Is(name) {
+			return true
+		}
+	}
+	return false
+}
+
+//Check returns an error if the class(or its parents) misses a method or a
+//property the interface requires.
+func (i *Interface) Check(line string, cls *Class) Object {
+	for _, m := range i.Methods {
+		if cls.GetMethod(m.Name.Value) == nil {
+			return NewError(line, INTERFACEERROR, cls.Name, "method", m.Name.Value, i.Name)
+		}
+	}
+	for _, name := range i.Properties {
+		if cls.GetProperty(name) == nil {
+			return NewError(line, INTERFACEERROR, cls.Name, "property", name, i.Name)
+		}
+	}
+	for _, p := range i.Parents {
+		if err := p.Check(line, cls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//interface name : parentInterfaces { fn method(parameters) property name }
+func evalInterfaceStatement(i *ast.InterfaceStatement, scope *Scope) Object {
+	iface := &Interface{Name: i.Name.Value, Methods: i.Methods}
+	for _, p := range i.Parents {
+		parent, ok := scope.Get(p.Value)
+		if !ok {
+			return NewError(p.Pos().Sline(), UNKNOWNIDENT, p.Value)
+		}
+		parentIface, ok := parent.(*Interface)
+		if !ok {
+			return NewError(p.Pos().Sline(), NOTINTERFACEERROR, p.Value)
+		}
+		iface.Parents = append(iface.Parents, parentIface)
+	}
+	for _, p := range i.Properties {
+		iface.Properties = append(iface.Properties, p.Value)
+	}
+
+	scope.Set(i.Name.Value, iface)
+	return NIL
+}
@@ -56,60 +56,60 @@ func (j *Json) Marshal(line string, args ...Object) Object {
 		value := args[0].(*Integer)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *UInteger:
 		value := args[0].(*UInteger)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *Float:
 		value := args[0].(*Float)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *String:
 		value := args[0].(*String)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *Boolean:
 		value := args[0].(*Boolean)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *Array:
 		value := args[0].(*Array)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *Tuple:
 		value := args[0].(*Tuple)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	case *Hash:
 		value := args[0].(*Hash)
 		res, err := value.MarshalJSON()
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return NewString(string(res))
 	default:
-		return NewError(line, JSONERROR)
+		return newException(line, JSONERROR_CLASS, "unsupported type "+string(args[0].Type()))
 	}
 }
 
@@ -131,25 +131,25 @@ func (j *Json) UnMarshal(line string, args ...Object) Object {
 		a := &Array{}
 		err := a.UnmarshalJSON(b)
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return a
 	} else if r == '{' { // hash
 		h := NewHash()
 		err := h.UnmarshalJSON(b)
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return h
 	} else { //simple types, e.g. number, string
 		var val interface{}
 		err := json.Unmarshal(b, &val)
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		ret, err := unmarshalJsonObject(val)
 		if err != nil {
-			return NewNil(err.Error())
+			return nilFailure(line, JSONERROR_CLASS, err)
 		}
 		return ret
 	}
@@ -179,7 +179,7 @@ func (j *Json) Indent(line string, args ...Object) Object {
 
 	err := json.Indent(&out, b, "", indent)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, JSONERROR_CLASS, err)
 	}
 	return NewString(out.String())
 }
@@ -209,7 +209,7 @@ func (j *Json) ReadFile(line string, args ...Object) Object {
 
 	byteValue, err := ioutil.ReadFile(strObj.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, IOERROR_CLASS, err)
 	}
 
 	return j.UnMarshal(line, NewString(string(byteValue)))
@@ -231,13 +231,13 @@ func (j *Json) WriteFile(line string, args ...Object) Object {
 	}
 
 	v := j.Marshal(line, args[1])
-	if v.Type() == NIL_OBJ {
+	if v.Type() != STRING_OBJ { //nil, or the exception of an unsupported type
 		return v
 	}
 
 	err := ioutil.WriteFile(fileNameObj.String, []byte(v.(*String).String), os.FileMode(permObj.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, IOERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -0,0 +1,234 @@
+package eval
+
+import (
+	"originscript/ast"
+)
+
+//The type names of the type patterns('int', 'int(n)'...), the other names of
+//the type patterns are classes.
+var patternTypes = map[string]ObjectType{
+	"int":     INTEGER_OBJ,
+	"uint":    UINTEGER_OBJ,
+	"float":   FLOAT_OBJ,
+	"decimal": DECIMAL_OBJ,
+	"string":  STRING_OBJ,
+	"bool":    BOOLEAN_OBJ,
+	"array":   ARRAY_OBJ,
+	"tuple":   TUPLE_OBJ,
+	"hash":    HASH_OBJ,
+}
+
+//IsPatternType reports if 'name' is a type name of the type patterns.
+func IsPatternType(name string) bool {
+	_, ok := patternTypes[name]
+	return ok
+}
+
+//evalMatchExpression evaluates the block of the first arm whose pattern
+//matches the value, and whose guard is true. The names bound by the pattern
+//are variables of the arm's scope. The value is nil if no arm matches.
+func evalMatchExpression(me *ast.MatchExpr, scope *Scope) Object {
+	value := Eval(me.Expr, scope)
+	if value.Type() == ERROR_OBJ {
+		return value
+	}
+
+	for _, arm := range me.Arms {
+		armScope := NewScope(scope, nil)
+
+		matched := len(arm.Patterns) == 0 //'else'
+		for _, pattern := range arm.Patterns {
+			bindings := make(map[string]Object)
+			ok, err := matchPattern(pattern, value, bindings, scope)
+			if err != nil {
+				return err
+			}
+			if ok {
+				for name, v := range bindings {
+					armScope.Set(name, v)
+				}
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			cond := Eval(arm.Guard, armScope)
+			if cond.Type() == ERROR_OBJ {
+				return cond
+			}
+			if !IsTrue(cond) {
+				continue
+			}
+		}
+		return Eval(arm.Block, armScope)
+	}
+	return NIL
+}
+
+//matchPattern reports if 'value' matches 'pattern', the names bound by the
+//pattern are added to 'bindings'. The error is not nil if the pattern is invalid.
+func matchPattern(pattern ast.Expression, value Object, bindings map[string]Object, scope *Scope) (bool, Object) {
+	line := pattern.Pos().Sline()
+
+	switch p := pattern.(type) {
+	case *ast.IdentPattern:
+		if p.Var == "_" {
+			return true, nil
+		}
+		if t, ok := patternTypes[p.Var]; ok {
+			return value.Type() == t, nil
+		}
+		if obj, ok := scope.Get(p.Var); ok {
+			if cls, ok := obj.(*Class); ok {
+				instance, ok := value.(*ObjectInstance)
+				return ok && InstanceOf(cls.Name, instance), nil
+			}
+			if iface, ok := obj.(*Interface); ok {
+				instance, ok := value.(*ObjectInstance)
+				return ok && InstanceOf(iface.Name, instance), nil
+			}
+		}
+		bindings[p.Var] = value
+		return true, nil
+
+	case *ast.ValuePattern:
+		v := Eval(p.Value, scope)
+		if v.Type() == ERROR_OBJ {
+			return false, v
+		}
+		return equal(true, value, v), nil
+
+	case *ast.TuplePattern:
+		tuple, ok := value.(*Tuple)
+		if !ok || len(tuple.Members) != len(p.Members) {
+			return false, nil
+		}
+		return matchPatterns(p.Members, tuple.Members, bindings, scope)
+
+	case *ast.ArrayPattern:
+		arr, ok := value.(*Array)
+		if !ok || len(arr.Members) < len(p.Members) || (!p.HasRest && len(arr.Members) != len(p.Members)) {
+			return false, nil
+		}
+		ok, err := matchPatterns(p.Members, arr.Members[:len(p.Members)], bindings, scope)
+		if ok && p.Var != "" {
+			rest := make([]Object, len(arr.Members)-len(p.Members))
+			copy(rest, arr.Members[len(p.Members):])
+			bindings[p.Var] = &Array{Members: rest}
+		}
+		return ok, err
+
+	case *ast.HashPattern:
+		hash, ok := value.(*Hash)
+		if !ok {
+			return false, nil
+		}
+		for i, k := range p.Keys {
+			key := Eval(k, scope)
+			if key.Type() == ERROR_OBJ {
+				return false, key
+			}
+			hashable, ok := key.(Hashable)
+			if !ok {
+				return false, NewError(k.Pos().Sline(), KEYERROR, key.Type())
+			}
+			pair, ok := hash.Pairs[hashable.HashKey()]
+			if !ok {
+				return false, nil
+			}
+			if ok, err := matchPattern(p.Values[i], pair.Value, bindings, scope); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *ast.TypePattern:
+		if t, ok := patternTypes[p.Type]; ok {
+			if len(p.Patterns) > 1 {
+				return false, NewError(line, GENERICERROR, "type pattern '"+p.Type+"(...)' takes one pattern")
+			}
+			if value.Type() != t {
+				return false, nil
+			}
+			return matchPatterns(p.Patterns, []Object{value}, bindings, scope)
+		}
+
+		obj, ok := scope.Get(p.Type)
+		if !ok {
+			return false, NewError(line, CLSNOTDEFINE, p.Type)
+		}
+		cls, ok := obj.(*Class)
+		if !ok {
+			return false, NewError(line, NOTCLASSERROR, p.Type)
+		}
+		instance, ok := value.(*ObjectInstance)
+		if !ok || !InstanceOf(cls.Name, instance) {
+			return false, nil
+		}
+		for i, name := range p.Names {
+			if name == "" {
+				return false, NewError(line, GENERICERROR, "pattern of class '"+p.Type+"' must be 'member' or 'member: pattern', got '"+p.Patterns[i].String()+"'")
+			}
+			member, ok := instanceMember(instance, name)
+			if !ok {
+				return false, nil
+			}
+			if ok, err := matchPattern(p.Patterns[i], member, bindings, scope); !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	return false, NewError(line, GENERICERROR, "invalid pattern '"+pattern.String()+"'")
+}
+
+func matchPatterns(patterns []ast.Expression, values []Object, bindings map[string]Object, scope *Scope) (bool, Object) {
+	for i, pattern := range patterns {
+		if ok, err := matchPattern(pattern, values[i], bindings, scope); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+//instanceMember returns the value of a member or a property of an instance.
+func instanceMember(instance *ObjectInstance, name string) (Object, bool) {
+	if name == "this" || name == "parent" {
+		return nil, false
+	}
+
+	//the members of the class and of its parents, not the variables around the class
+	lookup := func(name string) (Object, bool) {
+		s := instance.Scope
+		for cls := instance.Class; cls != nil && s != nil; cls, s = cls.Parent, s.parentScope {
+			s.RLock()
+			val, ok := s.lookup(name)
+			s.RUnlock()
+			if ok {
+				return val, true
+			}
+		}
+		return nil, false
+	}
+	if val, ok := lookup(name); ok {
+		return val, true
+	}
+
+	p := instance.GetProperty(name)
+	if p == nil || p.Getter == nil {
+		return nil, false
+	}
+	if len(p.Getter.Body.Statements) == 0 { //property xxx { get; }
+		return lookup("_" + name)
+	}
+	result := Eval(p.Getter.Body, instance.Scope)
+	if rv, ok := result.(*ReturnValue); ok {
+		return rv.Value, true
+	}
+	return result, result.Type() != ERROR_OBJ
+}
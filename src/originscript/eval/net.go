@@ -83,7 +83,7 @@ func (n *NetObj) LookupAddr(line string, args ...Object) Object {
 
 	names, err := net.LookupAddr(addr.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	arr := &Array{}
@@ -106,7 +106,7 @@ func (n *NetObj) LookupHost(line string, args ...Object) Object {
 
 	addrs, err := net.LookupHost(host.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	arr := &Array{}
@@ -129,7 +129,7 @@ func (n *NetObj) LookupIP(line string, args ...Object) Object {
 
 	ips, err := net.LookupIP(host.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	arr := &Array{}
@@ -158,7 +158,7 @@ func (n *NetObj) LookupPort(line string, args ...Object) Object {
 
 	port, err := net.LookupPort(network.String, service.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return NewInteger(int64(port))
@@ -176,7 +176,7 @@ func (n *NetObj) SplitHostPort(line string, args ...Object) Object {
 
 	host, port, err := net.SplitHostPort(hostport.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	arr := &Array{}
@@ -245,7 +245,7 @@ func (t *TcpConnObject) Close(line string, args ...Object) Object {
 	}
 	err := t.Conn.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -257,7 +257,7 @@ func (t *TcpConnObject) CloseRead(line string, args ...Object) Object {
 
 	err := t.Conn.CloseRead()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -269,7 +269,7 @@ func (t *TcpConnObject) CloseWrite(line string, args ...Object) Object {
 
 	err := t.Conn.CloseWrite()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -281,7 +281,7 @@ func (t *TcpConnObject) Read(line string, args ...Object) Object {
 
 	bytes, err := ioutil.ReadAll(t.Conn)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return NewString(string(bytes))
@@ -313,7 +313,7 @@ func (t *TcpConnObject) Read2(line string, args ...Object) Object {
 	}
 
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return NewString(string(data))
@@ -331,7 +331,7 @@ func (t *TcpConnObject) Write(line string, args ...Object) Object {
 
 	n, err := t.Conn.Write([]byte(str.String))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 	return NewInteger(int64(n))
 }
@@ -349,7 +349,7 @@ func (t *TcpConnObject) SetDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := t.Conn.SetDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -367,7 +367,7 @@ func (t *TcpConnObject) SetReadDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := t.Conn.SetReadDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -385,7 +385,7 @@ func (t *TcpConnObject) SetWriteDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := t.Conn.SetWriteDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -402,7 +402,7 @@ func (t *TcpConnObject) SetLinger(line string, args ...Object) Object {
 
 	err := t.Conn.SetLinger(int(sec.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -419,7 +419,7 @@ func (t *TcpConnObject) SetNoDelay(line string, args ...Object) Object {
 
 	err := t.Conn.SetNoDelay(noDelay.Bool)
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -436,7 +436,7 @@ func (t *TcpConnObject) SetReadBuffer(line string, args ...Object) Object {
 
 	err := t.Conn.SetReadBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -453,7 +453,7 @@ func (t *TcpConnObject) SetWriteBuffer(line string, args ...Object) Object {
 
 	err := t.Conn.SetWriteBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -496,7 +496,7 @@ func (l *TCPListenerObject) Close(line string, args ...Object) Object {
 	}
 	err := l.Listener.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -508,7 +508,7 @@ func (l *TCPListenerObject) AcceptTCP(line string, args ...Object) Object {
 
 	tcpConn, err := l.Listener.AcceptTCP()
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return &TcpConnObject{Conn: tcpConn, Address: l.Address}
@@ -527,7 +527,7 @@ func (l *TCPListenerObject) SetDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := l.Listener.SetDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -581,7 +581,7 @@ func (u *UdpConnObject) Close(line string, args ...Object) Object {
 	}
 	err := u.Conn.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -593,7 +593,7 @@ func (u *UdpConnObject) Read(line string, args ...Object) Object {
 
 	bytes, err := ioutil.ReadAll(u.Conn)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return NewString(string(bytes))
@@ -611,7 +611,7 @@ func (u *UdpConnObject) Write(line string, args ...Object) Object {
 
 	n, err := u.Conn.Write([]byte(str.String))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 	return NewInteger(int64(n))
 }
@@ -629,7 +629,7 @@ func (u *UdpConnObject) SetDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -647,7 +647,7 @@ func (u *UdpConnObject) SetReadDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetReadDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -665,7 +665,7 @@ func (u *UdpConnObject) SetWriteDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetWriteDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -682,7 +682,7 @@ func (u *UdpConnObject) SetReadBuffer(line string, args ...Object) Object {
 
 	err := u.Conn.SetReadBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -699,7 +699,7 @@ func (u *UdpConnObject) SetWriteBuffer(line string, args ...Object) Object {
 
 	err := u.Conn.SetWriteBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -757,7 +757,7 @@ func (u *UnixConnObject) Close(line string, args ...Object) Object {
 	}
 	err := u.Conn.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -769,7 +769,7 @@ func (u *UnixConnObject) CloseRead(line string, args ...Object) Object {
 
 	err := u.Conn.CloseRead()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -782,7 +782,7 @@ func (u *UnixConnObject) CloseWrite(line string, args ...Object) Object {
 
 	err := u.Conn.CloseWrite()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -795,7 +795,7 @@ func (u *UnixConnObject) Read(line string, args ...Object) Object {
 
 	bytes, err := ioutil.ReadAll(u.Conn)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return NewString(string(bytes))
@@ -813,7 +813,7 @@ func (u *UnixConnObject) Write(line string, args ...Object) Object {
 
 	n, err := u.Conn.Write([]byte(str.String))
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 	return NewInteger(int64(n))
 }
@@ -831,7 +831,7 @@ func (u *UnixConnObject) SetDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -849,7 +849,7 @@ func (u *UnixConnObject) SetReadDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetReadDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -867,7 +867,7 @@ func (u *UnixConnObject) SetWriteDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := u.Conn.SetWriteDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -884,7 +884,7 @@ func (u *UnixConnObject) SetReadBuffer(line string, args ...Object) Object {
 
 	err := u.Conn.SetReadBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -901,7 +901,7 @@ func (u *UnixConnObject) SetWriteBuffer(line string, args ...Object) Object {
 
 	err := u.Conn.SetWriteBuffer(int(bytes.Int64))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -944,7 +944,7 @@ func (l *UnixListenerObject) Close(line string, args ...Object) Object {
 	}
 	err := l.Listener.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -956,7 +956,7 @@ func (l *UnixListenerObject) AcceptUnix(line string, args ...Object) Object {
 
 	unixConn, err := l.Listener.AcceptUnix()
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, NETERROR_CLASS, err)
 	}
 
 	return &UnixConnObject{Conn: unixConn, Address: l.Address}
@@ -975,7 +975,7 @@ func (l *UnixListenerObject) SetDeadline(line string, args ...Object) Object {
 	d := time.Duration(int64(time.Second) * sec.Int64)
 	err := l.Listener.SetDeadline(time.Now().Add(d))
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, NETERROR_CLASS, err)
 	}
 	return TRUE
 }
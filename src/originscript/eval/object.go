@@ -15,6 +15,7 @@ import (
 	_ "sort"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -216,8 +217,22 @@ func (b *Builtin) CallMethod(line string, scope *Scope, method string, args ...O
 }
 
 type ImportedObject struct {
-	Name  string
-	Scope *Scope
+	Name    string
+	Scope   *Scope
+	Exports map[string]bool //the names marked with 'export', nil if there's none
+}
+
+//Exported reports if the files requiring the module can use its name 'name':
+//the names marked with 'export', or the capitalized ones if nothing is marked.
+//The names starting with '_' are always private.
+func (io *ImportedObject) Exported(name string) bool {
+	if name == "" || name[0] == '_' {
+		return false
+	}
+	if io.Exports != nil {
+		return io.Exports[name]
+	}
+	return unicode.IsUpper(rune(name[0]))
 }
 
 func (io *ImportedObject) Inspect() string  { return fmt.Sprintf("imported object: %s", io.Name) }
@@ -274,6 +289,7 @@ func (rv *ReturnValue) CallMethod(line string, scope *Scope, method string, args
 type Throw struct {
 	stmt  *ast.ThrowStmt
 	value Object
+	stack []StackFrame //the stack of the throw statement
 }
 
 func (t *Throw) Inspect() string  { return t.value.Inspect() }
@@ -0,0 +1,123 @@
+package eval
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+//Opcode is the operation of a bytecode instruction, its operands follow it
+//as big-endian uint16s.
+type Opcode byte
+
+const (
+	OpConstant    Opcode = iota //push a copy of the constant
+	OpNil                       //push NIL
+	OpTrue                      //push TRUE
+	OpFalse                     //push FALSE
+	OpPop                       //end of a statement, the popped value is the result of the block
+	OpGet                       //push the value of the identifier
+	OpGetLocal                  //push the local variable in the slot, or the value of the identifier
+	OpLit                       //'lit name = value', the value stays on the stack
+	OpLitLocal                  //'lit name = value' of a local variable
+	OpAssign                    //assign the value on the stack, push the result
+	OpAssignLocal               //'name = value' of a local variable
+	OpPrefix                    //prefix operator on the value on the stack
+	OpInfix                     //infix operator on the two values on the stack
+	OpAdd                       //'+' on the two values on the stack, without 'evalInfixExpression' for two Integers or Floats
+	OpSub                       //'-', like OpAdd
+	OpMul                       //'*', like OpAdd
+	OpDiv                       //'/', like OpAdd
+	OpMod                       //'%', like OpAdd
+	OpEqual                     //'==', like OpAdd
+	OpNotEqual                  //'!=', like OpAdd
+	OpLess                      //'<', like OpAdd
+	OpLessEq                    //'<=', like OpAdd
+	OpGreater                   //'>', like OpAdd
+	OpGreaterEq                 //'>=', like OpAdd
+	OpPostfix                   //postfix operator on the value on the stack
+	OpJump                      //jump to the target
+	OpJumpIfFalse               //pop the condition, jump to the target if it's false
+	OpEnterScope                //enter a new scope
+	OpLeaveScope                //go back to the parent scope
+	OpLoop                      //enter a loop, with its 'break' and 'continue' targets
+	OpEndLoop                   //leave the loop
+	OpBreak                     //jump to the 'break' target of the loop
+	OpContinue                  //jump to the 'continue' target of the loop
+	OpCallee                    //push the function or the builtin, or evaluate the whole call and jump to the target
+	OpCall                      //call the function with the arguments on the stack
+	OpArray                     //make an array of the values on the stack
+	OpReturn                    //return the values on the stack
+	OpResult                    //return the result of the last statement
+	OpEval                      //evaluate the node with the tree-walker
+)
+
+type definition struct {
+	Name     string
+	Operands int //the number of uint16 operands
+}
+
+var definitions = map[Opcode]*definition{
+	OpConstant:    {"OpConstant", 1},
+	OpNil:         {"OpNil", 0},
+	OpTrue:        {"OpTrue", 0},
+	OpFalse:       {"OpFalse", 0},
+	OpPop:         {"OpPop", 0},
+	OpGet:         {"OpGet", 1},
+	OpGetLocal:    {"OpGetLocal", 2},
+	OpLit:         {"OpLit", 1},
+	OpLitLocal:    {"OpLitLocal", 2},
+	OpAssign:      {"OpAssign", 1},
+	OpAssignLocal: {"OpAssignLocal", 2},
+	OpPrefix:      {"OpPrefix", 1},
+	OpInfix:       {"OpInfix", 1},
+	OpAdd:         {"OpAdd", 1},
+	OpSub:         {"OpSub", 1},
+	OpMul:         {"OpMul", 1},
+	OpDiv:         {"OpDiv", 1},
+	OpMod:         {"OpMod", 1},
+	OpEqual:       {"OpEqual", 1},
+	OpNotEqual:    {"OpNotEqual", 1},
+	OpLess:        {"OpLess", 1},
+	OpLessEq:      {"OpLessEq", 1},
+	OpGreater:     {"OpGreater", 1},
+	OpGreaterEq:   {"OpGreaterEq", 1},
+	OpPostfix:     {"OpPostfix", 1},
+	OpJump:        {"OpJump", 1},
+	OpJumpIfFalse: {"OpJumpIfFalse", 1},
+	OpEnterScope:  {"OpEnterScope", 0},
+	OpLeaveScope:  {"OpLeaveScope", 0},
+	OpLoop:        {"OpLoop", 2},
+	OpEndLoop:     {"OpEndLoop", 0},
+	OpBreak:       {"OpBreak", 0},
+	OpContinue:    {"OpContinue", 0},
+	OpCallee:      {"OpCallee", 2},
+	OpCall:        {"OpCall", 1},
+	OpArray:       {"OpArray", 1},
+	OpReturn:      {"OpReturn", 1},
+	OpResult:      {"OpResult", 0},
+	OpEval:        {"OpEval", 1},
+}
+
+//Instructions is the bytecode of a program or a block.
+type Instructions []byte
+
+//String disassembles the instructions, one per line.
+func (ins Instructions) String() string {
+	var out strings.Builder
+	for ip := 0; ip < len(ins); {
+		def := definitions[Opcode(ins[ip])]
+		fmt.Fprintf(&out, "%04d %s", ip, def.Name)
+		ip++
+		for i := 0; i < def.Operands; i++ {
+			fmt.Fprintf(&out, " %d", readOperand(ins, ip))
+			ip += 2
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func readOperand(ins Instructions, ip int) int {
+	return int(binary.BigEndian.Uint16(ins[ip:]))
+}
@@ -0,0 +1,445 @@
+package eval
+
+import (
+	"originscript/ast"
+	"originscript/token"
+	"reflect"
+	"strconv"
+)
+
+//OptimizeProgram rewrites the program to an equivalent one doing less work at
+//run time: the constant expressions are folded, the uses of the global
+//constants with a constant value are replaced by the value, and the branches
+//of 'if'/'unless' which can never be taken are removed. The constant expressions
+//are computed by the evaluator itself, so the folded values are the ones the
+//program would compute. The imported modules are optimized when they're loaded.
+func OptimizeProgram(program *ast.Program) {
+	o := &optimizer{
+		consts:  make(map[string]Object),
+		bound:   make(map[string]int),
+		visited: make(map[ast.Node]bool),
+	}
+	o.bindings(program, make(map[ast.Node]bool))
+	program.Statements = o.statements(program.Statements, true)
+}
+
+type optimizer struct {
+	consts  map[string]Object //the global constants which can be inlined
+	bound   map[string]int    //how many times a name is declared or assigned
+	visited map[ast.Node]bool
+}
+
+var (
+	expressionType = reflect.TypeOf((*ast.Expression)(nil)).Elem()
+	statementsType = reflect.TypeOf([]ast.Statement(nil))
+)
+
+//inlinePositions are the fields where an identifier is only read, so a
+//constant can be replaced by its value.
+var inlinePositions = map[string]bool{
+	"InfixExpression.Left":           true,
+	"InfixExpression.Right":          true,
+	"PrefixExpression.Right":         true,
+	"IfConditionExpr.Cond":           true,
+	"UnlessExpression.Condition":     true,
+	"WhileLoop.Condition":            true,
+	"TernaryExpression.Condition":    true,
+	"TernaryExpression.IfTrue":       true,
+	"TernaryExpression.IfFalse":      true,
+	"CallExpression.Arguments":       true,
+	"NewExpression.Arguments":        true,
+	"LetStatement.Values":            true,
+	"ConstStatement.Value":           true,
+	"AssignExpression.Value":         true,
+	"ReturnStatement.ReturnValue":    true,
+	"ReturnStatement.ReturnValues":   true,
+	"ArrayLiteral.Members":           true,
+	"TupleLiteral.Members":           true,
+	"IndexExpression.Index":          true,
+	"InterpolatedString.ExprMap":     true,
+	"ThrowStmt.Expr":                 true,
+	"ExpressionStatement.Expression": true,
+}
+
+//bindings counts the declarations and the assignments of the names, a constant
+//whose name is also used by an other variable is not inlined.
+func (o *optimizer) bindings(n ast.Node, visited map[ast.Node]bool) {
+	if resolverIsNil(n) || visited[n] {
+		return
+	}
+	visited[n] = true
+
+	bind := func(e ast.Expression) {
+		if ident, ok := e.(*ast.Identifier); ok {
+			o.bound[ident.Value]++
+		}
+	}
+	switch n := n.(type) {
+	case *ast.LetStatement:
+		for _, name := range n.Names {
+			bind(name)
+		}
+	case *ast.ConstStatement:
+		for _, name := range n.Name {
+			bind(name)
+		}
+	case *ast.FunctionStatement:
+		bind(n.Name)
+	case *ast.FunctionLiteral:
+		for _, param := range n.Parameters {
+			bind(param)
+		}
+	case *ast.AssignExpression:
+		bind(n.Name)
+	case *ast.PostfixExpression:
+		bind(n.Left)
+	case *ast.PrefixExpression:
+		if n.Operator == "++" || n.Operator == "--" {
+			bind(n.Right)
+		}
+	case *ast.ClassStatement:
+		bind(n.Name)
+	case *ast.InterfaceStatement:
+		bind(n.Name)
+	case *ast.EnumStatement:
+		bind(n.Name)
+	case *ast.PropertyDeclStmt:
+		bind(n.Name)
+	case *ast.JoinExpr:
+		bind(n.IntoVar)
+	}
+
+	//the loop, comprehension and 'catch' variables are strings
+	v := reflect.ValueOf(n).Elem()
+	if v.Kind() == reflect.Struct {
+		_, hasKey := v.Type().FieldByName("Key")
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			switch name := v.Type().Field(i).Name; {
+			case f.Kind() != reflect.String:
+			case name == "Var" || name == "Key" || name == "JoinVar" || (name == "Value" && hasKey):
+				o.bound[f.String()]++
+			}
+		}
+	}
+
+	for _, child := range resolverChildren(n) {
+		o.bindings(child, visited)
+	}
+}
+
+//statements optimizes a statement list. The 'if' whose branch is known is
+//replaced by the statements of the branch(it shares the scope of the list),
+//and the constant expressions are removed, but not the last statement which
+//is the value of the list.
+func (o *optimizer) statements(stmts []ast.Statement, global bool) []ast.Statement {
+	var out []ast.Statement
+	for i, stmt := range stmts {
+		o.node(stmt)
+		last := i == len(stmts)-1
+
+		switch s := stmt.(type) {
+		case *ast.ConstStatement:
+			if global && !s.StaticFlag {
+				o.addConsts(s)
+			}
+		case *ast.ExpressionStatement:
+			if body := knownBranch(s.Expression); body != nil {
+				if block, ok := body.(*ast.BlockStatement); ok {
+					if len(block.Statements) > 0 || !last {
+						out = append(out, block.Statements...)
+						continue
+					}
+				} else if bs, ok := body.(ast.Statement); ok {
+					out = append(out, bs)
+					continue
+				}
+			}
+			if constant(s.Expression) != nil && !last {
+				continue
+			}
+		}
+		out = append(out, stmt)
+	}
+	if out == nil && stmts != nil {
+		out = []ast.Statement{}
+	}
+	return out
+}
+
+//addConsts records the constants of 's' which can be inlined.
+func (o *optimizer) addConsts(s *ast.ConstStatement) {
+	for i, name := range s.Name {
+		if i >= len(s.Value) || o.bound[name.Value] != 1 {
+			continue
+		}
+		if obj := constant(s.Value[i]); obj != nil {
+			o.consts[name.Value] = obj
+		}
+	}
+}
+
+//node optimizes the expressions and the statement lists contained in 'n'.
+func (o *optimizer) node(n ast.Node) {
+	if resolverIsNil(n) || o.visited[n] {
+		return
+	}
+	o.visited[n] = true
+
+	v := reflect.ValueOf(n).Elem()
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		switch field.Name {
+		case "Doc", "Program", "Functions", "Func": //comments, imported modules, resolved function
+			continue
+		}
+		if f := v.Field(i); f.CanSet() {
+			o.field(n, v.Type().Name()+"."+field.Name, f)
+		}
+	}
+}
+
+//field optimizes the value 'f' of the field 'name' of the node 'parent'.
+func (o *optimizer) field(parent ast.Node, name string, f reflect.Value) {
+	inline := inlinePositions[name]
+	if p, ok := parent.(*ast.PrefixExpression); ok && (p.Operator == "++" || p.Operator == "--") {
+		inline = false
+	}
+
+	switch {
+	case f.Type() == statementsType:
+		if !f.IsNil() {
+			f.Set(reflect.ValueOf(o.statements(f.Interface().([]ast.Statement), false)))
+		}
+	case f.Type() == expressionType:
+		if !f.IsNil() {
+			f.Set(reflect.ValueOf(o.expr(f.Interface().(ast.Expression), inline)))
+		}
+	case f.Kind() == reflect.Interface || f.Kind() == reflect.Ptr:
+		if !f.IsNil() {
+			if child, ok := f.Interface().(ast.Node); ok {
+				o.node(child)
+			}
+		}
+	case f.Kind() == reflect.Slice:
+		//the keys of a hash are also the keys of its 'Pairs', they're not replaced
+		keys := name == "HashLiteral.Order"
+		for i := 0; i < f.Len(); i++ {
+			if keys {
+				o.node(f.Index(i).Interface().(ast.Node))
+				continue
+			}
+			o.field(parent, name, f.Index(i))
+		}
+	case f.Kind() == reflect.Map:
+		for _, k := range f.MapKeys() {
+			if key, ok := k.Interface().(ast.Node); ok {
+				o.node(key)
+			}
+			value := f.MapIndex(k)
+			if value.Type() == expressionType && !value.IsNil() {
+				f.SetMapIndex(k, reflect.ValueOf(o.expr(value.Interface().(ast.Expression), inline)))
+			} else if child, ok := value.Interface().(ast.Node); ok {
+				o.node(child)
+			}
+		}
+	}
+}
+
+//expr returns the optimized expression 'e'. The identifiers are replaced by
+//the value of the constant if 'inline' is true.
+func (o *optimizer) expr(e ast.Expression, inline bool) ast.Expression {
+	o.node(e)
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		if obj, ok := o.consts[e.Value]; ok && inline {
+			return literal(obj, e.Token)
+		}
+	case *ast.InfixExpression:
+		if obj := foldInfix(e); obj != nil {
+			return literal(obj, e.Token)
+		}
+	case *ast.PrefixExpression:
+		if obj := foldPrefix(e); obj != nil {
+			return literal(obj, e.Token)
+		}
+	case *ast.TernaryExpression:
+		if cond := constant(e.Condition); cond != nil {
+			if IsTrue(cond) {
+				return e.IfTrue
+			}
+			return e.IfFalse
+		}
+	case *ast.IfExpression:
+		return optimizeIf(e)
+	case *ast.UnlessExpression:
+		if cond := constant(e.Condition); cond != nil {
+			var body ast.Node = e.Consequence
+			if IsTrue(cond) {
+				body = e.Alternative
+			}
+			return branch(e.Token, body)
+		}
+	}
+	return e
+}
+
+//optimizeIf removes the conditions which are always false, and the conditions
+//following a condition which is always true.
+func optimizeIf(ie *ast.IfExpression) ast.Expression {
+	var conds []*ast.IfConditionExpr
+	for _, c := range ie.Conditions {
+		cond := constant(c.Cond)
+		if cond == nil {
+			conds = append(conds, c)
+			continue
+		}
+		if IsTrue(cond) {
+			if len(conds) == 0 {
+				return branch(ie.Token, c.Body)
+			}
+			//the condition becomes the 'else' part
+			ie.Conditions, ie.Alternative = conds, c.Body
+			return ie
+		}
+	}
+	if len(conds) == 0 {
+		return branch(ie.Token, ie.Alternative)
+	}
+	ie.Conditions = conds
+	return ie
+}
+
+//branch returns an expression evaluating 'body', the branch of an 'if' known
+//to be taken: 'if (true) { body }', or the expression of 'body' if it's a
+//single expression, or nil if there's no body.
+func branch(tok token.Token, body ast.Node) ast.Expression {
+	switch b := body.(type) {
+	case nil:
+		return literal(NIL, tok)
+	case *ast.BlockStatement:
+		if b == nil {
+			return literal(NIL, tok)
+		}
+		if len(b.Statements) == 1 {
+			if es, ok := b.Statements[0].(*ast.ExpressionStatement); ok {
+				return es.Expression
+			}
+		}
+	case *ast.ExpressionStatement:
+		return b.Expression
+	}
+	cond := &ast.IfConditionExpr{Token: tok, Cond: literal(TRUE, tok), Body: body}
+	return &ast.IfExpression{Token: tok, Conditions: []*ast.IfConditionExpr{cond}}
+}
+
+//knownBranch returns the body of an 'if (true) { body }' made by 'branch'.
+func knownBranch(e ast.Expression) ast.Node {
+	ie, ok := e.(*ast.IfExpression)
+	if !ok || len(ie.Conditions) != 1 || ie.Alternative != nil {
+		return nil
+	}
+	if b, ok := ie.Conditions[0].Cond.(*ast.Boolean); ok && b.Value {
+		return ie.Conditions[0].Body
+	}
+	return nil
+}
+
+//constant returns the value of a literal, or nil if 'e' is not a literal.
+func constant(e ast.Expression) Object {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return NewInteger(e.Value)
+	case *ast.UIntegerLiteral:
+		return NewUInteger(e.Value)
+	case *ast.FloatLiteral:
+		return NewFloat(e.Value)
+	case *ast.StringLiteral:
+		return NewString(e.Value)
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(e.Value)
+	case *ast.NilLiteral:
+		return NIL
+	}
+	return nil
+}
+
+//foldInfix returns the value of an infix expression of two literals, or nil
+//if it can't be computed before running the program.
+func foldInfix(e *ast.InfixExpression) (obj Object) {
+	if e.Token.Type == token.UDO || isMetaOperators(e.Token.Type) {
+		return nil
+	}
+	left, right := constant(e.Left), constant(e.Right)
+	if left == nil || right == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			obj = nil
+		}
+	}()
+	return foldable(evalInfixExpression(e, left, right, nil))
+}
+
+//foldPrefix is like foldInfix for a prefix expression.
+func foldPrefix(e *ast.PrefixExpression) (obj Object) {
+	switch e.Operator {
+	case "!", "-", "+":
+	default:
+		return nil
+	}
+	if e.Token.Type == token.UDO || isMetaOperators(e.Token.Type) {
+		return nil
+	}
+	right := constant(e.Right)
+	if right == nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			obj = nil
+		}
+	}()
+	return foldable(evalPrefix(e, right, nil))
+}
+
+//foldable returns 'obj' if it can be written as a literal.
+func foldable(obj Object) Object {
+	switch obj.(type) {
+	case *Integer, *UInteger, *Float, *String, *Boolean, *Nil:
+		return obj
+	}
+	return nil
+}
+
+//literal returns the literal of the value 'obj', at the position of 'tok'.
+func literal(obj Object, tok token.Token) ast.Expression {
+	tok = token.Token{Pos: tok.Pos}
+	switch obj := obj.(type) {
+	case *Integer:
+		tok.Type, tok.Literal = token.INT, strconv.FormatInt(obj.Int64, 10)
+		return &ast.IntegerLiteral{Token: tok, Value: obj.Int64}
+	case *UInteger:
+		tok.Type, tok.Literal = token.UINT, strconv.FormatUint(obj.UInt64, 10)
+		return &ast.UIntegerLiteral{Token: tok, Value: obj.UInt64}
+	case *Float:
+		tok.Type, tok.Literal = token.FLOAT, strconv.FormatFloat(obj.Float64, 'g', -1, 64)
+		return &ast.FloatLiteral{Token: tok, Value: obj.Float64}
+	case *String:
+		tok.Type, tok.Literal = token.STRING, obj.String
+		return &ast.StringLiteral{Token: tok, Value: obj.String}
+	case *Boolean:
+		tok.Type, tok.Literal = token.FALSE, "false"
+		if obj.Bool {
+			tok.Type, tok.Literal = token.TRUE, "true"
+		}
+		return &ast.Boolean{Token: tok, Value: obj.Bool}
+	}
+	tok.Type, tok.Literal = token.NIL, "nil"
+	return &ast.NilLiteral{Token: tok}
+}
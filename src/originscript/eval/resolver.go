@@ -0,0 +1,186 @@
+package eval
+
+import (
+	"originscript/ast"
+	"reflect"
+)
+
+//Resolve assigns slots to the local variables of the functions of the program:
+//the parameters and the 'lit' variables of the function body. The scope of
+//a function call stores them in an array, and the identifiers referring to
+//them are annotated with the slot, so evaluating them does not look up the
+//scope chain by name. The other identifiers(free variables, globals, the
+//variables of the inner scopes...) are still looked up by name.
+func Resolve(program *ast.Program) {
+	r := &resolver{visited: make(map[ast.Node]bool)}
+	r.walk(program, nil)
+}
+
+type resolver struct {
+	visited map[ast.Node]bool
+}
+
+//walk annotates the identifiers of 'node' which are local variables of 'fl'.
+func (r *resolver) walk(node ast.Node, fl *ast.FunctionLiteral) {
+	if resolverIsNil(node) || r.visited[node] {
+		return
+	}
+	r.visited[node] = true
+
+	switch node := node.(type) {
+	case *ast.FunctionLiteral:
+		r.function(node)
+		return
+	case *ast.Identifier:
+		node.Func, node.Slot = nil, 0
+		if fl != nil {
+			if idx, ok := fl.SlotIndex[node.Value]; ok {
+				node.Func, node.Slot = fl, idx
+			}
+		}
+		return
+	}
+
+	for _, child := range resolverChildren(node) {
+		r.walk(child, fl)
+	}
+}
+
+func (r *resolver) function(fl *ast.FunctionLiteral) {
+	fl.Slots, fl.SlotIndex = nil, nil
+
+	names := []string{}
+	consts := make(map[string]bool)
+	for _, param := range fl.Parameters {
+		if ident, ok := param.(*ast.Identifier); ok {
+			names = append(names, ident.Value)
+		}
+	}
+	if fl.Body != nil {
+		declared(fl.Body.Statements, &names, consts)
+	}
+	addSlots(fl, names, consts)
+
+	//the default values are evaluated before the call, they are not resolved
+	for _, value := range fl.Values {
+		r.walk(value, nil)
+	}
+	for _, param := range fl.Parameters {
+		r.walk(param, fl)
+	}
+	r.walk(fl.Body, fl)
+}
+
+//addSlots assigns a slot to each name, except the constants and the names
+//which cannot be stored in a slot.
+func addSlots(fl *ast.FunctionLiteral, names []string, consts map[string]bool) {
+	for _, name := range names {
+		if _, ok := fl.SlotIndex[name]; ok || consts[name] || !resolvable(name) {
+			continue
+		}
+		if fl.SlotIndex == nil {
+			fl.SlotIndex = make(map[string]int)
+		}
+		fl.SlotIndex[name] = len(fl.Slots)
+		fl.Slots = append(fl.Slots, name)
+	}
+}
+
+//declared collects the names declared in the function scope by 'stmts'. The
+//bodies of 'if' share the scope, the other blocks have their own scope.
+func declared(stmts []ast.Statement, names *[]string, consts map[string]bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.LetStatement:
+			for _, name := range s.Names {
+				*names = append(*names, name.Value)
+			}
+		case *ast.ConstStatement:
+			for _, name := range s.Name {
+				consts[name.Value] = true
+			}
+		case *ast.FunctionStatement:
+			*names = append(*names, s.Name.Value)
+		case *ast.ExpressionStatement:
+			ie, ok := s.Expression.(*ast.IfExpression)
+			if !ok {
+				continue
+			}
+			for _, cond := range ie.Conditions {
+				if block, ok := cond.Body.(*ast.BlockStatement); ok {
+					declared(block.Statements, names, consts)
+				}
+			}
+			if block, ok := ie.Alternative.(*ast.BlockStatement); ok {
+				declared(block.Statements, names, consts)
+			}
+		}
+	}
+}
+
+//resolvable reports if the variable can be stored in a slot. The builtin
+//classes and the globals are found before the local variables.
+func resolvable(name string) bool {
+	switch name {
+	case "", "_", "this", "self", "parent":
+		return false
+	}
+	if _, ok := BuiltinClasses[name]; ok {
+		return false
+	}
+	if _, ok := GetGlobalObj(name); ok {
+		return false
+	}
+	return true
+}
+
+func resolverIsNil(n ast.Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+var astNodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+//resolverChildren returns the nodes directly contained in a node.
+func resolverChildren(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			if v.Type().Implements(astNodeType) {
+				nodes = append(nodes, v.Interface().(ast.Node))
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			for _, k := range v.MapKeys() {
+				walk(k)
+				walk(v.MapIndex(k))
+			}
+		}
+	}
+
+	v := reflect.ValueOf(n).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		switch v.Type().Field(i).Name {
+		case "Doc", "Program", "Functions", "Func": //comments, imported modules, resolved function
+			continue
+		}
+		if f := v.Field(i); f.CanInterface() {
+			walk(f)
+		}
+	}
+	return nodes
+}
@@ -6,6 +6,7 @@ import (
 	"originscript/ast"
 	_ "os"
 	"sync"
+	"sync/atomic"
 )
 
 var BuiltinClasses = map[string]*Class{
@@ -25,11 +26,41 @@ func NewScope(p *Scope, w io.Writer) *Scope {
 	} else {
 		ret.Writer = p.Writer
 		ret.CallStack = p.CallStack
+		ret.frame = p.frame
 	}
 
 	return ret
 }
 
+//Copy returns a new scope with the variables of 's'(the slots of a function scope
+//excluded), setting or assigning a variable of the copy does not change 's'.
+//The values are shared, not copied.
+func (s *Scope) Copy() *Scope {
+	s.RLock()
+	defer s.RUnlock()
+
+	ret := NewScope(s.parentScope, s.Writer)
+	for k, v := range s.store {
+		ret.store[k] = v
+	}
+	for k, v := range s.readonly {
+		ret.readonly[k] = v
+	}
+	return ret
+}
+
+//NewFunctionScope creates the scope of a call of 'fl'. The variables the
+//resolver assigned to slots are stored in an array instead of the map.
+func NewFunctionScope(fl *ast.FunctionLiteral, p *Scope) *Scope {
+	ret := NewScope(p, nil)
+	ret.frame = ret
+	ret.fn = fl
+	if len(fl.Slots) != 0 {
+		ret.slots = make([]Object, len(fl.Slots))
+	}
+	return ret
+}
+
 //CallStack is a stack for CallFrame
 type CallStack struct {
 	Frames []CallFrame
@@ -56,6 +87,16 @@ type Scope struct {
 	Writer      io.Writer
 	CallStack   *CallStack
 
+	//The slots of a function scope(see resolver.go). 'frame' is the function
+	//scope the scope belongs to, it's the scope itself for a function scope.
+	frame    *Scope
+	fn       *ast.FunctionLiteral
+	slots    []Object //a nil value means the variable is not set yet
+	shadowed []bool   //the slot variable is redeclared in an inner scope
+	shared   int32    //the scope may be used by other goroutines
+
+	caught Object //the Throw or Error handled by a catch clause, for rethrowing it
+
 	//We need to use `Mutex`, because we added 'spawn'(multithread).
 	//if not，when running `spawn`, there will be lot of errors, even core dump.
 	//The reason is golang's map is not thread safe
@@ -71,18 +112,173 @@ func (s *Scope) Get(name string) (Object, bool) {
 		return val, ok
 	}
 
-	obj, ok := s.store[name]
+	obj, ok := s.lookup(name)
 	if !ok && s.parentScope != nil {
 		obj, ok = s.parentScope.Get(name)
 	}
 	return obj, ok
 }
 
+//lookup returns the variable of this scope, without the parent scopes.
+func (s *Scope) lookup(name string) (Object, bool) {
+	if s.fn != nil {
+		if idx, ok := s.fn.SlotIndex[name]; ok {
+			obj := s.slots[idx]
+			return obj, obj != nil
+		}
+	}
+	obj, ok := s.store[name]
+	return obj, ok
+}
+
+//slot returns the value of the resolved identifier, it's not ok if the
+//identifier must be looked up by name.
+func (s *Scope) slot(i *ast.Identifier) (Object, bool) {
+	f := s.frame
+	if f == nil || f.fn != i.Func {
+		return nil, false
+	}
+	return f.getSlot(i.Slot)
+}
+
+//getSlot returns the value of a slot of the function scope 's', it's not ok
+//if the variable is not set yet or redeclared in an inner scope.
+func (s *Scope) getSlot(idx int) (Object, bool) {
+	if atomic.LoadInt32(&s.shared) != 0 {
+		s.RLock()
+		defer s.RUnlock()
+	}
+	if s.shadowed != nil && s.shadowed[idx] {
+		return nil, false
+	}
+	obj := s.slots[idx]
+	return obj, obj != nil
+}
+
+//setSlot sets a slot of the function scope 's'. If 'declare' is true, it's a
+//'lit' in 's' itself. Otherwise it's an assignment, which is not ok if it must
+//go through 'Reset': the variable is not set yet, redeclared in an inner scope
+//or readonly.
+func (s *Scope) setSlot(idx int, val Object, declare bool) bool {
+	if atomic.LoadInt32(&s.shared) != 0 {
+		s.Lock()
+		defer s.Unlock()
+	}
+	if !declare {
+		if s.slots[idx] == nil || s.shadowed != nil && s.shadowed[idx] {
+			return false
+		}
+		if len(s.readonly) != 0 && s.readonly[s.fn.Slots[idx]] {
+			return false
+		}
+	}
+	s.slots[idx] = val
+	return true
+}
+
+//adopt makes the root scope 's' the function scope of 'fl' while a program
+//runs, 'fl' has the slots the compiler assigned to the variables of the
+//program(see programFrame). The variables already set are moved to the slots.
+//It's not ok if 's' is not a root scope or is already a function scope.
+func (s *Scope) adopt(fl *ast.FunctionLiteral) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.parentScope != nil || s.frame != nil || len(fl.Slots) == 0 {
+		return false
+	}
+	s.frame, s.fn = s, fl
+	s.slots = make([]Object, len(fl.Slots))
+	for idx, name := range fl.Slots {
+		if obj, ok := s.store[name]; ok {
+			s.slots[idx] = obj
+			delete(s.store, name)
+		}
+	}
+	return true
+}
+
+//release moves the variables of the slots back to the store when the program
+//ends, so the scope could be used by the next program(e.g. the REPL's).
+func (s *Scope) release() {
+	s.Lock()
+	defer s.Unlock()
+
+	for idx, obj := range s.slots {
+		if obj != nil {
+			s.store[s.fn.Slots[idx]] = obj
+		}
+	}
+	s.frame, s.fn, s.slots, s.shadowed = nil, nil, nil, nil
+}
+
+//set sets the variable of this scope. If a slot variable of the function
+//is redeclared in an inner scope, the identifiers of the slot must be looked
+//up by name from now on.
+func (s *Scope) set(name string, val Object) {
+	if s.fn != nil {
+		if idx, ok := s.fn.SlotIndex[name]; ok {
+			s.slots[idx] = val
+			return
+		}
+	}
+	s.store[name] = val
+
+	if f := s.frame; f != nil && f != s && f.fn != nil { //a released root scope has no 'fn'
+		if idx, ok := f.fn.SlotIndex[name]; ok {
+			f.Lock()
+			if f.shadowed == nil {
+				f.shadowed = make([]bool, len(f.slots))
+			}
+			f.shadowed[idx] = true
+			f.Unlock()
+		}
+	}
+}
+
+//share marks the function scopes of the scope chain as used by other goroutines,
+//e.g. by a closure or a 'spawn'.
+func (s *Scope) share() {
+	for ; s != nil; s = s.parentScope {
+		if s.frame == s {
+			atomic.StoreInt32(&s.shared, 1)
+		}
+	}
+}
+
+//each calls 'fn' for each variable of this scope.
+func (s *Scope) each(fn func(name string, val Object)) {
+	for k, v := range s.store {
+		fn(k, v)
+	}
+	for idx, v := range s.slots {
+		if v != nil {
+			fn(s.fn.Slots[idx], v)
+		}
+	}
+}
+
 // Get all the keys of the scope.
 func (s *Scope) GetKeys() []string {
 	keys := make([]string, 0, len(s.store))
-	for k := range s.store {
+	s.each(func(k string, _ Object) {
 		keys = append(keys, k)
+	})
+	return keys
+}
+
+// Get all the keys of the scope, including the keys of the parent scopes.
+func (s *Scope) GetAllKeys() []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	keys := make([]string, 0, len(s.store))
+	s.each(func(k string, _ Object) {
+		keys = append(keys, k)
+	})
+
+	if s.parentScope != nil {
+		keys = append(keys, s.parentScope.GetAllKeys()...)
 	}
 	return keys
 }
@@ -91,9 +287,9 @@ func (s *Scope) DebugPrint(indent string) {
 	s.Lock()
 	defer s.Unlock()
 
-	for k, v := range s.store {
+	s.each(func(k string, v Object) {
 		fmt.Fprintf(s.Writer, "%s<%s> = <%s>  value.Type: %T\n", indent, k, v.Inspect(), v)
-	}
+	})
 
 	if s.parentScope != nil {
 		fmt.Fprintf(s.Writer, "\n%sParentScope:\n", indent)
@@ -107,7 +303,7 @@ func (s *Scope) IsReadOnly(name string) bool {
 	defer s.Unlock()
 
 	//check if it is readonly
-	_, ok := s.store[name]
+	_, ok := s.lookup(name)
 	if ok {
 		if s.readonly[name] {
 			return true
@@ -125,7 +321,7 @@ func (s *Scope) Set(name string, val Object) Object {
 	s.Lock()
 	defer s.Unlock()
 
-	s.store[name] = val
+	s.set(name, val)
 	return val
 }
 
@@ -133,7 +329,7 @@ func (s *Scope) SetConst(name string, val Object) Object {
 	s.Lock()
 	defer s.Unlock()
 
-	s.store[name] = val
+	s.set(name, val)
 	s.readonly[name] = true //mark it as readonly
 
 	return val
@@ -144,9 +340,9 @@ func (s *Scope) Reset(name string, val Object) (Object, bool) {
 	defer s.Unlock()
 
 	var ok bool
-	_, ok = s.store[name]
+	_, ok = s.lookup(name)
 	if ok {
-		s.store[name] = val
+		s.set(name, val)
 	}
 
 	if !ok && s.parentScope != nil {
@@ -154,7 +350,7 @@ func (s *Scope) Reset(name string, val Object) (Object, bool) {
 	}
 
 	if !ok {
-		s.store[name] = val
+		s.set(name, val)
 		ok = true
 	}
 	return val, ok
@@ -198,6 +394,18 @@ func GetGlobalObj(name string) (Object, bool) {
 	return obj, ok
 }
 
+// GetGlobalNames returns the names of all the objects registered using SetGlobalObj.
+func GetGlobalNames() []string {
+	GlobalMutex.Lock()
+	defer GlobalMutex.Unlock()
+
+	names := make([]string, 0, len(GlobalScopes))
+	for k := range GlobalScopes {
+		names = append(names, k)
+	}
+	return names
+}
+
 func SetGlobalObj(name string, Obj Object) {
 	GlobalMutex.Lock()
 	defer GlobalMutex.Unlock()
@@ -99,7 +99,7 @@ func (s *SqlObject) Ping(line string, args ...Object) Object {
 
 	err := s.Db.Ping()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -111,7 +111,7 @@ func (s *SqlObject) Close(line string, args ...Object) Object {
 	}
 	err := s.Db.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -164,7 +164,7 @@ func (s *SqlObject) Exec(line string, args ...Object) Object {
 
 	result, err := s.Db.Exec(query.String, params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 
 	return &DbResultObject{Result: result, Name: s.Name}
@@ -192,7 +192,7 @@ func (s *SqlObject) Query(line string, args ...Object) Object {
 
 	rows, err := s.Db.Query(query.String, params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbRowsObject{Rows: rows, Name: s.Name}
 
@@ -234,7 +234,7 @@ func (s *SqlObject) Prepare(line string, args ...Object) Object {
 
 	stmt, err := s.Db.Prepare(query.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbStmtObject{Stmt: stmt, Name: s.Name}
 }
@@ -246,7 +246,7 @@ func (s *SqlObject) Begin(line string, args ...Object) Object {
 
 	tx, err := s.Db.Begin()
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbTxObject{Tx: tx, Name: s.Name}
 }
@@ -328,7 +328,7 @@ func (r *DbRowsObject) Columns(line string, args ...Object) Object {
 	arr := &Array{}
 	cols, err := r.Rows.Columns()
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 
 	for _, col := range cols {
@@ -361,7 +361,7 @@ func (r *DbRowsObject) Close(line string, args ...Object) Object {
 
 	err := r.Rows.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 
 	return TRUE
@@ -438,7 +438,7 @@ func (s *DbStmtObject) Close(line string, args ...Object) Object {
 	}
 	err := s.Stmt.Close()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -456,7 +456,7 @@ func (s *DbStmtObject) Exec(line string, args ...Object) Object {
 
 	result, err := s.Stmt.Exec(params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbResultObject{Result: result, Name: s.Name}
 }
@@ -473,7 +473,7 @@ func (s *DbStmtObject) Query(line string, args ...Object) Object {
 
 	rows, err := s.Stmt.Query(params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbRowsObject{Rows: rows, Name: s.Name}
 
@@ -544,7 +544,7 @@ func (t *DbTxObject) Exec(line string, args ...Object) Object {
 
 	result, err := t.Tx.Exec(query.String, params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbResultObject{Result: result, Name: t.Name}
 }
@@ -571,7 +571,7 @@ func (t *DbTxObject) Query(line string, args ...Object) Object {
 
 	rows, err := t.Tx.Query(query.String, params...)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbRowsObject{Rows: rows, Name: t.Name}
 
@@ -613,7 +613,7 @@ func (t *DbTxObject) Prepare(line string, args ...Object) Object {
 
 	stmt, err := t.Tx.Prepare(query.String)
 	if err != nil {
-		return NewNil(err.Error())
+		return nilFailure(line, SQLERROR_CLASS, err)
 	}
 	return &DbStmtObject{Stmt: stmt, Name: t.Name}
 }
@@ -639,7 +639,7 @@ func (t *DbTxObject) Commit(line string, args ...Object) Object {
 
 	err := t.Tx.Commit()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -651,7 +651,7 @@ func (t *DbTxObject) Rollback(line string, args ...Object) Object {
 
 	err := t.Tx.Rollback()
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 	return TRUE
 }
@@ -733,7 +733,7 @@ func scan(rowObj interface{}, line string, args ...Object) Object {
 		case *Integer, *UInteger, *Boolean, *Float, *String, *TimeObj:
 			values = append(values, v)
 		default:
-			return NewError(line, DBSCANERROR)
+			return newException(line, SQLERROR_CLASS, "scan type "+string(v.Type())+" not supported")
 		} //end switch
 	} //end for
 
@@ -748,7 +748,7 @@ func scan(rowObj interface{}, line string, args ...Object) Object {
 	}
 
 	if err != nil {
-		return NewFalseObj(err.Error())
+		return falseFailure(line, SQLERROR_CLASS, err)
 	}
 
 	return TRUE
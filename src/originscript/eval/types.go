@@ -0,0 +1,266 @@
+package eval
+
+import (
+	"fmt"
+	"io/ioutil"
+	"originscript/ast"
+)
+
+//TypeCheck enables the runtime checking of the type annotations(--typecheck):
+//the arguments and the return values of the functions, the 'lit' declarations
+//and the assignments to the properties and to the typed members of the classes.
+//Without it the annotations are ignored.
+var TypeCheck bool
+
+//checkType returns an error if 'obj' is not of type 't', 'what' describes the
+//checked value in the message, e.g. "argument 1('a') of 'add'".
+func checkType(t *ast.TypeExpr, obj Object, scope *Scope, line string, what string) Object {
+	ok, err := isType(t, obj, scope, line)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NewError(line, TYPEMISMATCHERROR, what, t.String(), typeName(obj))
+	}
+	return nil
+}
+
+func isType(t *ast.TypeExpr, obj Object, scope *Scope, line string) (bool, Object) {
+	if len(t.Union) > 0 {
+		for _, alt := range t.Union {
+			if ok, err := isType(alt, obj, scope, line); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	switch t.Name {
+	case "any":
+		return true, nil
+	case "nil":
+		return obj.Type() == NIL_OBJ, nil
+	case "fn", "function":
+		return obj.Type() == FUNCTION_OBJ || obj.Type() == BUILTIN_OBJ, nil
+	}
+
+	if ot, ok := patternTypes[t.Name]; ok {
+		if obj.Type() != ot {
+			return false, nil
+		}
+		return isMembersType(t, obj, scope, line)
+	}
+
+	cls, ok := scope.Get(t.Name)
+	if !ok {
+		return false, NewError(line, GENERICERROR, "unknown type '"+t.Name+"'")
+	}
+	switch cls.(type) {
+	case *Class, *Interface:
+		instance, ok := obj.(*ObjectInstance)
+		return ok && InstanceOf(t.Name, instance), nil
+	}
+	return false, NewError(line, GENERICERROR, "'"+t.Name+"' is not a type")
+}
+
+//isMembersType checks the members of 'array<T>', 'tuple<T>' and 'hash<K, V>'(or 'hash<V>').
+func isMembersType(t *ast.TypeExpr, obj Object, scope *Scope, line string) (bool, Object) {
+	if len(t.Args) == 0 {
+		return true, nil
+	}
+
+	var members []Object
+	switch o := obj.(type) {
+	case *Array:
+		members = o.Members
+	case *Tuple:
+		members = o.Members
+	case *Hash:
+		key, value := t.Args[0], t.Args[len(t.Args)-1]
+		for _, pair := range o.Pairs {
+			if len(t.Args) == 2 {
+				if ok, err := isType(key, pair.Key, scope, line); !ok || err != nil {
+					return ok, err
+				}
+			}
+			if ok, err := isType(value, pair.Value, scope, line); !ok || err != nil {
+				return ok, err
+			}
+		}
+		return true, nil
+	}
+	for _, m := range members {
+		if ok, err := isType(t.Args[0], m, scope, line); !ok || err != nil {
+			return ok, err
+		}
+	}
+	return true, nil
+}
+
+//typeName returns the type of 'obj' as it's written in the annotations.
+func typeName(obj Object) string {
+	switch o := obj.(type) {
+	case *ObjectInstance:
+		return o.Class.Name
+	case *Function, *Builtin:
+		return "fn"
+	}
+	if obj.Type() == NIL_OBJ {
+		return "nil"
+	}
+	for name, t := range patternTypes {
+		if obj.Type() == t {
+			return name
+		}
+	}
+	return string(obj.Type())
+}
+
+//checkMemberType checks the value assigned to the member 'name' of 'instance', if
+//the member is annotated, e.g. 'lit age: int'.
+func checkMemberType(instance *ObjectInstance, name string, val Object, line string) Object {
+	t := instance.Class.GetMemberType(name)
+	if t == nil {
+		return nil
+	}
+	what := "member '" + name + "' of class '" + instance.Class.Name + "'"
+	return checkType(t, val, instance.Scope, line, what)
+}
+
+//isMember reports whether 'name' in 'scope', the scope of a method of 'instance',
+//is the instance's member, not a local variable of the method.
+func isMember(name string, scope *Scope, instance *ObjectInstance) bool {
+	for s := scope; s != nil; s = s.parentScope {
+		if s == instance.Scope {
+			return true
+		}
+		s.RLock()
+		_, ok := s.lookup(name)
+		s.RUnlock()
+		if ok {
+			return false
+		}
+	}
+	return false
+}
+
+//checkArgTypes checks the arguments of a call of the function 'name'.
+func checkArgTypes(name string, fn *ast.FunctionLiteral, args []Object, scope *Scope, line string) Object {
+	if len(fn.ParamTypes) == 0 {
+		return nil
+	}
+	for i, param := range fn.Parameters {
+		if i >= len(args) || (fn.Variadic && i == len(fn.Parameters)-1) {
+			break
+		}
+		if t, ok := fn.ParamTypes[param.String()]; ok {
+			what := fmt.Sprintf("argument %d('%s') of '%s'", i+1, param.String(), name)
+			if err := checkType(t, args[i], scope, line, what); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//checkReturnType checks the return value of a call of the function 'name'.
+func checkReturnType(name string, fn *ast.FunctionLiteral, ret Object, scope *Scope, line string) Object {
+	if fn.ReturnType == nil || ret.Type() == ERROR_OBJ {
+		return nil
+	}
+	return checkType(fn.ReturnType, ret, scope, line, "return value of '"+name+"'")
+}
+
+//methodName returns the name of the method 'fn' of the instance's class.
+func methodName(fn *Function) string {
+	if fn.Instance != nil {
+		for cls := fn.Instance.Class; cls != nil; cls = cls.Parent {
+			for name, m := range cls.Methods {
+				if f, ok := m.(*Function); ok && f.Literal == fn.Literal {
+					return name
+				}
+			}
+		}
+	}
+	return "fn"
+}
+
+//sampleValue returns a value of the type 't', as it's written in the annotations.
+//The static checker('origion check') uses them to probe the builtin types.
+func sampleValue(t string) Object {
+	switch t {
+	case "int":
+		return NewInteger(1)
+	case "uint":
+		return NewUInteger(1)
+	case "float":
+		return NewFloat(1.5)
+	case "string":
+		return NewString("a")
+	case "bool":
+		return TRUE
+	case "array":
+		return &Array{Members: []Object{NewInteger(1)}}
+	case "tuple":
+		return &Tuple{Members: []Object{NewInteger(1)}}
+	case "hash":
+		return NewHash()
+	case "nil":
+		return NIL
+	}
+	return nil
+}
+
+//probe calls 'f', a panic(e.g. the method needs arguments) returns nil.
+func probe(f func() Object) (obj Object) {
+	defer func() {
+		if recover() != nil {
+			obj = nil
+		}
+	}()
+	return f()
+}
+
+//HasMethod reports if the values of the type 't'(e.g. "string") have the
+//builtin method 'name'. The method is called without arguments on a sample
+//value, only a NOMETHODERROR means it doesn't exist.
+func HasMethod(t string, name string) bool {
+	obj := sampleValue(t)
+	if obj == nil {
+		return true
+	}
+	r := probe(func() Object { return obj.CallMethod("", NewScope(nil, ioutil.Discard), name) })
+	err, ok := r.(*Error)
+	return !ok || err.Kind != NOMETHODERROR
+}
+
+//TypeMethods returns the names of the builtin methods of the type 't'(e.g. "string").
+func TypeMethods(t string) []string {
+	var names []string
+	if obj := sampleValue(t); obj != nil {
+		for _, name := range MethodNames(obj) {
+			if HasMethod(t, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+//InfixResult applies the operator of 'node' to the sample values of the types
+//'left' and 'right', and returns the type of the result, or the error if the
+//operator doesn't support these types. The type is "" if it's unknown.
+func InfixResult(node *ast.InfixExpression, left string, right string) (string, *Error) {
+	l, r := sampleValue(left), sampleValue(right)
+	if l == nil || r == nil {
+		return "", nil
+	}
+	result := probe(func() Object { return evalInfixExpression(node, l, r, NewScope(nil, ioutil.Discard)) })
+	if result == nil {
+		return "", nil
+	}
+	if err, ok := result.(*Error); ok {
+		return "", err
+	}
+	return typeName(result), nil
+}
@@ -0,0 +1,497 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"originscript/ast"
+	"os"
+)
+
+//VM is a stack machine running the bytecode of a program or a block. It uses
+//the same objects, scopes and builtins as the tree-walker.
+type VM struct {
+	code  *Bytecode
+	scope *Scope
+	frame *Scope //the function scope of the slots of the bytecode, nil if the locals are looked up by name
+	stack []Object
+	loops []vmLoop
+	last  Object //result of the last statement
+	ip    int
+	op    Opcode   //the running instruction
+	node  ast.Node //the node of the running instruction, to report the errors
+
+	this       Object //'this' of a method, the assignments of its locals go through 'evalAssign'
+	thisLooked bool
+}
+
+//vmLoop is a running loop.
+type vmLoop struct {
+	brk, cont int
+	scope     *Scope //scope of the loop
+	sp        int    //stack size when the loop started
+}
+
+func NewVM(code *Bytecode, scope *Scope) *VM {
+	vm := &VM{code: code, scope: scope, node: code.Node}
+	if f := scope.frame; f != nil && code.Frame != nil && f.fn == code.Frame {
+		vm.frame = f
+	}
+	return vm
+}
+
+//runCompiled runs the program or the block with the VM, falls back to the
+//tree-walker if it cannot be compiled.
+func runCompiled(node ast.Node, scope *Scope) Object {
+	code := compiled(node)
+	if code == nil {
+		switch node := node.(type) {
+		case *ast.Program:
+			return evalBlockStatements(node.Statements, scope)
+		case *ast.BlockStatement:
+			return evalBlockStatements(node.Statements, scope)
+		}
+		return NIL
+	}
+	if _, ok := node.(*ast.Program); ok && scope.adopt(code.Frame) {
+		defer scope.release()
+	}
+	return NewVM(code, scope).Run()
+}
+
+//Run runs the bytecode. Like the block evaluation of the tree-walker, it stops
+//at the first error, throw or return, and returns it.
+func (vm *VM) Run() Object {
+	for {
+		result, ok := vm.run()
+		if ok {
+			return result
+		}
+
+		//like the tree-walker evaluating a node, the failed instruction
+		//results in NIL and the execution goes on.
+		switch vm.op {
+		case OpGet, OpGetLocal, OpLit, OpLitLocal, OpAssign, OpAssignLocal, OpPrefix, OpInfix, OpPostfix, OpCallee, OpCall,
+			OpAdd, OpSub, OpMul, OpDiv, OpMod, OpEqual, OpNotEqual, OpLess, OpLessEq, OpGreater, OpGreaterEq:
+			vm.push(NIL)
+		default:
+			return NIL
+		}
+	}
+}
+
+//run runs the bytecode from 'vm.ip', it's not ok if it panics.
+func (vm *VM) run() (result Object, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := PanicToError(r, vm.node)
+			fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+			result, ok = nil, false
+		}
+	}()
+
+	ins := vm.code.Instructions
+	for vm.ip < len(ins) {
+		op := Opcode(ins[vm.ip])
+		vm.op = op
+		vm.ip++
+
+		switch op {
+		case OpConstant:
+			vm.push(copyConstant(vm.code.Constants[readOperand(ins, vm.ip)]))
+			vm.ip += 2
+
+		case OpNil:
+			vm.push(NIL)
+
+		case OpTrue:
+			vm.push(TRUE)
+
+		case OpFalse:
+			vm.push(FALSE)
+
+		case OpPop:
+			val := vm.pop()
+			switch val.(type) {
+			case *ReturnValue, *Error, *Throw:
+				return val, true
+			case *Break:
+				if len(vm.loops) == 0 {
+					return val, true
+				}
+				vm.ip = vm.jumpOut(true)
+				continue
+			case *Continue:
+				if len(vm.loops) == 0 {
+					return val, true
+				}
+				vm.ip = vm.jumpOut(false)
+				continue
+			}
+			vm.last = val
+
+		case OpGet:
+			node := vm.nodeAt(ins, vm.ip).(*ast.Identifier)
+			vm.ip += 2
+			vm.push(evalIdentifier(node, vm.scope))
+
+		case OpGetLocal:
+			idx := readOperand(ins, vm.ip)
+			node := vm.nodeAt(ins, vm.ip+2).(*ast.Identifier)
+			vm.ip += 4
+			if vm.frame != nil {
+				if val, ok := vm.frame.getSlot(idx); ok {
+					if is, ok := val.(*InterpolatedString); ok {
+						is.Interpolate(vm.scope)
+					}
+					vm.push(val)
+					continue
+				}
+			}
+			vm.push(evalIdentifier(node, vm.scope))
+
+		case OpLit:
+			l := vm.nodeAt(ins, vm.ip).(*ast.LetStatement)
+			vm.ip += 2
+			val := letValue(vm.pop())
+			if val.Type() != ERROR_OBJ {
+				vm.scope.Set(l.Names[0].Value, val)
+			}
+			vm.push(val)
+
+		case OpLitLocal:
+			idx := readOperand(ins, vm.ip)
+			l := vm.nodeAt(ins, vm.ip+2).(*ast.LetStatement)
+			vm.ip += 4
+			val := letValue(vm.pop())
+			if val.Type() != ERROR_OBJ {
+				//in an inner scope, it's a new variable shadowing the slot
+				if vm.frame == nil || vm.scope != vm.frame || !vm.frame.setSlot(idx, val, true) {
+					vm.scope.Set(l.Names[0].Value, val)
+				}
+			}
+			vm.push(val)
+
+		case OpAssign:
+			a := vm.nodeAt(ins, vm.ip).(*ast.AssignExpression)
+			vm.ip += 2
+			val := vm.pop()
+			if val.Type() != ERROR_OBJ {
+				val = evalAssign(a, val, vm.scope)
+			}
+			vm.push(val)
+
+		case OpAssignLocal:
+			idx := readOperand(ins, vm.ip)
+			a := vm.nodeAt(ins, vm.ip+2).(*ast.AssignExpression)
+			vm.ip += 4
+			val := vm.pop()
+			if val.Type() != ERROR_OBJ && !vm.assignLocal(idx, val) {
+				val = evalAssign(a, val, vm.scope)
+			}
+			vm.push(val)
+
+		case OpPrefix:
+			p := vm.nodeAt(ins, vm.ip).(*ast.PrefixExpression)
+			vm.ip += 2
+			right := vm.pop()
+			if right.Type() != ERROR_OBJ {
+				right = evalPrefix(p, right, vm.scope)
+			}
+			vm.push(right)
+
+		case OpInfix:
+			node := vm.nodeAt(ins, vm.ip).(*ast.InfixExpression)
+			vm.ip += 2
+			right := vm.pop()
+			left := vm.pop()
+			switch {
+			case left.Type() == ERROR_OBJ:
+				vm.push(left)
+			case right.Type() == ERROR_OBJ:
+				vm.push(right)
+			default:
+				vm.push(evalInfixExpression(node, left, right, vm.scope))
+			}
+
+		case OpAdd, OpSub, OpMul, OpDiv, OpMod, OpEqual, OpNotEqual, OpLess, OpLessEq, OpGreater, OpGreaterEq:
+			right := vm.pop()
+			left := vm.pop()
+			if val, ok := arith(op, left, right); ok {
+				vm.ip += 2
+				vm.push(val)
+				continue
+			}
+			node := vm.nodeAt(ins, vm.ip).(*ast.InfixExpression)
+			vm.ip += 2
+			switch {
+			case left.Type() == ERROR_OBJ:
+				vm.push(left)
+			case right.Type() == ERROR_OBJ:
+				vm.push(right)
+			default:
+				vm.push(evalInfixExpression(node, left, right, vm.scope))
+			}
+
+		case OpPostfix:
+			node := vm.nodeAt(ins, vm.ip).(*ast.PostfixExpression)
+			vm.ip += 2
+			left := vm.pop()
+			if left.Type() != ERROR_OBJ {
+				left = evalPostfixExpression(left, node, vm.scope)
+			}
+			vm.push(left)
+
+		case OpJump:
+			vm.ip = readOperand(ins, vm.ip)
+
+		case OpJumpIfFalse:
+			cond := vm.pop()
+			if cond.Type() == ERROR_OBJ {
+				return cond, true
+			}
+			if IsTrue(cond) {
+				vm.ip += 2
+			} else {
+				vm.ip = readOperand(ins, vm.ip)
+			}
+
+		case OpEnterScope:
+			vm.scope = NewScope(vm.scope, nil)
+
+		case OpLeaveScope:
+			vm.scope = vm.scope.parentScope
+
+		case OpLoop:
+			brk, cont := readOperand(ins, vm.ip), readOperand(ins, vm.ip+2)
+			vm.ip += 4
+			vm.loops = append(vm.loops, vmLoop{brk: brk, cont: cont, scope: vm.scope, sp: len(vm.stack)})
+
+		case OpEndLoop:
+			vm.loops = vm.loops[:len(vm.loops)-1]
+
+		case OpBreak, OpContinue:
+			if len(vm.loops) == 0 { //e.g. the block of a 'foreach' loop, which is run by the tree-walker
+				if op == OpBreak {
+					return BREAK, true
+				}
+				return CONTINUE, true
+			}
+			vm.ip = vm.jumpOut(op == OpBreak)
+
+		case OpCallee:
+			call := vm.nodeAt(ins, vm.ip).(*ast.CallExpression)
+			end := readOperand(ins, vm.ip+2)
+			vm.ip += 4
+
+			name := call.Function.(*ast.Identifier).Value
+			if fn, ok := vm.scope.Get(name); ok {
+				if f, ok := fn.(*Function); ok && !f.Async {
+					vm.push(f)
+					continue
+				}
+			} else if builtin, ok := builtins[name]; ok {
+				vm.push(builtin)
+				continue
+			}
+			//classes, async functions, unknown names...
+			vm.ip = end
+			vm.push(evalFunctionCall(call, vm.scope))
+
+		case OpCall:
+			call := vm.nodeAt(ins, vm.ip).(*ast.CallExpression)
+			vm.ip += 2
+
+			args := vm.popN(len(call.Arguments))
+			switch fn := vm.pop().(type) {
+			case *Function:
+				vm.push(callFunctionObj(call, fn, args, vm.scope))
+			case *Builtin:
+				vm.push(callBuiltin(call, fn, args, vm.scope))
+			}
+
+		case OpArray:
+			n := readOperand(ins, vm.ip)
+			vm.ip += 2
+			vm.push(&Array{Members: vm.popN(n)})
+
+		case OpReturn:
+			n := readOperand(ins, vm.ip)
+			vm.ip += 2
+			ret := &ReturnValue{Value: NIL, Values: vm.popN(n)}
+			if n > 0 {
+				ret.Value = ret.Values[0]
+			}
+			return ret, true
+
+		case OpResult:
+			return vm.last, true
+
+		case OpEval:
+			node := vm.nodeAt(ins, vm.ip)
+			vm.ip += 2
+			vm.push(Eval(node, vm.scope))
+
+		default:
+			panic(fmt.Sprintf("unknown opcode %d", op))
+		}
+	}
+	return vm.last, true
+}
+
+//assignLocal assigns the local variable in the slot, it's not ok if the
+//assignment must be evaluated by 'evalAssign'.
+func (vm *VM) assignLocal(idx int, val Object) bool {
+	if vm.frame == nil || TypeCheck {
+		return false
+	}
+	if !vm.thisLooked {
+		vm.this, _ = vm.scope.Get("this")
+		vm.thisLooked = true
+	}
+	if vm.this != nil {
+		return false
+	}
+	return vm.frame.setSlot(idx, val, false)
+}
+
+//letValue is the value of 'lit name = value', the first value if the function
+//returns multiple values.
+func letValue(val Object) Object {
+	if tuple, ok := val.(*Tuple); ok && tuple.IsMulti {
+		val = NIL
+		if len(tuple.Members) != 0 {
+			val = tuple.Members[0]
+		}
+	}
+	return val
+}
+
+//arith is the fast path of the typed arithmetic instructions, for two Integers
+//or two Floats. The results are the ones of 'evalNumberInfixExpression', which
+//computes the Integers as float64. It's not ok for the other operands and the
+//division by zero, they're evaluated by 'evalInfixExpression'.
+func arith(op Opcode, left, right Object) (Object, bool) {
+	var l, r float64
+	var isInt bool
+	switch lv := left.(type) {
+	case *Integer:
+		rv, ok := right.(*Integer)
+		if !ok {
+			return nil, false
+		}
+		l, r, isInt = float64(lv.Int64), float64(rv.Int64), true
+	case *Float:
+		rv, ok := right.(*Float)
+		if !ok {
+			return nil, false
+		}
+		l, r = lv.Float64, rv.Float64
+	default:
+		return nil, false
+	}
+
+	switch op {
+	case OpAdd:
+		return newNumber(l+r, isInt), true
+	case OpSub:
+		return newNumber(l-r, isInt), true
+	case OpMul:
+		return newNumber(l*r, isInt), true
+	case OpDiv:
+		if r == 0 {
+			return nil, false
+		}
+		return NewFloat(l / r), true
+	case OpMod:
+		if !isInt {
+			return NewFloat(math.Mod(l, r)), true
+		}
+		if int64(r) == 0 {
+			return nil, false
+		}
+		return NewInteger(int64(l) % int64(r)), true
+	case OpEqual:
+		return nativeBoolToBooleanObject(l == r), true
+	case OpNotEqual:
+		return nativeBoolToBooleanObject(l != r), true
+	case OpLess:
+		return nativeBoolToBooleanObject(l < r), true
+	case OpLessEq:
+		return nativeBoolToBooleanObject(l <= r), true
+	case OpGreater:
+		return nativeBoolToBooleanObject(l > r), true
+	case OpGreaterEq:
+		return nativeBoolToBooleanObject(l >= r), true
+	}
+	return nil, false
+}
+
+func newNumber(val float64, isInt bool) Object {
+	if isInt {
+		return NewInteger(int64(val))
+	}
+	return NewFloat(val)
+}
+
+//jumpOut leaves the current iteration of the innermost loop, returns the target.
+func (vm *VM) jumpOut(brk bool) int {
+	loop := vm.loops[len(vm.loops)-1]
+	vm.stack = vm.stack[:loop.sp]
+	if brk {
+		vm.scope = loop.scope
+		return loop.brk
+	}
+	return loop.cont
+}
+
+//nodeAt returns the node operand of the running instruction.
+func (vm *VM) nodeAt(ins Instructions, ip int) ast.Node {
+	vm.node = vm.code.Nodes[readOperand(ins, ip)]
+	return vm.node
+}
+
+func (vm *VM) push(obj Object) {
+	withStack(obj, vm.node, vm.scope)
+	vm.stack = append(vm.stack, obj)
+}
+
+func (vm *VM) pop() Object {
+	obj := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return obj
+}
+
+//popN pops 'n' values, in the order they were pushed.
+func (vm *VM) popN(n int) []Object {
+	values := make([]Object, n)
+	copy(values, vm.stack[len(vm.stack)-n:])
+	vm.stack = vm.stack[:len(vm.stack)-n]
+	return values
+}
+
+//callBuiltin calls the builtin with the evaluated arguments of 'call'.
+func callBuiltin(call *ast.CallExpression, builtin *Builtin, args []Object, scope *Scope) Object {
+	//check for errors
+	for _, v := range args {
+		if v.Type() == ERROR_OBJ {
+			return v
+		}
+	}
+	return builtin.Fn(call.Function.Pos().Sline(), scope, args...)
+}
+
+//copyConstant returns a new object for the mutable constants, the tree-walker
+//creates a new object each time a literal is evaluated.
+func copyConstant(obj Object) Object {
+	switch o := obj.(type) {
+	case *Integer:
+		return NewInteger(o.Int64)
+	case *UInteger:
+		return NewUInteger(o.UInt64)
+	case *Float:
+		return NewFloat(o.Float64)
+	case *String:
+		return NewString(o.String)
+	}
+	return obj
+}
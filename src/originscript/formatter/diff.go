@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+//number of unchanged lines shown around a change
+const diffContext = 3
+
+//Diff returns a unified diff between the original source and the formatted one,
+//or an empty string if they are the same.
+func Diff(filename string, a, b []byte) string {
+	if bytes.Equal(a, b) {
+		return ""
+	}
+	x := splitLines(a)
+	y := splitLines(b)
+
+	//each edit is ' ', '-' or '+' followed by the line
+	edits := lcsEdits(x, y)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s (formatted)\n", filename, filename)
+
+	i := 0
+	for i < len(edits) {
+		if edits[i][0] == ' ' {
+			i++
+			continue
+		}
+
+		//a hunk: the changes which are close to each other, with their context
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end][0] != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next][0] == ' ' {
+				next++
+			}
+			if next == len(edits) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		stop := end + diffContext
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+
+		//line numbers of the hunk
+		aLine, bLine := 1, 1
+		for _, e := range edits[:start] {
+			if e[0] != '+' {
+				aLine++
+			}
+			if e[0] != '-' {
+				bLine++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, e := range edits[start:stop] {
+			if e[0] != '+' {
+				aCount++
+			}
+			if e[0] != '-' {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, e := range edits[start:stop] {
+			buf.WriteString(e + "\n")
+		}
+		i = stop
+	}
+	return buf.String()
+}
+
+func splitLines(s []byte) []string {
+	text := strings.Replace(string(s), "\r\n", "\n", -1)
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+//lcsEdits computes the edits from 'x' to 'y' using the longest common subsequence.
+func lcsEdits(x, y []string) []string {
+	var edits []string
+
+	//the common prefix and suffix are skipped, they are the most of a formatted file.
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		edits = append(edits, " "+x[prefix])
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	xs := x[prefix : len(x)-suffix]
+	ys := y[prefix : len(y)-suffix]
+
+	//lcs[i][j]: length of the LCS of xs[i:] and ys[j:]
+	lcs := make([][]int, len(xs)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(ys)+1)
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for j := len(ys) - 1; j >= 0; j-- {
+			if xs[i] == ys[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(xs) || j < len(ys) {
+		switch {
+		case i < len(xs) && j < len(ys) && xs[i] == ys[j]:
+			edits = append(edits, " "+xs[i])
+			i++
+			j++
+		case i < len(xs) && (j == len(ys) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, "-"+xs[i])
+			i++
+		default:
+			edits = append(edits, "+"+ys[j])
+			j++
+		}
+	}
+
+	for _, line := range x[len(x)-suffix:] {
+		edits = append(edits, " "+line)
+	}
+	return edits
+}
@@ -0,0 +1,1412 @@
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"originscript/ast"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const indentStr = "    "
+
+//Source formats a whole source file. Unlike the 'Formatter' which works on the
+//characters of the input, it's driven by the AST: blocks are re-indented,
+//the layout of classes and services is normalized, and the comments are kept.
+func Source(filename string, src []byte) ([]byte, error) {
+	input := string(src)
+
+	l := lexer.New(filename, input)
+	p := parser.NewWithDoc(l, filepath.Dir(filename))
+	program := p.ParseProgram()
+
+	var errs []string
+	for _, msg := range p.Errors() {
+		if strings.Contains(msg, "e3209") { //a missing module does not matter for formatting
+			continue
+		}
+		errs = append(errs, msg)
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	pr := newPrinter(input, p.Comments())
+	pr.program(program)
+
+	out := pr.out.Bytes()
+	if strings.HasPrefix(input, "\ufeff") {
+		out = append([]byte("\ufeff"), out...)
+	}
+	if strings.Contains(input, "\r\n") { //keep the line endings of the source
+		out = bytes.Replace(out, []byte("\n"), []byte("\r\n"), -1)
+	}
+	return out, nil
+}
+
+//Print prints a program parsed from 'src' which may have been transformed since,
+//e.g. by the optimizer. The comments are not printed, and the literals which are
+//not in the source(e.g. the value of a folded expression) are printed from their
+//values, so the output can be parsed again.
+func Print(src []byte, program *ast.Program) []byte {
+	pr := newPrinter(string(src), nil)
+	pr.program(program)
+	return pr.out.Bytes()
+}
+
+type comment struct {
+	start int //rune offset in the source
+	end   int
+	text  string
+	done  bool
+}
+
+type printer struct {
+	src      []rune
+	lines    []int //offsets of the beginning of each source line
+	comments []*comment
+	next     int //the first comment which is not printed yet
+	out      bytes.Buffer
+	indent   int
+	lastLine int //source line of the last printed item, 0 at the beginning of a block
+
+	spans   map[interface{}][2]int
+	exports map[ast.Statement]bool //the statements marked with 'export'
+}
+
+func newPrinter(input string, groups []*ast.CommentGroup) *printer {
+	p := &printer{src: []rune(input), spans: make(map[interface{}][2]int)}
+
+	p.lines = append(p.lines, 0)
+	for i, ch := range p.src {
+		if ch == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	for _, g := range groups {
+		for _, c := range g.List {
+			start := c.Token.Pos.Offset
+			text := strings.TrimRight(c.Text, " \t\r\n")
+			end := start + len([]rune(text))
+			if c.Token.Type == token.DEFINE { //'#define   X' may have more spaces than the text
+				end = p.lineEnd(start)
+			}
+			p.comments = append(p.comments, &comment{start: start, end: end, text: text})
+		}
+	}
+	sort.SliceStable(p.comments, func(i, j int) bool { return p.comments[i].start < p.comments[j].start })
+	return p
+}
+
+func (p *printer) program(program *ast.Program) {
+	stmts := append([]ast.Statement{}, program.Statements...)
+	p.exports = make(map[ast.Statement]bool)
+	for _, s := range program.Exports {
+		p.exports[s] = true
+	}
+	for _, imp := range program.Imports {
+		stmts = append(stmts, imp)
+	}
+	sort.SliceStable(stmts, func(i, j int) bool {
+		si, _ := p.span(stmts[i])
+		sj, _ := p.span(stmts[j])
+		return si < sj
+	})
+
+	p.stmtList(stmts, false)
+	p.flushComments(len(p.src))
+}
+
+//stmtList prints the statements each on its own line, with the comments and
+//blank lines between them. In a class or service body(members == true), the
+//methods and the properties with a body are separated by a blank line.
+func (p *printer) stmtList(stmts []ast.Statement, members bool) {
+	for i, s := range stmts {
+		start, end := p.span(s)
+		if start < 0 {
+			continue
+		}
+		p.flushComments(start)
+		if members && i > 0 && p.lastLine > 0 && (isBigMember(stmts[i-1]) || isBigMember(s)) {
+			p.lastLine = -1 //force a blank line
+		}
+		p.blankLine(start)
+		p.writeIndent()
+		if p.exports[s] {
+			p.write("export ")
+		}
+		p.stmt(s)
+		p.semicolon(start, end)
+		p.finishLine(end)
+	}
+}
+
+func isBigMember(s ast.Statement) bool {
+	switch s := s.(type) {
+	case *ast.FunctionStatement:
+		return true
+	case *ast.PropertyDeclStmt:
+		return s.Getter != nil && s.Getter.Body != nil && s.Getter.Body.Token.Pos.Line != 0 ||
+			s.Setter != nil && s.Setter.Body != nil && s.Setter.Body.Token.Pos.Line != 0
+	}
+	return false
+}
+
+//flushComments prints all the comments before the offset as standalone lines.
+func (p *printer) flushComments(offset int) {
+	for p.next < len(p.comments) && p.comments[p.next].start < offset {
+		c := p.comments[p.next]
+		p.next++
+		if c.done {
+			continue
+		}
+		c.done = true
+		p.blankLine(c.start)
+		p.writeIndent()
+		p.write(c.text)
+		p.write("\n")
+		p.lastLine = p.line(c.end - 1)
+	}
+}
+
+//finishLine ends the line of an item which ended at the source offset 'end'.
+//A comment on the same source line is kept on the line, the comments which
+//were inside the item but could not be printed there follow it.
+func (p *printer) finishLine(end int) {
+	line := p.line(end - 1)
+
+	var inner []*comment
+	for p.next < len(p.comments) && p.comments[p.next].start < end {
+		if c := p.comments[p.next]; !c.done {
+			inner = append(inner, c)
+		}
+		p.next++
+	}
+	if p.next < len(p.comments) {
+		if c := p.comments[p.next]; !c.done && p.line(c.start) == line && !strings.Contains(c.text, "\n") {
+			p.write("  " + c.text)
+			c.done = true
+			p.next++
+		}
+	}
+	p.write("\n")
+	p.lastLine = line
+
+	for _, c := range inner {
+		c.done = true
+		p.writeIndent()
+		p.write(c.text)
+		p.write("\n")
+	}
+}
+
+//blankLine keeps (at most) one of the blank lines before the item at 'offset'.
+func (p *printer) blankLine(offset int) {
+	if p.lastLine < 0 || (p.lastLine > 0 && p.line(offset) > p.lastLine+1) {
+		p.write("\n")
+	}
+	p.lastLine = 0
+}
+
+//semicolon keeps the ';' which ends the statement in the source, because the
+//newlines are not significant, e.g. 'return;' followed by '(a, b) = f()'.
+func (p *printer) semicolon(start, end int) {
+	if b := bytes.TrimRight(p.out.Bytes(), " "); len(b) > 0 && b[len(b)-1] == ';' {
+		return
+	}
+	i := end - 1
+	for i > start && unicode.IsSpace(p.src[i]) {
+		i--
+	}
+	if p.src[i] == ';' {
+		p.write(";")
+		return
+	}
+	for i = end; i < len(p.src) && (p.src[i] == ' ' || p.src[i] == '\t'); i++ {
+	}
+	if i < len(p.src) && p.src[i] == ';' {
+		p.write(";")
+	}
+}
+
+func (p *printer) write(s string) {
+	p.out.WriteString(s)
+}
+
+func (p *printer) writeIndent() {
+	p.write(strings.Repeat(indentStr, p.indent))
+}
+
+//line returns the 1-based source line of the offset.
+func (p *printer) line(offset int) int {
+	return sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > offset })
+}
+
+func (p *printer) lineEnd(offset int) int {
+	for offset < len(p.src) && p.src[offset] != '\n' && p.src[offset] != '\r' {
+		offset++
+	}
+	return offset
+}
+
+//multiline reports whether a node spans more than one source line.
+func (p *printer) multiline(n interface{}) bool {
+	start, end := p.span(n)
+	return start >= 0 && p.line(start) != p.line(end-1)
+}
+
+//newlineAfter reports whether the source has a newline right after the token,
+//it's used to keep the user's choice between one-line and multi-line lists.
+func (p *printer) newlineAfter(tok token.Token) bool {
+	if tok.Pos.Line == 0 {
+		return false
+	}
+	for i := p.tokenEnd(tok); i < len(p.src); i++ {
+		switch p.src[i] {
+		case ' ', '\t', '\r':
+			continue
+		case '\n':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+//sameLineComment appends the comment which follows the token on the same line, e.g. 'if x { # comment'.
+func (p *printer) sameLineComment(tok token.Token) {
+	if tok.Pos.Line == 0 || p.next >= len(p.comments) {
+		return
+	}
+	c := p.comments[p.next]
+	if !c.done && c.start > tok.Pos.Offset && p.line(c.start) == tok.Pos.Line && !strings.Contains(c.text, "\n") {
+		p.write("  " + c.text)
+		c.done = true
+		p.next++
+	}
+}
+
+///////////////////////////////////////////////////////////
+//                      STATEMENTS                       //
+///////////////////////////////////////////////////////////
+
+func (p *printer) stmt(s ast.Statement) {
+	switch s := s.(type) {
+	case *ast.ExpressionStatement:
+		p.operand(s.Expression)
+	case *ast.LetStatement:
+		p.letStmt(s)
+	case *ast.ConstStatement:
+		p.constStmt(s)
+	case *ast.ReturnStatement:
+		p.write(s.Token.Literal)
+		if len(s.ReturnValues) > 0 {
+			p.write(" ")
+			p.exprList(s.ReturnValues)
+		}
+	case *ast.DeferStmt:
+		p.write(s.Token.Literal + " ")
+		p.operand(s.Call)
+	case *ast.SpawnStmt:
+		p.write(s.Token.Literal + " ")
+		p.operand(s.Call)
+	case *ast.ThrowStmt:
+		p.write(s.Token.Literal)
+		if s.Expr != nil {
+			p.write(" ")
+			p.operand(s.Expr)
+		}
+	case *ast.TryStmt:
+		p.write(s.Token.Literal + " ")
+		p.block(s.Try)
+		for _, c := range s.Catches {
+			p.write(" catch ")
+			if len(c.Types) > 0 {
+				p.write("(" + c.Var + ": " + strings.Join(c.Types, " | ") + ") ")
+			} else if c.Var != "" {
+				p.write(c.Var + " ")
+			}
+			p.block(c.Block)
+		}
+		if s.Finally != nil {
+			p.write(" finally ")
+			p.block(s.Finally)
+		}
+	case *ast.FunctionStatement:
+		p.funcStmt(s)
+	case *ast.ClassStatement:
+		p.classStmt(s)
+	case *ast.PropertyDeclStmt:
+		p.propertyStmt(s)
+	case *ast.ServiceStatement:
+		p.write(s.Token.Literal + " " + s.Name.Value + " on ")
+		addr := s.Addr
+		if s.Debug {
+			addr += ":debug"
+		}
+		p.write("\"" + addr + "\" ")
+		p.body(s.Block, true)
+	case *ast.IfMacroStatement:
+		p.write(s.Token.Literal + " " + s.ConditionStr + " ")
+		p.block(s.Consequence)
+		if s.Alternative != nil {
+			p.write(" #else ")
+			p.block(s.Alternative)
+		}
+	case *ast.UsingStmt:
+		p.write(s.Token.Literal + " (")
+		p.expr(s.Expr)
+		p.write(") ")
+		p.block(s.Block)
+	case *ast.BlockStatement:
+		p.block(s)
+	case *ast.ImportStatement:
+		p.importStmt(s)
+	default: //e.g. enum statements
+		p.verbatim(s)
+	}
+}
+
+func (p *printer) letStmt(s *ast.LetStatement) {
+	p.annotations(s.Annotations)
+	p.write(s.ModifierLevel.String())
+	if s.StaticFlag {
+		p.write("static ")
+	}
+	if p.hasText(s.Token) { //not the dummy 'var' token of 'a, b = 1, 2'
+		p.write(s.Token.Literal + " ")
+	}
+
+	names := make([]string, len(s.Names))
+	for i, name := range s.Names {
+		names[i] = name.Value
+		if t, ok := s.Types[name.Value]; ok {
+			names[i] += ": " + t.String()
+		}
+	}
+	if s.DestructingFlag {
+		p.write("(" + strings.Join(names, ", ") + ")")
+	} else {
+		p.write(strings.Join(names, ", "))
+	}
+
+	if len(s.Values) > 0 {
+		p.write(" = ")
+		p.exprList(s.Values)
+	}
+}
+
+func (p *printer) constStmt(s *ast.ConstStatement) {
+	p.write(s.Token.Literal + " ")
+	if !p.nextCharIs(s.Token, '(') { //const x = xxx
+		p.write(s.Name[0].Value + " = ")
+		p.operand(s.Value[0])
+		return
+	}
+
+	//const ( A, B = 5, C ), the values which are generated have no position.
+	multiline := p.multiline(s)
+	p.write("(")
+	if multiline {
+		p.write("\n")
+		p.indent++
+	}
+	for i, name := range s.Name {
+		if multiline {
+			p.flushComments(name.Token.Pos.Offset)
+			p.writeIndent()
+		} else if i > 0 {
+			p.write(", ")
+		}
+		p.write(name.Value)
+		if v := s.Value[i]; p.hasPos(v) {
+			p.write(" = ")
+			p.operand(v)
+		}
+		if multiline {
+			if i < len(s.Name)-1 {
+				p.write(",")
+			}
+			_, end := p.span(name)
+			if p.hasPos(s.Value[i]) {
+				_, end = p.span(s.Value[i])
+			}
+			p.finishLine(end)
+		}
+	}
+	if multiline {
+		p.indent--
+		p.writeIndent()
+	}
+	p.write(")")
+}
+
+func (p *printer) funcStmt(s *ast.FunctionStatement) {
+	if s.Name.Token.Type == token.FUNCTION { //e.g. 'fn(x, y) { x + y }(2, 3)'
+		p.verbatim(s)
+		return
+	}
+
+	fn := s.FunctionLiteral
+	p.annotations(s.Annotations)
+	p.write(fn.ModifierLevel.String())
+	if fn.StaticFlag {
+		p.write("static ")
+	}
+	if fn.Async {
+		p.write("async ")
+	}
+	p.write(s.Token.Literal + " " + s.Name.Value)
+	p.params(fn)
+	p.write(" ")
+	p.block(fn.Body)
+}
+
+func (p *printer) classStmt(s *ast.ClassStatement) {
+	p.write(s.Token.Literal + " ")
+	if s.IsAnnotation {
+		p.write("@")
+	}
+	p.write(s.Name.Value)
+	if s.CategoryName != nil {
+		p.write("(" + s.CategoryName.Value + ")")
+	}
+	if s.ClassLiteral.Parent != "" {
+		p.write(" : " + strings.Join(append([]string{s.ClassLiteral.Parent}, s.ClassLiteral.Interfaces...), ", "))
+	}
+	p.write(" ")
+	p.body(s.ClassLiteral.Block, true)
+}
+
+func (p *printer) propertyStmt(s *ast.PropertyDeclStmt) {
+	p.annotations(s.Annotations)
+	p.write(s.ModifierLevel.String())
+	if s.StaticFlag {
+		p.write("static ")
+	}
+
+	//the getter and setter of 'property xxx;' are generated by the parser.
+	if s.Getter != nil && s.Getter.Token.Pos == s.Name.Token.Pos {
+		_, end := p.span(s)
+		p.verbatimRange(s.Token.Pos.Offset, end)
+		return
+	}
+
+	p.write(s.Token.Literal + " ")
+	if len(s.Indexes) > 0 {
+		indexes := make([]string, len(s.Indexes))
+		for i, idx := range s.Indexes {
+			indexes[i] = idx.Value
+		}
+		p.write("this[" + strings.Join(indexes, ", ") + "]")
+	} else {
+		p.write(s.Name.Value)
+	}
+	if s.Type != nil {
+		p.write(": " + s.Type.String())
+	}
+
+	hasBody := func(b *ast.BlockStatement) bool { return b != nil && b.Token.Pos.Line != 0 }
+	if (s.Getter == nil || !hasBody(s.Getter.Body)) && (s.Setter == nil || !hasBody(s.Setter.Body)) {
+		p.write(" {")
+		if s.Getter != nil {
+			p.write(" get;")
+		}
+		if s.Setter != nil {
+			p.write(" set;")
+		}
+		p.write(" }")
+		return
+	}
+
+	p.write(" {\n")
+	p.indent++
+	p.lastLine = 0
+	accessor := func(tok token.Token, body *ast.BlockStatement) {
+		p.flushComments(tok.Pos.Offset)
+		p.blankLine(tok.Pos.Offset)
+		p.writeIndent()
+		p.write(tok.Literal)
+		end := p.tokenEnd(tok)
+		if hasBody(body) {
+			p.write(" ")
+			p.block(body)
+			end = body.RBraceToken.Pos.Offset + 1
+		} else {
+			p.write(";")
+		}
+		p.finishLine(end)
+	}
+	if s.Getter != nil {
+		accessor(s.Getter.Token, s.Getter.Body)
+	}
+	if s.Setter != nil {
+		accessor(s.Setter.Token, s.Setter.Body)
+	}
+	p.flushComments(s.SrcEndToken.Pos.Offset)
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+}
+
+//require net.util as nutil
+//require util (parse, format)
+func (p *printer) importStmt(s *ast.ImportStatement) {
+	p.write(s.String())
+}
+
+func (p *printer) annotations(annos []*ast.AnnotationStmt) {
+	for _, anno := range annos {
+		p.write("@" + anno.Name.Value)
+		if len(anno.Attributes) > 0 {
+			keys := make([]string, 0, len(anno.Attributes))
+			for k := range anno.Attributes {
+				keys = append(keys, k)
+			}
+			sort.Slice(keys, func(i, j int) bool { //keep the order of the source
+				si, _ := p.span(anno.Attributes[keys[i]])
+				sj, _ := p.span(anno.Attributes[keys[j]])
+				return si < sj
+			})
+
+			open, close := "(", ")"
+			if p.nextCharIs(anno.Name.Token, '{') {
+				open, close = "{", "}"
+			}
+			p.write(open)
+			for i, k := range keys {
+				if i > 0 {
+					p.write(", ")
+				}
+				p.write(k + "=")
+				p.expr(anno.Attributes[k])
+			}
+			p.write(close)
+		}
+		p.write("\n")
+		p.writeIndent()
+	}
+}
+
+//block prints a '{ }' block, with its statements re-indented.
+func (p *printer) block(b *ast.BlockStatement) {
+	p.body(b, false)
+}
+
+func (p *printer) body(b *ast.BlockStatement, members bool) {
+	start, end := p.span(b)
+	rbrace := end - 1
+	if b.RBraceToken.Pos.Line != 0 {
+		rbrace = b.RBraceToken.Pos.Offset
+	}
+
+	if len(b.Statements) == 0 && !p.hasComments(start, rbrace) {
+		p.write("{}")
+		return
+	}
+
+	p.write("{")
+	p.sameLineComment(b.Token)
+	p.write("\n")
+	p.indent++
+	p.lastLine = 0
+	p.stmtList(b.Statements, members)
+	p.flushComments(rbrace)
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+}
+
+func (p *printer) hasComments(start, end int) bool {
+	for i := p.next; i < len(p.comments) && p.comments[i].start < end; i++ {
+		if !p.comments[i].done && p.comments[i].start > start {
+			return true
+		}
+	}
+	return false
+}
+
+///////////////////////////////////////////////////////////
+//                      EXPRESSIONS                      //
+///////////////////////////////////////////////////////////
+
+//operand prints an expression, keeping the parentheses around it in the source.
+func (p *printer) operand(e ast.Expression) {
+	if p.parenthesized(e) {
+		p.write("(")
+		p.expr(e)
+		p.write(")")
+		return
+	}
+	p.expr(e)
+}
+
+func (p *printer) exprList(list []ast.Expression) {
+	for i, e := range list {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.operand(e)
+	}
+}
+
+func (p *printer) expr(e ast.Expression) {
+	switch e := e.(type) {
+	case nil:
+	case *ast.Identifier:
+		p.write(e.Value)
+	case *ast.PrefixExpression:
+		p.write(e.Operator)
+		if r, ok := e.Right.(*ast.PrefixExpression); ok && r.Operator[0] == e.Operator[0] { //'- -x', not '--x'
+			p.write(" ")
+		} else if unicode.IsLetter([]rune(e.Operator)[0]) {
+			p.write(" ")
+		}
+		p.operand(e.Right)
+	case *ast.InfixExpression:
+		p.operand(e.Left)
+		p.write(" " + e.Operator + " ")
+		p.operand(e.Right)
+	case *ast.PostfixExpression:
+		p.operand(e.Left)
+		p.write(e.Operator)
+	case *ast.AssignExpression:
+		p.operand(e.Name)
+		p.write(" " + e.Token.Literal + " ")
+		p.operand(e.Value)
+	case *ast.TernaryExpression:
+		p.operand(e.Condition)
+		p.write(" ? ")
+		p.operand(e.IfTrue)
+		p.write(" : ")
+		p.operand(e.IfFalse)
+	case *ast.RangeLiteral:
+		p.operand(e.StartIdx)
+		p.write("..")
+		p.operand(e.EndIdx)
+	case *ast.Pipe:
+		p.operand(e.Left)
+		p.write(" " + e.Token.Literal + " ")
+		p.operand(e.Right)
+	case *ast.CallExpression:
+		p.operand(e.Function)
+		p.list(e.Token, "(", e.Arguments, ")")
+	case *ast.MethodCallExpression:
+		p.operand(e.Object)
+		p.write(e.Token.Literal)
+		p.expr(e.Call)
+	case *ast.IndexExpression:
+		p.operand(e.Left)
+		p.write("[")
+		p.expr(e.Index)
+		p.write("]")
+	case *ast.SliceExpression:
+		if p.hasPos(e.StartIndex) {
+			p.operand(e.StartIndex)
+		}
+		p.write(":")
+		if e.EndIndex != nil {
+			p.operand(e.EndIndex)
+		}
+	case *ast.ClassIndexerExpression:
+		p.exprList(e.Parameters)
+	case *ast.ArrayLiteral:
+		if e.Token.Type != token.LBRACKET || e.CreationCount != nil { //qw(...) or '[]10'
+			p.verbatim(e)
+			return
+		}
+		p.list(e.Token, "[", e.Members, "]")
+	case *ast.TupleLiteral:
+		if len(e.Members) == 1 {
+			p.write("(")
+			p.operand(e.Members[0])
+			p.write(",)")
+			return
+		}
+		p.list(e.Token, "(", e.Members, ")")
+	case *ast.HashLiteral:
+		p.hash(e)
+	case *ast.FunctionLiteral:
+		p.funcLiteral(e)
+	case *ast.ClassLiteral:
+		p.write(e.Token.Literal)
+		if e.Parent != "" {
+			p.write(" : " + e.Parent)
+		}
+		p.write(" ")
+		p.body(e.Block, true)
+	case *ast.NewExpression:
+		p.write(e.Token.Literal + " ")
+		p.operand(e.Class)
+		p.list(e.Token, "(", e.Arguments, ")")
+	case *ast.AwaitExpr:
+		p.write(e.Token.Literal + " ")
+		p.operand(e.Call)
+	case *ast.BreakExpression:
+		p.write(e.Token.Literal)
+	case *ast.ContinueExpression:
+		p.write(e.Token.Literal)
+	case *ast.IfExpression:
+		for i, c := range e.Conditions {
+			if i > 0 {
+				p.write(" ")
+				if c.Token.Type == token.IF {
+					p.write("else ")
+				}
+			}
+			p.write(c.Token.Literal + " ")
+			p.operand(c.Cond)
+			p.write(" ")
+			p.node(c.Body)
+		}
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.node(e.Alternative)
+		}
+	case *ast.UnlessExpression:
+		p.write(e.Token.Literal + " ")
+		p.operand(e.Condition)
+		p.write(" ")
+		p.block(e.Consequence)
+		if e.Alternative != nil {
+			p.write(" else ")
+			p.block(e.Alternative)
+		}
+	case *ast.DoLoop:
+		p.write(e.Token.Literal + " ")
+		p.block(e.Block)
+	case *ast.ForEverLoop:
+		p.write(e.Token.Literal + " ")
+		p.block(e.Block)
+	case *ast.WhileLoop:
+		p.write(e.Token.Literal + " ")
+		p.operand(e.Condition)
+		p.loopBody(e.Block)
+	case *ast.ForLoop:
+		p.write(e.Token.Literal + " (")
+		p.expr(e.Init)
+		p.write("; ")
+		p.expr(e.Cond)
+		p.write("; ")
+		p.expr(e.Update)
+		p.write(")")
+		p.loopBody(e.Block)
+	case *ast.ForEachArrayLoop:
+		p.write(e.Token.Literal + " " + e.Var + " in ")
+		p.operand(e.Value)
+		p.where(e.Cond)
+		p.loopBody(e.Block)
+	case *ast.ForEachMapLoop:
+		p.write(e.Token.Literal + " " + e.Key + ", " + e.Value + " in ")
+		p.operand(e.X)
+		p.where(e.Cond)
+		p.loopBody(e.Block)
+	case *ast.ForEachDotRange:
+		p.write(e.Token.Literal + " " + e.Var + " in ")
+		p.operand(e.StartIdx)
+		p.write("..")
+		p.operand(e.EndIdx)
+		p.where(e.Cond)
+		p.loopBody(e.Block)
+	case *ast.CaseExpr:
+		p.caseExpr(e)
+	case *ast.IntegerLiteral, *ast.UIntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean, *ast.NilLiteral:
+		if text, ok := p.literal(e); ok {
+			p.write(text)
+			return
+		}
+		p.verbatim(e)
+	default: //strings, regexps, commands, comprehensions, linq, etc.
+		p.verbatim(e)
+	}
+}
+
+//node prints the body of an 'if', which is a block or a single expression statement.
+func (p *printer) node(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.BlockStatement:
+		p.block(n)
+	case ast.Statement:
+		p.stmt(n)
+	case ast.Expression:
+		p.operand(n)
+	}
+}
+
+//loopBody prints ' { block }' or ' => expr'
+func (p *printer) loopBody(n ast.Node) {
+	if b, ok := n.(*ast.BlockStatement); ok {
+		p.write(" ")
+		p.block(b)
+		return
+	}
+	p.write(" => ")
+	p.node(n)
+}
+
+func (p *printer) where(cond ast.Expression) {
+	if cond != nil {
+		p.write(" where ")
+		p.operand(cond)
+	}
+}
+
+func (p *printer) params(fn *ast.FunctionLiteral) {
+	p.write("(")
+	for i, param := range fn.Parameters {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expr(param)
+		if t, ok := fn.ParamTypes[param.String()]; ok {
+			p.write(": " + t.String())
+		}
+		if v, ok := fn.Values[param.String()]; ok {
+			p.write(" = ")
+			p.operand(v)
+		}
+		if fn.Variadic && i == len(fn.Parameters)-1 {
+			p.write("...")
+		}
+	}
+	p.write(")")
+	if fn.ReturnType != nil {
+		p.write(" -> " + fn.ReturnType.String())
+	}
+}
+
+func (p *printer) funcLiteral(fn *ast.FunctionLiteral) {
+	if fn.Async {
+		p.write("async ")
+	}
+	if fn.Token.Pos.Line != 0 { //fn(x) { block }
+		p.write(fn.Token.Literal)
+		p.params(fn)
+		p.write(" ")
+		p.block(fn.Body)
+		return
+	}
+
+	//(x, y) => x + y
+	p.params(fn)
+	p.write(" => ")
+	if fn.Body.Token.Pos.Line != 0 {
+		p.block(fn.Body)
+	} else if len(fn.Body.Statements) > 0 {
+		p.stmt(fn.Body.Statements[0])
+	}
+}
+
+func (p *printer) hash(h *ast.HashLiteral) {
+	if len(h.Order) == 0 {
+		p.write("{}")
+		return
+	}
+
+	multiline := p.newlineAfter(h.Token)
+	p.write("{")
+	if multiline {
+		p.write("\n")
+		p.indent++
+	}
+	for i, key := range h.Order {
+		start, _ := p.span(key)
+		if multiline {
+			p.flushComments(start)
+			p.writeIndent()
+		} else if i > 0 {
+			p.write(", ")
+		}
+		p.operand(key)
+		p.write(": ")
+		p.operand(h.Pairs[key])
+		if multiline {
+			if i < len(h.Order)-1 {
+				p.write(",")
+			}
+			_, end := p.span(h.Pairs[key])
+			p.finishLine(end)
+		}
+	}
+	if multiline {
+		p.flushComments(h.RBraceToken.Pos.Offset)
+		p.indent--
+		p.writeIndent()
+	}
+	p.write("}")
+}
+
+//list prints the items of a call, an array or a tuple. If the source has a newline
+//after the opening token, every item is printed on its own line.
+func (p *printer) list(open token.Token, lparen string, items []ast.Expression, rparen string) {
+	if len(items) == 0 || !p.newlineAfter(open) {
+		p.write(lparen)
+		for i, item := range items {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expr(item)
+		}
+		p.write(rparen)
+		return
+	}
+
+	p.write(lparen + "\n")
+	p.indent++
+	for i, item := range items {
+		start, end := p.span(item)
+		p.flushComments(start)
+		p.writeIndent()
+		p.expr(item)
+		if i < len(items)-1 {
+			p.write(",")
+		}
+		p.finishLine(end)
+	}
+	p.indent--
+	p.writeIndent()
+	p.write(rparen)
+}
+
+//case x in {
+//    1, 2 { block }
+//    else { block }
+//}
+func (p *printer) caseExpr(c *ast.CaseExpr) {
+	p.write(c.Token.Literal + " ")
+	p.operand(c.Expr)
+	if c.IsWholeMatch {
+		p.write(" is {\n")
+	} else {
+		p.write(" in {\n")
+	}
+	p.indent++
+	p.lastLine = 0
+	for i := 0; i < len(c.Matches); i++ {
+		start, _ := p.span(c.Matches[i])
+		p.flushComments(start)
+		p.blankLine(start)
+		p.writeIndent()
+
+		var block *ast.BlockStatement
+		switch m := c.Matches[i].(type) {
+		case *ast.CaseElseExpr:
+			p.write(m.Token.Literal + " ")
+			block = m.Block
+		case *ast.CaseMatchExpr:
+			//the patterns separated by ',' share the same block
+			p.operand(m.Expr)
+			for i+1 < len(c.Matches) {
+				next, ok := c.Matches[i+1].(*ast.CaseMatchExpr)
+				if !ok || next.Block != m.Block {
+					break
+				}
+				p.write(", ")
+				p.operand(next.Expr)
+				i++
+			}
+			p.write(" ")
+			block = m.Block
+		}
+		p.block(block)
+		p.finishLine(block.RBraceToken.Pos.Offset + 1)
+	}
+	_, end := p.span(c)
+	p.flushComments(end - 1)
+	p.indent--
+	p.writeIndent()
+	p.write("}")
+}
+
+///////////////////////////////////////////////////////////
+//                    SOURCE POSITIONS                   //
+///////////////////////////////////////////////////////////
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+var qwPairs = map[rune]rune{'(': ')', '<': '>', '{': '}'}
+
+//span returns the source range [start, end) of a node, computed from all the
+//tokens of its subtree. The parentheses and brackets which are not kept in the
+//AST(e.g. the ')' of a call) are added by balancing the range.
+func (p *printer) span(n interface{}) (int, int) {
+	if sp, ok := p.spans[n]; ok {
+		return sp[0], sp[1]
+	}
+
+	start, end := -1, -1
+	seen := make(map[uintptr]bool)
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Ptr:
+			if v.IsNil() || seen[v.Pointer()] {
+				return
+			}
+			seen[v.Pointer()] = true
+			walk(v.Elem())
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			for _, k := range v.MapKeys() {
+				walk(k)
+				walk(v.MapIndex(k))
+			}
+		case reflect.Struct:
+			if v.Type() == tokenType {
+				tok := v.Interface().(token.Token)
+				if tok.Pos.Line == 0 || tok.Type == token.EOF { //generated by the parser
+					return
+				}
+				if start < 0 || tok.Pos.Offset < start {
+					start = tok.Pos.Offset
+				}
+				if e := p.tokenEnd(tok); e > end {
+					end = e
+				}
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				switch v.Type().Field(i).Name {
+				case "Doc", "Program", "Functions": //comments, and the imported modules
+					continue
+				}
+				if f := v.Field(i); f.CanInterface() {
+					walk(f)
+				}
+			}
+		}
+	}
+	walk(reflect.ValueOf(n))
+
+	if start >= 0 {
+		start, end = p.balance(start, end)
+	}
+	if a, ok := n.(*ast.ArrayLiteral); ok && a.Token.Type == token.QW && start >= 0 { //quo<a, b>: the closing char is not kept
+		if i := p.skipSpace(p.tokenEnd(a.Token)); i < len(p.src) {
+			if closer, ok := qwPairs[p.src[i]]; ok && p.src[end-1] != closer {
+				end = p.delimitedEnd(end, closer)
+			}
+		}
+	}
+	p.spans[n] = [2]int{start, end}
+	return start, end
+}
+
+//balance extends the range to the unmatched parentheses and brackets around it.
+func (p *printer) balance(start, end int) (int, int) {
+	depth, minDepth := 0, 0
+	p.scan(start, end, func(i int) {
+		switch p.src[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < minDepth {
+				minDepth = depth
+			}
+		}
+	})
+
+	for n := -minDepth; n > 0; n-- {
+		i := start - 1
+		for i >= 0 && unicode.IsSpace(p.src[i]) {
+			i--
+		}
+		if i < 0 || !strings.ContainsRune("([{", p.src[i]) {
+			break
+		}
+		start = i
+	}
+	for n := depth - minDepth; n > 0; n-- {
+		i := p.skipSpace(end)
+		if i >= len(p.src) || !strings.ContainsRune(")]}", p.src[i]) {
+			break
+		}
+		end = i + 1
+	}
+	return start, end
+}
+
+//skipSpace skips the white spaces and comments from the offset.
+func (p *printer) skipSpace(i int) int {
+	for i < len(p.src) {
+		if unicode.IsSpace(p.src[i]) {
+			i++
+		} else if c := p.commentAt(i); c != nil {
+			i = c.end
+		} else {
+			break
+		}
+	}
+	return i
+}
+
+func (p *printer) commentAt(offset int) *comment {
+	i := sort.Search(len(p.comments), func(i int) bool { return p.comments[i].start >= offset })
+	if i < len(p.comments) && p.comments[i].start == offset {
+		return p.comments[i]
+	}
+	return nil
+}
+
+//scan calls 'fn' for each character of the range which is code, i.e. not in a string or a comment.
+func (p *printer) scan(start, end int, fn func(i int)) {
+	for i := start; i < end; i++ {
+		if c := p.commentAt(i); c != nil {
+			i = c.end - 1
+			continue
+		}
+		switch p.src[i] {
+		case '"', '\'', '`':
+			i = p.stringEnd(i) - 1
+			continue
+		}
+		fn(i)
+	}
+}
+
+//tokenEnd returns the offset after the token's source text.
+func (p *printer) tokenEnd(tok token.Token) int {
+	off := tok.Pos.Offset
+	if off >= len(p.src) {
+		return len(p.src)
+	}
+
+	switch tok.Type {
+	case token.STRING, token.ISTRING, token.CMD:
+		if strings.ContainsRune("\"'`", p.src[off]) {
+			return p.stringEnd(off)
+		}
+	case token.REGEX:
+		if p.src[off] == '/' {
+			return p.delimitedEnd(off+1, '/')
+		}
+	case token.DATETIME:
+		if p.hasPrefix(off, "dt/") {
+			return p.delimitedEnd(off+3, '/')
+		}
+	case token.LD: //<$file>
+		return p.delimitedEnd(off+1, '>')
+	case token.INT, token.UINT, token.FLOAT:
+		i := off
+		for i < len(p.src) && (isWordRune(p.src[i]) || p.src[i] == '.' && i+1 < len(p.src) && unicode.IsDigit(p.src[i+1])) {
+			i++
+		}
+		return i
+	}
+
+	if p.hasPrefix(off, tok.Literal) {
+		return off + len([]rune(tok.Literal))
+	}
+	return off + 1
+}
+
+//stringEnd returns the offset after the string which starts at the offset.
+func (p *printer) stringEnd(off int) int {
+	q := p.src[off]
+	if q == '`' && p.hasPrefix(off, "``") { //raw string
+		for i := off + 2; i < len(p.src)-1; i++ {
+			if p.src[i] == '`' && p.src[i+1] == '`' {
+				return i + 2
+			}
+		}
+		return len(p.src)
+	}
+
+	braces := 0
+	for i := off + 1; i < len(p.src); i++ {
+		switch ch := p.src[i]; {
+		case ch == '\\' && q != '\'':
+			i++
+		case q == '\'' && ch == '{': //interpolated expressions
+			braces++
+		case q == '\'' && ch == '}':
+			braces--
+		case ch == q && braces <= 0:
+			return i + 1
+		}
+	}
+	return len(p.src)
+}
+
+func (p *printer) delimitedEnd(i int, delim rune) int {
+	for ; i < len(p.src); i++ {
+		if p.src[i] == '\\' {
+			i++
+		} else if p.src[i] == delim {
+			return i + 1
+		}
+	}
+	return len(p.src)
+}
+
+func (p *printer) hasPrefix(off int, s string) bool {
+	for _, ch := range s {
+		if off >= len(p.src) || p.src[off] != ch {
+			return false
+		}
+		off++
+	}
+	return true
+}
+
+//literal returns the text of a literal which is not in the source at its position,
+//e.g. the value of an expression folded by the optimizer. It returns false if the
+//literal is in the source.
+func (p *printer) literal(e ast.Expression) (string, bool) {
+	var tok token.Token
+	var text string
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		tok, text = e.Token, strconv.FormatInt(e.Value, 10)
+	case *ast.UIntegerLiteral:
+		tok, text = e.Token, strconv.FormatUint(e.Value, 10)+"u"
+	case *ast.FloatLiteral:
+		tok, text = e.Token, strconv.FormatFloat(e.Value, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+	case *ast.StringLiteral:
+		tok, text = e.Token, quote(e.Value)
+	case *ast.Boolean:
+		tok, text = e.Token, strconv.FormatBool(e.Value)
+	case *ast.NilLiteral:
+		tok, text = e.Token, "nil"
+	}
+	if tok.Pos.Line == 0 { //generated by the parser
+		return "", false
+	}
+
+	if off := tok.Pos.Offset; off < len(p.src) {
+		src := lexer.New("", string(p.src[off:p.tokenEnd(tok)])).NextToken()
+		if src.Type == tok.Type && src.Literal == tok.Literal {
+			return "", false
+		}
+	}
+	return text, true
+}
+
+//quote returns the string literal of 's', in double quotes.
+func quote(s string) string {
+	var out bytes.Buffer
+	out.WriteByte('"')
+	for _, ch := range s {
+		switch ch {
+		case '"', '\\':
+			out.WriteRune('\\')
+			out.WriteRune(ch)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\f':
+			out.WriteString(`\f`)
+		default:
+			out.WriteRune(ch)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
+//hasText reports whether the token is in the source, not generated by the parser.
+func (p *printer) hasText(tok token.Token) bool {
+	return tok.Pos.Line != 0 && p.hasPrefix(tok.Pos.Offset, tok.Literal)
+}
+
+func (p *printer) hasPos(n interface{}) bool {
+	start, _ := p.span(n)
+	return start >= 0
+}
+
+//nextCharIs reports whether the first character after the token is 'ch'.
+func (p *printer) nextCharIs(tok token.Token, ch rune) bool {
+	i := p.skipSpace(p.tokenEnd(tok))
+	return i < len(p.src) && p.src[i] == ch
+}
+
+//parenthesized reports whether the expression is enclosed by a pair of parentheses in the source.
+func (p *printer) parenthesized(e ast.Expression) bool {
+	start, end := p.span(e)
+	if start < 0 {
+		return false
+	}
+	i := start - 1
+	for i >= 0 && unicode.IsSpace(p.src[i]) {
+		i--
+	}
+	j := p.skipSpace(end)
+	return i >= 0 && p.src[i] == '(' && j < len(p.src) && p.src[j] == ')'
+}
+
+//verbatim prints the source of a node as it is, only its lines are re-indented.
+func (p *printer) verbatim(n interface{}) {
+	if start, end := p.span(n); start >= 0 {
+		p.verbatimRange(start, end)
+	}
+}
+
+func (p *printer) verbatimRange(start, end int) {
+
+	//the indentation of the node's first line is replaced by the current one.
+	lineStart := p.lines[p.line(start)-1]
+	base := 0
+	for lineStart+base < start && (p.src[lineStart+base] == ' ' || p.src[lineStart+base] == '\t') {
+		base++
+	}
+	newlines := make(map[int]bool)
+	p.scan(start, end, func(i int) {
+		if p.src[i] == '\n' {
+			newlines[i] = true
+		}
+	})
+
+	var buf bytes.Buffer
+	for i := start; i < end; i++ {
+		ch := p.src[i]
+		if ch == '\r' {
+			continue
+		}
+		buf.WriteRune(ch)
+		if newlines[i] {
+			j := 0
+			for j < base && i+1 < end && (p.src[i+1] == ' ' || p.src[i+1] == '\t') {
+				i++
+				j++
+			}
+			if i+1 < end && p.src[i+1] != '\r' && p.src[i+1] != '\n' {
+				buf.WriteString(strings.Repeat(indentStr, p.indent))
+			}
+		}
+	}
+	p.write(buf.String())
+
+	for _, c := range p.comments {
+		if c.start >= start && c.start < end {
+			c.done = true
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,110 @@
+package formatter
+
+import (
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/parser"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"lit x = 1+2*3   # trailing\n", "lit x = 1 + 2 * 3  # trailing\n"},
+		{"lit p = (1+2)*3\n", "lit p = (1 + 2) * 3\n"},
+		{"lit arr = [1,2,   3]\nlit h = {\"a\":1}\n", "lit arr = [1, 2, 3]\nlit h = {\"a\": 1}\n"},
+		{
+			"fn add(a, b=2) {\n  if a > b { return a }\n     else {\n return b\n  }\n}\n",
+			"fn add(a, b = 2) {\n    if a > b {\n        return a\n    } else {\n        return b\n    }\n}\n",
+		},
+		{
+			"# leading\n\n\n\nlit x = 1\n/* block\n   comment */\nlit y = 'x={x}'\n",
+			"# leading\n\nlit x = 1\n/* block\n   comment */\nlit y = 'x={x}'\n",
+		},
+		{
+			"class A : B {\n  lit x\n  fn f() { return x }\n  property P { get; set; }\n}\n",
+			"class A : B {\n    lit x\n\n    fn f() {\n        return x\n    }\n\n    property P { get; set; }\n}\n",
+		},
+		{
+			"println(\n1,\n  2, # two\n3)\n",
+			"println(\n    1,\n    2,  # two\n    3\n)\n",
+		},
+		{
+			"const (\n A,\n  B = 5, # five\n C\n)\n",
+			"const (\n    A,\n    B = 5,  # five\n    C\n)\n",
+		},
+		{"lit raw = ``a\n   b``\n", "lit raw = ``a\n   b``\n"},
+	}
+
+	for _, tt := range tests {
+		out, err := Source("test.aero", []byte(tt.input))
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+		}
+		if string(out) != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, string(out))
+		}
+
+		//formatting must be idempotent
+		again, err := Source("test.aero", out)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", out, err)
+		}
+		if string(again) != string(out) {
+			t.Errorf("Source is not idempotent.\nfirst=%q\nsecond=%q", string(out), string(again))
+		}
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	if _, err := Source("test.aero", []byte("lit x = )\n")); err == nil {
+		t.Errorf("expected a syntax error, got nil")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"lit x = 1+2*3 # comment\n", "lit x = 7\n"},
+		{"const A = \"a\\\"b\" + \"\\tc\"\nprintln(A + \"\")\n", "const A = \"a\\\"b\\tc\"\nprintln(\"a\\\"b\\tc\")\n"},
+		{"lit f = 1.5 * 2\nlit u = 1u + 2u\nlit b = 1 < 2\n", "lit f = 3.0\nlit u = 3u\nlit b = true\n"},
+		{"if 1 > 2 { println(\"a\") } else { println('b{1}') }\n", "println('b{1}')\n"},
+		{"lit s = \"kept\"\nlit n = 10\n", "lit s = \"kept\"\nlit n = 10\n"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New("test.aero", tt.input), ".")
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("%q: parser errors: %v", tt.input, p.Errors())
+		}
+		eval.OptimizeProgram(program)
+		out := string(Print([]byte(tt.input), program))
+		if out != tt.expected {
+			t.Errorf("Print(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, out)
+		}
+
+		//the output is a program again
+		p = parser.New(lexer.New("test.aero", out), ".")
+		p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Errorf("Print(%q) cannot be parsed: %v", tt.input, p.Errors())
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := []byte("lit x = 1\nlit y=2\nlit z = 3\n")
+	b := []byte("lit x = 1\nlit y = 2\nlit z = 3\n")
+	expected := "--- t.aero\n+++ t.aero (formatted)\n@@ -1,3 +1,3 @@\n lit x = 1\n-lit y=2\n+lit y = 2\n lit z = 3\n"
+	if got := Diff("t.aero", a, b); got != expected {
+		t.Errorf("Diff wrong.\nexpected=%q\ngot=%q", expected, got)
+	}
+	if got := Diff("t.aero", a, a); got != "" {
+		t.Errorf("Diff of the same sources should be empty, got=%q", got)
+	}
+}
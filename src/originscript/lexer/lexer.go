@@ -253,6 +253,7 @@ func (l *Lexer) NextToken() token.Token {
 					prevToken.Type == token.RPAREN || // (a+c) / b
 					prevToken.Type == token.RBRACKET || // a[3] / b
 					prevToken.Type == token.IDENT || // a / b
+					prevToken.Type == token.STRING || // "a" / b
 					prevToken.Type == token.INT || // 3 / b
 					prevToken.Type == token.FLOAT || // 3.5 / b
 					prevToken.Type == token.FUNCTION { // e.g. fn /() - operator overloading
@@ -263,8 +264,13 @@ func (l *Lexer) NextToken() token.Token {
 						tok = newToken(token.SLASH, l.ch)
 					}
 				} else { //regexp
-					tok.Literal = l.readRegExLiteral()
-					tok.Type = token.REGEX
+					if s, err := l.readRegExLiteral(); err == nil {
+						tok.Literal = s
+						tok.Type = token.REGEX
+						tok.Pos = pos
+						return tok
+					}
+					tok = newToken(token.ILLEGAL, l.ch)
 					tok.Pos = pos
 					return tok
 				}
@@ -692,7 +698,7 @@ func (l *Lexer) readIdentifier() token.Token {
 	return tok
 }
 
-func (l *Lexer) readRegExLiteral() (literal string) {
+func (l *Lexer) readRegExLiteral() (string, error) {
 	position := l.position
 	/* read until closing slash */
 	for {
@@ -702,10 +708,12 @@ func (l *Lexer) readRegExLiteral() (literal string) {
 			l.readNext()
 		} else if l.ch == '/' {
 			// This is the closing
-			literal = string(l.input[position+1 : l.position])
+			literal := string(l.input[position+1 : l.position])
 			l.readNext() //skip the '/'
-
-			return
+			return literal, nil
+		}
+		if l.ch == 0 {
+			return "", errors.New("unexpected EOF")
 		}
 	}
 }
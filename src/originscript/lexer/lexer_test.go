@@ -7,14 +7,14 @@ import (
 )
 
 func TestNextToken(t *testing.T) {
-	input := `let five = 5;
-	let ten_dummy = 10;
+	input := `lit five = 5;
+	lit ten_dummy = 10;
 	
-	let add = fn(x, y) {
+	lit add = fn(x, y) {
 		x + y;
 	};
 	
-	let result = add(five, ten);
+	lit result = add(five, ten);
 	5 < 10 > 5;
 	
 	if (5 < 10) {
@@ -29,24 +29,24 @@ func TestNextToken(t *testing.T) {
 	"foobar";
 	"foo bar";
 	[];
-	function.call
+	object.call
 	{ "foo" : "bar" }
 	[1:3]
 	5 % 4
-	import tests
+	require tests
 	x and y
 	x or y
 	struct
 	do
-	if (~\d+(\w)+.*$~.exec("abc def") == 0) {  # this is just a comment
+	if (/\d+(\w)+.*$/.exec("abc def") == 0) {  // this is just a comment
 	    return "found"
 	}
-	# this is another command
-	let a234 = ~[ab|cd].*\~efg$~
-	let ww = 1.523 + 2    # test for floating point number
+	// this is another comment
+	lit a234 = /[ab|cd].*\/efg$/
+	lit ww = 1.523 + 2    // test for floating point number
 	for item in arr
-	grep { $_ > 5 }
-	if (abc =~ ~\d+~)
+	gp { $_ > 5 }
+	if (abc =~ /\d+/)
 	y ? a : b
 	52.9..80.7
 	52..80
@@ -55,17 +55,17 @@ func TestNextToken(t *testing.T) {
 		expectedType    token.TokenType
 		expectedLiteral string
 	}{
-		{token.LET, "let"},
+		{token.LET, "lit"},
 		{token.IDENT, "five"},
 		{token.ASSIGN, "="},
 		{token.INT, "5"},
 		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
+		{token.LET, "lit"},
 		{token.IDENT, "ten_dummy"},
 		{token.ASSIGN, "="},
 		{token.INT, "10"},
 		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
+		{token.LET, "lit"},
 		{token.IDENT, "add"},
 		{token.ASSIGN, "="},
 		{token.FUNCTION, "fn"},
@@ -81,7 +81,7 @@ func TestNextToken(t *testing.T) {
 		{token.SEMICOLON, ";"},
 		{token.RBRACE, "}"},
 		{token.SEMICOLON, ";"},
-		{token.LET, "let"},
+		{token.LET, "lit"},
 		{token.IDENT, "result"},
 		{token.ASSIGN, "="},
 		{token.IDENT, "add"},
@@ -137,12 +137,12 @@ func TestNextToken(t *testing.T) {
 		{token.LBRACKET, "["},
 		{token.RBRACKET, "]"},
 		{token.SEMICOLON, ";"},
-		{token.IDENT, "function"},
+		{token.IDENT, "object"},
 		{token.DOT, "."},
 		{token.IDENT, "call"},
 		{token.LBRACE, "{"},
 		{token.STRING, "foo"},
-		{token.FATARROW, "=>"},
+		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
 		{token.LBRACKET, "["},
@@ -153,7 +153,7 @@ func TestNextToken(t *testing.T) {
 		{token.INT, "5"},
 		{token.MOD, "%"},
 		{token.INT, "4"},
-		{token.IMPORT, "import"},
+		{token.IMPORT, "require"},
 		{token.IDENT, "tests"},
 		{token.IDENT, "x"},
 		{token.AND, "and"},
@@ -164,7 +164,7 @@ func TestNextToken(t *testing.T) {
 		{token.STRUCT, "struct"},
 		{token.DO, "do"},
 
-		//if (~\d+(\w)+.*$~.exec("abc def") == 0) {
+		//if (/\d+(\w)+.*$/.exec("abc def") == 0) {
 		//    return "found"
 		//}
 		{token.IF, "if"},
@@ -183,14 +183,14 @@ func TestNextToken(t *testing.T) {
 		{token.STRING, "found"},
 		{token.RBRACE, "}"},
 
-		//let a234 = ~[ab|cd].*\~efg$~
-		{token.LET, "let"},
+		//lit a234 = /[ab|cd].*\/efg$/
+		{token.LET, "lit"},
 		{token.IDENT, "a234"},
 		{token.ASSIGN, "="},
-		{token.REGEX, `[ab|cd].*\~efg$`},
+		{token.REGEX, `[ab|cd].*\/efg$`},
 
-		//let ww = 1.523 + 2
-		{token.LET, "let"},
+		//lit ww = 1.523 + 2
+		{token.LET, "lit"},
 		{token.IDENT, "ww"},
 		{token.ASSIGN, "="},
 		{token.FLOAT, "1.523"},
@@ -203,15 +203,15 @@ func TestNextToken(t *testing.T) {
 		{token.IN, "in"},
 		{token.IDENT, "arr"},
 
-		//grep { $_ > 5 }
-		{token.GREP, "grep"},
+		//gp { $_ > 5 }
+		{token.GREP, "gp"},
 		{token.LBRACE, "{"},
 		{token.IDENT, "$_"},
 		{token.GT, ">"},
 		{token.INT, "5"},
 		{token.RBRACE, "}"},
 
-		//input := `if (abc =~ ~\d+~)`
+		//input := `if (abc =~ /\d+/)`
 		{token.IF, "if"},
 		{token.LPAREN, "("},
 		{token.IDENT, "abc"},
@@ -235,10 +235,10 @@ func TestNextToken(t *testing.T) {
 		{token.INT, "52"},
 		{token.DOTDOT, ".."},
 		{token.INT, "80"},
-		{token.EOF, ""},
+		{token.EOF, "<EOF>"},
 	}
 
-	l := New(input)
+	l := New("", input)
 
 	for i, tt := range tests {
 		tok := l.NextToken()
@@ -253,3 +253,29 @@ func TestNextToken(t *testing.T) {
 	}
 
 }
+
+func TestRegExLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []token.Token
+	}{
+		{`a =~ /ab\/c/`, []token.Token{{Type: token.IDENT, Literal: "a"}, {Type: token.MATCH, Literal: "=~"}, {Type: token.REGEX, Literal: `ab\/c`}}},
+		{`"ab" / "c"`, []token.Token{{Type: token.STRING, Literal: "ab"}, {Type: token.SLASH, Literal: "/"}, {Type: token.STRING, Literal: "c"}}},
+		//not terminated, it must not read past the end of the input
+		{`a =~ /abc`, []token.Token{{Type: token.IDENT, Literal: "a"}, {Type: token.MATCH, Literal: "=~"}, {Type: token.ILLEGAL}}},
+		{`a =~ /abc\`, []token.Token{{Type: token.IDENT, Literal: "a"}, {Type: token.MATCH, Literal: "=~"}, {Type: token.ILLEGAL}}},
+	}
+
+	for _, tt := range tests {
+		l := New("", tt.input)
+		for i, expected := range append(tt.expected, token.Token{Type: token.EOF, Literal: "<EOF>"}) {
+			tok := l.NextToken()
+			if tok.Type != expected.Type {
+				t.Fatalf("%q: tokens[%d] - tokentype wrong. expected=%q, got %q", tt.input, i, expected.Type, tok.Type)
+			}
+			if expected.Type != token.ILLEGAL && tok.Literal != expected.Literal {
+				t.Fatalf("%q: tokens[%d] - literal wrong. expected=%q, got %q", tt.input, i, expected.Literal, tok.Literal)
+			}
+		}
+	}
+}
@@ -0,0 +1,1102 @@
+package linter
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"originscript/ast"
+	"originscript/eval"
+	"originscript/token"
+	"sort"
+	"strings"
+)
+
+//The checks of `origion check`, each diagnostic belongs to one of them.
+const (
+	NOMETHOD = "nomethod" //a method the type doesn't have
+	OPERANDS = "operands" //an operator on types it doesn't support
+	ARGCOUNT = "argcount" //a call with too many or not enough arguments
+)
+
+//the types of the results of the builtin functions
+var builtinResults = map[string]string{
+	"len": "int", "str": "string", "int": "int", "uint": "uint", "float": "float",
+	"chr": "string", "ord": "int", "range": "array", "sprintf": "string", "type": "string",
+}
+
+//the types of the results of the methods of the builtin types
+var methodResults = map[string]string{
+	"string.len": "int", "string.count": "int", "string.find": "int", "string.index": "int",
+	"string.lastIndex": "int", "string.rindex": "int", "string.rfind": "int", "string.compare": "int",
+	"string.lower": "string", "string.upper": "string", "string.title": "string", "string.trim": "string",
+	"string.strip": "string", "string.trimLeft": "string", "string.lstrip": "string", "string.trimRight": "string",
+	"string.rstrip": "string", "string.trimPrefix": "string", "string.trimSuffix": "string",
+	"string.replace": "string", "string.repeat": "string", "string.substr": "string",
+	"string.chomp": "string", "string.reverse": "string",
+	"string.split": "array", "string.fields": "array",
+	"string.contains": "bool", "string.containsAny": "bool", "string.hasPrefix": "bool", "string.startswith": "bool",
+	"string.hasSuffix": "bool", "string.endswith": "bool", "string.isEmpty": "bool",
+	"array.len": "int", "array.index": "int", "array.filter": "array", "array.grep": "array",
+	"array.map": "array", "array.includes": "bool", "array.empty": "bool",
+	"tuple.len":  "int",
+	"int.isEven": "bool", "int.isOdd": "bool", "uint.isEven": "bool", "uint.isOdd": "bool",
+}
+
+//the types of the results of the standard library's modules. They are used only
+//if the module is not a hash of Go functions(see eval.RegisterFunctions).
+var stdlibResults = map[string]string{
+	"math.sqrt": "float", "math.pow": "float", "math.floor": "float", "math.ceil": "float", "math.abs": "float",
+	"math.sin": "float", "math.cos": "float", "math.tan": "float", "math.exp": "float", "math.isNaN": "bool",
+	"strings.upper": "string", "strings.lower": "string", "strings.trim": "string", "strings.split": "array",
+	"strings.contains": "bool", "strings.hasPrefix": "bool", "strings.len": "int", "strings.index": "int",
+	"fmt.sprintf": "string", "fmt.sprint": "string", "fmt.sprintln": "string",
+	"filepath.base": "string", "filepath.dir": "string", "filepath.ext": "string", "filepath.join": "string",
+	"os.getenv": "string", "os.getwd": "string", "json.marshal": "string",
+}
+
+//the builtin types whose methods are checked: the methods of the hashes could be their keys
+var methodTypes = map[string]bool{"int": true, "uint": true, "float": true, "string": true, "bool": true, "array": true, "tuple": true}
+
+//RunCheck checks the files and the directories of 'paths', and writes the diagnostics like Run.
+func RunCheck(paths []string, jsonOutput bool, out io.Writer) int {
+	return run("check", CheckFile, paths, jsonOutput, out)
+}
+
+//CheckFile parses and checks a single file, the syntax errors are reported as diagnostics.
+func CheckFile(filename string) []Diagnostic {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return []Diagnostic{{Filename: filename, Check: SYNTAX, Message: err.Error()}}
+	}
+	return CheckSource(filename, string(f))
+}
+
+//CheckSource parses and checks the source code of a file.
+func CheckSource(filename string, src string) []Diagnostic {
+	program, diags := parse(filename, src)
+	if program == nil {
+		return diags
+	}
+	return Check(program)
+}
+
+//Check infers the types of a program and of the modules it requires, and reports
+//the mistakes they prove. The diagnostics are sorted by their position.
+func Check(program *ast.Program) []Diagnostic {
+	c := newChecker(program, make(map[*ast.Program]*checker))
+	for _, check := range c.checks {
+		check()
+	}
+	sortDiagnostics(c.diags)
+	return c.diags
+}
+
+//typ is an inferred type, a nil *typ is unknown.
+type typ struct {
+	name   string     //as in the type annotations: "int", "string", "fn"..., or the class name
+	class  *classInfo //the instances of a class of the program
+	fn     *funcInfo  //a function of the program
+	module *checker   //a 'require'd module
+}
+
+//join returns the type of a variable which could hold a value of type 'a' or 'b'.
+func join(a, b *typ) *typ {
+	if a == nil || b == nil || a.name != b.name || a.class != b.class || a.module != b.module {
+		return nil
+	}
+	if a.fn != b.fn {
+		return &typ{name: "fn"}
+	}
+	return a
+}
+
+const (
+	notInferred = iota
+	inferring
+	inferred
+)
+
+//variable is a declared name, its type is inferred from all the values
+//assigned to it, it's unknown if they don't agree.
+type variable struct {
+	fixed  *typ          //a function, or 'this'
+	annot  *ast.TypeExpr //the annotated type, e.g. 'lit x: int'
+	values []value
+	opaque bool //the type can't be inferred, e.g. a loop variable
+
+	t     *typ
+	state int
+}
+
+//value is an expression assigned to a variable, and the scope of the assignment.
+type value struct {
+	expr  ast.Expression
+	scope *tscope
+}
+
+//tscope follows the scopes of the linter.
+type tscope struct {
+	parent  *tscope
+	vars    map[string]*variable
+	lenient bool //the names may come from somewhere the checker doesn't know(e.g. the parent class)
+}
+
+func newTScope(parent *tscope) *tscope {
+	s := &tscope{parent: parent, vars: make(map[string]*variable)}
+	if parent != nil {
+		s.lenient = parent.lenient
+	}
+	return s
+}
+
+//declare returns the variable 'name' of the scope, it's created if needed.
+func (s *tscope) declare(name string) *variable {
+	v, ok := s.vars[name]
+	if !ok {
+		v = &variable{}
+		s.vars[name] = v
+	}
+	return v
+}
+
+//lookup finds the variable 'name'. If it's not found, 'lenient' reports
+//whether it could be declared somewhere the checker doesn't know.
+func (s *tscope) lookup(name string) (v *variable, lenient bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, false
+		}
+		if s.lenient {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+type funcInfo struct {
+	lit     *ast.FunctionLiteral
+	returns []returnSite
+	scope   *tscope //the scope of the body
+
+	ret   *typ
+	state int
+}
+
+//returnSite is a 'return' of a function, and the scope of its values.
+type returnSite struct {
+	stmt  *ast.ReturnStatement
+	scope *tscope
+}
+
+type classInfo struct {
+	name     string
+	parent   string
+	declared bool //false for the categories of a class the checker doesn't know
+	methods  map[string]*funcInfo
+	members  map[string]bool //the fields and the properties
+}
+
+type checker struct {
+	global     *tscope
+	classes    map[string]*classInfo
+	funcs      map[*ast.FunctionLiteral]*funcInfo
+	modules    map[string]*checker //the 'require'd modules by name
+	exports    map[string]bool     //the names marked with 'export', nil if there's none
+	extensions map[string]bool     //the methods added to the builtin types, e.g. 'string$shout'
+	fn         *funcInfo           //the function being walked
+	visited    map[ast.Node]bool
+	checks     []func() //run when all the declarations are known
+	diags      []Diagnostic
+}
+
+//newChecker walks a program, the modules already walked are in 'loaded'.
+func newChecker(program *ast.Program, loaded map[*ast.Program]*checker) *checker {
+	c := &checker{
+		global:     newTScope(nil),
+		classes:    make(map[string]*classInfo),
+		funcs:      make(map[*ast.FunctionLiteral]*funcInfo),
+		modules:    make(map[string]*checker),
+		extensions: make(map[string]bool),
+		visited:    make(map[ast.Node]bool),
+		exports:    program.ExportedNames(),
+	}
+	loaded[program] = c
+	for name, imp := range program.Imports {
+		if imp.Program == nil {
+			continue
+		}
+		m, ok := loaded[imp.Program]
+		if !ok {
+			m = newChecker(imp.Program, loaded)
+		}
+		if len(imp.Names) == 0 {
+			c.modules[name] = m
+		}
+		for _, name := range imp.Names { //'require util (parse, format)'
+			v := c.global.declare(name.Value)
+			if mv, ok := m.global.vars[name.Value]; ok {
+				v.fixed = m.varType(mv)
+			}
+			if v.fixed == nil {
+				v.opaque = true
+			} else if v.fixed.fn != nil {
+				m.returnType(v.fixed.fn) //inferred in the module's scope
+			}
+		}
+	}
+	c.stmts(program.Statements, c.global)
+	return c
+}
+
+func (c *checker) report(pos token.Position, check string, format string, args ...interface{}) {
+	c.diags = append(c.diags, Diagnostic{
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) stmts(list []ast.Statement, s *tscope) {
+	for _, stmt := range list {
+		c.node(stmt, s)
+	}
+}
+
+func (c *checker) node(n ast.Node, s *tscope) {
+	if isNil(n) || c.visited[n] {
+		return
+	}
+	c.visited[n] = true
+
+	switch n := n.(type) {
+	case *ast.LetStatement:
+		for _, v := range n.Values {
+			c.node(v, s)
+		}
+		for i, name := range n.Names {
+			if name.Token.Type == token.UNDERSCORE {
+				continue
+			}
+			v := s.declare(name.Value)
+			switch {
+			case n.Types[name.Value] != nil:
+				v.annot = n.Types[name.Value]
+			case n.InClass || n.DestructingFlag || len(n.Values) != len(n.Names):
+				v.opaque = true
+			default:
+				v.values = append(v.values, value{n.Values[i], s})
+			}
+		}
+	case *ast.ConstStatement:
+		for _, v := range n.Value {
+			c.node(v, s)
+		}
+		for i, name := range n.Name {
+			v := s.declare(name.Value)
+			if len(n.Value) != len(n.Name) {
+				v.opaque = true
+			} else {
+				v.values = append(v.values, value{n.Value[i], s})
+			}
+		}
+	case *ast.AssignExpression:
+		c.node(n.Value, s)
+		switch name := n.Name.(type) {
+		case *ast.Identifier:
+			v, lenient := s.lookup(name.Value)
+			if v == nil && lenient {
+				return
+			}
+			if v == nil { //'x = 1' declares 'x' in the outermost scope if it's unknown
+				v = c.global.declare(name.Value)
+			}
+			if n.Token.Literal == "=" {
+				v.values = append(v.values, value{n.Value, s})
+			} else { //'+=', '-='...
+				v.opaque = true
+			}
+		case *ast.MethodCallExpression: //'obj.field = value' could add the field
+			c.node(name.Object, s)
+		default:
+			c.node(n.Name, s)
+		}
+
+	case *ast.FunctionStatement:
+		f := c.function(n.FunctionLiteral, s)
+		v := s.declare(n.Name.Value)
+		if v.fixed != nil { //declared twice
+			v.opaque = true
+		}
+		v.fixed = &typ{name: "fn", fn: f}
+		if strings.Contains(n.Name.Value, "$") {
+			c.extensions[n.Name.Value] = true
+		}
+	case *ast.FunctionLiteral:
+		c.function(n, s)
+	case *ast.ReturnStatement:
+		if c.fn != nil {
+			c.fn.returns = append(c.fn.returns, returnSite{n, s})
+		}
+		for _, v := range n.ReturnValues {
+			c.node(v, s)
+		}
+		c.node(n.ReturnValue, s)
+
+	case *ast.ClassStatement:
+		cls := c.class(n.Name.Value)
+		if n.CategoryName == nil {
+			s.declare(n.Name.Value).opaque = true
+			cls.declared, cls.parent = true, n.ClassLiteral.Parent
+		}
+		c.classBody(cls, n.ClassLiteral, s, n.CategoryName != nil)
+	case *ast.ClassLiteral:
+		cls := &classInfo{name: n.Name, parent: n.Parent, declared: true,
+			methods: make(map[string]*funcInfo), members: make(map[string]bool)}
+		c.classBody(cls, n, s, false)
+	case *ast.PropertyDeclStmt:
+		c.node(n.Default, s)
+		ps := newTScope(s)
+		ps.lenient = true //the indexes and 'value'
+		if n.Getter != nil {
+			c.node(n.Getter.Body, ps)
+		}
+		if n.Setter != nil {
+			c.node(n.Setter.Body, ps)
+		}
+	case *ast.EnumStatement:
+		s.declare(n.Name.Value).opaque = true
+	case *ast.InterfaceStatement:
+		s.declare(n.Name.Value).opaque = true
+	case *ast.ServiceStatement:
+		s.declare(n.Name.Value).opaque = true
+		ss := newTScope(s)
+		ss.lenient = true
+		c.node(n.Block, ss)
+
+	case *ast.MethodCallExpression:
+		c.node(n.Object, s)
+		c.member(n.Call, s)
+		c.checks = append(c.checks, func() { c.checkMethodCall(n, s, 0) })
+	case *ast.CallExpression:
+		c.node(n.Function, s)
+		for _, arg := range n.Arguments {
+			c.node(arg, s)
+		}
+		c.checks = append(c.checks, func() { c.checkCall(n, s, 0) })
+	case *ast.Pipe: //the left value is the first argument of the right call
+		c.node(n.Left, s)
+		switch r := n.Right.(type) {
+		case *ast.CallExpression:
+			c.visited[r] = true
+			c.node(r.Function, s)
+			for _, arg := range r.Arguments {
+				c.node(arg, s)
+			}
+			c.checks = append(c.checks, func() { c.checkCall(r, s, 1) })
+		case *ast.MethodCallExpression:
+			c.visited[r] = true
+			c.node(r.Object, s)
+			c.member(r.Call, s)
+			c.checks = append(c.checks, func() { c.checkMethodCall(r, s, 1) })
+		default:
+			c.node(n.Right, s)
+		}
+	case *ast.NewExpression:
+		for _, arg := range n.Arguments {
+			c.node(arg, s)
+		}
+		c.checks = append(c.checks, func() { c.checkNew(n) })
+	case *ast.InfixExpression:
+		c.node(n.Left, s)
+		c.node(n.Right, s)
+		c.checks = append(c.checks, func() { c.checkInfix(n, s) })
+
+	case *ast.ForLoop:
+		ls := newTScope(s)
+		c.node(n.Init, ls)
+		c.node(n.Cond, ls)
+		c.node(n.Update, ls)
+		c.node(n.Block, ls)
+	case *ast.ForEachArrayLoop:
+		c.node(n.Value, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.Block)
+	case *ast.ForEachMapLoop:
+		c.node(n.X, s)
+		c.loop(s, []string{n.Key, n.Value}, n.Cond, n.Block)
+	case *ast.ForEachDotRange:
+		c.node(n.StartIdx, s)
+		c.node(n.EndIdx, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.Block)
+	case *ast.WhileLoop:
+		c.loop(s, nil, n.Condition, n.Block)
+	case *ast.DoLoop:
+		c.loop(s, nil, n.Block)
+	case *ast.ForEverLoop:
+		c.loop(s, nil, n.Block)
+	case *ast.ListComprehension:
+		c.node(n.Value, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.Expr)
+	case *ast.ListRangeComprehension:
+		c.node(n.StartIdx, s)
+		c.node(n.EndIdx, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.Expr)
+	case *ast.ListMapComprehension:
+		c.node(n.X, s)
+		c.loop(s, []string{n.Key, n.Value}, n.Cond, n.Expr)
+	case *ast.HashComprehension:
+		c.node(n.Value, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.KeyExpr, n.ValExpr)
+	case *ast.HashRangeComprehension:
+		c.node(n.StartIdx, s)
+		c.node(n.EndIdx, s)
+		c.loop(s, []string{n.Var}, n.Cond, n.KeyExpr, n.ValExpr)
+	case *ast.HashMapComprehension:
+		c.node(n.X, s)
+		c.loop(s, []string{n.Key, n.Value}, n.Cond, n.KeyExpr, n.ValExpr)
+
+	case *ast.TryStmt:
+		c.node(n.Try, s)
+		for _, cc := range n.Catches {
+			c.loop(s, []string{cc.Var}, cc.Block)
+		}
+		c.node(n.Finally, s)
+	case *ast.MatchExpr:
+		c.node(n.Expr, s)
+		for _, arm := range n.Arms {
+			as := newTScope(s)
+			for _, p := range arm.Patterns {
+				c.pattern(p, s, as)
+			}
+			c.node(arm.Guard, as)
+			c.node(arm.Block, as)
+		}
+	case *ast.UsingStmt:
+		us := newTScope(s)
+		if n.Expr != nil {
+			c.node(n.Expr.Value, s)
+			if ident, ok := n.Expr.Name.(*ast.Identifier); ok {
+				us.declare(ident.Value).opaque = true
+			}
+		}
+		c.node(n.Block, us)
+	case *ast.IfMacroStatement: //only the branch chosen by the parser
+		if n.Condition {
+			c.node(n.Consequence, s)
+		} else {
+			c.node(n.Alternative, s)
+		}
+	case *ast.QueryExpr:
+		qs := newTScope(s)
+		qs.lenient = true
+		c.node(n.From, qs)
+		c.node(n.QueryBody, qs)
+
+	default:
+		for _, child := range children(n) {
+			c.node(child, s)
+		}
+	}
+}
+
+//function walks a function's body in its own scope.
+func (c *checker) function(fl *ast.FunctionLiteral, s *tscope) *funcInfo {
+	f := &funcInfo{lit: fl, scope: newTScope(s)}
+	c.funcs[fl] = f
+	for i, p := range fl.Parameters {
+		ident, ok := p.(*ast.Identifier)
+		if !ok {
+			continue
+		}
+		v := f.scope.declare(ident.Value)
+		switch {
+		case fl.Variadic && i == len(fl.Parameters)-1:
+			v.fixed = &typ{name: "array"}
+		case fl.ParamTypes[ident.Value] != nil:
+			v.annot = fl.ParamTypes[ident.Value]
+		default:
+			v.opaque = true
+		}
+	}
+	for _, v := range fl.Values { //default values
+		c.node(v, f.scope)
+	}
+
+	outer := c.fn
+	c.fn = f
+	c.node(fl.Body, f.scope)
+	c.fn = outer
+	return f
+}
+
+//class returns the class 'name' of the program, it's created if needed.
+func (c *checker) class(name string) *classInfo {
+	cls, ok := c.classes[name]
+	if !ok {
+		cls = &classInfo{name: name, methods: make(map[string]*funcInfo), members: make(map[string]bool)}
+		c.classes[name] = cls
+	}
+	return cls
+}
+
+//classBody walks the members of a class, or of a category of the class.
+func (c *checker) classBody(cls *classInfo, cl *ast.ClassLiteral, s *tscope, category bool) {
+	cs := newTScope(s)
+	//the members of the parent class, or of the class of a category
+	cs.lenient = category || (cl.Parent != "" && cl.Parent != "object")
+	cs.declare("this").fixed = &typ{name: cls.name, class: cls}
+	for name := range cl.Properties {
+		cls.members[name] = true
+		cs.declare(name).opaque = true
+		cs.declare("_" + name).opaque = true
+	}
+	for _, m := range cl.Members {
+		for _, name := range m.Names {
+			cls.members[name.Value] = true
+		}
+	}
+
+	if cl.Block != nil {
+		c.stmts(cl.Block.Statements, cs)
+	}
+	for name, m := range cl.Methods {
+		c.node(m, cs)
+		cls.methods[name] = c.funcs[m.FunctionLiteral]
+	}
+}
+
+//loop walks the parts of a loop in a new scope, where the loop's variables are declared.
+func (c *checker) loop(s *tscope, vars []string, parts ...ast.Node) {
+	ls := newTScope(s)
+	for _, v := range vars {
+		ls.declare(v).opaque = true
+	}
+	for _, part := range parts {
+		c.node(part, ls)
+	}
+}
+
+//pattern walks a pattern of a 'match' arm, its bindings are declared in the arm's scope 'as'.
+func (c *checker) pattern(p ast.Expression, s *tscope, as *tscope) {
+	switch p := p.(type) {
+	case *ast.IdentPattern:
+		if p.Var != "_" && !eval.IsPatternType(p.Var) {
+			as.declare(p.Var).opaque = true
+		}
+	case *ast.ValuePattern:
+		c.node(p.Value, s)
+	case *ast.TuplePattern:
+		for _, m := range p.Members {
+			c.pattern(m, s, as)
+		}
+	case *ast.ArrayPattern:
+		for _, m := range p.Members {
+			c.pattern(m, s, as)
+		}
+		if p.Var != "" {
+			as.declare(p.Var).opaque = true
+		}
+	case *ast.HashPattern:
+		for _, v := range p.Values {
+			c.pattern(v, s, as)
+		}
+	case *ast.TypePattern:
+		for _, m := range p.Patterns {
+			c.pattern(m, s, as)
+		}
+	}
+}
+
+//member walks the part after the '.' of a method call: the method's name is not a variable.
+func (c *checker) member(e ast.Expression, s *tscope) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+	case *ast.CallExpression:
+		for _, arg := range e.Arguments {
+			c.node(arg, s)
+		}
+	case *ast.IndexExpression:
+		c.member(e.Left, s)
+		c.node(e.Index, s)
+	default:
+		c.node(e, s)
+	}
+}
+
+//typeOf infers the type of an expression, nil if it's unknown.
+func (c *checker) typeOf(e ast.Expression, s *tscope) *typ {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return &typ{name: "int"}
+	case *ast.UIntegerLiteral:
+		return &typ{name: "uint"}
+	case *ast.FloatLiteral:
+		return &typ{name: "float"}
+	case *ast.StringLiteral, *ast.InterpolatedString:
+		return &typ{name: "string"}
+	case *ast.Boolean:
+		return &typ{name: "bool"}
+	case *ast.ArrayLiteral:
+		return &typ{name: "array"}
+	case *ast.TupleLiteral:
+		return &typ{name: "tuple"}
+	case *ast.HashLiteral:
+		return &typ{name: "hash"}
+	case *ast.NilLiteral:
+		return &typ{name: "nil"}
+	case *ast.FunctionLiteral:
+		return &typ{name: "fn", fn: c.funcs[e]}
+	case *ast.Identifier:
+		if v, _ := s.lookup(e.Value); v != nil {
+			return c.varType(v)
+		}
+		if m, ok := c.modules[e.Value]; ok {
+			return &typ{name: "module", module: m}
+		}
+	case *ast.PrefixExpression:
+		switch e.Operator {
+		case "!":
+			return &typ{name: "bool"}
+		case "-", "+":
+			if t := c.typeOf(e.Right, s); t != nil && t.class == nil && (t.name == "int" || t.name == "float") {
+				return t
+			}
+		}
+	case *ast.InfixExpression:
+		return c.infixType(e, s)
+	case *ast.CallExpression:
+		return c.callType(e, s)
+	case *ast.MethodCallExpression:
+		return c.methodCallType(e, s)
+	case *ast.NewExpression:
+		if ident, ok := e.Class.(*ast.Identifier); ok {
+			if cls, ok := c.classes[ident.Value]; ok && cls.declared {
+				return &typ{name: cls.name, class: cls}
+			}
+		}
+	}
+	return nil
+}
+
+//annotType returns the type of an annotation, the unions are unknown.
+func (c *checker) annotType(t *ast.TypeExpr) *typ {
+	if len(t.Union) > 0 {
+		return nil
+	}
+	switch t.Name {
+	case "any":
+		return nil
+	case "nil":
+		return &typ{name: "nil"}
+	case "fn", "function":
+		return &typ{name: "fn"}
+	}
+	if eval.IsPatternType(t.Name) {
+		return &typ{name: t.Name}
+	}
+	if cls, ok := c.classes[t.Name]; ok && cls.declared {
+		return &typ{name: cls.name, class: cls}
+	}
+	return nil
+}
+
+func (c *checker) varType(v *variable) *typ {
+	switch {
+	case v.opaque:
+		return nil
+	case v.annot != nil:
+		return c.annotType(v.annot)
+	case v.state == inferring: //e.g. 'x = x + 1'
+		return nil
+	case v.state == inferred:
+		return v.t
+	}
+
+	v.state = inferring
+	t, first := v.fixed, v.fixed == nil
+	for _, val := range v.values {
+		if vt := c.typeOf(val.expr, val.scope); first {
+			t, first = vt, false
+		} else {
+			t = join(t, vt)
+		}
+		if t == nil {
+			break
+		}
+	}
+	v.t, v.state = t, inferred
+	return t
+}
+
+//returnType infers the type of the results of a function: its annotation, or
+//the type of all its 'return', if the body ends with one.
+func (c *checker) returnType(f *funcInfo) *typ {
+	if f.lit.ReturnType != nil {
+		return c.annotType(f.lit.ReturnType)
+	}
+	switch f.state {
+	case inferring: //recursive
+		return nil
+	case inferred:
+		return f.ret
+	}
+
+	f.state = inferring
+	var t *typ
+	stmts := f.lit.Body.Statements
+	if n := len(stmts); n > 0 {
+		if _, ok := stmts[n-1].(*ast.ReturnStatement); ok {
+			for i, r := range f.returns {
+				var rt *typ
+				switch len(r.stmt.ReturnValues) {
+				case 0:
+					rt = &typ{name: "nil"}
+				case 1:
+					rt = c.typeOf(r.stmt.ReturnValues[0], r.scope)
+				default: //multiple values are returned as a tuple
+					rt = &typ{name: "tuple"}
+				}
+				if i == 0 {
+					t = rt
+				} else {
+					t = join(t, rt)
+				}
+			}
+		}
+	}
+	f.ret, f.state = t, inferred
+	return t
+}
+
+func (c *checker) callType(call *ast.CallExpression, s *tscope) *typ {
+	t := c.typeOf(call.Function, s)
+	if t != nil && t.fn != nil {
+		return c.returnType(t.fn)
+	}
+	if ident, ok := call.Function.(*ast.Identifier); ok {
+		if v, lenient := s.lookup(ident.Value); v == nil && !lenient {
+			if name, ok := builtinResults[ident.Value]; ok {
+				return &typ{name: name}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *checker) methodCallType(n *ast.MethodCallExpression, s *tscope) *typ {
+	name, isCall := memberName(n.Call)
+	if name == "" {
+		return nil
+	}
+	if obj, ok := stdlibModule(n); ok {
+		if _, ok := goFunctions(obj); !ok && isCall {
+			if result, ok := stdlibResults[n.Object.String()+"."+name]; ok {
+				return &typ{name: result}
+			}
+		}
+		return nil
+	}
+
+	t := c.typeOf(n.Object, s)
+	switch {
+	case t == nil:
+	case t.module != nil:
+		if v, ok := t.module.global.vars[name]; ok {
+			mt := t.module.varType(v)
+			if !isCall {
+				return mt
+			}
+			if mt != nil && mt.fn != nil {
+				return t.module.returnType(mt.fn)
+			}
+		}
+	case t.class != nil:
+		if f, _, _ := c.findMethod(t.class, name); f != nil && isCall {
+			return c.returnType(f)
+		}
+	case methodTypes[t.name]:
+		if !c.extensions[runtimeName(t.name)+"$"+name] {
+			if result, ok := methodResults[t.name+"."+name]; ok {
+				return &typ{name: result}
+			}
+		}
+	}
+	return nil
+}
+
+//the operators whose result's type is inferred from the types of the operands
+var inferredOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true,
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+func (c *checker) infixType(n *ast.InfixExpression, s *tscope) *typ {
+	if !inferredOperators[n.Operator] {
+		return nil
+	}
+	l, r := c.typeOf(n.Left, s), c.typeOf(n.Right, s)
+	if l == nil || r == nil || l.class != nil || r.class != nil {
+		return nil
+	}
+	name, err := eval.InfixResult(n, l.name, r.name)
+	if err != nil || name == "" {
+		return nil
+	}
+	if n.Operator == "/" && name != "float" { //'7/2' is a float, '6/2' is not
+		return nil
+	}
+	return &typ{name: name}
+}
+
+//findMethod finds the method 'name' of a class or of its parents. 'exists' is
+//true for the fields and the properties too, 'sure' is false if the checker
+//doesn't know all the parents.
+func (c *checker) findMethod(cls *classInfo, name string) (f *funcInfo, exists bool, sure bool) {
+	seen := make(map[*classInfo]bool)
+	for cls != nil && !seen[cls] {
+		seen[cls] = true
+		if !cls.declared {
+			return nil, false, false
+		}
+		if f, ok := cls.methods[name]; ok {
+			return f, true, true
+		}
+		if cls.members[name] {
+			return nil, true, true
+		}
+
+		switch cls.parent {
+		case "", "object":
+			_, ok := eval.BASE_CLASS.Methods[name]
+			return nil, ok, true
+		}
+		if builtin, ok := eval.BuiltinClasses[cls.parent]; ok { //e.g. 'Exception'
+			return nil, builtin.GetMethod(name) != nil || builtin.GetProperty(name) != nil, true
+		}
+		cls = c.classes[cls.parent]
+	}
+	return nil, false, false
+}
+
+//methodNames returns the names of the methods of a class and of its parents, for the suggestions.
+func (c *checker) methodNames(cls *classInfo) []string {
+	var names []string
+	seen := make(map[*classInfo]bool)
+	for ; cls != nil && !seen[cls]; cls = c.classes[cls.parent] {
+		seen[cls] = true
+		for name := range cls.methods {
+			names = append(names, name)
+		}
+	}
+	for name := range eval.BASE_CLASS.Methods {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (c *checker) checkCall(call *ast.CallExpression, s *tscope, extra int) {
+	if t := c.typeOf(call.Function, s); t != nil && t.fn != nil {
+		c.arity(call.Function.String(), t.fn, len(call.Arguments)+extra, call.Pos())
+	}
+}
+
+func (c *checker) checkNew(n *ast.NewExpression) {
+	ident, ok := n.Class.(*ast.Identifier)
+	if !ok {
+		return
+	}
+	if cls, ok := c.classes[ident.Value]; ok {
+		if f, _, _ := c.findMethod(cls, "init"); f != nil {
+			c.arity("new "+ident.Value, f, len(n.Arguments), n.Pos())
+		}
+	}
+}
+
+func (c *checker) checkMethodCall(n *ast.MethodCallExpression, s *tscope, extra int) {
+	name, isCall := memberName(n.Call)
+	if name == "" {
+		return
+	}
+	nargs := extra
+	if call, ok := n.Call.(*ast.CallExpression); ok {
+		nargs += len(call.Arguments)
+	}
+
+	if obj, ok := stdlibModule(n); ok { //e.g. 'bufio.NewReader(r)'
+		if funcs, ok := goFunctions(obj); ok && isCall && !contains(funcs, name) && !eval.HasMethod("hash", name) {
+			c.reportMethod(n.Call.Pos(), name, funcs, "undefined function '%s' of module '%s'", name, n.Object.String())
+		}
+		return
+	}
+
+	t := c.typeOf(n.Object, s)
+	switch {
+	case t == nil:
+	case t.module != nil:
+		v, ok := t.module.global.vars[name]
+		if !ok {
+			var names []string
+			for k := range t.module.global.vars {
+				if t.module.exported(k) {
+					names = append(names, k)
+				}
+			}
+			c.reportMethod(n.Call.Pos(), name, names, "module '%s' has no member '%s'", n.Object.String(), name)
+			return
+		}
+		if !t.module.exported(name) {
+			c.report(n.Call.Pos(), NOMETHOD, "'%s' is not exported by module '%s'", name, n.Object.String())
+			return
+		}
+		if mt := t.module.varType(v); isCall && mt != nil && mt.fn != nil {
+			c.arity(n.Object.String()+"."+name, mt.fn, nargs, n.Call.Pos())
+		}
+	case t.class != nil:
+		if !isCall {
+			return
+		}
+		f, exists, sure := c.findMethod(t.class, name)
+		if !sure {
+			return
+		}
+		if !exists {
+			c.reportMethod(n.Call.Pos(), name, c.methodNames(t.class), "undefined method '%s' for class '%s'", name, t.class.name)
+		} else if f != nil {
+			c.arity(name, f, nargs, n.Call.Pos())
+		}
+	case methodTypes[t.name]:
+		if !c.extensions[runtimeName(t.name)+"$"+name] && !eval.HasMethod(t.name, name) {
+			c.reportMethod(n.Call.Pos(), name, eval.TypeMethods(t.name), "undefined method '%s' for type %s", name, t.name)
+		}
+	}
+}
+
+//reportMethod reports the undefined method 'name', with the names of 'known' which look like it.
+func (c *checker) reportMethod(pos token.Position, name string, known []string, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if found := eval.TypoSuggestions(known, name); len(found) != 0 {
+		sort.Strings(found)
+		msg += ", did you mean: " + strings.Join(found, ", ") + "?"
+	}
+	c.report(pos, NOMETHOD, "%s", msg)
+}
+
+//exported reports if the files requiring the module can use its name 'name',
+//like eval.ImportedObject.Exported.
+func (c *checker) exported(name string) bool {
+	return (&eval.ImportedObject{Exports: c.exports}).Exported(name)
+}
+
+//arity reports a call of the function 'f' with 'got' arguments, if it needs more or less of them.
+func (c *checker) arity(name string, f *funcInfo, got int, pos token.Position) {
+	fl := f.lit
+	max := len(fl.Parameters)
+	min := 0
+	for i, p := range fl.Parameters {
+		if _, ok := fl.Values[p.String()]; ok || (fl.Variadic && i == max-1) {
+			break
+		}
+		min++
+	}
+
+	switch {
+	case got < min:
+		expected := fmt.Sprint(min)
+		if min != max {
+			expected = "at least " + expected
+		}
+		c.report(pos, ARGCOUNT, "not enough arguments in call to '%s': expected %s, got %d", name, expected, got)
+	case !fl.Variadic && got > max:
+		expected := fmt.Sprint(max)
+		if min != max {
+			expected = "at most " + expected
+		}
+		c.report(pos, ARGCOUNT, "too many arguments in call to '%s': expected %s, got %d", name, expected, got)
+	}
+}
+
+//checkInfix reports the arithmetic operators on types which don't support it,
+//e.g. a string minus a hash, or a string plus a hash(which concatenates the hash's text).
+func (c *checker) checkInfix(n *ast.InfixExpression, s *tscope) {
+	switch n.Operator {
+	case "+", "-", "*", "/", "%":
+	default:
+		return
+	}
+	l, r := c.typeOf(n.Left, s), c.typeOf(n.Right, s)
+	if l == nil || r == nil || l.class != nil || r.class != nil {
+		return
+	}
+	if _, err := eval.InfixResult(n, l.name, r.name); err != nil && err.Kind == eval.INFIXOP {
+		c.report(n.Pos(), OPERANDS, "unsupported operator '%s' for %s and %s", n.Operator, l.name, r.name)
+		return
+	}
+	if n.Operator == "+" && (l.name == "string" && r.name == "hash" || l.name == "hash" && r.name == "string") {
+		c.report(n.Pos(), OPERANDS, "'+' of %s and %s concatenates the hash's text", l.name, r.name)
+	}
+}
+
+//memberName returns the name after the '.' of a method call, and whether it's called.
+func memberName(e ast.Expression) (string, bool) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		return e.Value, false
+	case *ast.CallExpression:
+		if ident, ok := e.Function.(*ast.Identifier); ok {
+			return ident.Value, true
+		}
+	}
+	return "", false
+}
+
+//stdlibModule returns the module of the standard library of a method call, e.g. 'fmt' of 'fmt.println()'.
+//Like the evaluator, it's preferred over a variable of the same name.
+func stdlibModule(n *ast.MethodCallExpression) (eval.Object, bool) {
+	if ident, ok := n.Object.(*ast.Identifier); ok {
+		return eval.GetGlobalObj(ident.Value)
+	}
+	return nil, false
+}
+
+//goFunctions returns the names of the functions of a module which is a hash of Go
+//functions(see eval.RegisterFunctions), e.g. 'NewReader' of 'bufio'.
+func goFunctions(obj eval.Object) ([]string, bool) {
+	h, ok := obj.(*eval.Hash)
+	if !ok || len(h.Order) == 0 {
+		return nil, false
+	}
+	var names []string
+	for _, hk := range h.Order {
+		pair := h.Pairs[hk]
+		key, ok1 := pair.Key.(*eval.String)
+		_, ok2 := pair.Value.(*eval.GoFuncObject)
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		names = append(names, key.String)
+	}
+	return names, true
+}
+
+//runtimeName returns the name of a builtin type used by the extension methods, e.g. 'integer$next'.
+func runtimeName(t string) string {
+	switch t {
+	case "int":
+		return "integer"
+	case "uint":
+		return "uinteger"
+	case "bool":
+		return "boolean"
+	}
+	return t
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
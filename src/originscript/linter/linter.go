@@ -0,0 +1,926 @@
+// Package linter implements the `origion lint` and `origion check` subcommands.
+//
+// The linter walks the AST of a program and reports the likely bugs before the
+// program is run: unused variables and parameters, unreachable code,
+// unknown identifiers, shadowed variables, assignments to constants,
+// 'defer' outside a function, and the 'match' missing members of an enum.
+//
+// The checker(check.go) infers the types of the expressions, and reports the
+// mistakes they prove: undefined methods, unsupported operators and wrong
+// argument counts.
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"originscript/ast"
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//The checks, each diagnostic belongs to one of them.
+const (
+	SYNTAX      = "syntax"
+	UNUSED      = "unused"
+	UNREACHABLE = "unreachable"
+	UNDEFINED   = "undefined"
+	SHADOW      = "shadow"
+	CONSTASSIGN = "constassign"
+	DEFER       = "defer"
+	EXHAUSTIVE  = "exhaustive"
+)
+
+//Diagnostic is one problem found in a source file.
+type Diagnostic struct {
+	Filename string `json:"file"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+//String returns the diagnostic as 'file:line:col: message (check)'.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s (%s)", d.Filename, d.Line, d.Col, d.Message, d.Check)
+}
+
+//the syntax errors contain the position as ' (filename;line;col) '
+var errorPosRegex = regexp.MustCompile(`\(([^;()]*);(\d+);(\d+)\)`)
+
+//the '$VAR' interpolations of a command, e.g. `ls $dir`
+var cmdVarRegex = regexp.MustCompile(`(\\)?\$([a-zA-Z_]+)`)
+
+//Discover returns the files to lint: the files given, and the '*.aero' files of the directories.
+func Discover(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && (file == path || strings.HasSuffix(file, ".aero")) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+//Run lints the files and the directories of 'paths', and writes the diagnostics
+//to 'out', one per line, or as a JSON array if 'jsonOutput' is true.
+//It returns the number of diagnostics.
+func Run(paths []string, jsonOutput bool, out io.Writer) int {
+	return run("lint", LintFile, paths, jsonOutput, out)
+}
+
+//run applies 'fileFn' to the files of 'paths', and writes the diagnostics to 'out'.
+func run(command string, fileFn func(string) []Diagnostic, paths []string, jsonOutput bool, out io.Writer) int {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := Discover(paths)
+	if err != nil {
+		fmt.Fprintf(out, "OriginScript: %s: %s\n", command, err.Error())
+		return 1
+	}
+
+	diags := []Diagnostic{}
+	for _, file := range files {
+		diags = append(diags, fileFn(file)...)
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(diags, "", "  ")
+		fmt.Fprintln(out, string(data))
+	} else {
+		for _, d := range diags {
+			fmt.Fprintln(out, d.String())
+		}
+	}
+	return len(diags)
+}
+
+//LintFile parses and checks a single file, the syntax errors are reported as diagnostics.
+func LintFile(filename string) []Diagnostic {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return []Diagnostic{{Filename: filename, Check: SYNTAX, Message: err.Error()}}
+	}
+	return LintSource(filename, string(f))
+}
+
+//LintSource parses and checks the source code of a file.
+func LintSource(filename string, src string) []Diagnostic {
+	program, diags := parse(filename, src)
+	if program == nil {
+		return diags
+	}
+	return Lint(program)
+}
+
+//parse parses the source code of a file, the program is nil if it has syntax errors,
+//which are returned as diagnostics.
+func parse(filename string, src string) (*ast.Program, []Diagnostic) {
+	l := lexer.New(filename, src)
+	p := parser.New(l, filepath.Dir(filename))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		var diags []Diagnostic
+		for _, msg := range p.Errors() {
+			d := Diagnostic{Filename: filename, Check: SYNTAX, Message: strings.TrimSpace(msg)}
+			if m := errorPosRegex.FindStringSubmatch(msg); m != nil {
+				d.Line, _ = strconv.Atoi(m[2])
+				d.Col, _ = strconv.Atoi(m[3])
+			}
+			diags = append(diags, d)
+		}
+		return nil, diags
+	}
+	return program, nil
+}
+
+//Lint checks a parsed program. The diagnostics are sorted by their position.
+func Lint(program *ast.Program) []Diagnostic {
+	l := &linter{
+		global:  newScope(nil),
+		classes: make(map[string]*scope),
+		visited: make(map[ast.Node]bool),
+		enums:   make(map[string][]string),
+		ifaces:  make(map[string]bool),
+	}
+	for _, imp := range program.Imports {
+		if len(imp.Names) == 0 {
+			l.declare(l.global, imp.ImportPath, otherDecl, imp.Pos())
+		}
+		for _, name := range imp.Names {
+			l.declare(l.global, name.Value, otherDecl, name.Pos())
+		}
+	}
+	l.stmts(program.Statements, l.global)
+	l.resolve()
+	for _, m := range l.matches { //after all the enums are known
+		l.exhaustive(m)
+	}
+
+	sortDiagnostics(l.diags)
+	return l.diags
+}
+
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+type declKind int
+
+const (
+	varDecl   declKind = iota //'lit'
+	constDecl                 //'const'
+	paramDecl                 //function parameter
+	otherDecl                 //functions, classes, loop variables...
+	implicitDecl              //'x = 1' when 'x' is unknown
+)
+
+//decl is a declared name.
+type decl struct {
+	name  string
+	kind  declKind
+	pos   token.Position
+	used  bool
+	scope *scope
+}
+
+//scope follows the scopes created by the evaluator: functions, loops, catch
+//blocks, classes... but not the 'if' blocks, which use the enclosing scope.
+type scope struct {
+	parent  *scope
+	decls   map[string]*decl
+	local   bool   //inside a function, the unused names are reported
+	class   bool   //class or service body
+	extends string //the parent class of a class body
+	lenient bool   //the names may come from somewhere we don't know(e.g. 'from' queries)
+}
+
+func newScope(parent *scope) *scope {
+	s := &scope{parent: parent, decls: make(map[string]*decl)}
+	if parent != nil {
+		s.local = parent.local
+		s.lenient = parent.lenient
+	}
+	return s
+}
+
+//ref is a use of a name, it's resolved when all the declarations are known,
+//because a function could use the names declared after it.
+type ref struct {
+	scope  *scope
+	name   string
+	pos    token.Position
+	assign bool //target of '=', which declares the name if it's unknown
+	modify bool //target of an assignment
+	soft   bool //never reported if unknown, e.g. a bare word hash key
+}
+
+type linter struct {
+	global    *scope
+	classes   map[string]*scope //class bodies by class name
+	ifaces    map[string]bool   //interface names
+	decls     []*decl
+	refs      []ref
+	funcDepth int
+	visited   map[ast.Node]bool
+	diags     []Diagnostic
+	enums     map[string][]string //the members of the enums by enum name
+	matches   []*ast.MatchExpr
+}
+
+func (l *linter) report(pos token.Position, check string, format string, args ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{
+		Filename: pos.Filename,
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) declare(s *scope, name string, kind declKind, pos token.Position) {
+	if name == "" || name == "_" {
+		return
+	}
+	if _, ok := s.decls[name]; ok { //redeclared in the same scope
+		return
+	}
+	d := &decl{name: name, kind: kind, pos: pos, scope: s}
+	s.decls[name] = d
+	l.decls = append(l.decls, d)
+}
+
+func (l *linter) use(s *scope, ident *ast.Identifier) {
+	l.refs = append(l.refs, ref{scope: s, name: ident.Value, pos: ident.Pos()})
+}
+
+//stmts checks a list of statements, the statements after a 'return', 'throw',
+//'break' or 'continue' are reported once.
+func (l *linter) stmts(list []ast.Statement, s *scope) {
+	dead, reported := false, false
+	for _, stmt := range list {
+		if isNil(stmt) {
+			continue
+		}
+		if dead && !reported {
+			l.report(stmt.Pos(), UNREACHABLE, "unreachable code")
+			reported = true
+		}
+		l.node(stmt, s)
+		if isTerminator(stmt) {
+			dead = true
+		}
+	}
+}
+
+func isTerminator(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStmt:
+		return true
+	case *ast.ExpressionStatement:
+		switch s.Expression.(type) {
+		case *ast.BreakExpression, *ast.ContinueExpression:
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) node(n ast.Node, s *scope) {
+	if isNil(n) || l.visited[n] {
+		return
+	}
+	l.visited[n] = true
+
+	switch n := n.(type) {
+	case *ast.BlockStatement:
+		l.stmts(n.Statements, s)
+	case *ast.Identifier:
+		l.use(s, n)
+
+	case *ast.LetStatement:
+		for _, v := range n.Values {
+			l.node(v, s)
+		}
+		kind := varDecl
+		if n.InClass {
+			kind = otherDecl
+		}
+		for _, name := range n.Names {
+			if name.Token.Type != token.UNDERSCORE {
+				l.declare(s, name.Value, kind, name.Pos())
+			}
+		}
+	case *ast.ConstStatement:
+		for _, v := range n.Value {
+			l.node(v, s)
+		}
+		for _, name := range n.Name {
+			l.declare(s, name.Value, constDecl, name.Pos())
+		}
+	case *ast.AssignExpression:
+		l.node(n.Value, s)
+		if ident, ok := n.Name.(*ast.Identifier); ok {
+			l.refs = append(l.refs, ref{scope: s, name: ident.Value, pos: ident.Pos(),
+				assign: n.Token.Literal == "=", modify: true})
+		} else {
+			l.node(n.Name, s)
+		}
+
+	case *ast.FunctionStatement:
+		l.declare(s, n.Name.Value, otherDecl, n.Name.Pos())
+		//the parameters of the methods are part of the class's interface
+		l.function(n.FunctionLiteral, s, !s.class)
+	case *ast.FunctionLiteral:
+		l.function(n, s, true)
+	case *ast.DeferStmt:
+		if l.funcDepth == 0 {
+			l.report(n.Pos(), DEFER, "defer outside function")
+		}
+		l.node(n.Call, s)
+
+	case *ast.MethodCallExpression:
+		l.node(n.Object, s)
+		l.member(n.Call, s)
+	case *ast.HashLiteral:
+		for _, key := range n.Order {
+			if ident, ok := key.(*ast.Identifier); ok { //a bare word, unless it's a known name
+				l.refs = append(l.refs, ref{scope: s, name: ident.Value, pos: ident.Pos(), soft: true})
+			} else {
+				l.node(key, s)
+			}
+			l.node(n.Pairs[key], s)
+		}
+	case *ast.StructLiteral:
+		for _, v := range n.Pairs {
+			l.node(v, s)
+		}
+	case *ast.EnumLiteral:
+		for _, v := range n.Pairs {
+			l.node(v, s)
+		}
+	case *ast.EnumStatement:
+		l.declare(s, n.Name.Value, otherDecl, n.Name.Pos())
+		l.node(n.EnumLiteral, s)
+		var members []*ast.Identifier
+		for key := range n.EnumLiteral.Pairs {
+			if ident, ok := key.(*ast.Identifier); ok {
+				members = append(members, ident)
+			}
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Pos().Offset < members[j].Pos().Offset })
+		for _, m := range members {
+			l.enums[n.Name.Value] = append(l.enums[n.Name.Value], m.Value)
+		}
+	case *ast.CmdExpression:
+		for _, m := range cmdVarRegex.FindAllStringSubmatch(n.Value, -1) {
+			if m[1] == "" {
+				l.refs = append(l.refs, ref{scope: s, name: m[2], pos: n.Pos(), soft: true})
+			}
+		}
+
+	case *ast.ClassStatement:
+		if n.CategoryName != nil { //a category of an existing class
+			l.use(s, n.Name)
+			cs := newScope(s)
+			cs.class, cs.extends = true, n.Name.Value
+			l.classBody(n.ClassLiteral, cs)
+			return
+		}
+		l.declare(s, n.Name.Value, otherDecl, n.Name.Pos())
+		l.class(n.ClassLiteral, s)
+	case *ast.ClassLiteral:
+		l.class(n, s)
+	case *ast.InterfaceStatement:
+		l.declare(s, n.Name.Value, otherDecl, n.Name.Pos())
+		l.ifaces[n.Name.Value] = true
+		for _, p := range n.Parents {
+			l.use(s, p)
+		}
+	case *ast.PropertyDeclStmt:
+		l.property(n, s)
+	case *ast.ServiceStatement:
+		l.declare(s, n.Name.Value, otherDecl, n.Name.Pos())
+		ss := newScope(s)
+		ss.class = true
+		l.declare(ss, "vars", otherDecl, n.Pos())
+		if n.Block != nil {
+			l.stmts(n.Block.Statements, ss)
+		}
+	case *ast.AnnotationStmt, *ast.ClassIndexerExpression, *ast.DiamondExpr:
+		//names of the annotation classes, and of the global objects
+
+	case *ast.ForLoop:
+		ls := newScope(s)
+		l.node(n.Init, ls)
+		l.node(n.Cond, ls)
+		l.node(n.Update, ls)
+		l.node(n.Block, ls)
+	case *ast.ForEachArrayLoop:
+		l.node(n.Value, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.Block)
+	case *ast.ForEachMapLoop:
+		l.node(n.X, s)
+		l.loop(s, n.Token.Pos, []string{n.Key, n.Value}, n.Cond, n.Block)
+	case *ast.ForEachDotRange:
+		l.node(n.StartIdx, s)
+		l.node(n.EndIdx, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.Block)
+	case *ast.WhileLoop:
+		l.loop(s, n.Token.Pos, nil, n.Condition, n.Block)
+	case *ast.DoLoop:
+		l.loop(s, n.Token.Pos, nil, nil, n.Block)
+	case *ast.ForEverLoop:
+		l.loop(s, n.Token.Pos, nil, nil, n.Block)
+	case *ast.ListComprehension:
+		l.node(n.Value, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.Expr)
+	case *ast.ListRangeComprehension:
+		l.node(n.StartIdx, s)
+		l.node(n.EndIdx, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.Expr)
+	case *ast.ListMapComprehension:
+		l.node(n.X, s)
+		l.loop(s, n.Token.Pos, []string{n.Key, n.Value}, n.Cond, n.Expr)
+	case *ast.HashComprehension:
+		l.node(n.Value, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.KeyExpr, n.ValExpr)
+	case *ast.HashRangeComprehension:
+		l.node(n.StartIdx, s)
+		l.node(n.EndIdx, s)
+		l.loop(s, n.Token.Pos, []string{n.Var}, n.Cond, n.KeyExpr, n.ValExpr)
+	case *ast.HashMapComprehension:
+		l.node(n.X, s)
+		l.loop(s, n.Token.Pos, []string{n.Key, n.Value}, n.Cond, n.KeyExpr, n.ValExpr)
+	case *ast.GrepExpr:
+		l.node(n.Value, s)
+		l.loop(s, n.Token.Pos, nil, n.Block, n.Expr)
+	case *ast.MapExpr:
+		l.node(n.Value, s)
+		l.loop(s, n.Token.Pos, nil, n.Block, n.Expr)
+
+	case *ast.TryStmt:
+		l.node(n.Try, s)
+		for _, c := range n.Catches {
+			cs := newScope(s)
+			l.declare(cs, c.Var, otherDecl, c.Block.Pos())
+			l.node(c.Block, cs)
+		}
+		l.node(n.Finally, s)
+	case *ast.CaseMatchExpr:
+		l.node(n.Expr, s)
+		l.node(n.Block, newScope(s))
+	case *ast.CaseElseExpr:
+		l.node(n.Block, newScope(s))
+	case *ast.MatchExpr:
+		l.node(n.Expr, s)
+		for _, arm := range n.Arms {
+			as := newScope(s)
+			for _, p := range arm.Patterns {
+				l.pattern(p, s, as)
+			}
+			l.node(arm.Guard, as)
+			l.node(arm.Block, as)
+		}
+		l.matches = append(l.matches, n)
+	case *ast.UsingStmt:
+		us := newScope(s)
+		if n.Expr != nil {
+			l.node(n.Expr.Value, s)
+			if ident, ok := n.Expr.Name.(*ast.Identifier); ok {
+				l.declare(us, ident.Value, otherDecl, ident.Pos())
+			}
+		}
+		l.node(n.Block, us)
+	case *ast.IfMacroStatement: //only the branch chosen by the parser
+		if n.Condition {
+			l.node(n.Consequence, s)
+		} else {
+			l.node(n.Alternative, s)
+		}
+
+	case *ast.QueryExpr:
+		qs := newScope(s)
+		qs.lenient = true
+		l.node(n.From, qs)
+		l.node(n.QueryBody, qs)
+	case *ast.FromExpr:
+		l.node(n.Expr, s)
+		l.declare(s, n.Var, otherDecl, n.Pos())
+	case *ast.JoinExpr:
+		l.declare(s, n.JoinVar, otherDecl, n.Pos())
+		l.node(n.InExpr, s)
+		l.node(n.OnExpr, s)
+		l.node(n.EqualExpr, s)
+		if n.IntoVar != nil {
+			l.declare(s, n.IntoVar.Value, otherDecl, n.IntoVar.Pos())
+		}
+	case *ast.QueryContinuationExpr:
+		l.declare(s, n.Var, otherDecl, n.Pos())
+		l.node(n.Expr, s)
+
+	default:
+		for _, child := range children(n) {
+			l.node(child, s)
+		}
+	}
+}
+
+//function checks a function's body in its own scope. The unused parameters
+//are reported if 'params' is true.
+func (l *linter) function(fl *ast.FunctionLiteral, s *scope, params bool) {
+	if fl == nil {
+		return
+	}
+	fs := newScope(s)
+	fs.local = true
+
+	kind := paramDecl
+	if !params {
+		kind = otherDecl
+	}
+	for _, p := range fl.Parameters {
+		if ident, ok := p.(*ast.Identifier); ok {
+			l.declare(fs, ident.Value, kind, ident.Pos())
+		}
+	}
+	for _, v := range fl.Values { //default values
+		l.node(v, fs)
+	}
+
+	l.funcDepth++
+	l.node(fl.Body, fs)
+	l.funcDepth--
+}
+
+//loop checks the parts of a loop or a comprehension in a new scope, where the loop's variables are declared.
+func (l *linter) loop(s *scope, pos token.Position, vars []string, parts ...ast.Node) {
+	ls := newScope(s)
+	for _, v := range vars {
+		l.declare(ls, v, otherDecl, pos)
+	}
+	for _, part := range parts {
+		l.node(part, ls)
+	}
+}
+
+//pattern checks a pattern of a 'match' arm, its bindings are declared in the arm's scope 'as'.
+func (l *linter) pattern(p ast.Expression, s *scope, as *scope) {
+	switch p := p.(type) {
+	case *ast.IdentPattern:
+		if p.Var == "_" || eval.IsPatternType(p.Var) {
+			return
+		}
+		if _, ok := l.classes[p.Var]; ok || l.ifaces[p.Var] {
+			l.refs = append(l.refs, ref{scope: s, name: p.Var, pos: p.Pos()})
+			return
+		}
+		if _, ok := eval.BuiltinClasses[p.Var]; ok {
+			return
+		}
+		l.declare(as, p.Var, otherDecl, p.Pos())
+	case *ast.ValuePattern:
+		l.node(p.Value, s)
+	case *ast.TuplePattern:
+		for _, m := range p.Members {
+			l.pattern(m, s, as)
+		}
+	case *ast.ArrayPattern:
+		for _, m := range p.Members {
+			l.pattern(m, s, as)
+		}
+		l.declare(as, p.Var, otherDecl, p.RBracketToken.Pos)
+	case *ast.HashPattern:
+		for _, v := range p.Values {
+			l.pattern(v, s, as)
+		}
+	case *ast.TypePattern:
+		if !eval.IsPatternType(p.Type) {
+			l.refs = append(l.refs, ref{scope: s, name: p.Type, pos: p.Pos()})
+		}
+		for _, m := range p.Patterns {
+			l.pattern(m, s, as)
+		}
+	}
+}
+
+//exhaustive reports the 'match' whose patterns are members of an enum, but
+//not all of them, and which has no 'else' or catch-all arm.
+func (l *linter) exhaustive(m *ast.MatchExpr) {
+	var enum string
+	covered := make(map[string]bool)
+	for _, arm := range m.Arms {
+		if len(arm.Patterns) == 0 { //'else'
+			return
+		}
+		for _, p := range arm.Patterns {
+			if ident, ok := p.(*ast.IdentPattern); ok && arm.Guard == nil {
+				if _, isClass := l.classes[ident.Var]; !isClass && !l.ifaces[ident.Var] && !eval.IsPatternType(ident.Var) {
+					return //'_' or a binding
+				}
+			}
+
+			vp, ok := p.(*ast.ValuePattern)
+			if !ok {
+				continue
+			}
+			mc, ok := vp.Value.(*ast.MethodCallExpression)
+			if !ok {
+				continue
+			}
+			obj, ok1 := mc.Object.(*ast.Identifier)
+			member, ok2 := mc.Call.(*ast.Identifier)
+			if !ok1 || !ok2 || l.enums[obj.Value] == nil {
+				continue
+			}
+			if enum != "" && enum != obj.Value { //members of several enums
+				return
+			}
+			enum = obj.Value
+			if arm.Guard == nil {
+				covered[member.Value] = true
+			}
+		}
+	}
+	if enum == "" {
+		return
+	}
+
+	var missing []string
+	for _, member := range l.enums[enum] {
+		if !covered[member] {
+			missing = append(missing, member)
+		}
+	}
+	if len(missing) != 0 {
+		l.report(m.Pos(), EXHAUSTIVE, "match on enum '%s' is not exhaustive, missing: %s", enum, strings.Join(missing, ", "))
+	}
+}
+
+//member checks the part after the '.' of a method call: the method or field name is not a variable.
+func (l *linter) member(e ast.Expression, s *scope) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+	case *ast.CallExpression:
+		l.member(e.Function, s)
+		for _, arg := range e.Arguments {
+			l.node(arg, s)
+		}
+	case *ast.IndexExpression:
+		l.member(e.Left, s)
+		l.node(e.Index, s)
+	default:
+		l.node(e, s)
+	}
+}
+
+func (l *linter) class(cl *ast.ClassLiteral, s *scope) {
+	cs := newScope(s)
+	cs.class = true
+	if cl.Parent != "" && cl.Parent != "object" {
+		cs.extends = cl.Parent
+	}
+	if cl.Name != "" {
+		l.classes[cl.Name] = cs
+	}
+	for _, name := range cl.Interfaces {
+		l.refs = append(l.refs, ref{scope: s, name: name, pos: cl.Pos()})
+	}
+	l.classBody(cl, cs)
+}
+
+func (l *linter) classBody(cl *ast.ClassLiteral, cs *scope) {
+	//the methods use the fields and the properties declared after them
+	for name, p := range cl.Properties {
+		l.declare(cs, name, otherDecl, p.Name.Pos())
+		l.declare(cs, "_"+name, otherDecl, p.Name.Pos())
+	}
+	if cl.Block != nil {
+		l.stmts(cl.Block.Statements, cs)
+	}
+}
+
+func (l *linter) property(p *ast.PropertyDeclStmt, s *scope) {
+	l.declare(s, p.Name.Value, otherDecl, p.Name.Pos())
+	l.declare(s, "_"+p.Name.Value, otherDecl, p.Name.Pos())
+	l.node(p.Default, s)
+
+	accessor := func(body *ast.BlockStatement, setter bool) {
+		as := newScope(s)
+		as.local = true
+		for _, idx := range p.Indexes {
+			l.declare(as, idx.Value, otherDecl, idx.Pos())
+		}
+		if setter {
+			l.declare(as, "value", otherDecl, body.Pos())
+		}
+		l.funcDepth++
+		l.node(body, as)
+		l.funcDepth--
+	}
+	if p.Getter != nil && p.Getter.Body != nil {
+		accessor(p.Getter.Body, false)
+	}
+	if p.Setter != nil && p.Setter.Body != nil {
+		accessor(p.Setter.Body, true)
+	}
+}
+
+//lookup finds the declaration of a name. If it's not found, 'lenient' reports
+//whether the name could be declared somewhere the linter doesn't know.
+func (l *linter) lookup(s *scope, name string) (d *decl, lenient bool) {
+	for ; s != nil; s = s.parent {
+		if d, ok := s.decls[name]; ok {
+			return d, false
+		}
+		lenient = lenient || s.lenient
+
+		//the members of the parent classes
+		seen := make(map[string]bool)
+		for base := s.extends; base != "" && !seen[base]; {
+			seen[base] = true
+			cs, ok := l.classes[base]
+			if !ok { //e.g. a class of an imported module
+				lenient = true
+				break
+			}
+			if d, ok := cs.decls[name]; ok {
+				return d, false
+			}
+			base = cs.extends
+		}
+	}
+	return nil, lenient
+}
+
+//resolve resolves the uses of the names, and reports the problems which need all the declarations.
+func (l *linter) resolve() {
+	known := knownNames()
+
+	//'x = 1' declares 'x' in the outermost scope if it's unknown.
+	for _, r := range l.refs {
+		if !r.assign || known[r.name] {
+			continue
+		}
+		if d, _ := l.lookup(r.scope, r.name); d == nil {
+			l.declare(l.global, r.name, implicitDecl, r.pos)
+		}
+	}
+
+	for _, r := range l.refs {
+		if strings.HasPrefix(r.name, "$") || strings.HasPrefix(r.name, "@") { //e.g. '$_', '@_'
+			continue
+		}
+		d, lenient := l.lookup(r.scope, r.name)
+		if d == nil {
+			if !r.soft && !lenient && !known[r.name] {
+				l.reportUnknown(r, known)
+			}
+			continue
+		}
+		if r.modify && d.kind == constDecl {
+			l.report(r.pos, CONSTASSIGN, "Const variable '%s' cannot be modified", r.name)
+		}
+		if !r.assign {
+			d.used = true
+		}
+	}
+
+	for _, d := range l.decls {
+		if strings.HasPrefix(d.name, "_") {
+			continue
+		}
+		if !d.used && d.scope.local {
+			switch d.kind {
+			case varDecl:
+				l.report(d.pos, UNUSED, "'%s' declared but not used", d.name)
+			case paramDecl:
+				l.report(d.pos, UNUSED, "parameter '%s' is not used", d.name)
+			}
+		}
+		if d.kind == varDecl || d.kind == constDecl {
+			if outer, _ := l.lookup(d.scope.parent, d.name); outer != nil && outer.kind != implicitDecl {
+				l.report(d.pos, SHADOW, "declaration of '%s' shadows declaration at line %d", d.name, outer.pos.Line)
+			}
+		}
+	}
+}
+
+func (l *linter) reportUnknown(r ref, known map[string]bool) {
+	var keys []string
+	for s := r.scope; s != nil; s = s.parent {
+		for name := range s.decls {
+			keys = append(keys, name)
+		}
+	}
+	for name := range known {
+		keys = append(keys, name)
+	}
+
+	found := eval.TypoSuggestions(keys, r.name)
+	if len(found) == 0 {
+		l.report(r.pos, UNDEFINED, "unknown identifier: '%s' is not defined", r.name)
+		return
+	}
+	sort.Strings(found)
+	l.report(r.pos, UNDEFINED, "unknown identifier: '%s' is not defined, did you mean: %s?", r.name, strings.Join(found, ", "))
+}
+
+//knownNames returns the names which are always defined: the builtins, and the global objects.
+func knownNames() map[string]bool {
+	known := map[string]bool{"this": true, "self": true, "parent": true}
+	for _, name := range eval.BuiltinNames() {
+		known[name] = true
+	}
+	for name := range eval.BuiltinClasses {
+		known[name] = true
+	}
+	for _, name := range eval.GetGlobalNames() {
+		//e.g. 'math.Pi' => 'math'
+		known[strings.Split(name, ".")[0]] = true
+	}
+	return known
+}
+
+func isNil(n ast.Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+var nodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+//children returns the nodes directly contained in a node.
+func children(n ast.Node) []ast.Node {
+	var nodes []ast.Node
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if v.IsNil() {
+				return
+			}
+			if v.Type().Implements(nodeType) {
+				if child := v.Interface().(ast.Node); !isNil(child) {
+					nodes = append(nodes, child)
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			for _, k := range v.MapKeys() {
+				walk(k)
+				walk(v.MapIndex(k))
+			}
+		}
+	}
+
+	v := reflect.ValueOf(n).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		switch v.Type().Field(i).Name {
+		case "Doc", "Program", "Functions", "Annotations", "Func": //comments, imported modules, annotations, resolved function
+			continue
+		}
+		if f := v.Field(i); f.CanInterface() {
+			walk(f)
+		}
+	}
+	return nodes
+}
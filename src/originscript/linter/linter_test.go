@@ -0,0 +1,151 @@
+package linter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"fn f(a, b) { lit c = 1; return a }\nf(1, 2)\n", []string{
+			"t.aero:1:9: parameter 'b' is not used (unused)",
+			"t.aero:1:18: 'c' declared but not used (unused)",
+		}},
+		{"fn f() { return 1; println(2) }\nf()\n", []string{
+			"t.aero:1:20: unreachable code (unreachable)",
+		}},
+		{"lit total = 1\nprintln(totl)\n", []string{
+			"t.aero:2:9: unknown identifier: 'totl' is not defined, did you mean: total? (undefined)",
+		}},
+		{"lit x = 1\nfn f() { lit x = 2; return x }\nprintln(x, f())\n", []string{
+			"t.aero:2:14: declaration of 'x' shadows declaration at line 1 (shadow)",
+		}},
+		{"const MAX = 1\nMAX = 2\n", []string{
+			"t.aero:2:1: Const variable 'MAX' cannot be modified (constassign)",
+		}},
+		{"defer println(1)\nfn f() { defer println(2) }\nf()\n", []string{
+			"t.aero:1:1: defer outside function (defer)",
+		}},
+		{"enum Color { RED, GREEN, BLUE }\nfn f(c) { return match c { Color.RED { 1 } Color.GREEN if c { 2 } } }\nprintln(f(Color.RED))\n", []string{
+			"t.aero:2:18: match on enum 'Color' is not exhaustive, missing: GREEN, BLUE (exhaustive)",
+		}},
+		//no diagnostics: implicit globals, functions used before their declaration,
+		//class members, bare word hash keys, loop variables.
+		{`x = 1
+fn f() { return g(x) }
+fn g(y) { return y }
+class Dog {
+    lit name
+    property Age { get; set; }
+    fn bark(times) { println(name, Age, _Age) }
+}
+lit h = {name: 1}
+for i in 1..3 { println(i, h) }
+println(f())
+`, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, d := range LintSource("t.aero", tt.input) {
+			got = append(got, d.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("LintSource(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"lit s = \"abc\"\nprintln(s.uper(), len(s).foo(), str(1).upper())\n", []string{
+			"t.aero:2:11: undefined method 'uper' for type string, did you mean: upper? (nomethod)",
+			"t.aero:2:26: undefined method 'foo' for type int (nomethod)",
+		}},
+		{"class Dog { fn bark() { return 1 } }\nlit d = new Dog()\nprintln(d.bark(), d.toString(), d.barc())\n", []string{
+			"t.aero:3:35: undefined method 'barc' for class 'Dog', did you mean: bark? (nomethod)",
+		}},
+		{"lit h = {\"a\": 1}\nprintln(\"a\" - h, \"a\" + h, 1 + 2.5)\n", []string{
+			"t.aero:2:13: unsupported operator '-' for string and hash (operands)",
+			"t.aero:2:22: '+' of string and hash concatenates the hash's text (operands)",
+		}},
+		{"fn add(a, b = 1) { return a + b }\nfn f(a, rest...) { return a }\nprintln(add(), add(1), add(1, 2, 3), f(1, 2, 3), f())\n", []string{
+			"t.aero:3:9: not enough arguments in call to 'add': expected at least 1, got 0 (argcount)",
+			"t.aero:3:24: too many arguments in call to 'add': expected at most 2, got 3 (argcount)",
+			"t.aero:3:50: not enough arguments in call to 'f': expected at least 1, got 0 (argcount)",
+		}},
+		{"fn name() -> string { return x }\nfn first(a: array) { return a.first() }\nprintln(name().foo(), first([1]).foo(), 1 |> first())\n", []string{
+			"t.aero:3:16: undefined method 'foo' for type string (nomethod)",
+		}},
+		//no diagnostics: the types which could change, the loop variables, the
+		//parent classes, the hash keys, the extension methods.
+		{`lit x = 1
+x = "a"
+println(x.upper())
+lit s = "a"
+for s in [1, 2] { println(s.isEven()) }
+class Base { fn hello() { return 1 } }
+class Child : Base { }
+lit child = new Child()
+println(child.hello())
+lit h = {}
+h.greet = fn() { return 1 }
+println(h.greet())
+fn string$shout() { return self.upper() }
+println("a".shout())
+`, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, d := range CheckSource("t.aero", tt.input) {
+			got = append(got, d.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("CheckSource(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestCheckRequire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "util.aero"), []byte("fn Parse(s) { return int(s) }\nfn helper() {}\n"), 0644)
+	main := filepath.Join(dir, "main.aero")
+	ioutil.WriteFile(main, []byte("require util\nrequire util (Parse)\nprintln(util.Parse(\"1\").foo(), util.Parse(), util.Prase(\"1\"))\nprintln(util.helper(), Parse(1, 2).bar())\n"), 0644)
+
+	var got []string
+	for _, d := range CheckFile(main) {
+		got = append(got, strings.TrimPrefix(d.String(), dir+string(filepath.Separator)))
+	}
+	expected := []string{
+		"main.aero:3:25: undefined method 'foo' for type int (nomethod)",
+		"main.aero:3:37: not enough arguments in call to 'util.Parse': expected 1, got 0 (argcount)",
+		"main.aero:3:51: module 'util' has no member 'Prase' (nomethod)",
+		"main.aero:4:14: 'helper' is not exported by module 'util' (nomethod)",
+		"main.aero:4:24: too many arguments in call to 'Parse': expected 1, got 2 (argcount)",
+		"main.aero:4:36: undefined method 'bar' for type int (nomethod)",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("CheckFile wrong.\nexpected=%q\ngot=%q", expected, got)
+	}
+}
+
+func TestLintSyntaxError(t *testing.T) {
+	diags := LintSource("t.aero", "lit x = )\n")
+	if len(diags) == 0 || diags[0].Check != SYNTAX {
+		t.Errorf("expected a syntax diagnostic, got=%v", diags)
+	}
+}
@@ -0,0 +1,621 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"originscript/ast"
+	doc "originscript/docs"
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	//the syntax errors contain the position as ' (filename;line;col) '
+	errorPosRegex = regexp.MustCompile(`\(([^;()]*);(\d+);(\d+)\)\s*-?\s*`)
+	//the error lines are ' (filename;line) '
+	errorLineRegex = regexp.MustCompile(`\(([^;()]*);(\d+)\)`)
+)
+
+//document is an opened text document, parsed each time it changes.
+type document struct {
+	uri         string
+	path        string
+	lines       []string
+	program     *ast.Program
+	functions   map[string]*ast.FunctionLiteral //'parser.Functions', including the imported ones
+	diagnostics []Diagnostic
+}
+
+func newDocument(uri string, text string) *document {
+	d := &document{uri: uri, path: uriToPath(uri)}
+	d.parse(text)
+	return d
+}
+
+func (d *document) parse(text string) {
+	d.lines = strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	d.program = &ast.Program{Imports: make(map[string]*ast.ImportStatement)}
+	d.functions = nil
+	d.diagnostics = []Diagnostic{}
+
+	//the document is being edited, a parser's panic should not stop the server.
+	defer func() {
+		if r := recover(); r != nil {
+			d.diagnostics = append(d.diagnostics, Diagnostic{Severity: SeverityError, Source: "origion",
+				Message: fmt.Sprintf("parser error: %v", r)})
+		}
+	}()
+
+	//the parser needs the lines to know which comments are documentation
+	parser.FileLines = d.lines
+	l := lexer.New(d.path, text)
+	p := parser.NewWithDoc(l, filepath.Dir(d.path))
+	d.program = p.ParseProgram()
+	d.functions = p.Functions
+
+	errorLines := p.ErrorLines()
+	for i, msg := range p.Errors() {
+		diag := Diagnostic{Severity: SeverityError, Source: "origion", Message: strings.TrimSpace(msg)}
+		filename, line, col := "", 0, 0
+		if m := errorPosRegex.FindStringSubmatchIndex(msg); m != nil {
+			filename = msg[m[2]:m[3]]
+			line, _ = strconv.Atoi(msg[m[4]:m[5]])
+			col, _ = strconv.Atoi(msg[m[6]:m[7]])
+			diag.Message = strings.TrimSpace(msg[m[1]:])
+		} else if i < len(errorLines) {
+			if m := errorLineRegex.FindStringSubmatch(errorLines[i]); m != nil {
+				filename = m[1]
+				line, _ = strconv.Atoi(m[2])
+			}
+		}
+
+		if filename != "" && filename != d.path { //error of an imported module
+			diag.Message = fmt.Sprintf("%s:%d: %s", filename, line, diag.Message)
+		} else if line > 0 {
+			start := Position{Line: line - 1}
+			if col > 0 {
+				start.Character = col - 1
+			}
+			diag.Range = Range{Start: start, End: Position{Line: start.Line, Character: d.wordEnd(start)}}
+		}
+		d.diagnostics = append(d.diagnostics, diag)
+	}
+}
+
+//wordEnd returns the end of the word at 'pos', the end of the line if there is no word.
+func (d *document) wordEnd(pos Position) int {
+	if pos.Line >= len(d.lines) {
+		return pos.Character
+	}
+	runes := []rune(d.lines[pos.Line])
+	end := pos.Character
+	for end < len(runes) && isWordRune(runes[end]) {
+		end++
+	}
+	if end == pos.Character {
+		end = len(runes)
+	}
+	return end
+}
+
+//wordAt returns the word at 'pos', and the word before it if they are separated by a '.',
+//e.g. 'fmt' and 'println' for 'fmt.println'.
+func (d *document) wordAt(pos Position) (qualifier string, word string) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return "", ""
+	}
+	runes := []rune(d.lines[pos.Line])
+	start := pos.Character
+	if start > len(runes) {
+		start = len(runes)
+	}
+	end := start
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWordRune(runes[end]) {
+		end++
+	}
+	word = string(runes[start:end])
+	return qualifierBefore(runes, start), word
+}
+
+//prefixAt returns the part of the word before 'pos', used for completion.
+func (d *document) prefixAt(pos Position) (qualifier string, prefix string) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return "", ""
+	}
+	runes := []rune(d.lines[pos.Line])
+	end := pos.Character
+	if end > len(runes) {
+		end = len(runes)
+	}
+	start := end
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+	return qualifierBefore(runes, start), string(runes[start:end])
+}
+
+func qualifierBefore(runes []rune, start int) string {
+	if start == 0 || runes[start-1] != '.' {
+		return ""
+	}
+	end := start - 1
+	begin := end
+	for begin > 0 && isWordRune(runes[begin-1]) {
+		begin--
+	}
+	return string(runes[begin:end])
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+//scope returns the statements where the names qualified by 'qualifier' are declared:
+//the imported module's, or the document's.
+func (d *document) scope(qualifier string) *ast.Program {
+	if imp, ok := d.program.Imports[qualifier]; ok && imp.Program != nil {
+		return imp.Program
+	}
+	return d.program
+}
+
+//lookup finds the declaration of a name: a function, a class, a method or a top-level variable.
+func (d *document) lookup(qualifier string, name string) ast.Statement {
+	program := d.scope(qualifier)
+	programs := []*ast.Program{program}
+	if program == d.program && qualifier == "" { //the unqualified names of the imported modules
+		for _, imp := range d.imports() {
+			if imp.Program != nil {
+				programs = append(programs, imp.Program)
+			}
+		}
+	}
+
+	for _, p := range programs {
+		for _, stmt := range p.Statements {
+			if declares(stmt, name) {
+				return stmt
+			}
+		}
+	}
+
+	//e.g. 'dog.bark()': the methods of the classes
+	for _, p := range programs {
+		for _, stmt := range p.Statements {
+			if c, ok := stmt.(*ast.ClassStatement); ok {
+				if fn, ok := c.ClassLiteral.Methods[name]; ok {
+					return fn
+				}
+				if prop, ok := c.ClassLiteral.Properties[name]; ok {
+					return prop
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//imports returns the imported modules sorted by name.
+func (d *document) imports() []*ast.ImportStatement {
+	var list []*ast.ImportStatement
+	for _, imp := range d.program.Imports {
+		list = append(list, imp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ImportPath < list[j].ImportPath })
+	return list
+}
+
+func declares(stmt ast.Statement, name string) bool {
+	switch s := stmt.(type) {
+	case *ast.FunctionStatement:
+		return s.Name.Value == name
+	case *ast.ClassStatement:
+		return s.Name.Value == name && s.CategoryName == nil
+	case *ast.EnumStatement:
+		return s.Name.Value == name
+	case *ast.InterfaceStatement:
+		return s.Name.Value == name
+	case *ast.ServiceStatement:
+		return s.Name.Value == name
+	case *ast.LetStatement:
+		for _, n := range s.Names {
+			if n.Value == name {
+				return true
+			}
+		}
+	case *ast.ConstStatement:
+		for _, n := range s.Name {
+			if n.Value == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//hover returns the documentation of the name at 'pos' as markdown.
+func (d *document) hover(pos Position) string {
+	qualifier, word := d.wordAt(pos)
+	if word == "" {
+		return ""
+	}
+
+	var out bytes.Buffer
+	code := func(text string) {
+		out.WriteString("```origion\n" + strings.TrimSpace(text) + "\n```\n")
+	}
+	switch s := d.lookup(qualifier, word).(type) {
+	case *ast.FunctionStatement:
+		fd := doc.FuncDoc(s)
+		code(fd.Value.Text)
+		if text := strings.TrimSpace(fd.Value.Doc); text != "" {
+			out.WriteString("\n" + text + "\n")
+		}
+		if len(fd.Params) > 0 {
+			out.WriteString("\n**Parameters**\n\n")
+			for _, p := range fd.Params {
+				out.WriteString(funcInfo(p))
+			}
+		}
+		if len(fd.Returns) > 0 {
+			out.WriteString("\n**Returns**\n\n")
+			for _, r := range fd.Returns {
+				out.WriteString(funcInfo(r))
+			}
+		}
+	case *ast.ClassStatement:
+		code(s.Docs())
+		docText(&out, s.Doc)
+	case *ast.PropertyDeclStmt:
+		code(s.Docs())
+		docText(&out, s.Doc)
+	case *ast.LetStatement:
+		code(s.Docs())
+		docText(&out, s.Doc)
+	case *ast.ConstStatement:
+		code(s.Docs())
+		docText(&out, s.Doc)
+	case *ast.EnumStatement:
+		code("enum " + s.Name.Value)
+		docText(&out, s.Doc)
+	case *ast.InterfaceStatement:
+		code(s.String())
+		docText(&out, s.Doc)
+	case *ast.ServiceStatement:
+		code("service " + s.Name.Value)
+		docText(&out, s.Doc)
+	default:
+		if _, ok := d.program.Imports[word]; ok && qualifier == "" {
+			out.WriteString("module `" + word + "`\n")
+		} else if qualifier == "" && isBuiltin(word) {
+			out.WriteString("builtin function `" + word + "`\n")
+		} else if qualifier != "" && contains(stdlibMembers(qualifier), word) {
+			out.WriteString("builtin method `" + qualifier + "." + word + "`\n")
+		} else if qualifier == "" && contains(stdlibModules(), word) {
+			out.WriteString("builtin module `" + word + "`\n")
+		}
+	}
+	return out.String()
+}
+
+func funcInfo(fi *doc.FuncInfo) string {
+	var out bytes.Buffer
+	out.WriteString("- ")
+	if fi.Name != "" {
+		out.WriteString("`" + fi.Name + "` ")
+	}
+	if fi.Type != "" {
+		out.WriteString("*" + fi.Type + "* ")
+	}
+	out.WriteString(strings.TrimSpace(fi.Desc) + "\n")
+	return out.String()
+}
+
+func docText(out *bytes.Buffer, group *ast.CommentGroup) {
+	if text := strings.TrimSpace(group.Text()); text != "" {
+		out.WriteString("\n" + text + "\n")
+	}
+}
+
+//definition returns the location of the declaration of the name at 'pos'.
+func (d *document) definition(pos Position) *Location {
+	qualifier, word := d.wordAt(pos)
+	if word == "" {
+		return nil
+	}
+
+	if stmt := d.lookup(qualifier, word); stmt != nil {
+		if name := declName(stmt, word); name != nil {
+			return location(name.Pos(), len(word))
+		}
+	}
+
+	//imported module: the start of its file
+	if imp, ok := d.program.Imports[word]; ok && qualifier == "" && imp.Program != nil {
+		if p := imp.Program.Pos(); p.Filename != "" {
+			return &Location{URI: pathToURI(p.Filename)}
+		}
+	}
+
+	//functions known by the parser, e.g. the functions of the imported modules' imports
+	if fl, ok := d.functions[word]; ok && fl.Token.Pos.IsValid() {
+		return location(fl.Token.Pos, len(fl.Token.Literal))
+	}
+	return nil
+}
+
+//declName returns the identifier of the declared name in a statement.
+func declName(stmt ast.Statement, name string) *ast.Identifier {
+	switch s := stmt.(type) {
+	case *ast.FunctionStatement:
+		return s.Name
+	case *ast.ClassStatement:
+		return s.Name
+	case *ast.EnumStatement:
+		return s.Name
+	case *ast.InterfaceStatement:
+		return s.Name
+	case *ast.ServiceStatement:
+		return s.Name
+	case *ast.PropertyDeclStmt:
+		return s.Name
+	case *ast.LetStatement:
+		for _, n := range s.Names {
+			if n.Value == name {
+				return n
+			}
+		}
+	case *ast.ConstStatement:
+		for _, n := range s.Name {
+			if n.Value == name {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+//symbols returns the outline of the document.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, stmt := range d.program.Statements {
+		symbols = append(symbols, statementSymbols(stmt, false)...)
+	}
+	return symbols
+}
+
+func statementSymbols(stmt ast.Statement, member bool) []DocumentSymbol {
+	symbol := func(name *ast.Identifier, kind int, detail string) DocumentSymbol {
+		sel := identRange(name)
+		return DocumentSymbol{Name: name.Value, Kind: kind, Detail: detail, Range: nodeRange(stmt, sel), SelectionRange: sel}
+	}
+
+	switch s := stmt.(type) {
+	case *ast.FunctionStatement:
+		kind := SymbolFunction
+		if member {
+			kind = SymbolMethod
+		}
+		return []DocumentSymbol{symbol(s.Name, kind, s.Docs())}
+	case *ast.ClassStatement:
+		sym := symbol(s.Name, SymbolClass, "")
+		if s.ClassLiteral.Block != nil {
+			for _, m := range s.ClassLiteral.Block.Statements {
+				sym.Children = append(sym.Children, statementSymbols(m, true)...)
+			}
+		}
+		return []DocumentSymbol{sym}
+	case *ast.ServiceStatement:
+		sym := symbol(s.Name, SymbolModule, s.Addr)
+		if s.Block != nil {
+			for _, m := range s.Block.Statements {
+				sym.Children = append(sym.Children, statementSymbols(m, true)...)
+			}
+		}
+		return []DocumentSymbol{sym}
+	case *ast.EnumStatement:
+		return []DocumentSymbol{symbol(s.Name, SymbolEnum, "")}
+	case *ast.InterfaceStatement:
+		return []DocumentSymbol{symbol(s.Name, SymbolInterface, s.Docs())}
+	case *ast.PropertyDeclStmt:
+		return []DocumentSymbol{symbol(s.Name, SymbolProperty, "")}
+	case *ast.LetStatement:
+		kind := SymbolVariable
+		if member {
+			kind = SymbolField
+		}
+		var list []DocumentSymbol
+		for _, n := range s.Names {
+			if n.Token.Type != token.UNDERSCORE {
+				list = append(list, symbol(n, kind, ""))
+			}
+		}
+		return list
+	case *ast.ConstStatement:
+		var list []DocumentSymbol
+		for _, n := range s.Name {
+			list = append(list, symbol(n, SymbolConstant, ""))
+		}
+		return list
+	}
+	return nil
+}
+
+//completion returns the names which could be completed at 'pos'.
+func (d *document) completion(pos Position) []CompletionItem {
+	qualifier, prefix := d.prefixAt(pos)
+	items := make(map[string]CompletionItem)
+	add := func(label string, kind int, detail string) {
+		if _, ok := items[label]; !ok && strings.HasPrefix(label, prefix) && label != "" {
+			items[label] = CompletionItem{Label: label, Kind: kind, Detail: detail}
+		}
+	}
+
+	if qualifier != "" {
+		if imp, ok := d.program.Imports[qualifier]; ok {
+			if imp.Program != nil {
+				module := &eval.ImportedObject{Exports: imp.Program.ExportedNames()}
+				for _, stmt := range imp.Program.Statements {
+					for _, sym := range statementSymbols(stmt, false) {
+						if module.Exported(sym.Name) {
+							add(sym.Name, completionKind(sym.Kind), sym.Detail)
+						}
+					}
+				}
+			}
+		} else {
+			for _, name := range stdlibMembers(qualifier) {
+				add(name, CompletionMethod, qualifier+"."+name)
+			}
+		}
+		return sortedItems(items)
+	}
+
+	for _, sym := range d.symbols() {
+		add(sym.Name, completionKind(sym.Kind), sym.Detail)
+	}
+	for name, imp := range d.program.Imports {
+		if len(imp.Names) == 0 { //not 'require util (parse, format)'
+			add(name, CompletionModule, "module")
+		}
+	}
+	for _, name := range token.Keywords() {
+		add(name, CompletionKeyword, "keyword")
+	}
+	for _, name := range eval.BuiltinNames() {
+		add(name, CompletionFunction, "builtin function")
+	}
+	for _, name := range stdlibModules() {
+		add(name, CompletionModule, "builtin module")
+	}
+	return sortedItems(items)
+}
+
+func completionKind(symbolKind int) int {
+	switch symbolKind {
+	case SymbolClass, SymbolInterface:
+		return CompletionClass
+	case SymbolFunction:
+		return CompletionFunction
+	case SymbolMethod:
+		return CompletionMethod
+	case SymbolConstant, SymbolEnum:
+		return CompletionConstant
+	case SymbolModule:
+		return CompletionModule
+	}
+	return CompletionVariable
+}
+
+func sortedItems(items map[string]CompletionItem) []CompletionItem {
+	list := make([]CompletionItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Label < list[j].Label })
+	return list
+}
+
+//stdlibModules returns the names of the builtin modules, e.g. 'fmt', 'math'.
+func stdlibModules() []string {
+	var names []string
+	for _, name := range eval.GetGlobalNames() {
+		names = append(names, strings.Split(name, ".")[0])
+	}
+	return names
+}
+
+//stdlibMembers returns the names which could be used after 'module.', e.g. 'fmt.println'.
+func stdlibMembers(module string) []string {
+	var names []string
+	for _, name := range eval.GetGlobalNames() {
+		if strings.HasPrefix(name, module+".") {
+			names = append(names, strings.TrimPrefix(name, module+"."))
+		}
+	}
+	if obj, ok := eval.GetGlobalObj(module); ok {
+		names = append(names, eval.MethodNames(obj)...)
+	}
+	return names
+}
+
+func isBuiltin(name string) bool {
+	return contains(eval.BuiltinNames(), name)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+//location converts a position of the AST(1-based) to a location(0-based).
+func location(pos token.Position, length int) *Location {
+	start := Position{Line: pos.Line - 1, Character: pos.Col - 1}
+	return &Location{
+		URI:   pathToURI(pos.Filename),
+		Range: Range{Start: start, End: Position{Line: start.Line, Character: start.Character + length}},
+	}
+}
+
+func identRange(ident *ast.Identifier) Range {
+	return location(ident.Pos(), utf8.RuneCountInString(ident.Value)).Range
+}
+
+//nodeRange returns the range of a statement, 'sel' if its end is unknown.
+func nodeRange(n ast.Node, sel Range) (r Range) {
+	r = sel
+	defer func() { //End() could fail on an incomplete statement
+		if recover() != nil {
+			r = sel
+		}
+	}()
+
+	start, end := n.Pos(), n.End()
+	if !start.IsValid() || !end.IsValid() || end.Line < start.Line {
+		return sel
+	}
+	r = Range{
+		Start: Position{Line: start.Line - 1, Character: start.Col - 1},
+		End:   Position{Line: end.Line - 1, Character: end.Col},
+	}
+	if before(sel.Start, r.Start) || before(r.End, sel.End) { //the range must contain 'sel'
+		return sel
+	}
+	return r
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
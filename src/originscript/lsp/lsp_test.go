@@ -0,0 +1,216 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//client is an in-process JSON-RPC client of the server.
+type client struct {
+	t             *testing.T
+	conn          *Conn
+	messages      chan *Message //read from the server, the pipes are not buffered
+	id            int
+	notifications []*Message
+}
+
+func newClient(t *testing.T) (*client, func()) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	done := make(chan error)
+	go func() {
+		done <- NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+
+	c := &client{t: t, conn: NewConn(clientIn, clientOut), messages: make(chan *Message, 16)}
+	go func() {
+		for {
+			msg, err := c.conn.Read()
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+
+	stop := func() {
+		c.notify("exit", nil)
+		if err := <-done; err != nil {
+			t.Errorf("server returned error: %s", err)
+		}
+	}
+	return c, stop
+}
+
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.id++
+	data, _ := json.Marshal(params)
+	id := json.RawMessage(mustMarshal(c.id))
+	if err := c.conn.Write(&Message{ID: &id, Method: method, Params: data}); err != nil {
+		c.t.Fatalf("%s: write error: %s", method, err)
+	}
+
+	for msg := range c.messages {
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s: %s", method, msg.Error)
+		}
+		if result != nil {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				c.t.Fatalf("%s: invalid result %s: %s", method, msg.Result, err)
+			}
+		}
+		return
+	}
+	c.t.Fatalf("%s: no response", method)
+}
+
+func (c *client) notify(method string, params interface{}) {
+	data, _ := json.Marshal(params)
+	if err := c.conn.Write(&Message{Method: method, Params: data}); err != nil {
+		c.t.Fatalf("%s: write error: %s", method, err)
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+const testSource = `#Add two numbers.
+#@param {int} a the first number
+#@returns {int} the sum
+fn add(a, b) {
+    return a + b
+}
+
+class Dog {
+    lit name
+    fn bark() { println(name) }
+}
+
+lit total = add(1, 2)
+fmt.println(total)
+`
+
+func TestServer(t *testing.T) {
+	c, stop := newClient(t)
+	defer stop()
+
+	var init InitializeResult
+	c.call("initialize", map[string]interface{}{}, &init)
+	if !init.Capabilities.HoverProvider || !init.Capabilities.DefinitionProvider || init.Capabilities.CompletionProvider == nil {
+		t.Fatalf("wrong capabilities: %+v", init.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	uri := "file:///tmp/test.aero"
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, LanguageID: "origion", Text: testSource}})
+
+	//hover: the documentation of 'add', from its use on line 13
+	var hover Hover
+	c.call("textDocument/hover", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: 12, Character: 13}}, &hover)
+	if !strings.Contains(hover.Contents.Value, "function add") || !strings.Contains(hover.Contents.Value, "Add two numbers.") ||
+		!strings.Contains(hover.Contents.Value, "`a` *int* the first number") {
+		t.Errorf("wrong hover: %q", hover.Contents.Value)
+	}
+
+	//the diagnostics are published when the document is opened
+	if len(c.notifications) != 1 || c.notifications[0].Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("expected a publishDiagnostics notification, got=%v", c.notifications)
+	}
+	var diags PublishDiagnosticsParams
+	json.Unmarshal(c.notifications[0].Params, &diags)
+	if len(diags.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got=%v", diags.Diagnostics)
+	}
+
+	//definition
+	var loc Location
+	c.call("textDocument/definition", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: 12, Character: 13}}, &loc)
+	if loc.URI != uri || loc.Range.Start != (Position{Line: 3, Character: 3}) {
+		t.Errorf("wrong definition: %+v", loc)
+	}
+
+	//document symbols
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name)
+		for _, child := range s.Children {
+			names = append(names, s.Name+"."+child.Name)
+		}
+	}
+	if got := strings.Join(names, " "); got != "add Dog Dog.name Dog.bark total" {
+		t.Errorf("wrong symbols: %s", got)
+	}
+
+	//completion of a keyword, and of a stdlib module's methods
+	var list CompletionList
+	c.call("textDocument/completion", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: 13, Character: 6}}, &list)
+	if !hasLabel(list.Items, "println") || hasLabel(list.Items, "fn") {
+		t.Errorf("wrong completion after 'fmt.pr': %v", list.Items)
+	}
+	c.call("textDocument/completion", TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: 3, Character: 1}}, &list)
+	if !hasLabel(list.Items, "fn") {
+		t.Errorf("wrong completion after 'f': %v", list.Items)
+	}
+
+	//a syntax error
+	c.notifications = nil
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "lit x = )\n"}},
+	})
+	c.call("shutdown", nil, nil)
+	if len(c.notifications) != 1 {
+		t.Fatalf("expected a publishDiagnostics notification, got=%v", c.notifications)
+	}
+	json.Unmarshal(c.notifications[0].Params, &diags)
+	if len(diags.Diagnostics) == 0 || diags.Diagnostics[0].Range.Start.Line != 0 || diags.Diagnostics[0].Severity != SeverityError {
+		t.Errorf("wrong diagnostics: %+v", diags.Diagnostics)
+	}
+}
+
+func hasLabel(items []CompletionItem, label string) bool {
+	for _, item := range items {
+		if item.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseErrorResponse(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":`
+	in := strings.NewReader("Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body)
+	var out bytes.Buffer
+	if err := NewServer(in, &out).Run(); err != nil {
+		t.Fatalf("server returned error: %s", err)
+	}
+
+	response := out.String()
+	response = response[strings.Index(response, "\r\n\r\n")+4:]
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(response), &msg); err != nil {
+		t.Fatalf("invalid response %q: %s", response, err)
+	}
+	if id, ok := msg["id"]; !ok || string(id) != "null" {
+		t.Errorf("the response must have a null 'id', got=%s", response)
+	}
+	var rerr ResponseError
+	if err := json.Unmarshal(msg["error"], &rerr); err != nil || rerr.Code != ParseError {
+		t.Errorf("expected a parse error, got=%s", response)
+	}
+}
@@ -0,0 +1,153 @@
+// Package lsp implements the `origion lsp` subcommand, a Language Server
+// Protocol server over stdio.
+//
+// It provides the parse diagnostics, hover documentation, go-to-definition,
+// document symbols and completion of the opened '*.aero' documents.
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+)
+
+//Server is a language server, it handles the messages one at a time.
+type Server struct {
+	conn     *Conn
+	docs     map[string]*document //opened documents by URI
+	shutdown bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{conn: NewConn(in, out), docs: make(map[string]*document)}
+}
+
+//Run handles the messages until the 'exit' notification, or the end of the input.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.conn.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if rerr, ok := err.(*ResponseError); ok { //invalid JSON
+			s.conn.Reply(&nullID, nil, rerr)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rerr := s.handle(msg)
+		if msg.ID != nil { //requests need a response, notifications don't
+			s.conn.Reply(msg.ID, result, rerr)
+		}
+	}
+}
+
+func (s *Server) handle(msg *Message) (interface{}, *ResponseError) {
+	if s.shutdown && msg.ID != nil {
+		return nil, &ResponseError{Code: InvalidRequest, Message: "server is shut down"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		result := InitializeResult{Capabilities: ServerCapabilities{
+			TextDocumentSync:       1,
+			HoverProvider:          true,
+			DefinitionProvider:     true,
+			DocumentSymbolProvider: true,
+			CompletionProvider:     &CompletionOptions{TriggerCharacters: []string{"."}},
+		}}
+		result.ServerInfo.Name = "origion"
+		return result, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if rerr := decode(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		d := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+		s.docs[d.uri] = d
+		s.publishDiagnostics(d)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if rerr := decode(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		d, ok := s.docs[params.TextDocument.URI]
+		if !ok || len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		d.parse(params.ContentChanges[len(params.ContentChanges)-1].Text) //full text sync
+		s.publishDiagnostics(d)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if rerr := decode(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		delete(s.docs, params.TextDocument.URI)
+		s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+
+	case "textDocument/hover":
+		d, pos, rerr := s.position(msg)
+		if d == nil {
+			return nil, rerr
+		}
+		if text := d.hover(pos); text != "" {
+			return Hover{Contents: MarkupContent{Kind: "markdown", Value: text}}, nil
+		}
+	case "textDocument/definition":
+		d, pos, rerr := s.position(msg)
+		if d == nil {
+			return nil, rerr
+		}
+		if loc := d.definition(pos); loc != nil {
+			return loc, nil
+		}
+	case "textDocument/completion":
+		d, pos, rerr := s.position(msg)
+		if d == nil {
+			return nil, rerr
+		}
+		return CompletionList{Items: d.completion(pos)}, nil
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if rerr := decode(msg, &params); rerr != nil {
+			return nil, rerr
+		}
+		if d, ok := s.docs[params.TextDocument.URI]; ok {
+			return d.symbols(), nil
+		}
+
+	default:
+		if msg.ID != nil { //unknown notifications(e.g. '$/cancelRequest') are ignored
+			return nil, &ResponseError{Code: MethodNotFound, Message: "method not found: " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+//position decodes the params of a request on a position of a document.
+func (s *Server) position(msg *Message) (*document, Position, *ResponseError) {
+	var params TextDocumentPositionParams
+	if rerr := decode(msg, &params); rerr != nil {
+		return nil, Position{}, rerr
+	}
+	return s.docs[params.TextDocument.URI], params.Position, nil
+}
+
+func (s *Server) publishDiagnostics(d *document) {
+	s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: d.uri, Diagnostics: d.diagnostics})
+}
+
+func decode(msg *Message, v interface{}) *ResponseError {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &ResponseError{Code: InvalidParams, Message: err.Error()}
+	}
+	return nil
+}
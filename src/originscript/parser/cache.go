@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"originscript/ast"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//CacheSuffix is appended to the name of a source file to get its cache file,
+//e.g. 'main.aero.cache'.
+const CacheSuffix = ".cache"
+
+//cacheFormat must be changed when the encoding of the cache changes.
+const cacheFormat = "2"
+
+var (
+	//Version is the interpreter version, the cache files of an other version are ignored.
+	Version string
+
+	//NoCache disables the cache files, the sources are always parsed.
+	NoCache bool
+)
+
+//cacheFile is the content of a cache file: a text header with the key, a line
+//per imported file and a line per 'require', an empty line, then the program
+//encoded by the ast codec.
+type cacheFile struct {
+	Key      string            //interpreter version and hash of the source
+	Deps     map[string]string //the imported files and the hash of their source
+	Requires map[string]string //the required modules(see requireKey) and the file each one was found in
+	Program  *ast.Program
+}
+
+//requireKey returns the key of the module 'importpath' required by a file of the
+//directory 'dir'(an absolute path) in 'cacheFile.Requires'.
+func requireKey(dir string, importpath string) string {
+	return dir + "\t" + importpath
+}
+
+//ParseProgramCached is like ParseProgram, but if the cache file of 'filename'
+//was created from the same source and imports by the same interpreter, and
+//each required module is still found in the same file(e.g. a module installed
+//in 'opkg/' may now be found before the one in ORIGION_PATH), the program is
+//loaded from it. Otherwise, the parsed program is saved to it.
+//The cache is not used when parsing the comments, and the debugger information
+//(e.g. 'Functions') is not cached.
+func (p *Parser) ParseProgramCached(filename string, src []byte) *ast.Program {
+	if NoCache || p.mode&ParseComments != 0 {
+		return p.ParseProgram()
+	}
+
+	path := p.path
+	if path == "" {
+		path = "."
+	}
+	key := cacheKey(src)
+	if c := loadCache(filename, key, path); c != nil {
+		for dep, hash := range c.Deps {
+			p.deps[dep] = hash
+		}
+		for req, file := range c.Requires {
+			p.requires[req] = file
+		}
+		return c.Program
+	}
+
+	program := p.ParseProgram()
+	if len(p.errors) == 0 {
+		saveCache(filename, &cacheFile{Key: key, Deps: p.deps, Requires: p.requires, Program: program})
+	}
+	return program
+}
+
+//CleanCache removes the cache files in the directory 'dir' and its sub-directories,
+//returns the number of removed files. Only the files next to their source are removed.
+func CleanCache(dir string) (int, error) {
+	n := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, CacheSuffix) {
+			return nil
+		}
+		if src, err := os.Stat(strings.TrimSuffix(path, CacheSuffix)); err != nil || src.IsDir() {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+//cacheKey returns the key of the cache of the source 'src'. The command line
+//defines change the parsed program, they're part of the key.
+func cacheKey(src []byte) string {
+	var defines []string
+	for name, value := range Defines {
+		defines = append(defines, name+"="+value)
+	}
+	sort.Strings(defines)
+	definesHash := sourceHash([]byte(strings.Join(defines, "\n")))
+	return Version + "/" + cacheFormat + "/" + ast.CodecSchema + "/" + sourceHash(src) + "/" + definesHash[:16]
+}
+
+func sourceHash(src []byte) string {
+	sum := sha256.Sum256(src)
+	return hex.EncodeToString(sum[:])
+}
+
+//loadCache returns the cache of 'filename', or nil if it's missing or stale. The
+//required modules are searched(see SearchPath) from the working directory 'wd'.
+func loadCache(filename string, key string, wd string) (c *cacheFile) {
+	data, err := ioutil.ReadFile(filename + CacheSuffix)
+	if err != nil {
+		return nil
+	}
+
+	//a corrupted cache file is ignored
+	r := bufio.NewReader(bytes.NewReader(data))
+	line, err := r.ReadString('\n')
+	if err != nil || strings.TrimSuffix(line, "\n") != key {
+		return nil
+	}
+	c = &cacheFile{Key: key, Deps: make(map[string]string), Requires: make(map[string]string)}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "require\t") { //require<TAB>dir<TAB>importpath<TAB>file
+			fields := strings.Split(line, "\t")
+			if len(fields) != 4 {
+				return nil
+			}
+			c.Requires[requireKey(fields[1], fields[2])] = fields[3]
+			continue
+		}
+		idx := strings.IndexByte(line, '\t')
+		if idx < 0 {
+			return nil
+		}
+		c.Deps[line[idx+1:]] = line[:idx]
+	}
+	rest, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+	if c.Program, err = ast.DecodeProgram(rest); err != nil || c.Program == nil {
+		return nil
+	}
+
+	//the imported files must be unchanged too
+	for dep, hash := range c.Deps {
+		src, err := ioutil.ReadFile(dep)
+		if err != nil || sourceHash(src) != hash {
+			return nil
+		}
+	}
+
+	//and each required module must be found in the same file, the search path
+	//(e.g. ORIGION_PATH) or the files in its directories may have changed
+	for req, file := range c.Requires {
+		idx := strings.IndexByte(req, '\t')
+		fn, ok := FindModule(req[idx+1:], SearchPath(wd, req[:idx]))
+		if abs, err := filepath.Abs(fn); err == nil {
+			fn = abs
+		}
+		if !ok || fn != file {
+			return nil
+		}
+	}
+	return c
+}
+
+//saveCache writes the cache file of 'filename', the errors are ignored(e.g. a
+//read-only directory), the program is parsed again next time.
+func saveCache(filename string, c *cacheFile) {
+	program, err := ast.EncodeProgram(c.Program)
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString(c.Key + "\n")
+	for dep, hash := range c.Deps {
+		if strings.ContainsRune(dep, '\n') {
+			return
+		}
+		buf.WriteString(hash + "\t" + dep + "\n")
+	}
+	for req, file := range c.Requires {
+		if strings.ContainsRune(req+file, '\n') || strings.Count(req+file, "\t") != 1 {
+			return
+		}
+		buf.WriteString("require\t" + req + "\t" + file + "\n")
+	}
+	buf.WriteString("\n")
+	buf.Write(program)
+
+	//write a temporary file first, so the cache is never read half-written
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+CacheSuffix+"*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename+CacheSuffix)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"originscript/token"
+	"strconv"
+)
+
+//Defines are the macros defined for all the parsed files(e.g. by the '-D'
+//command line option), the value of a macro without value is "".
+var Defines = make(map[string]string)
+
+//newDefines returns the initial macros of a parser.
+func newDefines() map[string]string {
+	defines := make(map[string]string, len(Defines))
+	for name, value := range Defines {
+		defines[name] = value
+	}
+	return defines
+}
+
+//The condition of '#ifdef' is evaluated while parsing:
+//
+//    #ifdef DEBUG { ... }                           DEBUG is defined
+//    #ifdef !DEBUG && (A || B) { ... }              '!', '&&', '||' and parentheses
+//    #ifdef PLATFORM == "linux" { ... }             the value of a macro
+//    #ifdef LEVEL >= 2 { ... }                      compared as numbers if both are numbers
+//
+//The functions below start at the first token of their part of the condition,
+//stop at its last token, and return its value and its text(for 'ConditionStr').
+
+func (p *Parser) parseMacroCondition() (bool, string, bool) {
+	value, str, ok := p.parseMacroAnd()
+	for ok && (p.peekTokenIs(token.CONDOR) || p.peekTokenIs(token.OR)) {
+		p.nextToken()
+		op := p.curToken.Literal
+		p.nextToken()
+		right, rstr, rok := p.parseMacroAnd()
+		value, str, ok = value || right, str+" "+op+" "+rstr, rok
+	}
+	return value, str, ok
+}
+
+func (p *Parser) parseMacroAnd() (bool, string, bool) {
+	value, str, ok := p.parseMacroUnary()
+	for ok && (p.peekTokenIs(token.CONDAND) || p.peekTokenIs(token.AND)) {
+		p.nextToken()
+		op := p.curToken.Literal
+		p.nextToken()
+		right, rstr, rok := p.parseMacroUnary()
+		value, str, ok = value && right, str+" "+op+" "+rstr, rok
+	}
+	return value, str, ok
+}
+
+func (p *Parser) parseMacroUnary() (bool, string, bool) {
+	switch p.curToken.Type {
+	case token.BANG:
+		p.nextToken()
+		value, str, ok := p.parseMacroUnary()
+		return !value, "!" + str, ok
+	case token.LPAREN:
+		p.nextToken()
+		value, str, ok := p.parseMacroCondition()
+		if !ok || !p.expectPeek(token.RPAREN) {
+			return false, "", false
+		}
+		return value, "(" + str + ")", true
+	}
+
+	left, lstr, defined, ok := p.parseMacroOperand()
+	if !ok {
+		return false, "", false
+	}
+
+	var op string
+	switch p.peekToken.Type {
+	case token.EQ, token.NEQ, token.LT, token.LE, token.GT, token.GE:
+		p.nextToken()
+		op = p.curToken.Literal
+	default:
+		if p.curToken.Type != token.IDENT {
+			p.macroError("expected a macro name")
+			return false, "", false
+		}
+		return defined, lstr, true
+	}
+
+	p.nextToken()
+	right, rstr, _, ok := p.parseMacroOperand()
+	if !ok {
+		return false, "", false
+	}
+	return compareMacroValues(left, op, right), lstr + " " + op + " " + rstr, true
+}
+
+//parseMacroOperand returns the value of a macro name or a literal.
+func (p *Parser) parseMacroOperand() (value string, str string, defined bool, ok bool) {
+	switch p.curToken.Type {
+	case token.IDENT:
+		value, defined = p.defines[p.curToken.Literal]
+		return value, p.curToken.Literal, defined, true
+	case token.STRING:
+		return p.curToken.Literal, strconv.Quote(p.curToken.Literal), true, true
+	case token.INT, token.UINT, token.FLOAT, token.TRUE, token.FALSE:
+		return p.curToken.Literal, p.curToken.Literal, true, true
+	}
+	p.macroError("expected a macro name or value")
+	return "", "", false, false
+}
+
+func (p *Parser) macroError(expected string) {
+	pos := p.curToken.Pos
+	msg := fmt.Sprintf("OriginScript: e3301: %v- %s, got %s instead", pos, expected, p.curToken.Type)
+	p.errors = append(p.errors, msg)
+	p.errorPos = append(p.errorPos, pos)
+}
+
+//compareMacroValues compares two macro values, as numbers if both are numbers.
+func compareMacroValues(left string, op string, right string) bool {
+	l, lerr := strconv.ParseFloat(left, 64)
+	r, rerr := strconv.ParseFloat(right, 64)
+	if lerr == nil && rerr == nil {
+		switch op {
+		case "==":
+			return l == r
+		case "!=":
+			return l != r
+		case "<":
+			return l < r
+		case "<=":
+			return l <= r
+		case ">":
+			return l > r
+		}
+		return l >= r
+	}
+
+	switch op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	}
+	return left >= right
+}
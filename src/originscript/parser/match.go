@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"fmt"
+	"originscript/ast"
+	"originscript/token"
+)
+
+// match expr {
+//    (x, y) if x > y { block }         tuple, with a guard
+//    [first, ...rest] { block }        array, 'rest' is the remaining members
+//    {"name": n, age} { block }        hash, 'age' is short for "age": age
+//    Point(x: 0, y) { block }          instance of a class, by member or property
+//    int(n), string { block }          type patterns, 'int(n)' binds the value to 'n'
+//    Color.RED, 1, "a", nil { block }  values, compared with '=='
+//    x { block }                       binds any value to 'x', '_' ignores it
+//    else { block }
+// }
+func (p *Parser) parseMatchExpression() ast.Expression {
+	me := &ast.MatchExpr{Token: p.curToken}
+
+	p.nextToken()
+	me.Expr = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) {
+		if p.curTokenIs(token.EOF) {
+			p.matchError(me.Token.Pos, "no end symbol '}' found for match expression")
+			return nil
+		}
+
+		arm := &ast.MatchArm{Token: p.curToken}
+		if p.curTokenIs(token.ELSE) {
+			if !p.expectPeek(token.LBRACE) {
+				return nil
+			}
+		} else {
+			for {
+				pattern := p.parsePattern()
+				if pattern == nil {
+					return nil
+				}
+				arm.Patterns = append(arm.Patterns, pattern)
+				if !p.peekTokenIs(token.COMMA) {
+					break
+				}
+				p.nextToken()
+				p.nextToken()
+			}
+
+			if p.peekTokenIs(token.IF) {
+				p.nextToken()
+				p.nextToken()
+				arm.Guard = p.parseExpression(LOWEST)
+			}
+			if !p.expectPeek(token.LBRACE) {
+				return nil
+			}
+		}
+
+		arm.Block = p.parseBlockStatement()
+		me.Arms = append(me.Arms, arm)
+		p.nextToken() //skip the '}'
+	}
+	me.RBraceToken = p.curToken
+
+	return me
+}
+
+//parsePattern parses the pattern starting at the current token, it stops at
+//the last token of the pattern.
+func (p *Parser) parsePattern() ast.Expression {
+	switch p.curToken.Type {
+	case token.UNDERSCORE:
+		return &ast.IdentPattern{Token: p.curToken, Var: "_"}
+	case token.IDENT:
+		if p.peekTokenIs(token.LPAREN) {
+			return p.parseTypePattern()
+		}
+		if !p.peekTokenIs(token.DOT) { //'Color.RED' is a value
+			return &ast.IdentPattern{Token: p.curToken, Var: p.curToken.Literal}
+		}
+	case token.LPAREN:
+		return p.parseTuplePattern()
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+
+	vp := &ast.ValuePattern{Token: p.curToken}
+	vp.Value = p.parseExpression(LOWEST)
+	if vp.Value == nil {
+		return nil
+	}
+	return vp
+}
+
+//parsePatterns parses the patterns separated by ',' until the 'end' token,
+//the current token is the one before the first pattern.
+func (p *Parser) parsePatterns(end token.TokenType) ([]ast.Expression, bool) {
+	patterns := []ast.Expression{}
+	for !p.peekTokenIs(end) {
+		p.nextToken()
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return nil, false
+		}
+		patterns = append(patterns, pattern)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	return patterns, p.expectPeek(end)
+}
+
+//(pattern, pattern), '(pattern)' is the pattern itself, '(pattern,)' is a tuple.
+func (p *Parser) parseTuplePattern() ast.Expression {
+	tp := &ast.TuplePattern{Token: p.curToken}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		tp.Members, tp.RParenToken = []ast.Expression{}, p.curToken
+		return tp
+	}
+
+	p.nextToken()
+	first := p.parsePattern()
+	if first == nil {
+		return nil
+	}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return first
+	}
+	if !p.expectPeek(token.COMMA) {
+		return nil
+	}
+
+	rest, ok := p.parsePatterns(token.RPAREN)
+	if !ok {
+		return nil
+	}
+	tp.Members, tp.RParenToken = append([]ast.Expression{first}, rest...), p.curToken
+	return tp
+}
+
+//[pattern, pattern, ...rest]
+func (p *Parser) parseArrayPattern() ast.Expression {
+	ap := &ast.ArrayPattern{Token: p.curToken, Members: []ast.Expression{}}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		if p.curTokenIs(token.ELLIPSIS) {
+			ap.HasRest = true
+			if p.peekTokenIs(token.IDENT) {
+				p.nextToken()
+				ap.Var = p.curToken.Literal
+			}
+			break
+		}
+
+		pattern := p.parsePattern()
+		if pattern == nil {
+			return nil
+		}
+		ap.Members = append(ap.Members, pattern)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	ap.RBracketToken = p.curToken
+	return ap
+}
+
+//{key: pattern, name}, the keys are strings, numbers, or bare words.
+func (p *Parser) parseHashPattern() ast.Expression {
+	hp := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		var key ast.Expression
+		switch p.curToken.Type {
+		case token.IDENT, token.STRING:
+			key = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+		case token.INT, token.UINT, token.TRUE, token.FALSE:
+			key = p.prefixParseFns[p.curToken.Type]() //not 'parseExpression', the ':' would be parsed
+		default:
+			p.matchError(p.curToken.Pos, fmt.Sprintf("expected a hash key in the pattern, got %s instead", p.curToken.Type))
+			return nil
+		}
+
+		var value ast.Expression
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+			if value = p.parsePattern(); value == nil {
+				return nil
+			}
+		} else if p.curTokenIs(token.IDENT) {
+			value = &ast.IdentPattern{Token: p.curToken, Var: p.curToken.Literal}
+		} else {
+			p.peekError(token.COLON)
+			return nil
+		}
+		hp.Keys = append(hp.Keys, key)
+		hp.Values = append(hp.Values, value)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	hp.RBraceToken = p.curToken
+	return hp
+}
+
+//Type(pattern), or Class(name: pattern, name)
+func (p *Parser) parseTypePattern() ast.Expression {
+	tp := &ast.TypePattern{Token: p.curToken, Type: p.curToken.Literal, Names: []string{}, Patterns: []ast.Expression{}}
+	p.nextToken() //'('
+
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) { //name: pattern
+			name := p.curToken.Literal
+			p.nextToken()
+			p.nextToken()
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil
+			}
+			tp.Names = append(tp.Names, name)
+			tp.Patterns = append(tp.Patterns, pattern)
+		} else {
+			pattern := p.parsePattern()
+			if pattern == nil {
+				return nil
+			}
+			if ident, ok := pattern.(*ast.IdentPattern); ok && ident.Var != "_" {
+				tp.Names = append(tp.Names, ident.Var) //'name' is 'name: name' for a class
+			} else {
+				tp.Names = append(tp.Names, "")
+			}
+			tp.Patterns = append(tp.Patterns, pattern)
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	tp.RParenToken = p.curToken
+	return tp
+}
+
+func (p *Parser) matchError(pos token.Position, msg string) {
+	msg = fmt.Sprintf("OriginScript: e3301: %v- %s", pos, msg)
+	p.errors = append(p.errors, msg)
+	p.errorPos = append(p.errorPos, pos)
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//The module search path, 'require net.util' loads 'net/util.aero' from the
+//first of these directories having it:
+//
+//    1. the working directory of the parser
+//    2. the directory of the file requiring the module
+//    3. the 'path=' directories of the project's origion.pack, then its 'opkg'
+//       directory(the installed packages). The project is the nearest directory
+//       with an origion.pack, from the file's directory up.
+//    4. the directories of ORIGION_PATH, separated by ':'(';' on Windows)
+//    5. './aerolibs'
+const (
+	//PackFile is the file of a project, it lists the installed packages and
+	//the project's settings.
+	PackFile = "origion.pack"
+
+	//PackDir is the directory of the installed packages, next to the PackFile.
+	PackDir = "opkg"
+
+	//PackPathPrefix starts the PackFile lines adding a directory to the module
+	//search path, e.g. 'path=lib', relative to the project's directory.
+	PackPathPrefix = "path="
+
+	//PathEnv is the environment variable adding directories to the module search path.
+	PathEnv = "ORIGION_PATH"
+)
+
+//SearchPath returns the directories searched for the modules required by a
+//file of the directory 'dir', 'wd' is the working directory of the parser.
+func SearchPath(wd string, dir string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(d string) {
+		if d == "" {
+			return
+		}
+		d = filepath.Clean(d)
+		if !seen[d] {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+
+	add(wd)
+	add(dir)
+	root := projectRoot(dir)
+	if root == "" {
+		root = projectRoot(wd)
+	}
+	if root != "" {
+		for _, d := range PackPaths(filepath.Join(root, PackFile)) {
+			if !filepath.IsAbs(d) {
+				d = filepath.Join(root, d)
+			}
+			add(d)
+		}
+		add(filepath.Join(root, PackDir))
+	}
+	for _, d := range filepath.SplitList(os.Getenv(PathEnv)) {
+		add(d)
+	}
+	add("aerolibs")
+	return dirs
+}
+
+//FindModule returns the file of the module 'importpath'(e.g. 'net/util') in
+//the directories 'dirs', or false if none of them has it.
+func FindModule(importpath string, dirs []string) (string, bool) {
+	for _, d := range dirs {
+		fn := filepath.Join(d, importpath+".aero")
+		if info, err := os.Stat(fn); err == nil && !info.IsDir() {
+			return fn, true
+		}
+	}
+	return "", false
+}
+
+//PackPaths returns the directories of the 'path=' lines of the pack file, the
+//other lines are the installed packages.
+func PackPaths(file string) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var dirs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, PackPathPrefix) {
+			if d := strings.TrimSpace(strings.TrimPrefix(line, PackPathPrefix)); d != "" {
+				dirs = append(dirs, filepath.FromSlash(d))
+			}
+		}
+	}
+	return dirs
+}
+
+//projectRoot returns the nearest directory with a pack file, from 'dir' up,
+//or "" if there's none.
+func projectRoot(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, PackFile)); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"originscript/ast"
 	"originscript/lexer"
 	"originscript/token"
-	//"os"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -163,10 +163,11 @@ type Parser struct {
 	// Comments
 	comments    []*ast.CommentGroup
 	lineComment *ast.CommentGroup // last line comment
+	peekComment *ast.CommentGroup // line comment before the peek token
 
 	l          *lexer.Lexer
 	errors     []string //error messages
-	errorLines []string
+	errorPos   []token.Position //where the errors are, in the order of 'errors'
 	path       string
 
 	curToken  token.Token
@@ -180,8 +181,29 @@ type Parser struct {
 	//for debugger use
 	Functions map[string]*ast.FunctionLiteral
 
-	//macro defines
-	defines map[string]bool
+	//macro defines, the name and the value
+	defines map[string]string
+
+	//the imported files and the hash of their source, for the cache
+	deps map[string]string
+
+	//the required modules and the files they were found in, for the cache(see requireKey)
+	requires map[string]string
+
+	//the files requiring the file being parsed, to detect the import cycles
+	importing []string
+}
+
+//BuiltinClasses are the classes defined by the interpreter(e.g. 'Exception'),
+//'new' can be followed by them without declaring them.
+var BuiltinClasses = make(map[string]bool)
+
+func newClassMap() map[string]bool {
+	classMap := make(map[string]bool, len(BuiltinClasses))
+	for name := range BuiltinClasses {
+		classMap[name] = true
+	}
+	return classMap
 }
 
 type (
@@ -201,15 +223,17 @@ func NewWithDoc(l *lexer.Lexer, wd string) *Parser {
 	p := &Parser{
 		l:          l,
 		errors:     []string{},
-		errorLines: []string{},
+		errorPos:   []token.Position{},
 		path:       wd,
 		mode:       ParseComments,
 	}
 	p.l.SetMode(lexer.ScanComments)
 
-	p.classMap = make(map[string]bool)
+	p.classMap = newClassMap()
 	p.Functions = make(map[string]*ast.FunctionLiteral)
-	p.defines = make(map[string]bool)
+	p.defines = newDefines()
+	p.deps = make(map[string]string)
+	p.requires = make(map[string]string)
 
 	p.registerAction()
 	p.nextToken()
@@ -221,13 +245,15 @@ func New(l *lexer.Lexer, wd string) *Parser {
 	p := &Parser{
 		l:          l,
 		errors:     []string{},
-		errorLines: []string{},
+		errorPos:   []token.Position{},
 		path:       wd,
 	}
 
-	p.classMap = make(map[string]bool)
+	p.classMap = newClassMap()
 	p.Functions = make(map[string]*ast.FunctionLiteral)
-	p.defines = make(map[string]bool)
+	p.defines = newDefines()
+	p.deps = make(map[string]string)
+	p.requires = make(map[string]string)
 
 	p.registerAction()
 	p.nextToken()
@@ -256,6 +282,7 @@ func (p *Parser) registerAction() {
 	p.registerPrefix(token.GREP, p.parseGrepExpression)
 	p.registerPrefix(token.MAP, p.parseMapExpression)
 	p.registerPrefix(token.CASE, p.parseCaseExpression)
+	p.registerPrefix(token.PMATCH, p.parseMatchExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteralExpression)
 	p.registerPrefix(token.REGEX, p.parseRegExLiteralExpression)
 	p.registerPrefix(token.LBRACKET, p.parseArrayExpression)
@@ -409,21 +436,19 @@ func (p *Parser) ParseProgram() *ast.Program {
 	}
 
 	for p.curToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		var stmt ast.Statement
+		if p.curTokenIs(token.EXPORT) {
+			stmt = p.parseExportStatement(program)
+		} else {
+			stmt = p.parseStatement()
+		}
 		if stmt != nil {
 			if len(p.errors) > 0 {
 				p.synchronize()
 			}
 
 			if importStmt, ok := stmt.(*ast.ImportStatement); ok {
-				importPath := strings.TrimSpace(importStmt.ImportPath)
-				_, ok := program.Imports[importPath]
-				if !ok {
-					for k, funcLiteral := range importStmt.Functions {
-						p.Functions[k] = funcLiteral
-					} //for debugger
-					program.Imports[importPath] = importStmt
-				}
+				p.addImport(program, importStmt)
 			} else {
 				program.Statements = append(program.Statements, stmt)
 			}
@@ -446,6 +471,8 @@ func (p *Parser) ParseProgram() *ast.Program {
 			DebugInfos = append(DebugInfos, n)
 		case *ast.EnumStatement:
 			DebugInfos = append(DebugInfos, n)
+		case *ast.InterfaceStatement:
+			DebugInfos = append(DebugInfos, n)
 		case *ast.IfExpression:
 			DebugInfos = append(DebugInfos, n)
 		case *ast.IfMacroStatement:
@@ -454,6 +481,8 @@ func (p *Parser) ParseProgram() *ast.Program {
 			DebugInfos = append(DebugInfos, n)
 		case *ast.CaseExpr:
 			DebugInfos = append(DebugInfos, n)
+		case *ast.MatchExpr:
+			DebugInfos = append(DebugInfos, n)
 		case *ast.DoLoop:
 			DebugInfos = append(DebugInfos, n)
 		case *ast.WhileLoop:
@@ -505,6 +534,11 @@ func (p *Parser) parseStatement() ast.Statement {
 		ret = p.parseSpawnStatement()
 	case token.IMPORT:
 		return p.parseImportStatement()
+	case token.EXPORT:
+		msg := fmt.Sprintf("OriginScript: e3301: %v- 'export' is only allowed at the top level of a file.", p.curToken.Pos)
+		p.errors = append(p.errors, msg)
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
+		return nil
 	case token.TRY:
 		return p.parseTryStatement()
 	case token.THROW:
@@ -524,6 +558,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseFunctionStatement()
 	case token.CLASS:
 		return p.parseClassStatement()
+	case token.INTERFACE:
+		return p.parseInterfaceStatement()
 	case token.SERVICE:
 		return p.parseServiceStatement()
 	case token.ENUM:
@@ -599,7 +635,7 @@ func (p *Parser) parseClassStatement() *ast.ClassStatement {
 			pos := p.fixPosCol()
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Class's category should be followed by an identifier or a ')', got %s instead.", pos, p.peekToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, pos.Sline())
+			p.errorPos = append(p.errorPos, pos)
 			return nil
 		}
 	}
@@ -772,7 +808,7 @@ func (p *Parser) parseTupleExpression(tok token.Token, expr ast.Expression) ast.
 			oldToken.Pos.Col = oldToken.Pos.Col + len(oldToken.Literal)
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be ',' or ')', got %s instead", oldToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, oldToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, oldToken.Pos)
 			return nil
 		}
 	}
@@ -784,19 +820,13 @@ func (p *Parser) parseTryStatement() ast.Statement {
 	p.nextToken()
 	tryStmt.Try = p.parseBlockStatement()
 
-	if p.peekTokenIs(token.CATCH) {
+	for p.peekTokenIs(token.CATCH) {
 		p.nextToken() //skip '}'
-
-		if p.peekTokenIs(token.IDENT) {
-			p.nextToken()
-			tryStmt.Var = p.curToken.Literal
-		}
-
-		if !p.expectPeek(token.LBRACE) {
+		catch := p.parseCatchClause()
+		if catch == nil {
 			return nil
 		}
-
-		tryStmt.Catch = p.parseBlockStatement()
+		tryStmt.Catches = append(tryStmt.Catches, catch)
 	}
 
 	if p.peekTokenIs(token.FINALLY) {
@@ -811,12 +841,59 @@ func (p *Parser) parseTryStatement() ast.Statement {
 	return tryStmt
 }
 
+//catch { block }
+//catch e { block }
+//catch (e) { block }
+//catch (e: Type1 | Type2) { block }
+func (p *Parser) parseCatchClause() *ast.CatchClause {
+	catch := &ast.CatchClause{Token: p.curToken}
+
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		catch.Var = p.curToken.Literal
+	} else if p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		catch.Var = p.curToken.Literal
+
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			for {
+				if !p.expectPeek(token.IDENT) {
+					return nil
+				}
+				catch.Types = append(catch.Types, p.curToken.Literal)
+				if !p.peekTokenIs(token.BITOR) {
+					break
+				}
+				p.nextToken()
+			}
+		}
+
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	catch.Block = p.parseBlockStatement()
+
+	return catch
+}
+
 func (p *Parser) parseThrowStatement() *ast.ThrowStmt {
 	stmt := &ast.ThrowStmt{Token: p.curToken}
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 		return stmt
 	}
+	if p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) { //rethrow, e.g. 'catch e { log(e); throw }'
+		return stmt
+	}
 	p.nextToken()
 	stmt.Expr = p.parseExpressionStatement().Expression
 
@@ -888,7 +965,7 @@ func (p *Parser) parseDeferStatement() *ast.DeferStmt {
 func (p *Parser) parseBreakWithoutLoopContext() ast.Expression {
 	msg := fmt.Sprintf("OriginScript: e3301: %v- 'break' outside of loop context", p.curToken.Pos)
 	p.errors = append(p.errors, msg)
-	p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+	p.errorPos = append(p.errorPos, p.curToken.Pos)
 
 	return p.parseBreakExpression()
 }
@@ -900,7 +977,7 @@ func (p *Parser) parseBreakExpression() ast.Expression {
 func (p *Parser) parseContinueWithoutLoopContext() ast.Expression {
 	msg := fmt.Sprintf("OriginScript: e3301: %v- 'continue' outside of loop context", p.curToken.Pos)
 	p.errors = append(p.errors, msg)
-	p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+	p.errorPos = append(p.errorPos, p.curToken.Pos)
 
 	return p.parseContinueExpression()
 }
@@ -937,12 +1014,23 @@ func (p *Parser) parseLetStatement(inClass bool, nextFlag bool) *ast.LetStatemen
 		if !p.curTokenIs(token.IDENT) && !p.curTokenIs(token.UNDERSCORE) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be identifier|underscore, got %s instead.", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return stmt
 		}
 		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		stmt.Names = append(stmt.Names, name)
 
+		t, ok := p.parseTypeAnnotation() //e.g. 'lit name: string'
+		if !ok {
+			return stmt
+		}
+		if t != nil {
+			if stmt.Types == nil {
+				stmt.Types = make(map[string]*ast.TypeExpr)
+			}
+			stmt.Types[name.Value] = t
+		}
+
 		if !p.peekTokenIs(token.ASSIGN) && !p.curTokenIs(token.SEMICOLON) && !p.peekTokenIs(token.COMMA) {
 			if p.peekTokenIs(token.SEMICOLON) {
 				p.nextToken()
@@ -959,7 +1047,7 @@ func (p *Parser) parseLetStatement(inClass bool, nextFlag bool) *ast.LetStatemen
 		if !p.curTokenIs(token.COMMA) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be comma, got %s instead.", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return stmt
 		}
 	}
@@ -1021,7 +1109,7 @@ func (p *Parser) parseLetStatement2(stmt *ast.LetStatement) *ast.LetStatement {
 		if !p.curTokenIs(token.IDENT) && !p.curTokenIs(token.UNDERSCORE) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be identifier|underscore, got %s instead.", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return stmt
 		}
 		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -1038,7 +1126,7 @@ func (p *Parser) parseLetStatement2(stmt *ast.LetStatement) *ast.LetStatement {
 	if !p.curTokenIs(token.ASSIGN) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be '=', got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return stmt
 	}
 
@@ -1079,7 +1167,7 @@ func (p *Parser) parseConstStatement() *ast.ConstStatement {
 			if !p.peekTokenIs(token.ASSIGN) && !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RPAREN) {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Token %s not allowed here.", p.peekToken.Pos, p.peekToken.Type)
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.peekToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.peekToken.Pos)
 				return nil
 			}
 
@@ -1108,7 +1196,7 @@ func (p *Parser) parseConstStatement() *ast.ConstStatement {
 			if _, ok := idPair[str_id]; ok { //is identifier redeclared?
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Identifier %s redeclared.", p.curToken.Pos, str_id)
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return nil
 			} else {
 				idPair[str_id] = value
@@ -1176,7 +1264,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 		pos := oldToken.Pos
 		msg := fmt.Sprintf("OriginScript: e3301: %v- no end symbol '}' found for block statement.", pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 	}
 
 	expression.RBraceToken = p.curToken
@@ -1210,7 +1298,7 @@ func (p *Parser) parseAssignExpression(name ast.Expression) ast.Expression {
 		if v.Right == nil {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- No right part of infix-expression", p.curToken.Pos)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 	}
@@ -1218,6 +1306,9 @@ func (p *Parser) parseAssignExpression(name ast.Expression) ast.Expression {
 	return e
 }
 
+//require net.util
+//require net.util as nutil
+//require util (parse, format)
 func (p *Parser) parseImportStatement() *ast.ImportStatement {
 	stmt := &ast.ImportStatement{Token: p.curToken}
 
@@ -1231,43 +1322,123 @@ func (p *Parser) parseImportStatement() *ast.ImportStatement {
 		p.nextToken()
 		paths = append(paths, p.curToken.Literal)
 	}
+	stmt.EndToken = p.curToken
 
 	path := strings.TrimSpace(strings.Join(paths, "/"))
+	stmt.Path = path
 	stmt.ImportPath = filepath.Base(path)
 
-	program, funcs, err := p.getImportedStatements(path)
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "as" {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return stmt
+		}
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		stmt.ImportPath = stmt.Alias.Value
+		stmt.EndToken = p.curToken
+	} else if p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		for {
+			if !p.expectPeek(token.IDENT) {
+				return stmt
+			}
+			stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+			if !p.peekTokenIs(token.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+		if !p.expectPeek(token.RPAREN) {
+			return stmt
+		}
+		stmt.EndToken = p.curToken
+	}
+
+	program, funcs, file, err := p.getImportedStatements(path)
 	if err != nil {
 		p.errors = append(p.errors, err.Error())
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return stmt
 	}
 	stmt.Functions = funcs
 	stmt.Program = program
+	stmt.File = file
 	//fmt.Println(stmt)
 	return stmt
 }
 
-func (p *Parser) getImportedStatements(importpath string) (*ast.Program, map[string]*ast.FunctionLiteral, error) {
+//addImport adds the import statement to the program, a module required twice
+//is imported once. Two modules can't be bound to the same name.
+func (p *Parser) addImport(program *ast.Program, importStmt *ast.ImportStatement) {
+	key := strings.TrimSpace(importStmt.ImportPath)
+	if len(importStmt.Names) > 0 { //the module itself is not bound
+		key = importStmt.String()
+	}
+	if prev, ok := program.Imports[key]; ok {
+		if prev.File != importStmt.File && len(importStmt.Names) == 0 {
+			msg := fmt.Sprintf("OriginScript: e3211: %v- '%s' is already the name of the module '%s' required at line %d, use 'require %s as name'.",
+				importStmt.Pos(), key, strings.Replace(prev.Path, "/", ".", -1), prev.Pos().Line, strings.Replace(importStmt.Path, "/", ".", -1))
+			p.errors = append(p.errors, msg)
+			p.errorPos = append(p.errorPos, importStmt.Pos())
+		}
+		return
+	}
+	for k, funcLiteral := range importStmt.Functions {
+		p.Functions[k] = funcLiteral
+	} //for debugger
+	program.Imports[key] = importStmt
+}
+
+//export lit name = value
+//export fn name(parameters) { block }
+//export class|enum|interface|const ...
+func (p *Parser) parseExportStatement(program *ast.Program) ast.Statement {
+	exportToken := p.curToken
+	p.nextToken()
+	stmt := p.parseStatement()
+	if stmt == nil {
+		return nil
+	}
+	if ast.DeclaredNames(stmt) == nil {
+		msg := fmt.Sprintf("OriginScript: e3301: %v- 'export' must be followed by a declaration(lit, const, fn, class, enum or interface).", exportToken.Pos)
+		p.errors = append(p.errors, msg)
+		p.errorPos = append(p.errorPos, exportToken.Pos)
+		return stmt
+	}
+	program.Exports = append(program.Exports, stmt)
+	return stmt
+}
+
+//getImportedStatements parses the module 'importpath', returns the program, its
+//functions(for the debugger) and the file it was loaded from.
+func (p *Parser) getImportedStatements(importpath string) (*ast.Program, map[string]*ast.FunctionLiteral, string, error) {
 	path := p.path
 
 	if path == "" {
 		path = "."
 	}
 
-	fn := filepath.Join(path, importpath+".aero")
+	//see SearchPath for the directories of the modules
+	dir := filepath.Dir(p.curToken.Pos.Filename)
+	dirs := SearchPath(path, dir)
+	fn, ok := FindModule(importpath, dirs)
+	if !ok {
+		return nil, nil, "", fmt.Errorf("OriginScript: e3209: %v- no file or directory: %s.aero, searched: %s", p.curToken.Pos, importpath, strings.Join(dirs, ", "))
+	}
 	f, err := ioutil.ReadFile(fn)
-	if err != nil { //error occurred, maybe the file do not exists.
-		// Check for 'MAGPIE_ROOT' environment variable
-		importRoot :=  "./aerolibs" //os.Getenv("MAGPIE_ROOT")
-		if len(importRoot) == 0 { //'MAGPIE_ROOT' environment variable is not set
-			return nil, nil, fmt.Errorf("OriginScript: e3209: %v- no file or directory: %s.aero, %s", p.curToken.Pos, importpath, path)
-		} else {
-			fn = filepath.Join(importRoot, importpath+".aero")
-			e, err := ioutil.ReadFile(fn)
-			if err != nil {
-				return nil, nil, fmt.Errorf("OriginScript: e3209: %v- no file or directory: %s.aero, %s", p.curToken.Pos, importpath, importRoot)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("OriginScript: e3209: %v- %s", p.curToken.Pos, err.Error())
+	}
+
+	//the module must not require(directly or not) the file requiring it
+	importing := append(append([]string{}, p.importing...), p.curToken.Pos.Filename)
+	for i, file := range importing {
+		if sameFile(file, fn) {
+			var cycle []string
+			for _, file := range append(importing[i:], fn) {
+				cycle = append(cycle, relPath(file))
 			}
-			f = e
+			return nil, nil, "", fmt.Errorf("OriginScript: e3210: %v- import cycle: %s", p.curToken.Pos, strings.Join(cycle, " -> "))
 		}
 	}
 
@@ -1278,12 +1449,52 @@ func (p *Parser) getImportedStatements(importpath string) (*ast.Program, map[str
 	} else {
 		ps = NewWithDoc(l, path)
 	}
-	parsed := ps.ParseProgram()
+	ps.importing = importing
+	parsed := ps.ParseProgramCached(fn, f)
 	if len(ps.errors) != 0 {
 		p.errors = append(p.errors, ps.errors...)
-		p.errorLines = append(p.errorLines, ps.errorLines...)
+		p.errorPos = append(p.errorPos, ps.errorPos...)
+	}
+	p.deps[fn] = sourceHash(f)
+	for dep, hash := range ps.deps {
+		p.deps[dep] = hash
 	}
-	return parsed, ps.Functions, nil
+	for req, file := range ps.requires {
+		p.requires[req] = file
+	}
+	if abs, err := filepath.Abs(fn); err == nil {
+		fn = abs
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	p.requires[requireKey(dir, importpath)] = fn
+	return parsed, ps.Functions, fn, nil
+}
+
+//relPath returns 'path' relative to the working directory if it's inside it.
+func relPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil || !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+//sameFile reports if the paths 'a' and 'b' are the same file.
+func sameFile(a string, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	fb, err := os.Stat(b)
+	return err == nil && os.SameFile(fa, fb)
 }
 
 func (p *Parser) parseDoLoopExpression() ast.Expression {
@@ -1324,7 +1535,7 @@ func (p *Parser) parseWhileLoopExpression() ast.Expression {
 	} else {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- for loop must be followed by a '{' or '=>'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -1399,7 +1610,7 @@ func (p *Parser) parseCForLoopExpression(curToken token.Token) ast.Expression {
 	if !p.peekTokenIs(token.LBRACE) && !p.peekTokenIs(token.FATARROW) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- for loop must be followed by a '{' or '=>'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -1419,7 +1630,7 @@ func (p *Parser) parseCForLoopExpression(curToken token.Token) ast.Expression {
 		} else {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Never end loop must use block statment.", p.curToken.Pos)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 		result = loop
@@ -1487,7 +1698,7 @@ func (p *Parser) parseForEachArrayOrRangeExpression(curToken token.Token, variab
 	} else {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- for loop must be followed by a '{' or '=>'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -1544,7 +1755,7 @@ func (p *Parser) parseForEachMapExpression(curToken token.Token, variable string
 	} else {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- for loop must be followed by a '{' or '=>'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -1598,7 +1809,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	if err != nil {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- could not parse %q as integer", p.curToken.Pos, p.curToken.Literal)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 	}
 	lit.Value = value
 	return lit
@@ -1624,7 +1835,7 @@ func (p *Parser) parseUIntegerLiteral() ast.Expression {
 	if err != nil {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- could not parse %q as unsigned integer", p.curToken.Pos, p.curToken.Literal)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 	}
 	lit.Value = value
 	return lit
@@ -1638,7 +1849,7 @@ func (p *Parser) parseFloatLiteral() ast.Expression {
 	if err != nil {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- could not parse %q as float", p.curToken.Pos, p.curToken.Literal)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 	}
 	lit.Value = value
 	return lit
@@ -1725,21 +1936,17 @@ func (p *Parser) parseMapExprExpression(tok token.Token) ast.Expression {
 	return me
 }
 
-// #ifdef xxx { block-statements } #else { block-statements }
+// #ifdef condition { block-statements } #else { block-statements }
+//(see macro.go for the condition)
 func (p *Parser) parseIfMacroStatement() *ast.IfMacroStatement {
 	stmt := &ast.IfMacroStatement{Token: p.curToken}
 
-	if !p.expectPeek(token.IDENT) { //macro name
-		pos := p.fixPosCol()
-		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be 'IDENT', got %s instead", pos, p.peekToken.Type)
-		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+	p.nextToken()
+	var ok bool
+	if stmt.Condition, stmt.ConditionStr, ok = p.parseMacroCondition(); !ok {
 		return nil
 	}
 
-	stmt.ConditionStr = p.curToken.Literal
-	_, stmt.Condition = p.defines[p.curToken.Literal]
-
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
@@ -1778,7 +1985,7 @@ func (p *Parser) parseConditionalExpressions(ie *ast.IfExpression) []*ast.IfCond
 				} else {
 					msg := fmt.Sprintf("OriginScript: e3301: %v- 'else' part must be followed by a '{'.", p.curToken.Pos)
 					p.errors = append(p.errors, msg)
-					p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+					p.errorPos = append(p.errorPos, p.curToken.Pos)
 					return nil
 				}
 				break
@@ -1807,7 +2014,7 @@ func (p *Parser) parseConditionalExpression() *ast.IfConditionExpr {
 	if !p.peekTokenIs(token.LBRACE) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- 'if' expression must be followed by a '{'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	} else {
 		p.nextToken()
@@ -1887,7 +2094,7 @@ func (p *Parser) parseIndexExpression(arr ast.Expression) ast.Expression {
 		pos := p.fixPosCol()
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be ']', got %s instead", pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 	}
 
 	return indexExp
@@ -1970,7 +2177,7 @@ func (p *Parser) parseHashExpression() ast.Expression {
 		pos := p.fixPosCol()
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be ':', got %s instead", pos, p.peekToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 	}
 
 	return nil
@@ -2130,7 +2337,7 @@ func (p *Parser) parseStructExpression() ast.Expression {
 	}
 	for !p.curTokenIs(token.RBRACE) {
 		p.nextToken()
-		key := p.parseExpression(LOWEST)
+		key := p.parseExpression(FATARROW) //not 'LOWEST', or 'a=>15' would be a short function
 		if !p.expectPeek(token.FATARROW) {
 			return nil
 		}
@@ -2339,7 +2546,7 @@ func (p *Parser) parseCaseExpression() ast.Expression {
 			if !p.curTokenIs(token.LBRACE) {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be '{', got %s instead", p.curToken.Pos, p.curToken.Type)
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 			}
 
 			aMatchBlock := p.parseBlockStatement()
@@ -2398,6 +2605,12 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 	p.parseFuncExpressionArray(fn, token.RPAREN)
 
+	if p.peekTokenIs(token.THINARROW) { //return type, e.g. 'fn add(a, b) -> int'
+		p.nextToken()
+		p.nextToken()
+		fn.ReturnType = p.parseType()
+	}
+
 	if p.expectPeek(token.LBRACE) {
 		fn.Body = p.parseBlockStatement()
 	}
@@ -2416,13 +2629,24 @@ func (p *Parser) parseFuncExpressionArray(fn *ast.FunctionLiteral, closure token
 		if !p.curTokenIs(token.IDENT) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Function parameter not identifier, GOT(%s)!", p.curToken.Pos, p.curToken.Literal)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return
 		}
 		key := p.curToken.Literal
 		name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		fn.Parameters = append(fn.Parameters, name)
 
+		t, ok := p.parseTypeAnnotation() //e.g. 'a: int'
+		if !ok {
+			return
+		}
+		if t != nil {
+			if fn.ParamTypes == nil {
+				fn.ParamTypes = make(map[string]*ast.TypeExpr)
+			}
+			fn.ParamTypes[key] = t
+		}
+
 		if p.peekTokenIs(token.ASSIGN) {
 			hasDefParamValue = true
 			p.nextToken()
@@ -2437,7 +2661,7 @@ func (p *Parser) parseFuncExpressionArray(fn *ast.FunctionLiteral, closure token
 			if hasDefParamValue && !fn.Variadic {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Function's default parameter order not correct!", p.curToken.Pos.Sline())
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return
 			}
 		}
@@ -2446,7 +2670,7 @@ func (p *Parser) parseFuncExpressionArray(fn *ast.FunctionLiteral, closure token
 			if fn.Variadic {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Variadic argument in function should be last!", p.curToken.Pos.Sline())
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return
 			}
 			p.nextToken()
@@ -2456,7 +2680,7 @@ func (p *Parser) parseFuncExpressionArray(fn *ast.FunctionLiteral, closure token
 			if fn.Variadic {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Only 1 variadic argument is allowed in function!", p.curToken.Pos.Sline())
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return
 			}
 			fn.Variadic = true
@@ -2465,7 +2689,7 @@ func (p *Parser) parseFuncExpressionArray(fn *ast.FunctionLiteral, closure token
 			if !p.peekTokenIs(closure) {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Variadic argument in function should be last!", p.curToken.Pos.Sline())
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return
 			}
 		}
@@ -2614,7 +2838,7 @@ func (p *Parser) parseEnumExpression() ast.Expression {
 		if !p.peekTokenIs(token.ASSIGN) && !p.peekTokenIs(token.COMMA) && !p.peekTokenIs(token.RBRACE) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Token %s not allowed here.", p.peekToken.Pos, p.peekToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.peekToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.peekToken.Pos)
 			return nil
 		}
 
@@ -2643,7 +2867,7 @@ func (p *Parser) parseEnumExpression() ast.Expression {
 		if _, ok := idPair[str_enum_id]; ok { //is identifier redeclared?
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Identifier %s redeclared.", p.curToken.Pos, str_enum_id)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		} else {
 			e.Pairs[enum_id] = enum_value
@@ -2711,7 +2935,7 @@ func (p *Parser) parseClassLiteralForAnno() ast.Expression {
 	if !p.curTokenIs(token.LBRACE) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be '{', got %s instead", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -2728,7 +2952,7 @@ func (p *Parser) parseClassLiteralForAnno() ast.Expression {
 		default:
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Only 'property' statement is allow in class annotation.", s.Pos())
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, s.Pos().Sline())
+			p.errorPos = append(p.errorPos, s.Pos())
 			return nil
 		}
 	}
@@ -2737,6 +2961,7 @@ func (p *Parser) parseClassLiteralForAnno() ast.Expression {
 }
 
 // class : parentClass { block }.
+// class : parentClass, interface1, interface2 { block }.
 //e.g. let classname = class : parentClass { block }
 func (p *Parser) parseClassLiteral() ast.Expression {
 	cls := &ast.ClassLiteral{
@@ -2752,12 +2977,19 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 			return nil
 		}
 		cls.Parent = p.curToken.Literal
+		for p.peekTokenIs(token.COMMA) { //interfaces
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			cls.Interfaces = append(cls.Interfaces, p.curToken.Literal)
+		}
 		p.nextToken()
 	}
 	if !p.curTokenIs(token.LBRACE) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be '{', got %s instead", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -2776,7 +3008,7 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 				case *ast.FunctionLiteral:
 					msg := fmt.Sprintf("OriginScript: e3301: %v- Function literal is not allowed in 'var' statement of class.", s.Pos())
 					p.errors = append(p.errors, msg)
-					p.errorLines = append(p.errorLines, s.Pos().Sline())
+					p.errorPos = append(p.errorPos, s.Pos())
 					return nil
 				default:
 					cls.Members = append(cls.Members, s)
@@ -2793,7 +3025,7 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 		default:
 			msg := fmt.Sprintf("OriginScript: e3301: %v- Only 'var' statement, 'function' statement and 'property' statement is allow in class definition.", s.Pos())
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, s.Pos().Sline())
+			p.errorPos = append(p.errorPos, s.Pos())
 			return nil
 		}
 	}
@@ -2801,6 +3033,85 @@ func (p *Parser) parseClassLiteral() ast.Expression {
 	return cls
 }
 
+//interface name { fn method(parameters) property name }
+//interface name : parentInterface1, parentInterface2 { ... }
+func (p *Parser) parseInterfaceStatement() ast.Statement {
+	stmt := &ast.InterfaceStatement{Token: p.curToken}
+	stmt.Doc = p.lineComment
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		for {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Parents = append(stmt.Parents, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+			if !p.peekTokenIs(token.COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken() //skip '{'
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.FUNCTION:
+			m := &ast.InterfaceMethod{Token: p.curToken}
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			m.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.LPAREN) {
+				return nil
+			}
+			fn := &ast.FunctionLiteral{Token: m.Token}
+			p.parseFuncExpressionArray(fn, token.RPAREN)
+			m.Parameters, m.ParamTypes = fn.Parameters, fn.ParamTypes
+			if p.peekTokenIs(token.THINARROW) {
+				p.nextToken()
+				p.nextToken()
+				m.ReturnType = p.parseType()
+			}
+			stmt.Methods = append(stmt.Methods, m)
+		case token.PROPERTY:
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Properties = append(stmt.Properties, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		default:
+			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'fn'|'property' in interface definition, got %s instead.", p.curToken.Pos, p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
+			return nil
+		}
+
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+		p.nextToken()
+	}
+	if !p.curTokenIs(token.RBRACE) {
+		pos := p.curToken.Pos
+		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be '}', got EOF instead. Block should end with '}'.", pos)
+		p.errors = append(p.errors, msg)
+		p.errorPos = append(p.errorPos, pos)
+		return nil
+	}
+	stmt.RBraceToken = p.curToken
+	stmt.SrcEndToken = p.curToken
+	return stmt
+}
+
 func (p *Parser) parseClassBody(processAnnoClass bool) *ast.BlockStatement {
 	stmts := &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
 
@@ -2813,13 +3124,14 @@ func (p *Parser) parseClassBody(processAnnoClass bool) *ast.BlockStatement {
 
 		p.nextToken()
 	}
+	stmts.RBraceToken = p.curToken
 
 	if p.peekTokenIs(token.EOF) && !p.curTokenIs(token.RBRACE) {
 		pos := p.peekToken.Pos
 		pos.Col += 1
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be '}', got EOF instead. Block should end with '}'.", pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 	}
 
 	return stmts
@@ -2850,7 +3162,7 @@ LABEL:
 			if !p.peekTokenIs(token.FUNCTION) && !p.peekTokenIs(token.PROPERTY) && !p.peekTokenIs(token.AT) && !p.peekTokenIs(token.STATIC) {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'function'|'property'|'static', or another annotation, got '%s' instead", p.peekToken.Pos, p.peekToken.Type)
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.peekToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.peekToken.Pos)
 				return nil
 			}
 			tokenIsLParen = false
@@ -2881,13 +3193,13 @@ LABEL:
 			if !p.curTokenIs(token.RPAREN) {
 				msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be ')', got '%s' instead", p.curToken.Pos, p.curToken.Type)
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+				p.errorPos = append(p.errorPos, p.curToken.Pos)
 				return nil
 			}
 		} else if !p.curTokenIs(token.RBRACE) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be '}', got '%s' instead", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 		p.nextToken()
@@ -2897,7 +3209,7 @@ LABEL:
 	if !isClassStmtToken(p.curToken) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'var'|'property'|'function'|'async'|'public'|'protected'|'private'|'static', got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -2939,7 +3251,7 @@ func (p *Parser) parseClassSubStmt(modifierLevel ast.ModifierLevel, staticFlag b
 		if p.curToken.Type != token.PROPERTY {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'property'.Only 'property' statement is allowed in class annotation.", p.curToken.Pos)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 		r = p.parsePropertyDeclStmt(processAnnoClass)
@@ -2992,6 +3304,14 @@ func (p *Parser) parsePropertyDeclStmt(processAnnoClass bool) *ast.PropertyDeclS
 	}
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	if !processAnnoClass {
+		t, ok := p.parseTypeAnnotation() //e.g. 'property Name: string { get; set; }'
+		if !ok {
+			return nil
+		}
+		stmt.Type = t
+	}
+
 	if processAnnoClass || p.peekTokenIs(token.SEMICOLON) { //annotation class' property defaults to have both getter and setter.
 		getterToken := token.Token{Pos: p.curToken.Pos, Type: token.GET, Literal: "get"}
 		stmt.Getter = &ast.GetterStmt{Token: getterToken}
@@ -3113,7 +3433,7 @@ func (p *Parser) parseNewExpression() ast.Expression {
 		pos := p.fixPosCol()
 		msg := fmt.Sprintf("OriginScript: e3301: %v- Invalid object construction for 'new'. maybe you want 'new xxx()'", pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 		return nil
 	}
 
@@ -3122,7 +3442,7 @@ func (p *Parser) parseNewExpression() ast.Expression {
 		pos := p.fixPosCol()
 		msg := fmt.Sprintf("OriginScript: e3301: %v- 'new' should follow a 'class' name.", pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 		return nil
 	}
 	newExp.Class = call.Function
@@ -3149,7 +3469,7 @@ func (p *Parser) parseStrExpressionArray(a []ast.Expression) []ast.Expression {
 	if !p.curTokenIs(token.IDENT) && !p.curTokenIs(token.INT) && !p.curTokenIs(token.FLOAT) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'IDENT|INT|FLOAT', got %s instead", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 	a = append(a, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
@@ -3160,7 +3480,7 @@ func (p *Parser) parseStrExpressionArray(a []ast.Expression) []ast.Expression {
 		if !p.curTokenIs(token.IDENT) && !p.curTokenIs(token.INT) && !p.curTokenIs(token.FLOAT) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'IDENT|INT|FLOAT', got %s instead", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 		a = append(a, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
@@ -3207,14 +3527,14 @@ func (p *Parser) parseFatArrowFunction(left ast.Expression) ast.Expression {
 			default:
 				msg := fmt.Sprintf("OriginScript: e3301: %v- Arrow function expects a list of identifiers as arguments", param.Pos())
 				p.errors = append(p.errors, msg)
-				p.errorLines = append(p.errorLines, param.Pos().Sline())
+				p.errorPos = append(p.errorPos, param.Pos())
 				return nil
 			}
 		}
 	default:
 		msg := fmt.Sprintf("OriginScript: e3301: %v- Arrow function expects identifiers as arguments", exprType.Pos())
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, exprType.Pos().Sline())
+		p.errorPos = append(p.errorPos, exprType.Pos())
 		return nil
 	}
 
@@ -3245,7 +3565,7 @@ func (p *Parser) parseUsingStatement() *ast.UsingStmt {
 	if _, ok := expr.(*ast.AssignExpression); !ok {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- Using should be followed by an assignment expression", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 	usingStmt.Expr = expr.(*ast.AssignExpression)
@@ -3253,7 +3573,7 @@ func (p *Parser) parseUsingStatement() *ast.UsingStmt {
 	if !p.curTokenIs(token.RPAREN) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be ')', got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3292,7 +3612,7 @@ func (p *Parser) parseLinqExpression() ast.Expression {
 	if queryExpr.QueryBody.(*ast.QueryBodyExpr).Expr == nil {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- Linq query must be ended with 'select' or 'group'.", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3576,7 +3896,7 @@ func (p *Parser) parseAsyncLiteral() ast.Expression {
 	if !p.curTokenIs(token.FUNCTION) && !p.curTokenIs(token.LPAREN) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- async should be followed by a function or lambda, got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3592,7 +3912,7 @@ func (p *Parser) parseAsyncStatement() ast.Statement {
 	if !p.curTokenIs(token.FUNCTION) {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- async should be followed by a function, got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3619,7 +3939,7 @@ func (p *Parser) parseAwaitExpression() ast.Expression {
 	default:
 		msg := fmt.Sprintf("OriginScript: e3301: %v- await keyword can only be used on function/method calls!", p.curToken.Pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3661,15 +3981,21 @@ func (p *Parser) parseDiamond() ast.Expression {
 
 //define macro
 func (p *Parser) parseDefineStatement() ast.Statement {
+	defTok := p.curToken
 	if !p.expectPeek(token.IDENT) { //macro name
 		pos := p.fixPosCol()
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be 'IDENT', got %s instead", pos, p.peekToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 		return nil
 	}
 
-	p.defines[p.curToken.Literal] = true
+	p.defines[p.curToken.Literal] = ""
+
+	if p.mode&ParseComments != 0 { //keep the define line for tools, e.g. the formatter
+		tok := token.Token{Type: token.DEFINE, Literal: "#define " + p.curToken.Literal, Pos: defTok.Pos}
+		p.comments = append(p.comments, &ast.CommentGroup{List: []*ast.Comment{{Token: tok, Text: tok.Literal}}})
+	}
 
 	return nil
 }
@@ -3719,7 +4045,7 @@ func (p *Parser) parseServiceStatement() *ast.ServiceStatement {
 		if annoLen != 1 {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- function(%s)'s annotation count not one", p.curToken.Pos, k)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 	}
@@ -3739,13 +4065,14 @@ func (p *Parser) parseServiceBody(s *ast.ServiceStatement) *ast.BlockStatement {
 
 		p.nextToken()
 	}
+	stmts.RBraceToken = p.curToken
 
 	if p.peekTokenIs(token.EOF) && !p.curTokenIs(token.RBRACE) {
 		pos := p.peekToken.Pos
 		pos.Col += 1
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be '}', got EOF instead. Block should end with '}'.", pos)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, pos.Sline())
+		p.errorPos = append(p.errorPos, pos)
 	}
 
 	return stmts
@@ -3764,7 +4091,7 @@ func (p *Parser) parseServiceStmt(s *ast.ServiceStatement) ast.Statement {
 		if p.curToken.Literal != "route" {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'route', got '%s' instead", p.curToken.Pos, p.curToken.Literal)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 		anno.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
@@ -3794,7 +4121,7 @@ func (p *Parser) parseServiceStmt(s *ast.ServiceStatement) ast.Statement {
 		if !p.curTokenIs(token.RPAREN) {
 			msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be ')', got '%s' instead", p.curToken.Pos, p.curToken.Type)
 			p.errors = append(p.errors, msg)
-			p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+			p.errorPos = append(p.errorPos, p.curToken.Pos)
 			return nil
 		}
 
@@ -3805,7 +4132,7 @@ func (p *Parser) parseServiceStmt(s *ast.ServiceStatement) ast.Statement {
 	if p.curToken.Type != token.FUNCTION {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- expected token to be 'function', got %s instead.", p.curToken.Pos, p.curToken.Type)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 		return nil
 	}
 
@@ -3821,7 +4148,7 @@ func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	if t != token.EOF {
 		msg := fmt.Sprintf("OriginScript: e3301: %v- no prefix parse functions for '%s' found", p.curToken.Pos, t)
 		p.errors = append(p.errors, msg)
-		p.errorLines = append(p.errorLines, p.curToken.Pos.Sline())
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
 	}
 }
 
@@ -3848,35 +4175,44 @@ func (p *Parser) curPrecedence() int {
 }
 
 func (p *Parser) nextToken() {
-	p.lineComment = nil
-
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	p.lineComment = p.peekComment
+	p.peekToken, p.peekComment = p.skipComments(p.l.NextToken())
+}
 
+//skipComments skips the comments before 'tok', so neither the current token nor the
+//peek token could be a comment. All the comments are recorded, and the doc comments
+//are returned, they will be the 'lineComment' when the token becomes the current token.
+func (p *Parser) skipComments(tok token.Token) (token.Token, *ast.CommentGroup) {
 	var list []*ast.Comment
-	for p.curToken.Type == token.COMMENT {
-		//if p.curToken.Literal[0] != '#' {
-		if p.isDocLine(p.curToken.Pos.Line) {
-			comment := &ast.Comment{Token: p.curToken, Text: p.curToken.Literal}
+	var all []*ast.Comment
+	for tok.Type == token.COMMENT {
+		comment := &ast.Comment{Token: tok, Text: tok.Literal}
+		all = append(all, comment)
+		//if tok.Literal[0] != '#' {
+		if p.isDocLine(tok.Pos.Line) {
 			list = append(list, comment)
 		}
-		p.curToken = p.peekToken
-		p.peekToken = p.l.NextToken()
+		tok = p.l.NextToken()
+	}
+	if all != nil {
+		p.comments = append(p.comments, &ast.CommentGroup{List: all})
 	}
 	if list != nil {
-		p.lineComment = &ast.CommentGroup{List: list}
+		return tok, &ast.CommentGroup{List: list}
 	}
+	return tok, nil
 }
 
 func (p *Parser) nextInterpToken() {
 	p.curToken = p.l.NextInterpToken()
-	p.peekToken = p.l.NextToken()
+	p.peekToken, p.peekComment = p.skipComments(p.l.NextToken())
 }
 
 // for date-time literal use
 func (p *Parser) nextInterpToken2() {
 	p.curToken = p.l.NextInterpToken2()
-	p.peekToken = p.l.NextToken()
+	p.peekToken, p.peekComment = p.skipComments(p.l.NextToken())
 }
 
 func (p *Parser) expectPeek(t token.TokenType) bool {
@@ -3892,20 +4228,35 @@ func (p *Parser) peekError(t token.TokenType) {
 	pos := p.fixPosCol()
 	msg := fmt.Sprintf("OriginScript: e3301: %v- expected next token to be %s, got %s instead", pos, t, p.peekToken.Type)
 	p.errors = append(p.errors, msg)
-	p.errorLines = append(p.errorLines, pos.Sline())
+	p.errorPos = append(p.errorPos, pos)
 }
 
 func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+//Comments returns all the comments of the source in order, including the '#define' lines
+//which are not kept in the AST. Only available when the parser is created using 'NewWithDoc'.
+func (p *Parser) Comments() []*ast.CommentGroup {
+	return p.comments
+}
+
 func (p *Parser) ErrorLines() []string {
-	return p.errorLines
+	lines := make([]string, len(p.errorPos))
+	for i, pos := range p.errorPos {
+		lines[i] = pos.Sline()
+	}
+	return lines
+}
+
+//ErrorPositions returns the positions of the errors, in the order of 'Errors'.
+func (p *Parser) ErrorPositions() []token.Position {
+	return p.errorPos
 }
 
 //Is the line document line or not
 func (p *Parser) isDocLine(lineNo int) bool {
-	if len(FileLines) == 0 {
+	if len(FileLines) == 0 || lineNo > len(FileLines) { //e.g. the lines of an imported file
 		return false
 	}
 
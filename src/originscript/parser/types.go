@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"originscript/ast"
+	"originscript/token"
+)
+
+// The type annotations:
+//    fn add(a: int, b: int) -> int { block }
+//    lit name: string = "x"
+//    property Age: int|nil { get; set; }
+// A type is a name(int, string, nil, fn, a class or an interface ...),
+// 'array<T>', 'hash<K, V>', or a union of them, e.g. 'int|nil'.
+//
+//parseType parses the type at the current token, the current token is the
+//last token of the type when it returns.
+func (p *Parser) parseType() *ast.TypeExpr {
+	t := p.parseTypeName()
+	if t == nil || !p.peekTokenIs(token.BITOR) {
+		return t
+	}
+
+	union := &ast.TypeExpr{Token: t.Token, Union: []*ast.TypeExpr{t}}
+	for p.peekTokenIs(token.BITOR) {
+		p.nextToken()
+		p.nextToken()
+		alt := p.parseTypeName()
+		if alt == nil {
+			return nil
+		}
+		union.Union = append(union.Union, alt)
+	}
+	return union
+}
+
+func (p *Parser) parseTypeName() *ast.TypeExpr {
+	switch p.curToken.Type {
+	case token.IDENT, token.NIL, token.FUNCTION:
+	default:
+		msg := fmt.Sprintf("OriginScript: e3301: %v- expected a type name, got %s instead.", p.curToken.Pos, p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		p.errorPos = append(p.errorPos, p.curToken.Pos)
+		return nil
+	}
+	t := &ast.TypeExpr{Token: p.curToken, Name: p.curToken.Literal}
+	if !p.peekTokenIs(token.LT) { //no type arguments
+		return t
+	}
+
+	p.nextToken()
+	for {
+		p.nextToken()
+		arg := p.parseType()
+		if arg == nil {
+			return nil
+		}
+		t.Args = append(t.Args, arg)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectTypeClose() {
+		return nil
+	}
+	return t
+}
+
+//the tokens the lexer could join with the '>' closing the type arguments
+var typeCloseRest = map[string]token.TokenType{
+	">": token.GT,     //'array<array<int>>'
+	",": token.COMMA,  //'hash<string, array<int>>'
+	"|": token.BITOR,  //'array<int>|nil'
+	"=": token.ASSIGN, //'lit a: array<int>= []'
+	"}": token.RBRACE,
+}
+
+//expectTypeClose advances to the '>' closing the type arguments. If the lexer
+//joined it with the next character(e.g. '>>' or '>,'), the token is split.
+func (p *Parser) expectTypeClose() bool {
+	tok := p.peekToken
+	if tok.Type == token.GT {
+		p.nextToken()
+		return true
+	}
+
+	if len(tok.Literal) == 2 && tok.Literal[0] == '>' {
+		if rest, ok := typeCloseRest[tok.Literal[1:]]; ok {
+			p.curToken = token.Token{Type: token.GT, Literal: ">", Pos: tok.Pos}
+			p.peekToken.Type, p.peekToken.Literal = rest, tok.Literal[1:]
+			p.peekToken.Pos.Col++
+			p.peekToken.Pos.Offset++
+			return true
+		}
+	}
+	p.peekError(token.GT)
+	return false
+}
+
+//parseTypeAnnotation parses the optional ': type' after the current token.
+func (p *Parser) parseTypeAnnotation() (*ast.TypeExpr, bool) {
+	if !p.peekTokenIs(token.COLON) {
+		return nil, true
+	}
+	p.nextToken()
+	p.nextToken()
+	t := p.parseType()
+	return t, t != nil
+}
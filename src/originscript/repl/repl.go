@@ -8,6 +8,7 @@ import (
 	"originscript/eval"
 	"originscript/lexer"
 	"originscript/parser"
+	"originscript/token"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,17 +17,6 @@ import (
 	//"github.com/peterh/liner"
 )
 
-var magpieKeywords = []string{
-	"fn", "let", "true", "false", "if", "else",
-	"elif", "return", "import", "and", "or", "struct", "do", "while",
-	"break", "continue", "for", "in", "where", "grep", "map", "case",
-	"is", "try", "catch", "finally", "throw", "qw", "unless", "spawn",
-	"enum", "defer", "nil", "class", "new", "this", "parent", "property",
-	"get", "set", "static", "public", "private", "protected", "interface", "default",
-	"from", "select", "group", "into", "orderby", "join", "on", "equals", "by", "ascending", "descending",
-	"async", "await", "service",
-}
-
 // Note: we should put the longest operators first.
 var magpieOperators = []string{
 	"+=", "-=", "*=", "/=", "%=", "^=",
@@ -51,11 +41,11 @@ var colors = map[liner.Category]liner.Color{
 	liner.IdentType:    liner.COLOR_WHITE,
 }
 
-const PROMPT = "magpie>> "
+const PROMPT = "origion>> "
 const CONT_PROMPT = "... " // continue prompt
 
 func Start(out io.Writer, color bool) {
-	history := filepath.Join(os.TempDir(), ".magpie_history")
+	history := filepath.Join(os.TempDir(), ".origion_history")
 	l := liner.NewLiner()
 	defer l.Close()
 
@@ -64,7 +54,7 @@ func Start(out io.Writer, color bool) {
 
 	if color {
 		l.SetSyntaxHighlight(color) //use syntax highlight or not
-		l.RegisterKeywords(magpieKeywords)
+		l.RegisterKeywords(token.Keywords())
 		l.RegisterOperators(magpieOperators)
 		l.RegisterColors(colors)
 	}
@@ -77,12 +67,14 @@ func Start(out io.Writer, color bool) {
 	if color && !l.IsInwinConsole() {
 		eval.REPLColor = true
 	}
-	scope := eval.NewScope(nil, os.Stdout)
 	wd, err := os.Getwd()
 	if err != nil {
 		io.WriteString(out, err.Error())
 		os.Exit(1)
 	}
+	sess := newSession(out, wd)
+	l.SetWordCompleter(sess.complete)
+	l.SetTabCompletionStyle(liner.TabPrints)
 
 	// var tmplines []string
 	var lex *lexer.Lexer
@@ -101,13 +93,19 @@ func Start(out io.Writer, color bool) {
 			tmpline := strings.TrimSpace(line)
 			if len(tmpline) == 0 || tmpline[0] == '#' { //empty line or single comment line
 				continue
+			} else if tmpline[0] == ':' { //meta-command
+				sess.runCommand(tmpline)
+				l.AppendHistory(tmpline)
+				continue
 			} else {
 				//check if the line is a valid expression or statement
 				lex = lexer.New("", tmpline)
 				p = parser.New(lex, wd)
 				program = p.ParseProgram()
 				if len(p.Errors()) == 0 { // no error
-					eval.Eval(program, scope)
+					if evalAndReport(out, program, sess.scope) {
+						sess.record(tmpline)
+					}
 					l.AppendHistory(tmpline)
 					continue
 				} else {
@@ -140,7 +138,9 @@ func Start(out io.Writer, color bool) {
 							p = parser.New(lex, wd)
 							program = p.ParseProgram()
 							if len(p.Errors()) == 0 { // no error
-								eval.Eval(program, scope)
+								if evalAndReport(out, program, sess.scope) {
+									sess.record(strings.TrimRight(text, "\n"))
+								}
 								l.AppendHistory(strings.Replace(text, "\n", "", -1))
 								break
 							} else {
@@ -162,6 +162,18 @@ func Start(out io.Writer, color bool) {
 	}
 }
 
+//evaluate the program in the REPL's scope, so the state is kept across inputs.
+//Runtime errors are reported, but they do not end the session.
+//It returns false if the evaluation failed.
+func evalAndReport(out io.Writer, program *ast.Program, scope *eval.Scope) bool {
+	result := eval.Eval(program, scope)
+	if result != nil && result.Type() == eval.ERROR_OBJ {
+		io.WriteString(out, result.Inspect())
+		return false
+	}
+	return true
+}
+
 func printParserErrors(out io.Writer, errors []string) {
 	for _, msg := range errors {
 		io.WriteString(out, "\t"+msg+"\n")
@@ -0,0 +1,234 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//session keeps the state of one REPL run: the evaluation scope and
+//all the inputs which were evaluated successfully(used by ':save').
+type session struct {
+	out    io.Writer
+	wd     string
+	scope  *eval.Scope
+	inputs []string
+}
+
+func newSession(out io.Writer, wd string) *session {
+	return &session{out: out, wd: wd, scope: eval.NewScope(nil, os.Stdout)}
+}
+
+var metaCommands = []struct {
+	name string
+	args string
+	desc string
+}{
+	{":help", "", "Show this help."},
+	{":load", "file.aero", "Evaluate a script file in the current session."},
+	{":type", "expr", "Show the type and value of an expression."},
+	{":reset", "", "Discard all the variables, functions and classes of the session."},
+	{":save", "session.aero", "Save all the inputs of the session to a file."},
+}
+
+//record an input which was evaluated successfully
+func (s *session) record(input string) {
+	s.inputs = append(s.inputs, input)
+}
+
+//runCommand processes the REPL meta-commands, i.e. lines starting with ':'
+func (s *session) runCommand(line string) {
+	fields := strings.Fields(line)
+	cmd := fields[0]
+	arg := strings.TrimSpace(strings.TrimPrefix(line, cmd))
+
+	switch cmd {
+	case ":help", ":h":
+		for _, c := range metaCommands {
+			fmt.Fprintf(s.out, "  %-8s %-14s %s\n", c.name, c.args, c.desc)
+		}
+		fmt.Fprintln(s.out, "  Type 'exit' or 'quit' to leave, press <Tab> to complete.")
+	case ":load":
+		if arg == "" {
+			fmt.Fprintln(s.out, "\tUsage: :load file.aero")
+			return
+		}
+		s.load(arg)
+	case ":type":
+		if arg == "" {
+			fmt.Fprintln(s.out, "\tUsage: :type expr")
+			return
+		}
+		s.showType(arg)
+	case ":reset":
+		s.scope = eval.NewScope(nil, os.Stdout)
+		s.inputs = nil
+		fmt.Fprintln(s.out, "\tSession reset.")
+	case ":save":
+		if arg == "" {
+			fmt.Fprintln(s.out, "\tUsage: :save session.aero")
+			return
+		}
+		content := strings.Join(s.inputs, "\n") + "\n"
+		if err := ioutil.WriteFile(arg, []byte(content), 0644); err != nil {
+			fmt.Fprintf(s.out, "\tCannot save session: %s\n", err.Error())
+			return
+		}
+		fmt.Fprintf(s.out, "\tSaved %d input(s) to '%s'.\n", len(s.inputs), arg)
+	default:
+		fmt.Fprintf(s.out, "\tUnknown command '%s', type ':help' for a list of commands.\n", cmd)
+	}
+}
+
+func (s *session) load(filename string) {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Fprintf(s.out, "\tCannot load file: %s\n", err.Error())
+		return
+	}
+
+	l := lexer.New(filename, string(f))
+	p := parser.New(l, s.wd)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.out, p.Errors())
+		return
+	}
+
+	evalAndReport(s.out, program, s.scope)
+	s.record(string(f))
+}
+
+func (s *session) showType(expr string) {
+	l := lexer.New("", expr)
+	p := parser.New(l, s.wd)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.out, p.Errors())
+		return
+	}
+
+	//evaluate in a copy of the scope, so the expression could not change the session's
+	//variables(e.g. ':type x = 1'). The objects are shared, ':type arr.push(1)' changes 'arr'.
+	result := eval.Eval(program, s.scope.Copy())
+	if result == nil {
+		result = eval.NIL
+	}
+	if result.Type() == eval.ERROR_OBJ {
+		io.WriteString(s.out, result.Inspect())
+		return
+	}
+	fmt.Fprintf(s.out, "\t%s: %s\n", result.Type(), result.Inspect())
+}
+
+//complete is the liner.WordCompleter of the REPL. It completes:
+//   1. identifiers of the session's scope, builtins, keywords and stdlib modules.
+//   2. methods of the object left of a '.', e.g. 'fmt.pr<Tab>' or 'arr.ma<Tab>'.
+//   3. meta-commands, e.g. ':lo<Tab>'.
+func (s *session) complete(line string, pos int) (head string, completions []string, tail string) {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	tail = string(runes[pos:])
+
+	start := pos
+	for start > 0 && isWordRune(runes[start-1]) {
+		start--
+	}
+	word := string(runes[start:pos])
+
+	if start == 1 && runes[0] == ':' { //meta-commands
+		for _, c := range metaCommands {
+			if strings.HasPrefix(c.name, ":"+word) {
+				completions = append(completions, c.name+" ")
+			}
+		}
+		return "", completions, tail
+	}
+
+	//'obj.prefix': find the object part before the '.'
+	if start > 0 && runes[start-1] == '.' {
+		objEnd := start - 1
+		objStart := objEnd
+		for objStart > 0 && (isWordRune(runes[objStart-1]) || runes[objStart-1] == '.') {
+			objStart--
+		}
+		objName := string(runes[objStart:objEnd])
+		head = string(runes[:start])
+		for _, name := range s.memberNames(objName) {
+			if strings.HasPrefix(name, word) {
+				completions = append(completions, name)
+			}
+		}
+		return head, uniqSorted(completions), tail
+	}
+
+	head = string(runes[:start])
+	if word == "" {
+		return head, nil, tail
+	}
+	for _, name := range s.topLevelNames() {
+		if strings.HasPrefix(name, word) {
+			completions = append(completions, name)
+		}
+	}
+	return head, uniqSorted(completions), tail
+}
+
+//names which could be used without a '.'
+func (s *session) topLevelNames() []string {
+	names := s.scope.GetAllKeys()
+	names = append(names, eval.BuiltinNames()...)
+	names = append(names, token.Keywords()...)
+	for _, name := range eval.GetGlobalNames() {
+		//e.g. 'math.Pi' => 'math'
+		names = append(names, strings.Split(name, ".")[0])
+	}
+	return names
+}
+
+//names which could be used after 'objName.'
+func (s *session) memberNames(objName string) []string {
+	var names []string
+
+	//variables registered using 'SetGlobalObj("xxx.yyy", ...)', e.g. 'math.Pi'
+	for _, name := range eval.GetGlobalNames() {
+		if strings.HasPrefix(name, objName+".") {
+			names = append(names, strings.TrimPrefix(name, objName+"."))
+		}
+	}
+
+	obj, ok := eval.GetGlobalObj(objName)
+	if !ok {
+		obj, ok = s.scope.Get(objName)
+	}
+	if ok {
+		names = append(names, eval.MethodNames(obj)...)
+	}
+	return names
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func uniqSorted(list []string) []string {
+	sort.Strings(list)
+	ret := list[:0]
+	for i, v := range list {
+		if i > 0 && list[i-1] == v {
+			continue
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
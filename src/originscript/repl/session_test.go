@@ -0,0 +1,201 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//feed evaluates the lines of 'input' in the session like the prompt of 'Start' does,
+//and returns the output of the session.
+func feed(s *session, input string) string {
+	var out bytes.Buffer
+	s.out = &out
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		} else if line[0] == ':' {
+			s.runCommand(line)
+			continue
+		}
+
+		p := parser.New(lexer.New("", line), s.wd)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			printParserErrors(&out, p.Errors())
+			continue
+		}
+		if evalAndReport(&out, program, s.scope) {
+			s.record(line)
+		}
+	}
+	return out.String()
+}
+
+func newTestSession(t *testing.T) *session {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newSession(&bytes.Buffer{}, wd)
+}
+
+func TestSessionState(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"lit x = 40\nfn add(a) { return a + x }\n:type add(2)", "\tINTEGER: 42\n"},
+		{"lit arr = [1]\narr.push(2)\n:type arr", "\tARRAY: [1, 2]\n"},
+		{"class A { fn get() { return 7 } }; lit a = new A()\n:type a.get()", "\tINTEGER: 7\n"},
+		//an error does not end the session
+		{"lit x = 1\nlit y = 1 / nosuchvar\nx = x + 1\n:type x", "\tINTEGER: 2\n"},
+	}
+
+	for _, tt := range tests {
+		output := feed(newTestSession(t), tt.input)
+		if !strings.HasSuffix(output, tt.expected) {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, output)
+		}
+	}
+
+	s := newTestSession(t)
+	feed(s, "lit x = 1\nlit y = 1 / nosuchvar\nx = 2\nlit z = (")
+	if strings.Join(s.inputs, "\n") != "lit x = 1\nx = 2" {
+		t.Errorf("only the inputs evaluated successfully must be recorded, got=%q", s.inputs)
+	}
+}
+
+func TestCompleteKeywords(t *testing.T) {
+	tests := []struct {
+		line     string
+		head     string
+		expected string
+	}{
+		{"whi", "", "while"},
+		{"lit a = ret", "lit a = ", "return"},
+		{"fn f() { ret", "fn f() { ", "return"},
+		{"if (a) { brea }", "if (a) { ", "break"},
+	}
+
+	s := newTestSession(t)
+	for _, tt := range tests {
+		pos := len(tt.line)
+		if strings.HasSuffix(tt.line, " }") {
+			pos -= 2
+		}
+		head, completions, _ := s.complete(tt.line, pos)
+		if head != tt.head {
+			t.Errorf("%q: wrong head. expected=%q, got=%q", tt.line, tt.head, head)
+		}
+		found := false
+		for _, c := range completions {
+			found = found || c == tt.expected
+		}
+		if !found {
+			t.Errorf("%q: expected %q in the completions, got=%v", tt.line, tt.expected, completions)
+		}
+	}
+
+	//no completion of an empty word
+	if _, completions, _ := s.complete("lit a = ", 8); completions != nil {
+		t.Errorf("expected no completions, got=%v", completions)
+	}
+}
+
+//the keywords completed(and highlighted) by the REPL are the ones of the lexer
+func TestKeywordsFromLexer(t *testing.T) {
+	keywords := token.Keywords()
+	if len(keywords) == 0 {
+		t.Fatal("no keywords")
+	}
+
+	names := make(map[string]bool)
+	for _, name := range newTestSession(t).topLevelNames() {
+		names[name] = true
+	}
+	for _, kw := range keywords {
+		if token.LookupIdent(kw) == token.IDENT {
+			t.Errorf("%q is not a keyword of the lexer", kw)
+		}
+		if tok := lexer.New("", kw).NextToken(); tok.Type == token.IDENT {
+			t.Errorf("%q is lexed as an identifier", kw)
+		}
+		if !names[kw] {
+			t.Errorf("keyword %q is not completed", kw)
+		}
+	}
+
+	//'let' is not a keyword of the lexer any more, 'lit' is
+	if names["let"] || !names["lit"] {
+		t.Errorf("wrong keywords completed, 'lit' expected instead of 'let'")
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lib := filepath.Join(dir, "lib.aero")
+	ioutil.WriteFile(lib, []byte("lit loaded = 3\nfn twice(a) { return a * 2 }\n"), 0644)
+	broken := filepath.Join(dir, "broken.aero")
+	ioutil.WriteFile(broken, []byte("lit a = (1\n"), 0644)
+	saved := filepath.Join(dir, "session.aero")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":load " + lib + "\n:type twice(loaded)", "\tINTEGER: 6\n"},
+		{":load " + filepath.Join(dir, "nosuchfile.aero"), "\tCannot load file: "},
+		{":load " + broken, "e3301"},
+		{":load", "\tUsage: :load file.aero\n"},
+		{":type 1 + 1.5", "\tFLOAT: 2.5\n"},
+		{":type", "\tUsage: :type expr\n"},
+		//':type' does not change the session
+		{"lit x = 1\n:type x = 5\n:type x", "\tINTEGER: 1\n"},
+		{":type lit y = 2\n:type y", "'y' is not defined"},
+		{"lit x = 1\n:reset\n:type x", "\tSession reset.\nRuntime Error: AeroScript: eUDE-0005: unknown identifier: 'x' is not defined"},
+		{"lit x = 1\nx = x + 1\n:save " + saved, "\tSaved 2 input(s) to '" + saved + "'.\n"},
+		{":save", "\tUsage: :save session.aero\n"},
+		{":save " + filepath.Join(dir, "nosuchdir", "session.aero"), "\tCannot save session: "},
+		{":nosuchcommand", "\tUnknown command ':nosuchcommand'"},
+	}
+
+	for _, tt := range tests {
+		output := feed(newTestSession(t), tt.input)
+		if !strings.Contains(output, tt.expected) {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, output)
+		}
+	}
+
+	//the saved session can be loaded
+	if content, _ := ioutil.ReadFile(saved); string(content) != "lit x = 1\nx = x + 1\n" {
+		t.Errorf("wrong session saved, got=%q", content)
+	}
+	if output := feed(newTestSession(t), ":load "+saved+"\n:type x"); output != "\tINTEGER: 2\n" {
+		t.Errorf("wrong session loaded, got=%q", output)
+	}
+
+	//the loaded files are saved, ':reset' discards the inputs
+	s := newTestSession(t)
+	feed(s, ":load "+lib+"\nlit z = twice(1)")
+	if len(s.inputs) != 2 || !strings.HasPrefix(s.inputs[0], "lit loaded = 3") {
+		t.Errorf("wrong inputs, got=%q", s.inputs)
+	}
+	feed(s, ":reset")
+	if len(s.inputs) != 0 {
+		t.Errorf("inputs not reset, got=%q", s.inputs)
+	}
+}
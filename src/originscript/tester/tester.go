@@ -0,0 +1,179 @@
+// Package tester implements the `origion test` subcommand.
+//
+// It discovers the `*_test.aero` files of a directory, and runs every
+// `fn test*()` function of these files in its own fresh scope.
+package tester
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"originscript/ast"
+	"originscript/eval"
+	"originscript/lexer"
+	"originscript/parser"
+	"originscript/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PASS  = "PASS"
+	FAIL  = "FAIL"
+	ERROR = "ERROR"
+)
+
+//Result is the result of one test function.
+type Result struct {
+	Name     string
+	Status   string         //PASS, FAIL or ERROR
+	Pos      token.Position //where the test failed, or the test function's position
+	Message  string
+	Duration time.Duration
+}
+
+//Summary counts the results of a test run.
+type Summary struct {
+	Passed int
+	Failed int
+	Errors int
+}
+
+func (s Summary) Ok() bool {
+	return s.Failed == 0 && s.Errors == 0
+}
+
+//the runtime errors end with ' at line  (filename;line) '
+var errorPosRegex = regexp.MustCompile(`at line\s+\(([^;()]*);(\d+)\)\s*$`)
+
+//Discover returns all the '*_test.aero' files under 'dir', sorted by name.
+func Discover(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), "_test.aero") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+//Run runs all the tests under 'dir', and writes the report to 'out'.
+func Run(dir string, out io.Writer) Summary {
+	var summary Summary
+
+	files, err := Discover(dir)
+	if err != nil {
+		fmt.Fprintf(out, "OriginScript: test: %s\n", err.Error())
+		summary.Errors++
+		return summary
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(out, "OriginScript: test: no '*_test.aero' files found in '%s'\n", dir)
+		return summary
+	}
+
+	start := time.Now()
+	for _, file := range files {
+		fmt.Fprintf(out, "=== %s\n", file)
+		for _, r := range RunFile(file) {
+			switch r.Status {
+			case PASS:
+				summary.Passed++
+				fmt.Fprintf(out, "%-5s %s (%.3fs)\n", r.Status, r.Name, r.Duration.Seconds())
+			case FAIL:
+				summary.Failed++
+				fmt.Fprintf(out, "%-5s %s:%d: %s (%.3fs)\n", r.Status, r.Pos.Filename, r.Pos.Line, r.Name, r.Duration.Seconds())
+			case ERROR:
+				summary.Errors++
+				fmt.Fprintf(out, "%-5s %s:%d: %s\n", r.Status, r.Pos.Filename, r.Pos.Line, r.Name)
+			}
+			if r.Message != "" {
+				fmt.Fprintf(out, "      %s\n", strings.Replace(strings.TrimRight(r.Message, "\n"), "\n", "\n      ", -1))
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "--- %d passed, %d failed, %d errors (%.3fs)\n",
+		summary.Passed, summary.Failed, summary.Errors, time.Since(start).Seconds())
+	if summary.Ok() {
+		fmt.Fprintln(out, "ok")
+	} else {
+		fmt.Fprintln(out, "FAILED")
+	}
+	return summary
+}
+
+//RunFile runs all the test functions of a single file.
+func RunFile(filename string) []Result {
+	f, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return []Result{{Name: filename, Status: ERROR, Pos: token.Position{Filename: filename}, Message: err.Error()}}
+	}
+
+	l := lexer.New(filename, string(f))
+	p := parser.New(l, filepath.Dir(filename))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return []Result{{Name: filename, Status: ERROR, Pos: token.Position{Filename: filename},
+			Message: strings.Join(p.Errors(), "\n")}}
+	}
+
+	var results []Result
+	for _, stmt := range program.Statements {
+		fn, ok := stmt.(*ast.FunctionStatement)
+		if !ok || !strings.HasPrefix(fn.Name.Value, "test") {
+			continue
+		}
+		results = append(results, runTest(program, fn))
+	}
+	return results
+}
+
+//runTest runs one test function in a fresh scope, so the tests could not affect each other.
+func runTest(program *ast.Program, fn *ast.FunctionStatement) Result {
+	r := Result{Name: fn.Name.Value, Status: PASS, Pos: fn.Pos()}
+	start := time.Now()
+
+	scope := eval.NewScope(nil, os.Stdout)
+	result := eval.Eval(program, scope)
+	if result == nil || result.Type() != eval.ERROR_OBJ {
+		call := &ast.CallExpression{Token: fn.Token, Function: fn.Name}
+		result = eval.Eval(call, scope)
+	}
+	if result != nil && result.Type() == eval.ERROR_OBJ {
+		r = errorResult(r, result.(*eval.Error))
+	} else if t, ok := result.(*eval.Throw); ok { //throw not handled by the test
+		r.Status = ERROR
+		r.Message = fmt.Sprintf("throw object '%s' not handled", t.Inspect())
+	}
+
+	r.Duration = time.Since(start)
+	return r
+}
+
+//errorResult fills the result with the error, assertion errors are reported as failures.
+func errorResult(r Result, e *eval.Error) Result {
+	r.Status = ERROR
+	if e.Kind == eval.ASSERTIONERROR || e.Kind == eval.ASSERTIONERROREX {
+		r.Status = FAIL
+	}
+
+	msg := strings.TrimSpace(e.Message)
+	if m := errorPosRegex.FindStringSubmatch(msg); m != nil {
+		r.Pos.Filename = m[1]
+		r.Pos.Line, _ = strconv.Atoi(m[2])
+		msg = strings.TrimSpace(msg[:len(msg)-len(m[0])])
+	}
+	r.Message = msg
+	return r
+}
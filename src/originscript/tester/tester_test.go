@@ -0,0 +1,165 @@
+package tester
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//the line numbers are checked by the tests
+const mathTest = `fn testEqual() {
+	assertEqual(3, 1 + 2)
+}
+
+fn testNotEqual() {
+	assertEqual(3, 1 + 3, "the sum")
+}
+
+fn testDeepEqual() {
+	assertDeepEqual([1, {"a" : [2]}], [1, {"a" : [2]}])
+}
+
+fn testNotDeepEqual() {
+	assertDeepEqual([1, 2], [1, 3])
+}
+
+fn testThrows() {
+	assertThrows(fn() { throw 1 })
+	assertThrows(fn() { 1 / 0 })
+}
+
+fn testNotThrows() {
+	assertThrows(fn() { return 1 })
+}
+
+fn testError() {
+	lit a = [1]
+	a.nosuchmethod()
+}
+
+fn helper() {
+	assertEqual(1, 2)
+}
+`
+
+//writeFiles writes the files(name: content) in a new directory, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"b_test.aero":     "",
+		"a_test.aero":     "",
+		"sub/c_test.aero": "",
+		"helper.aero":     "",
+		"test.aero":       "",
+	})
+	defer os.RemoveAll(dir)
+
+	files, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"a_test.aero", "b_test.aero", "sub/c_test.aero"}
+	if len(files) != len(expected) {
+		t.Fatalf("wrong files. expected=%v, got=%v", expected, files)
+	}
+	for i, file := range files {
+		if file != filepath.Join(dir, expected[i]) {
+			t.Errorf("files[%d]: expected=%s, got=%s", i, expected[i], file)
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"math_test.aero": mathTest})
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		status  string
+		line    int
+		message string
+	}{
+		{"testEqual", PASS, 1, ""},
+		{"testNotEqual", FAIL, 6, "assertion failed: the sum\n    expected: 3\n      actual: 4"},
+		{"testDeepEqual", PASS, 9, ""},
+		{"testNotDeepEqual", FAIL, 14, "assertion failed: assertDeepEqual\n    expected: [1, 2]\n      actual: [1, 3]"},
+		{"testThrows", PASS, 17, ""},
+		{"testNotThrows", FAIL, 23, "assertion failed: assertThrows\n    expected: an error or throw\n      actual: returned 1"},
+		{"testError", ERROR, 28, "nosuchmethod"},
+	}
+
+	results := RunFile(filepath.Join(dir, "math_test.aero"))
+	if len(results) != len(tests) {
+		t.Fatalf("wrong number of results. expected=%d, got=%d(%v)", len(tests), len(results), results)
+	}
+	for i, tt := range tests {
+		r := results[i]
+		if r.Name != tt.name || r.Status != tt.status {
+			t.Errorf("results[%d]: expected %s %s, got=%s %s", i, tt.status, tt.name, r.Status, r.Name)
+		}
+		if r.Pos.Line != tt.line {
+			t.Errorf("%s: wrong line. expected=%d, got=%d", tt.name, tt.line, r.Pos.Line)
+		}
+		if !strings.Contains(r.Message, tt.message) || (tt.message == "") != (r.Message == "") {
+			t.Errorf("%s: wrong message. expected=%q, got=%q", tt.name, tt.message, r.Message)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		files    map[string]string
+		summary  Summary
+		expected []string //in the report
+	}{
+		{map[string]string{"math_test.aero": mathTest},
+			Summary{Passed: 3, Failed: 3, Errors: 1},
+			[]string{"PASS  testEqual", "FAIL  %s/math_test.aero:6: testNotEqual", "--- 3 passed, 3 failed, 1 errors", "FAILED"}},
+		{map[string]string{"a_test.aero": "fn testA() { assertEqual(1, 1) }", "sub/b_test.aero": "fn testB() { assert(true) }"},
+			Summary{Passed: 2},
+			[]string{"=== %s/a_test.aero", "=== %s/sub/b_test.aero", "--- 2 passed, 0 failed, 0 errors", "\nok\n"}},
+		{map[string]string{"syntax_test.aero": "fn testA() {"},
+			Summary{Errors: 1},
+			[]string{"ERROR %s/syntax_test.aero:0: %s/syntax_test.aero", "e3301", "FAILED"}},
+		{map[string]string{"helper.aero": "fn testA() { assert(false) }"},
+			Summary{},
+			[]string{"no '*_test.aero' files found"}},
+	}
+
+	for i, tt := range tests {
+		dir := writeFiles(t, tt.files)
+		var out bytes.Buffer
+		summary := Run(dir, &out)
+		if summary != tt.summary {
+			t.Errorf("tests[%d]: wrong summary. expected=%+v, got=%+v", i, tt.summary, summary)
+		}
+		//the exit code of 'origion test' is 1 if the summary is not ok
+		if summary.Ok() != (tt.summary.Failed == 0 && tt.summary.Errors == 0) {
+			t.Errorf("tests[%d]: wrong Ok(). got=%t", i, summary.Ok())
+		}
+		for _, expected := range tt.expected {
+			expected = strings.Replace(expected, "%s", dir, -1)
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("tests[%d]: expected %q in the report, got=%q", i, expected, out.String())
+			}
+		}
+		os.RemoveAll(dir)
+	}
+}
@@ -1,6 +1,9 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 type TokenType int
 
@@ -83,6 +86,7 @@ const (
 	ELSE
 	RETURN
 	IMPORT
+	EXPORT
 	STRING
 	ISTRING
 	BYTES
@@ -103,6 +107,7 @@ const (
 	MAP
 	CASE
 	IS
+	PMATCH // match(pattern matching, 'MATCH' is '=~')
 	TRY
 	CATCH
 	FINALLY
@@ -115,7 +120,7 @@ const (
 	UNLESS
 
 	//class related
-	INTERFACE //NOT IMPLEMENTED
+	INTERFACE
 	CLASS
 	NEW
 	PROPERTY
@@ -183,6 +188,7 @@ var keywords = map[string]TokenType{
 	"else":      ELSE,
 	"return":    RETURN,
 	"require":   IMPORT,
+	"export":    EXPORT,
 	"and":       AND,
 	"or":        OR,
 	"struct":    STRUCT,
@@ -197,6 +203,7 @@ var keywords = map[string]TokenType{
 	"map":       MAP,
 	"case":      CASE,
 	"is":        IS,
+	"match":     PMATCH,
 	"try":       TRY,
 	"catch":     CATCH,
 	"finally":   FINALLY,
@@ -381,6 +388,8 @@ func (tt TokenType) String() string {
 		return "RETURN"
 	case IMPORT:
 		return "IMPORT"
+	case EXPORT:
+		return "EXPORT"
 	case STRING:
 		return "STRING"
 	case ISTRING:
@@ -419,6 +428,8 @@ func (tt TokenType) String() string {
 		return "CASE"
 	case IS:
 		return "IS"
+	case PMATCH:
+		return "PMATCH"
 	case TRY:
 		return "TRY"
 	case CATCH:
@@ -551,3 +562,15 @@ func LookupIdent(ident string) TokenType {
 	}
 	return IDENT
 }
+
+//Keywords returns all the keywords recognized by the lexer, in sorted order.
+//Tools like the REPL use it so that highlighting and completion always
+//agree with what the parser accepts.
+func Keywords() []string {
+	ret := make([]string, 0, len(keywords))
+	for k := range keywords {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}